@@ -0,0 +1,276 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/knadh/koanf/v2"
+)
+
+const (
+	// defaultAWSCacheTTL is how long AWS values are reused across loads.
+	defaultAWSCacheTTL = 5 * time.Minute
+	// ssmBatchSize and secretsBatchSize are the API limits of GetParameters
+	// and BatchGetSecretValue.
+	ssmBatchSize     = 10
+	secretsBatchSize = 20
+)
+
+// SSMClient is the part of *ssm.Client used to resolve ssm tags.
+type SSMClient interface {
+	GetParameters(ctx context.Context, params *ssm.GetParametersInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersOutput, error)
+}
+
+// SecretsManagerClient is the part of *secretsmanager.Client used to resolve
+// secretsmanager tags.
+type SecretsManagerClient interface {
+	BatchGetSecretValue(ctx context.Context, params *secretsmanager.BatchGetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.BatchGetSecretValueOutput, error)
+}
+
+// AWSConfig configures NewAWSSecrets.
+type AWSConfig struct {
+	// SSM resolves ssm tags. SecureString parameters are decrypted.
+	SSM SSMClient
+	// SecretsManager resolves secretsmanager tags.
+	SecretsManager SecretsManagerClient
+	// CacheTTL is how long fetched values are reused by later loads, e.g.
+	// Watch reloads. Default: 5m. A negative value disables caching.
+	CacheTTL time.Duration
+}
+
+// AWSSecrets resolves fields tagged with ssm or secretsmanager from AWS
+// Systems Manager Parameter Store and Secrets Manager (see WithAWS). It is
+// safe for concurrent use.
+type AWSSecrets struct {
+	cfg AWSConfig
+
+	mu    sync.Mutex
+	cache map[string]awsCacheEntry
+}
+
+type awsCacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+// NewAWSSecrets returns a resolver using the given clients, typically created
+// with ssm.NewFromConfig and secretsmanager.NewFromConfig.
+func NewAWSSecrets(cfg AWSConfig) *AWSSecrets {
+	if cfg.CacheTTL == 0 {
+		cfg.CacheTTL = defaultAWSCacheTTL
+	}
+	return &AWSSecrets{cfg: cfg, cache: make(map[string]awsCacheEntry)}
+}
+
+// WithAWS resolves fields tagged with an SSM parameter name or a Secrets
+// Manager secret ID using a:
+//
+//	type DatabaseConfig struct {
+//	    URL      string `yaml:"url" ssm:"/app/prod/db_url"`
+//	    Password string `yaml:"password" secretsmanager:"prod/app/db#password"`
+//	}
+//
+// A "#key" suffix selects a key of a JSON secret. Values are fetched in
+// batches, set over the file and remote values, and converted like
+// environment variables; environment variables still override them. Without
+// WithAWS, the tags are ignored.
+func WithAWS(a *AWSSecrets) Option {
+	return func(o *options) {
+		o.aws = a
+	}
+}
+
+// InvalidateCache drops the cached values, so the next load fetches them
+// again.
+func (a *AWSSecrets) InvalidateCache() {
+	a.mu.Lock()
+	a.cache = make(map[string]awsCacheEntry)
+	a.mu.Unlock()
+}
+
+// setAWSValues fetches the values of fields with ssm or secretsmanager tags and
+// sets them in k.
+func setAWSValues(k *koanf.Koanf, metas []fieldMeta, opt options) error {
+	var params, secrets []string
+	for _, meta := range metas {
+		if meta.ssmName != "" {
+			params = append(params, meta.ssmName)
+		}
+		if meta.secretID != "" {
+			id, _, _ := strings.Cut(meta.secretID, "#")
+			secrets = append(secrets, id)
+		}
+	}
+	if len(params) == 0 && len(secrets) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opt.remoteTimeout)
+	defer cancel()
+
+	a := opt.aws
+	paramValues, err := a.fetch(ctx, "ssm:", params, ssmBatchSize, a.getParameters)
+	if err != nil {
+		return err
+	}
+	secretValues, err := a.fetch(ctx, "secretsmanager:", secrets, secretsBatchSize, a.getSecrets)
+	if err != nil {
+		return err
+	}
+
+	for _, meta := range metas {
+		var raw string
+		switch {
+		case meta.ssmName != "":
+			raw = paramValues[meta.ssmName]
+		case meta.secretID != "":
+			id, key, hasKey := strings.Cut(meta.secretID, "#")
+			raw = secretValues[id]
+			if hasKey {
+				if raw, err = jsonSecretKey(raw, key); err != nil {
+					return fmt.Errorf("config: secret %q for %s: %w", id, meta.key, err)
+				}
+			}
+		default:
+			continue
+		}
+
+		value, err := parseEnvValue(meta, raw)
+		if err != nil {
+			return fmt.Errorf("config: aws value for %s: %w", meta.key, err)
+		}
+		if err := k.Set(meta.key, value); err != nil {
+			return fmt.Errorf("config: aws value for %s: %w", meta.key, err)
+		}
+	}
+	return nil
+}
+
+// fetch returns the values of names, taking cached ones from the cache and
+// fetching the others in batches of size.
+func (a *AWSSecrets) fetch(ctx context.Context, kind string, names []string, size int, get func(context.Context, []string) (map[string]string, error)) (map[string]string, error) {
+	values := make(map[string]string, len(names))
+	var missing []string
+	seen := make(map[string]bool, len(names))
+
+	now := time.Now()
+	a.mu.Lock()
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if entry, ok := a.cache[kind+name]; ok && now.Before(entry.expires) {
+			values[name] = entry.value
+			continue
+		}
+		missing = append(missing, name)
+	}
+	a.mu.Unlock()
+
+	for start := 0; start < len(missing); start += size {
+		batch := missing[start:min(start+size, len(missing))]
+		fetched, err := get(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		a.mu.Lock()
+		for name, value := range fetched {
+			values[name] = value
+			if a.cfg.CacheTTL > 0 {
+				a.cache[kind+name] = awsCacheEntry{value: value, expires: now.Add(a.cfg.CacheTTL)}
+			}
+		}
+		a.mu.Unlock()
+	}
+	return values, nil
+}
+
+func (a *AWSSecrets) getParameters(ctx context.Context, names []string) (map[string]string, error) {
+	if a.cfg.SSM == nil {
+		return nil, fmt.Errorf("config: ssm tags require AWSConfig.SSM")
+	}
+	out, err := a.cfg.SSM.GetParameters(ctx, &ssm.GetParametersInput{
+		Names:          names,
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("config: ssm get parameters: %w", err)
+	}
+	if len(out.InvalidParameters) > 0 {
+		return nil, fmt.Errorf("config: ssm parameters not found: %s", strings.Join(out.InvalidParameters, ", "))
+	}
+
+	values := make(map[string]string, len(out.Parameters))
+	for _, p := range out.Parameters {
+		values[aws.ToString(p.Name)] = aws.ToString(p.Value)
+	}
+	return values, nil
+}
+
+func (a *AWSSecrets) getSecrets(ctx context.Context, ids []string) (map[string]string, error) {
+	if a.cfg.SecretsManager == nil {
+		return nil, fmt.Errorf("config: secretsmanager tags require AWSConfig.SecretsManager")
+	}
+	out, err := a.cfg.SecretsManager.BatchGetSecretValue(ctx, &secretsmanager.BatchGetSecretValueInput{
+		SecretIdList: ids,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("config: secretsmanager get secrets: %w", err)
+	}
+	if len(out.Errors) > 0 {
+		failed := make([]string, len(out.Errors))
+		for i, e := range out.Errors {
+			failed[i] = fmt.Sprintf("%s: %s", aws.ToString(e.SecretId), aws.ToString(e.ErrorCode))
+		}
+		return nil, fmt.Errorf("config: secretsmanager get secrets: %s", strings.Join(failed, "; "))
+	}
+
+	// Secrets can be requested by name or ARN.
+	byID := make(map[string]string, 2*len(out.SecretValues))
+	for _, s := range out.SecretValues {
+		value := aws.ToString(s.SecretString)
+		if s.SecretString == nil {
+			value = string(s.SecretBinary)
+		}
+		byID[aws.ToString(s.Name)] = value
+		byID[aws.ToString(s.ARN)] = value
+	}
+	values := make(map[string]string, len(ids))
+	for _, id := range ids {
+		value, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("config: secretsmanager secret %q not returned", id)
+		}
+		values[id] = value
+	}
+	return values, nil
+}
+
+// jsonSecretKey returns the value of key in a JSON object secret. Non-string
+// values are returned as JSON.
+func jsonSecretKey(secret, key string) (string, error) {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("not a JSON object: %w", err)
+	}
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("no key %q", key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -12,18 +12,33 @@ import (
 )
 
 // Load reads the config file into target and optionally overrides values using
-// environment variables. The target must be a pointer to a struct.
+// environment variables. The target must be a pointer to a struct. Values from
+// remote providers (see WithRemote) are merged over the file and beneath the
+// environment. Defaults are set by SetDefaults methods (see Defaulter) before
+// the file and environment values are applied, and by envDefault tags for
+// fields that are still zero afterwards. Required fields that are still zero
+// are reported together in a *MissingFieldsError.
 func Load(path string, target any, opts ...Option) error {
+	return LoadInto(path, "", target, opts...)
+}
+
+// LoadInto is like Load but decodes only the section at key (e.g. "database")
+// into target. Environment variables keep their full path, so a field URL of
+// the "database" section is overridden by DATABASE_URL. To decode several
+// sections from a single read, use Open.
+func LoadInto(path, key string, target any, opts ...Option) error {
 	if target == nil {
 		return fmt.Errorf("config: target cannot be nil")
 	}
-
-	o := defaultOptions()
-	for _, opt := range opts {
-		opt(&o)
+	v, err := Open(path, opts...)
+	if err != nil {
+		return err
 	}
+	return v.Unmarshal(key, target)
+}
 
-	data, err := o.fileReader(path)
+func loadFile(k *koanf.Koanf, path string, o options) error {
+	data, err := readConfig(path, o)
 	if err != nil {
 		return fmt.Errorf("config: read %q: %w", path, err)
 	}
@@ -33,41 +48,25 @@ func Load(path string, target any, opts ...Option) error {
 		return err
 	}
 
-	k := koanf.New(".")
 	parser, err := parserFor(format)
 	if err != nil {
 		return err
 	}
 
-	if err := k.Load(rawbytes.Provider(data), parser); err != nil {
-		return fmt.Errorf("config: parse %q: %w", path, err)
-	}
-
-	metas, err := prepareFieldMeta(target, o)
+	data, err = decrypt(data, format, o.decryptors)
 	if err != nil {
-		return err
+		return fmt.Errorf("config: decrypt %q: %w", path, err)
 	}
 
-	if o.envEnabled {
-		if err := mergeEnv(k, metas, o); err != nil {
-			return err
-		}
-	}
-
-	if err := k.Unmarshal("", target); err != nil {
-		return fmt.Errorf("config: unmarshal: %w", err)
-	}
-
-	if err := applyDefaults(target, metas); err != nil {
-		return err
+	if err := k.Load(rawbytes.Provider(data), parser); err != nil {
+		return fmt.Errorf("config: parse %q: %w", path, err)
 	}
-
 	return nil
 }
 
 func resolveFormat(path string, forced Format) (Format, error) {
 	switch forced {
-	case FormatJSON, FormatYAML:
+	case FormatJSON, FormatYAML, FormatHCL:
 		return forced, nil
 	case FormatAuto:
 	default:
@@ -76,12 +75,19 @@ func resolveFormat(path string, forced Format) (Format, error) {
 		}
 	}
 
-	ext := strings.ToLower(filepath.Ext(path))
+	name := formatPath(path)
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == ".age" {
+		// config.yaml.age is an age-encrypted config.yaml (see AgeDecryptor).
+		ext = strings.ToLower(filepath.Ext(strings.TrimSuffix(name, filepath.Ext(name))))
+	}
 	switch ext {
 	case ".yaml", ".yml":
 		return FormatYAML, nil
 	case ".json":
 		return FormatJSON, nil
+	case ".hcl":
+		return FormatHCL, nil
 	default:
 		return "", fmt.Errorf("config: could not detect config format from %q", path)
 	}
@@ -93,6 +99,8 @@ func parserFor(format Format) (koanf.Parser, error) {
 		return json.Parser(), nil
 	case FormatYAML:
 		return yaml.Parser(), nil
+	case FormatHCL:
+		return hclParser{}, nil
 	default:
 		return nil, fmt.Errorf("config: unsupported format %q", format)
 	}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ConsulConfig configures ConsulProvider.
+type ConsulConfig struct {
+	// Address is the Consul HTTP address. Default: http://127.0.0.1:8500.
+	Address string
+	// Prefix is the KV prefix holding the configuration, e.g.
+	// "service/app/". Keys below it map to config keys with "/" as the
+	// separator, so "service/app/server/port" sets server.port. With Format,
+	// Prefix is instead a single key holding the whole document.
+	Prefix string
+	// Format parses the value of the Prefix key as a YAML, JSON or HCL
+	// document instead of reading one key per value.
+	Format Format
+	// Token is the ACL token sent as X-Consul-Token.
+	Token string
+	// Datacenter selects the datacenter to query. Default: the agent's.
+	Datacenter string
+	// HTTPClient sends the requests. Default: http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// ConsulProvider returns a RemoteProvider reading Consul KV through the HTTP
+// API. It implements RemoteWatcher with blocking queries, so Watch reloads as
+// soon as a key changes.
+func ConsulProvider(cfg ConsulConfig) RemoteProvider {
+	if cfg.Address == "" {
+		cfg.Address = "http://127.0.0.1:8500"
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	return &consulProvider{cfg: cfg}
+}
+
+type consulProvider struct {
+	cfg ConsulConfig
+}
+
+type consulPair struct {
+	Key   string
+	Value []byte
+}
+
+func (p *consulProvider) Read(ctx context.Context) (map[string]any, error) {
+	values, _, err := p.read(ctx, "")
+	return values, err
+}
+
+func (p *consulProvider) Watch(ctx context.Context, notify func(error)) error {
+	var index string
+	for {
+		_, next, err := p.read(ctx, index)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			notify(err)
+			if !sleepContext(ctx, remoteRetryDelay) {
+				return nil
+			}
+			continue
+		}
+		if index != "" && next != index {
+			notify(nil)
+		}
+		index = next
+	}
+}
+
+// read fetches the prefix. A non-empty index makes it a blocking query that
+// returns once the data changes past index. It returns the new index.
+func (p *consulProvider) read(ctx context.Context, index string) (map[string]any, string, error) {
+	query := url.Values{}
+	if p.cfg.Format == "" {
+		query.Set("recurse", "true")
+	}
+	if p.cfg.Datacenter != "" {
+		query.Set("dc", p.cfg.Datacenter)
+	}
+	if index != "" {
+		query.Set("index", index)
+		query.Set("wait", "5m")
+	}
+	endpoint := strings.TrimSuffix(p.cfg.Address, "/") + "/v1/kv/" + strings.TrimPrefix(p.cfg.Prefix, "/") + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("consul: %w", err)
+	}
+	if p.cfg.Token != "" {
+		req.Header.Set("X-Consul-Token", p.cfg.Token)
+	}
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("consul: %w", err)
+	}
+	defer resp.Body.Close()
+
+	next := resp.Header.Get("X-Consul-Index")
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return map[string]any{}, next, nil
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, "", fmt.Errorf("consul: read %q: %s: %s", p.cfg.Prefix, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var pairs []consulPair
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, "", fmt.Errorf("consul: decode %q: %w", p.cfg.Prefix, err)
+	}
+
+	if p.cfg.Format != "" {
+		if len(pairs) == 0 {
+			return map[string]any{}, next, nil
+		}
+		values, err := parseRemoteDocument(pairs[0].Value, p.cfg.Format)
+		if err != nil {
+			return nil, "", fmt.Errorf("consul: parse %q: %w", p.cfg.Prefix, err)
+		}
+		return values, next, nil
+	}
+
+	kv := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		if pair.Value != nil {
+			kv[pair.Key] = string(pair.Value)
+		}
+	}
+	return remoteValues(strings.TrimPrefix(p.cfg.Prefix, "/"), kv), next, nil
+}
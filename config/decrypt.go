@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// Decryptor decrypts the contents of a config file before it is parsed.
+// format is the format resolved for the file. Decryptors must return data
+// unchanged when it is not encrypted for them, so several can be combined.
+type Decryptor func(data []byte, format Format) ([]byte, error)
+
+// WithDecryptor adds a decryptor that runs on the config file before it is
+// parsed, e.g. SOPSDecryptor or AgeDecryptor. Decryptors run in the order
+// they were added. Remote sources are not decrypted.
+func WithDecryptor(d Decryptor) Option {
+	return func(o *options) {
+		if d != nil {
+			o.decryptors = append(o.decryptors, d)
+		}
+	}
+}
+
+func decrypt(data []byte, format Format, decryptors []Decryptor) ([]byte, error) {
+	for _, d := range decryptors {
+		var err error
+		if data, err = d(data, format); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// IsSOPS reports whether data is a SOPS-encrypted YAML or JSON document, i.e.
+// one with a top-level "sops" map holding the "mac" of the values.
+func IsSOPS(data []byte, format Format) bool {
+	if format != FormatYAML && format != FormatJSON {
+		return false
+	}
+	parser, err := parserFor(format)
+	if err != nil {
+		return false
+	}
+	doc, err := parser.Unmarshal(data)
+	if err != nil {
+		return false
+	}
+	metadata, ok := doc["sops"].(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = metadata["mac"]
+	return ok
+}
+
+// SOPSDecryptor returns a Decryptor for SOPS-encrypted YAML and JSON files
+// (see IsSOPS) that runs "sops --decrypt" with the file on stdin. binary
+// defaults to "sops" looked up in PATH. The key material is configured like
+// for the sops CLI, e.g. with SOPS_AGE_KEY_FILE or the cloud KMS credentials
+// of the environment, and the MAC of the file is verified by sops.
+func SOPSDecryptor(binary string) Decryptor {
+	if binary == "" {
+		binary = "sops"
+	}
+	return func(data []byte, format Format) ([]byte, error) {
+		if !IsSOPS(data, format) {
+			return data, nil
+		}
+
+		cmd := exec.Command(binary, "--decrypt",
+			"--input-type", string(format),
+			"--output-type", string(format),
+			"/dev/stdin")
+		cmd.Stdin = bytes.NewReader(data)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("config: sops decrypt: %w: %s", err, msg)
+			}
+			return nil, fmt.Errorf("config: sops decrypt: %w", err)
+		}
+		return out, nil
+	}
+}
+
+const ageHeader = "age-encryption.org/v1\n"
+
+// AgeDecryptor returns a Decryptor for files encrypted as a whole with age,
+// in binary or armored (-----BEGIN AGE ENCRYPTED FILE-----) form, e.g. with
+// "age -R recipients.txt -o config.yaml.age config.yaml". A ".age" suffix is
+// ignored when the format is detected from the file name. Identities can be
+// parsed from a key file with age.ParseIdentities.
+func AgeDecryptor(identities ...age.Identity) Decryptor {
+	return func(data []byte, _ Format) ([]byte, error) {
+		var src io.Reader
+		switch trimmed := bytes.TrimLeft(data, " \t\r\n"); {
+		case bytes.HasPrefix(data, []byte(ageHeader)):
+			src = bytes.NewReader(data)
+		case bytes.HasPrefix(trimmed, []byte(armor.Header)):
+			src = armor.NewReader(bytes.NewReader(trimmed))
+		default:
+			return data, nil
+		}
+
+		r, err := age.Decrypt(src, identities...)
+		if err != nil {
+			return nil, fmt.Errorf("config: age decrypt: %w", err)
+		}
+		out, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("config: age decrypt: %w", err)
+		}
+		return out, nil
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import "reflect"
+
+// Defaulter is implemented by config structs that set their own defaults.
+// Load calls SetDefaults on the target and on its nested structs before the
+// file and environment values are applied, so it is the place for defaults
+// that envDefault tags cannot express: nested structs, slices, maps or
+// computed values.
+//
+//	func (c *DatabaseConfig) SetDefaults() {
+//	    c.Pool = PoolConfig{MaxConns: runtime.NumCPU() * 4, IdleTimeout: 5 * time.Minute}
+//	    c.Replicas = []string{"localhost:5432"}
+//	}
+//
+// Nested structs are defaulted before the structs containing them, so an
+// outer SetDefaults can override the defaults of an inner one. Nil pointers
+// to structs are skipped.
+type Defaulter interface {
+	SetDefaults()
+}
+
+var defaulterType = reflect.TypeOf((*Defaulter)(nil)).Elem()
+
+// callSetDefaults calls SetDefaults on the addressable struct v and its nested
+// structs, innermost first.
+func callSetDefaults(v reflect.Value) {
+	if v.Type() == timeType {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		field := v.Field(i)
+		switch {
+		case field.Kind() == reflect.Struct:
+			callSetDefaults(field)
+		case field.Kind() == reflect.Pointer && !field.IsNil() && field.Elem().Kind() == reflect.Struct:
+			callSetDefaults(field.Elem())
+		}
+	}
+	if v.Addr().Type().Implements(defaulterType) {
+		v.Addr().Interface().(Defaulter).SetDefaults()
+	}
+}
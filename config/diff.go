@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change is a config key whose value differs between two configurations (see
+// Diff). Secret values are masked as in Dump.
+type Change struct {
+	Key string
+	// Old and New are the values, nil when the key is absent on that side.
+	Old, New any
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Key, c.Old, c.New)
+}
+
+// Diff returns the keys that differ between old and new, two values of the
+// same config struct, sorted by key. Values are masked like Dump, with the
+// same options, so the changes can be logged safely; a changed secret is
+// reported with masked values. Use it in a Watch onChange callback to audit
+// or react to the settings that actually changed:
+//
+//	config.Watch(path, &cfg, func(old, new AppConfig) error {
+//	    for _, c := range config.Diff(old, new) {
+//	        log.Info("config changed", "key", c.Key, "old", c.Old, "new", c.New)
+//	    }
+//	    return nil
+//	})
+func Diff(old, new any, opts ...DumpOption) []Change {
+	o := dumpOptions{maskTags: []string{"secret"}, maskNames: defaultMaskNames}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	// Compare the unmasked values and report the masked ones.
+	rawOld, rawNew := flattenDump(old, dumpOptions{}), flattenDump(new, dumpOptions{})
+	maskedOld, maskedNew := flattenDump(old, o), flattenDump(new, o)
+
+	keys := make(map[string]bool, len(rawNew))
+	for key := range rawOld {
+		keys[key] = true
+	}
+	for key := range rawNew {
+		keys[key] = true
+	}
+
+	var changes []Change
+	for key := range keys {
+		oldValue, inOld := rawOld[key]
+		newValue, inNew := rawNew[key]
+		if inOld == inNew && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, Change{Key: key, Old: maskedOld[key], New: maskedNew[key]})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// flattenDump returns the leaves of the dumped target keyed by their dotted
+// path. Lists are leaves.
+func flattenDump(target any, o dumpOptions) map[string]any {
+	out := make(map[string]any)
+	val := reflect.ValueOf(target)
+	if !val.IsValid() {
+		return out
+	}
+	var walk func(prefix []string, value any)
+	walk = func(prefix []string, value any) {
+		m, ok := value.(map[string]any)
+		if !ok || (len(m) == 0 && len(prefix) > 0) {
+			out[strings.Join(prefix, ".")] = value
+			return
+		}
+		for key, v := range m {
+			walk(withPath(prefix, key), v)
+		}
+	}
+	walk(nil, dumpValue(val, o))
+	return out
+}
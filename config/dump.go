@@ -0,0 +1,201 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf/parsers/yaml"
+)
+
+// maskedValue replaces secret values in Dump output.
+const maskedValue = "******"
+
+// defaultMaskNames are the words that mark a field as secret when its key ends
+// with one of them, e.g. "password", "db_password" or "apiKey".
+var defaultMaskNames = []string{"password", "passwd", "secret", "token", "key", "keys", "credentials", "passphrase"}
+
+type dumpOptions struct {
+	format    Format
+	maskTags  []string
+	maskNames []string
+	// timeFormat is the timeFormat tag of the field being dumped.
+	timeFormat string
+}
+
+// DumpOption configures Dump.
+type DumpOption func(*dumpOptions)
+
+// WithDumpFormat selects the output format, FormatYAML (the default) or
+// FormatJSON.
+func WithDumpFormat(format Format) DumpOption {
+	return func(o *dumpOptions) {
+		if format != FormatAuto {
+			o.format = format
+		}
+	}
+}
+
+// WithMaskTags sets the struct tags marking secret fields when set to "true",
+// e.g. `secret:"true"`. Default: "secret".
+func WithMaskTags(tags ...string) DumpOption {
+	return func(o *dumpOptions) {
+		o.maskTags = tags
+	}
+}
+
+// WithMaskNames sets the words that mark a field as secret when its key ends
+// with one of them, ignoring case and separators. Default: password, passwd,
+// secret, token, key, keys, credentials and passphrase.
+func WithMaskNames(names ...string) DumpOption {
+	return func(o *dumpOptions) {
+		o.maskNames = names
+	}
+}
+
+// Dump renders the configuration in target, typically after Load, with the
+// same keys as the config file and secrets masked, for startup logs and debug
+// endpoints:
+//
+//	out, err := config.Dump(&cfg)
+//	log.Info("effective config\n" + string(out))
+//
+// A field is masked when it has a mask tag (see WithMaskTags), a vault, ssm or
+// secretsmanager tag, or a key ending with a mask name (see WithMaskNames),
+// such as password or api_key. Zero values are not masked, so missing secrets
+// stay visible. Passwords in URLs, e.g. postgres://app:s3cret@db/app, are
+// masked in every string value.
+func Dump(target any, opts ...DumpOption) ([]byte, error) {
+	o := dumpOptions{
+		format:    FormatYAML,
+		maskTags:  []string{"secret"},
+		maskNames: defaultMaskNames,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	val := reflect.ValueOf(target)
+	for val.Kind() == reflect.Pointer && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: dump target must be a struct or a pointer to one (got %T)", target)
+	}
+	values, _ := dumpValue(val, o).(map[string]any)
+
+	switch o.format {
+	case FormatYAML:
+		return yaml.Parser().Marshal(values)
+	case FormatJSON:
+		return json.MarshalIndent(values, "", "  ")
+	default:
+		return nil, fmt.Errorf("config: unsupported dump format %q", o.format)
+	}
+}
+
+// dumpValue converts v to plain maps, lists and scalars.
+func dumpValue(v reflect.Value, o dumpOptions) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == timeType:
+		return formatTime(v.Interface().(time.Time), o.timeFormat)
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		out := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			key := baseFieldName(field)
+			if key == "" {
+				continue
+			}
+			fv := v.Field(i)
+			if o.secret(field, key) && !fv.IsZero() {
+				out[key] = maskedValue
+				continue
+			}
+			fo := o
+			fo.timeFormat = field.Tag.Get("timeFormat")
+			out[key] = dumpValue(fv, fo)
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = dumpValue(iter.Value(), o)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return maskURLPassword(string(v.Bytes()))
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = dumpValue(v.Index(i), o)
+		}
+		return out
+	case reflect.String:
+		return maskURLPassword(v.String())
+	default:
+		return v.Interface()
+	}
+}
+
+// secret reports whether the field named key holds a secret.
+func (o dumpOptions) secret(field reflect.StructField, key string) bool {
+	for _, tag := range o.maskTags {
+		if field.Tag.Get(tag) == "true" {
+			return true
+		}
+	}
+	for _, tag := range []string{"vault", "ssm", "secretsmanager"} {
+		if field.Tag.Get(tag) != "" {
+			return true
+		}
+	}
+
+	words := strings.Split(strings.ToLower(toScreamingSnake(key)), "_")
+	last := words[len(words)-1]
+	for _, name := range o.maskNames {
+		if strings.EqualFold(last, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskURLPassword masks the password of s when it is a URL with one.
+func maskURLPassword(s string) string {
+	if !strings.Contains(s, "://") || !strings.Contains(s, "@") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	if _, ok := u.User.Password(); !ok {
+		return s
+	}
+	// url.URL escapes "*", so set a placeholder and replace it afterwards.
+	u.User = url.UserPassword(u.User.Username(), "x")
+	return strings.Replace(u.String(), ":x@", ":"+maskedValue+"@", 1)
+}
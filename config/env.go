@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -23,13 +24,25 @@ var (
 type fieldMeta struct {
 	key          string
 	envVar       string
+	envAliases   []string
 	separator    string
+	timeFormat   string
 	fieldType    reflect.Type
 	defaultValue string
+	vaultRef     string
+	ssmName      string
+	secretID     string
+	required     bool
 	index        []int
 }
 
 func prepareFieldMeta(target any, opt options) ([]fieldMeta, error) {
+	return prepareFieldMetaAt(target, nil, opt)
+}
+
+// prepareFieldMetaAt collects the field metadata of target decoded at the key
+// path prefix, so keys and inferred environment names use the full path.
+func prepareFieldMetaAt(target any, prefix []string, opt options) ([]fieldMeta, error) {
 	val := reflect.ValueOf(target)
 	if val.Kind() != reflect.Pointer || val.IsNil() {
 		return nil, fmt.Errorf("config: target must be a non-nil pointer")
@@ -40,7 +53,7 @@ func prepareFieldMeta(target any, opt options) ([]fieldMeta, error) {
 	}
 
 	var metas []fieldMeta
-	collectFieldMeta(elem.Type(), nil, nil, opt, &metas)
+	collectFieldMeta(elem.Type(), prefix, nil, opt, &metas)
 	return metas, nil
 }
 
@@ -80,16 +93,22 @@ func collectFieldMeta(typ reflect.Type, path []string, indexPrefix []int, opt op
 		}
 
 		meta := fieldMeta{
-			key:       strings.Join(currentPath, "."),
-			envVar:    buildEnvKey(currentPath, fieldInfo, opt.envPrefix),
-			separator: sep,
-			fieldType: fieldInfo.Type,
-			index:     indexPath,
+			key:        strings.Join(currentPath, "."),
+			envVar:     buildEnvKey(currentPath, fieldInfo, opt.envPrefix),
+			envAliases: envAliases(fieldInfo),
+			separator:  sep,
+			timeFormat: fieldInfo.Tag.Get("timeFormat"),
+			fieldType:  fieldInfo.Type,
+			index:      indexPath,
 		}
 
 		if def := fieldInfo.Tag.Get("envDefault"); def != "" {
 			meta.defaultValue = def
 		}
+		meta.required = fieldInfo.Tag.Get("required") == "true" || hasTagOption(fieldInfo.Tag.Get("env"), "required")
+		meta.vaultRef = fieldInfo.Tag.Get("vault")
+		meta.ssmName = fieldInfo.Tag.Get("ssm")
+		meta.secretID = fieldInfo.Tag.Get("secretsmanager")
 
 		*metas = append(*metas, meta)
 	}
@@ -102,16 +121,23 @@ func mergeEnv(k *koanf.Koanf, metas []fieldMeta, opt options) error {
 		if meta.envVar == "" {
 			continue
 		}
-		raw, ok := opt.envLookup(meta.envVar)
-		if !ok {
-			continue
-		}
+		// The primary name wins over the aliases, which are tried in order.
+		for _, name := range append([]string{meta.envVar}, meta.envAliases...) {
+			raw, ok, err := lookupEnv(name, opt)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
 
-		value, err := parseEnvValue(meta, raw)
-		if err != nil {
-			return fmt.Errorf("config: override %s: %w", meta.envVar, err)
+			value, err := parseEnvValue(meta, raw)
+			if err != nil {
+				return fmt.Errorf("config: override %s: %w", name, err)
+			}
+			overrides[meta.key] = value
+			break
 		}
-		overrides[meta.key] = value
 	}
 
 	if len(overrides) == 0 {
@@ -124,9 +150,34 @@ func mergeEnv(k *koanf.Koanf, metas []fieldMeta, opt options) error {
 	return nil
 }
 
+// fileEnvSuffix marks environment variables naming a file that holds the
+// value, the Docker and Kubernetes convention for secrets
+// (DATABASE_PASSWORD_FILE=/run/secrets/db).
+const fileEnvSuffix = "_FILE"
+
+// lookupEnv returns the value of the environment variable name or, when only
+// name+"_FILE" is set, the trimmed contents of the file it points to. Setting
+// both is an error, as the intended source is ambiguous.
+func lookupEnv(name string, opt options) (string, bool, error) {
+	raw, ok := opt.envLookup(name)
+	path, fileOK := opt.envLookup(name + fileEnvSuffix)
+	switch {
+	case !fileOK:
+		return raw, ok, nil
+	case ok:
+		return "", false, fmt.Errorf("config: both %s and %s%s are set", name, name, fileEnvSuffix)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("config: read %s%s: %w", name, fileEnvSuffix, err)
+	}
+	return strings.TrimSpace(string(data)), true, nil
+}
+
 func parseEnvValue(meta fieldMeta, raw string) (any, error) {
 	holder := reflect.New(meta.fieldType).Elem()
-	if err := setFieldValue(holder, raw, meta.separator); err != nil {
+	if err := setFieldValue(holder, raw, meta.separator, meta.timeFormat); err != nil {
 		return nil, err
 	}
 	return holder.Interface(), nil
@@ -155,7 +206,7 @@ func applyDefaults(target any, metas []fieldMeta) error {
 			continue
 		}
 
-		if err := setFieldValue(field, meta.defaultValue, meta.separator); err != nil {
+		if err := setFieldValue(field, meta.defaultValue, meta.separator, meta.timeFormat); err != nil {
 			return fmt.Errorf("config: apply default for %s: %w", meta.key, err)
 		}
 	}
@@ -171,15 +222,26 @@ func shouldDescend(t reflect.Type) bool {
 func isSupportedLeaf(t reflect.Type) bool {
 	base := derefType(t)
 	switch base.Kind() {
+	case reflect.Slice:
+		return isScalarLeaf(base.Elem())
+	case reflect.Map:
+		return base.Key().Kind() == reflect.String && isScalarLeaf(base.Elem())
+	default:
+		return isScalarLeaf(base)
+	}
+}
+
+// isScalarLeaf reports whether t is parsed from a single value: a string,
+// bool, number, time.Duration or time.Time.
+func isScalarLeaf(t reflect.Type) bool {
+	switch t.Kind() {
 	case reflect.String, reflect.Bool,
 		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
 		reflect.Float32, reflect.Float64:
 		return true
-	case reflect.Slice:
-		return base.Elem().Kind() == reflect.String
 	case reflect.Struct:
-		return base == timeType
+		return t == timeType
 	default:
 		return false
 	}
@@ -214,8 +276,11 @@ func withPath(path []string, elem string) []string {
 	return cp
 }
 
+// nameTags are the struct tags that name a field's config key, by precedence.
+var nameTags = []string{"mapstructure", "yaml", "json", "hcl"}
+
 func baseFieldName(field reflect.StructField) string {
-	for _, key := range []string{"mapstructure", "yaml", "json"} {
+	for _, key := range nameTags {
 		if tag := cleanTag(field.Tag.Get(key)); tag != "" {
 			return tag
 		}
@@ -223,6 +288,37 @@ func baseFieldName(field reflect.StructField) string {
 	return field.Name
 }
 
+// unmarshalTag returns the first of nameTags used by typ or its nested structs,
+// so file keys are matched by the same names as the environment overrides.
+// Structs without any of them are matched by field name ("koanf").
+func unmarshalTag(typ reflect.Type) string {
+	seen := make(map[reflect.Type]bool)
+	var found func(t reflect.Type) string
+	found = func(t reflect.Type) string {
+		t = derefType(t)
+		if t.Kind() != reflect.Struct || t == timeType || seen[t] {
+			return ""
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			for _, key := range nameTags {
+				if _, ok := field.Tag.Lookup(key); ok {
+					return key
+				}
+			}
+			if tag := found(field.Type); tag != "" {
+				return tag
+			}
+		}
+		return ""
+	}
+	if tag := found(typ); tag != "" {
+		return tag
+	}
+	return "koanf"
+}
+
 func cleanTag(tag string) string {
 	if tag == "" {
 		return ""
@@ -237,8 +333,44 @@ func cleanTag(tag string) string {
 	return tag
 }
 
+// envTagOptions are the env tag entries after the name that are options
+// rather than alias names.
+var envTagOptions = map[string]bool{"required": true}
+
+// envAliases returns the alternative variable names listed in the env tag
+// after the primary name, e.g. DB_URL and POSTGRES_URL for
+// env:"DATABASE_URL,DB_URL,POSTGRES_URL".
+func envAliases(field reflect.StructField) []string {
+	tag := field.Tag.Get("env")
+	if tag == "-" {
+		return nil
+	}
+	_, rest, _ := strings.Cut(tag, ",")
+	var aliases []string
+	for _, name := range strings.Split(rest, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && !envTagOptions[name] {
+			aliases = append(aliases, name)
+		}
+	}
+	return aliases
+}
+
+// hasTagOption reports whether the comma-separated options of tag, after the
+// name, include option.
+func hasTagOption(tag, option string) bool {
+	_, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+	return false
+}
+
 func buildEnvKey(path []string, field reflect.StructField, prefix string) string {
-	envTag := field.Tag.Get("env")
+	envTag, _, _ := strings.Cut(field.Tag.Get("env"), ",")
+	envTag = strings.TrimSpace(envTag)
 	if envTag == "-" {
 		return ""
 	}
@@ -274,7 +406,9 @@ func buildEnvKey(path []string, field reflect.StructField, prefix string) string
 	return key
 }
 
-func setFieldValue(value reflect.Value, raw, sliceSep string) error {
+// setFieldValue parses raw into value. Slices and maps are split on sliceSep
+// and time.Time values are parsed with timeFormat (see parseTime).
+func setFieldValue(value reflect.Value, raw, sliceSep, timeFormat string) error {
 	if !value.CanSet() {
 		return fmt.Errorf("field cannot be set")
 	}
@@ -283,7 +417,7 @@ func setFieldValue(value reflect.Value, raw, sliceSep string) error {
 		if value.IsNil() {
 			value.Set(reflect.New(value.Type().Elem()))
 		}
-		return setFieldValue(value.Elem(), raw, sliceSep)
+		return setFieldValue(value.Elem(), raw, sliceSep, timeFormat)
 	}
 
 	switch value.Kind() {
@@ -327,14 +461,47 @@ func setFieldValue(value reflect.Value, raw, sliceSep string) error {
 		value.SetFloat(parsed)
 		return nil
 	case reflect.Slice:
-		if value.Type().Elem().Kind() == reflect.String {
-			value.Set(reflect.ValueOf(splitAndTrim(raw, sliceSep)))
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			value.SetBytes([]byte(raw))
 			return nil
 		}
-		return fmt.Errorf("unsupported slice type %s", value.Type())
+		if !isScalarLeaf(value.Type().Elem()) {
+			return fmt.Errorf("unsupported slice type %s", value.Type())
+		}
+		parts := splitAndTrim(raw, sliceSep)
+		slice := reflect.MakeSlice(value.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setFieldValue(slice.Index(i), part, sliceSep, timeFormat); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		value.Set(slice)
+		return nil
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String || !isScalarLeaf(value.Type().Elem()) {
+			return fmt.Errorf("unsupported map type %s", value.Type())
+		}
+		// Maps are written as key=value pairs: "team=core,tier=web".
+		m := reflect.MakeMap(value.Type())
+		for _, pair := range splitAndTrim(raw, sliceSep) {
+			if pair == "" {
+				continue
+			}
+			key, elem, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("map entry %q is not key=value", pair)
+			}
+			v := reflect.New(value.Type().Elem()).Elem()
+			if err := setFieldValue(v, strings.TrimSpace(elem), sliceSep, timeFormat); err != nil {
+				return fmt.Errorf("map entry %q: %w", key, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)).Convert(value.Type().Key()), v)
+		}
+		value.Set(m)
+		return nil
 	case reflect.Struct:
 		if value.Type() == timeType {
-			t, err := time.Parse(time.RFC3339, raw)
+			t, err := parseTime(raw, timeFormat)
 			if err != nil {
 				return err
 			}
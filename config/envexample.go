@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvExample generates a .env.example file listing every environment variable
+// Load reads for target's type, so the file is generated from the code
+// instead of maintained by hand. Pass the Load options that affect variable
+// names, such as WithEnvPrefix:
+//
+//	# server.port (int)
+//	APP_SERVER_PORT=8080
+//
+// Each variable is preceded by its config key and type and set to its default,
+// from SetDefaults methods (see Defaulter), envDefault tags or the values
+// already in target. Variables without a default are left empty.
+func EnvExample(target any, opts ...Option) ([]byte, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	typ := reflect.TypeOf(target)
+	if typ == nil || derefType(typ).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: env example target must be a struct or a pointer to one (got %T)", target)
+	}
+	val := reflect.New(derefType(typ))
+	if src := reflect.ValueOf(target); src.Kind() != reflect.Pointer || !src.IsNil() {
+		val.Elem().Set(reflect.Indirect(src))
+	}
+	metas, err := prepareFieldMeta(val.Interface(), o)
+	if err != nil {
+		return nil, err
+	}
+	callSetDefaults(val.Elem())
+
+	var b strings.Builder
+	for _, meta := range metas {
+		if meta.envVar == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		typeName := envTypeName(meta)
+		if meta.required {
+			typeName += ", required"
+		}
+		fmt.Fprintf(&b, "# %s (%s)\n", meta.key, typeName)
+		if len(meta.envAliases) > 0 {
+			fmt.Fprintf(&b, "# aliases: %s\n", strings.Join(meta.envAliases, ", "))
+		}
+		fmt.Fprintf(&b, "%s=%s\n", meta.envVar, envExampleValue(val.Elem(), meta))
+	}
+	return []byte(b.String()), nil
+}
+
+// envTypeName describes the type of a variable, including how lists and maps
+// are written.
+func envTypeName(meta fieldMeta) string {
+	t := derefType(meta.fieldType)
+	switch {
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		return fmt.Sprintf("%s, separated by %q", t, meta.separator)
+	case t.Kind() == reflect.Map:
+		return fmt.Sprintf("%s, key=value pairs separated by %q", t, meta.separator)
+	case t == timeType:
+		return "time.Time, " + timeFormatName(meta.timeFormat)
+	default:
+		return t.String()
+	}
+}
+
+// envExampleValue returns the default of the field described by meta, quoted
+// when it contains spaces or #.
+func envExampleValue(root reflect.Value, meta fieldMeta) string {
+	field, ok := fieldByIndex(root, meta.index)
+	value := ""
+	if ok && !field.IsZero() {
+		value = formatEnvValue(field, meta.separator, meta.timeFormat)
+	} else if meta.defaultValue != "" {
+		value = meta.defaultValue
+	}
+	if strings.ContainsAny(value, " #\"'") {
+		value = strconv.Quote(value)
+	}
+	return value
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex without panicking on nil
+// embedded or nested pointers.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			for v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					return reflect.Value{}, false
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// formatEnvValue formats v the way setFieldValue parses it.
+func formatEnvValue(v reflect.Value, sep, timeFormat string) string {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Type() == timeType:
+		return formatTime(v.Interface().(time.Time), timeFormat)
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = formatEnvValue(v.Index(i), sep, timeFormat)
+		}
+		return strings.Join(parts, sep)
+	case reflect.Map:
+		parts := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			parts = append(parts, iter.Key().String()+"="+formatEnvValue(iter.Value(), sep, timeFormat))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, sep)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
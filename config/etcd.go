@@ -0,0 +1,210 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// EtcdConfig configures EtcdProvider.
+type EtcdConfig struct {
+	// Endpoint is the etcd client URL. Default: http://127.0.0.1:2379.
+	Endpoint string
+	// Prefix is the key prefix holding the configuration, e.g. "/app/". Keys
+	// below it map to config keys with "/" as the separator, so
+	// "/app/server/port" sets server.port. With Format, Prefix is instead a
+	// single key holding the whole document.
+	Prefix string
+	// Format parses the value of the Prefix key as a YAML, JSON or HCL
+	// document instead of reading one key per value.
+	Format Format
+	// Username and Password authenticate against etcd when auth is enabled.
+	Username string
+	Password string
+	// HTTPClient sends the requests. Default: http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// EtcdProvider returns a RemoteProvider reading etcd v3 through its JSON
+// gateway (/v3/kv/range). It implements RemoteWatcher with /v3/watch, so
+// Watch reloads as soon as a key changes.
+func EtcdProvider(cfg EtcdConfig) RemoteProvider {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "http://127.0.0.1:2379"
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	return &etcdProvider{cfg: cfg}
+}
+
+type etcdProvider struct {
+	cfg EtcdConfig
+}
+
+type etcdKeyRange struct {
+	Key      []byte `json:"key"`
+	RangeEnd []byte `json:"range_end,omitempty"`
+}
+
+func (p *etcdProvider) Read(ctx context.Context) (map[string]any, error) {
+	token, err := p.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Kvs []struct {
+			Key   []byte `json:"key"`
+			Value []byte `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := p.post(ctx, "/v3/kv/range", token, p.keyRange(), &resp); err != nil {
+		return nil, err
+	}
+
+	if p.cfg.Format != "" {
+		if len(resp.Kvs) == 0 {
+			return map[string]any{}, nil
+		}
+		values, err := parseRemoteDocument(resp.Kvs[0].Value, p.cfg.Format)
+		if err != nil {
+			return nil, fmt.Errorf("etcd: parse %q: %w", p.cfg.Prefix, err)
+		}
+		return values, nil
+	}
+
+	kv := make(map[string]string, len(resp.Kvs))
+	for _, pair := range resp.Kvs {
+		kv[string(pair.Key)] = string(pair.Value)
+	}
+	return remoteValues(p.cfg.Prefix, kv), nil
+}
+
+func (p *etcdProvider) Watch(ctx context.Context, notify func(error)) error {
+	for {
+		err := p.watch(ctx, notify)
+		if ctx.Err() != nil {
+			return nil
+		}
+		notify(err)
+		if !sleepContext(ctx, remoteRetryDelay) {
+			return nil
+		}
+		// Changes made while disconnected were missed.
+		notify(nil)
+	}
+}
+
+// watch streams watch responses until the connection ends, calling notify
+// for every response carrying events.
+func (p *etcdProvider) watch(ctx context.Context, notify func(error)) error {
+	token, err := p.authenticate(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]any{"create_request": p.keyRange()})
+	if err != nil {
+		return err
+	}
+	resp, err := p.do(ctx, "/v3/watch", token, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Result struct {
+				Events []json.RawMessage `json:"events"`
+			} `json:"result"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			return fmt.Errorf("etcd: watch %q: %w", p.cfg.Prefix, err)
+		}
+		if len(msg.Result.Events) > 0 {
+			notify(nil)
+		}
+	}
+}
+
+// keyRange selects the Prefix key, or every key starting with Prefix.
+func (p *etcdProvider) keyRange() etcdKeyRange {
+	r := etcdKeyRange{Key: []byte(p.cfg.Prefix)}
+	if p.cfg.Format == "" {
+		r.RangeEnd = prefixRangeEnd(r.Key)
+	}
+	return r
+}
+
+// prefixRangeEnd returns the smallest key greater than every key starting with
+// prefix.
+func prefixRangeEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// Every byte is 0xff: range to the end of the keyspace.
+	return []byte{0}
+}
+
+// authenticate returns an auth token when a username is configured.
+func (p *etcdProvider) authenticate(ctx context.Context) (string, error) {
+	if p.cfg.Username == "" {
+		return "", nil
+	}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	req := map[string]string{"name": p.cfg.Username, "password": p.cfg.Password}
+	if err := p.post(ctx, "/v3/auth/authenticate", "", req, &resp); err != nil {
+		return "", err
+	}
+	return resp.Token, nil
+}
+
+func (p *etcdProvider) post(ctx context.Context, path, token string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("etcd: %w", err)
+	}
+	resp, err := p.do(ctx, path, token, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("etcd: decode %s: %w", path, err)
+	}
+	return nil
+}
+
+func (p *etcdProvider) do(ctx context.Context, path, token string, body []byte) (*http.Response, error) {
+	endpoint := strings.TrimSuffix(p.cfg.Endpoint, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("etcd: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("etcd: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("etcd: %s: %s: %s", path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
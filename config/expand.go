@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// placeholderPattern matches ${NAME} and ${NAME:-default}, optionally escaped
+// with a second dollar sign ($${NAME}).
+var placeholderPattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-[^}]*)?\}`)
+
+// expandPlaceholders replaces the placeholders in the string values loaded
+// into k, including strings inside lists.
+func expandPlaceholders(k *koanf.Koanf, opt options) error {
+	for key, value := range k.All() {
+		expanded, changed, err := expandValue(value, opt)
+		if err != nil {
+			return fmt.Errorf("config: expand %s: %w", key, err)
+		}
+		if !changed {
+			continue
+		}
+		if err := k.Set(key, expanded); err != nil {
+			return fmt.Errorf("config: expand %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func expandValue(value any, opt options) (any, bool, error) {
+	switch v := value.(type) {
+	case string:
+		expanded, err := expandString(v, opt)
+		return expanded, err == nil && expanded != v, err
+	case []any:
+		out := make([]any, len(v))
+		changed := false
+		for i, item := range v {
+			expanded, itemChanged, err := expandValue(item, opt)
+			if err != nil {
+				return nil, false, err
+			}
+			out[i] = expanded
+			changed = changed || itemChanged
+		}
+		return out, changed, nil
+	default:
+		return value, false, nil
+	}
+}
+
+// expandString replaces ${NAME} with the value of the environment variable
+// NAME, and ${NAME:-default} with default when NAME is unset or empty. A
+// placeholder without default whose variable is unset is an error, so a
+// missing secret does not silently produce a broken value. $${NAME} is kept
+// as the literal ${NAME}.
+func expandString(s string, opt options) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var missing []string
+	expanded := placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		groups := placeholderPattern.FindStringSubmatch(match)
+		name, fallback := groups[1], groups[2]
+		value, ok := opt.envLookup(name)
+		switch {
+		case fallback != "" && value == "":
+			return strings.TrimPrefix(fallback, ":-")
+		case !ok:
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
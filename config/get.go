@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// ErrKeyNotFound is returned by Lookup when a key is not set.
+var ErrKeyNotFound = errors.New("config: key not found")
+
+// Lookup returns the value at key converted to T, for dynamic lookups such as
+// feature flags or tuning knobs that don't warrant a struct field:
+//
+//	limit, err := config.Lookup[int](vals, "limits.requests_per_second")
+//
+// Values are converted like struct fields, so "8080" converts to an int and
+// "5s" to a time.Duration. The environment variable inferred from key (with
+// the WithEnvPrefix prefix) overrides the file, as for struct fields:
+// APP_LIMITS_REQUESTS_PER_SECOND for the key above. It returns ErrKeyNotFound
+// when neither is set.
+func Lookup[T any](v *Values, key string) (T, error) {
+	var out T
+	if v.o.envEnabled {
+		raw, ok, err := lookupEnv(envKeyFor(key, v.o), v.o)
+		if err != nil {
+			return out, err
+		}
+		if ok {
+			holder := reflect.ValueOf(&out).Elem()
+			if !isSupportedLeaf(holder.Type()) {
+				return out, fmt.Errorf("config: %s: cannot parse an environment variable as %T", key, out)
+			}
+			if err := setFieldValue(holder, raw, v.o.sliceSeparator, ""); err != nil {
+				return out, fmt.Errorf("config: %s: %w", key, err)
+			}
+			return out, nil
+		}
+	}
+
+	if !v.k.Exists(key) {
+		return out, fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	conf := koanf.UnmarshalConf{Tag: unmarshalTag(reflect.TypeOf(out))}
+	if err := v.k.UnmarshalWithConf(key, &out, conf); err != nil {
+		return out, fmt.Errorf("config: %s: %w", key, err)
+	}
+	return out, nil
+}
+
+// Get is Lookup returning def (or the zero value without def) when key is not
+// set or cannot be converted to T:
+//
+//	if config.Get(vals, "features.new_checkout", false) { ... }
+//	timeout := config.Get(vals, "http.timeout", 10*time.Second)
+func Get[T any](v *Values, key string, def ...T) T {
+	out, err := Lookup[T](v, key)
+	if err != nil {
+		var zero T
+		if len(def) > 0 {
+			return def[0]
+		}
+		return zero
+	}
+	return out
+}
+
+// envKeyFor infers the environment variable of a dotted key.
+func envKeyFor(key string, opt options) string {
+	return buildEnvKey(strings.Split(key, "."), reflect.StructField{}, opt.envPrefix)
+}
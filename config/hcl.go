@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/v2"
+)
+
+// hclParser is a koanf.Parser for HashiCorp HCL (version 1, the syntax used by
+// Nomad and Vault configuration files).
+type hclParser struct{}
+
+func (hclParser) Unmarshal(b []byte) (map[string]any, error) {
+	var out map[string]any
+	if err := hcl.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	flattenHCLBlocks(out)
+	return out, nil
+}
+
+func (hclParser) Marshal(map[string]any) ([]byte, error) {
+	return nil, errors.New("config: marshaling HCL is not supported")
+}
+
+// flattenHCLBlocks replaces blocks, which HCL decodes as one-element lists of
+// objects (server { port = 8080 } becomes "server": [{"port": 8080}]), with
+// the object itself so they map to nested structs like YAML and JSON objects.
+// Repeated blocks stay lists; restoreBlockLists turns a single block back into
+// a list when it is decoded into a slice field.
+func flattenHCLBlocks(m map[string]any) {
+	for k, v := range m {
+		if blocks, ok := v.([]map[string]any); ok && len(blocks) == 1 {
+			v = blocks[0]
+			m[k] = v
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			flattenHCLBlocks(v)
+		case []map[string]any:
+			for _, block := range v {
+				flattenHCLBlocks(block)
+			}
+		}
+	}
+}
+
+// restoreBlockLists wraps the objects that target slices of structs or maps of
+// typ, decoded at key, in one-element lists, so a []struct field gets the same
+// value from a single HCL block as from several.
+func restoreBlockLists(k *koanf.Koanf, key string, typ reflect.Type) error {
+	value := any(k.Raw())
+	if key != "" {
+		value = k.Get(key)
+	}
+	restored, changed := blockLists(value, typ)
+	if !changed {
+		return nil
+	}
+	if key == "" {
+		return k.Load(confmap.Provider(restored.(map[string]any), ""), nil)
+	}
+	return k.Load(confmap.Provider(map[string]any{key: restored}, "."), nil)
+}
+
+// blockLists returns value with the objects at slice fields of typ wrapped in
+// lists, and whether anything changed. value itself is not modified.
+func blockLists(value any, typ reflect.Type) (any, bool) {
+	typ = derefType(typ)
+	switch typ.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[string]any)
+		if !ok || typ == timeType {
+			return value, false
+		}
+		var out map[string]any
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := baseFieldName(field)
+			for k, v := range m {
+				if !strings.EqualFold(k, name) {
+					continue
+				}
+				if restored, changed := blockLists(v, field.Type); changed {
+					if out == nil {
+						out = maps.Clone(m)
+					}
+					out[k] = restored
+				}
+			}
+		}
+		if out == nil {
+			return value, false
+		}
+		return out, true
+
+	case reflect.Map:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return value, false
+		}
+		var out map[string]any
+		for k, v := range m {
+			if restored, changed := blockLists(v, typ.Elem()); changed {
+				if out == nil {
+					out = maps.Clone(m)
+				}
+				out[k] = restored
+			}
+		}
+		if out == nil {
+			return value, false
+		}
+		return out, true
+
+	case reflect.Slice:
+		elem := derefType(typ.Elem())
+		if elem.Kind() != reflect.Struct && elem.Kind() != reflect.Map {
+			return value, false
+		}
+		var list []any
+		changed := false
+		switch v := value.(type) {
+		case map[string]any:
+			list, changed = []any{v}, true
+		case []any:
+			list = v
+		case []map[string]any:
+			list = make([]any, len(v))
+			for i, block := range v {
+				list[i] = block
+			}
+		default:
+			return value, false
+		}
+		var out []any
+		for i, item := range list {
+			if restored, ok := blockLists(item, elem); ok {
+				if out == nil {
+					out = append([]any(nil), list...)
+				}
+				out[i] = restored
+			}
+		}
+		if out != nil {
+			return out, true
+		}
+		if changed {
+			return list, true
+		}
+		return value, false
+
+	default:
+		return value, false
+	}
+}
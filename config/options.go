@@ -1,8 +1,11 @@
 package config
 
 import (
+	"io"
 	"io/fs"
+	"log/slog"
 	"os"
+	"time"
 )
 
 type Format string
@@ -11,15 +14,30 @@ const (
 	FormatAuto Format = ""
 	FormatYAML Format = "yaml"
 	FormatJSON Format = "json"
+	FormatHCL  Format = "hcl"
 )
 
 type options struct {
 	envEnabled     bool
 	envPrefix      string
 	envLookup      func(string) (string, bool)
+	expandEnv      bool
+	strict         bool
 	fileReader     func(string) ([]byte, error)
+	decryptors     []Decryptor
+	http           *HTTPSource
+	stdin          io.Reader
 	sliceSeparator string
 	format         Format
+	remotes        []RemoteProvider
+	remoteTimeout  time.Duration
+	vault          *Vault
+	aws            *AWSSecrets
+
+	watchDebounce     time.Duration
+	watchErrorHandler func(error)
+	watchChanges      func([]Change)
+	watchLogger       *slog.Logger
 }
 
 func defaultOptions() options {
@@ -29,6 +47,8 @@ func defaultOptions() options {
 		fileReader:     os.ReadFile,
 		sliceSeparator: ",",
 		format:         FormatAuto,
+		remoteTimeout:  defaultRemoteTimeout,
+		watchDebounce:  defaultWatchDebounce,
 	}
 }
 
@@ -52,6 +72,17 @@ func WithEnvPrefix(prefix string) Option {
 	}
 }
 
+// WithExpandEnv enables ${NAME} and ${NAME:-default} placeholders in the string
+// values of the config file, resolved with the environment lookup before
+// environment overrides are applied, e.g.
+// url: "postgres://app:${DB_PASSWORD}@${DB_HOST:-localhost}/app". A
+// placeholder without default fails Load when its variable is unset.
+func WithExpandEnv() Option {
+	return func(o *options) {
+		o.expandEnv = true
+	}
+}
+
 func WithEnvLookup(fn func(string) (string, bool)) Option {
 	return func(o *options) {
 		if fn != nil {
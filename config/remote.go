@@ -0,0 +1,122 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/v2"
+)
+
+// defaultRemoteTimeout bounds how long Load waits for each remote provider.
+const defaultRemoteTimeout = 10 * time.Second
+
+// remoteRetryDelay is how long remote watches wait before retrying after an
+// error.
+const remoteRetryDelay = 5 * time.Second
+
+// RemoteProvider is a remote configuration source such as Consul KV or etcd
+// (see ConsulProvider and EtcdProvider).
+type RemoteProvider interface {
+	// Read returns the configuration as a nested map, keyed like the file.
+	Read(ctx context.Context) (map[string]any, error)
+}
+
+// RemoteWatcher is implemented by remote providers that can report changes.
+// Watch uses it to reload the configuration.
+type RemoteWatcher interface {
+	// Watch calls notify with a nil error whenever the configuration may
+	// have changed, until ctx is done. Transient errors are passed to notify
+	// and retried; Watch returns only when ctx is done or the provider cannot
+	// watch at all.
+	Watch(ctx context.Context, notify func(error)) error
+}
+
+// WithRemote adds a remote configuration source. Remote values are merged over
+// the file, in the order the providers are added, and beneath environment
+// overrides. With at least one remote provider, Load accepts an empty path to
+// skip the file.
+func WithRemote(provider RemoteProvider) Option {
+	return func(o *options) {
+		if provider != nil {
+			o.remotes = append(o.remotes, provider)
+		}
+	}
+}
+
+// WithRemoteTimeout sets how long Load waits for each remote provider.
+// Default: 10s.
+func WithRemoteTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.remoteTimeout = d
+		}
+	}
+}
+
+func mergeRemotes(k *koanf.Koanf, opt options) error {
+	for _, provider := range opt.remotes {
+		ctx, cancel := context.WithTimeout(context.Background(), opt.remoteTimeout)
+		values, err := provider.Read(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("config: read remote: %w", err)
+		}
+		if err := k.Load(confmap.Provider(values, ""), nil); err != nil {
+			return fmt.Errorf("config: apply remote: %w", err)
+		}
+	}
+	return nil
+}
+
+// remoteValues maps the keys of a key/value store below prefix to a nested
+// map: with prefix "service/app/", "service/app/server/port" becomes
+// server.port. Folder keys (ending in "/") are skipped.
+func remoteValues(prefix string, pairs map[string]string) map[string]any {
+	out := make(map[string]any)
+	for key, value := range pairs {
+		rel := strings.Trim(strings.TrimPrefix(key, prefix), "/")
+		if rel == "" || strings.HasSuffix(key, "/") {
+			continue
+		}
+		setNested(out, strings.Split(rel, "/"), value)
+	}
+	return out
+}
+
+func setNested(m map[string]any, path []string, value any) {
+	for _, part := range path[:len(path)-1] {
+		next, ok := m[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			m[part] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
+
+// parseRemoteDocument parses a single remote value holding a whole
+// configuration document.
+func parseRemoteDocument(data []byte, format Format) (map[string]any, error) {
+	parser, err := parserFor(format)
+	if err != nil {
+		return nil, err
+	}
+	return parser.Unmarshal(data)
+}
+
+// sleepContext waits for d or until ctx is done, reporting whether the full
+// delay elapsed.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MissingField is a required field that is still zero after Load.
+type MissingField struct {
+	// Key is the config file key, e.g. "database.url".
+	Key string
+	// EnvVar is the environment variable that would set it, if any.
+	EnvVar string
+	// EnvAliases are the alternative environment variables (see the env tag).
+	EnvAliases []string
+}
+
+// MissingFieldsError reports every required field that Load left zero. Fields
+// are required with a required:"true" tag or the required option of the env
+// tag (env:"DATABASE_URL,required" or env:",required").
+type MissingFieldsError struct {
+	Fields []MissingField
+}
+
+func (e *MissingFieldsError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		parts[i] = field.Key
+		if field.EnvVar != "" {
+			parts[i] += " (env " + strings.Join(append([]string{field.EnvVar}, field.EnvAliases...), " or ") + ")"
+		}
+	}
+	return fmt.Sprintf("config: missing required fields: %s", strings.Join(parts, ", "))
+}
+
+// checkRequired returns a *MissingFieldsError listing the required fields of
+// target that are zero.
+func checkRequired(target any, metas []fieldMeta, opt options) error {
+	root := reflect.ValueOf(target).Elem()
+	var missing []MissingField
+	for _, meta := range metas {
+		if !meta.required {
+			continue
+		}
+		if field, ok := fieldByIndex(root, meta.index); ok && !field.IsZero() {
+			continue
+		}
+		field := MissingField{Key: meta.key}
+		if opt.envEnabled {
+			field.EnvVar = meta.envVar
+			field.EnvAliases = meta.envAliases
+		}
+		missing = append(missing, field)
+	}
+	if len(missing) > 0 {
+		return &MissingFieldsError{Fields: missing}
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+type sampleOptions struct {
+	format     Format
+	sourceDirs []string
+	envPrefix  string
+	envEnabled bool
+}
+
+// SampleOption configures Sample.
+type SampleOption func(*sampleOptions)
+
+// WithSampleFormat selects the output format, FormatYAML (the default) or
+// FormatJSON. JSON has no comments, so only the YAML sample documents the
+// fields.
+func WithSampleFormat(format Format) SampleOption {
+	return func(o *sampleOptions) {
+		if format != FormatAuto {
+			o.format = format
+		}
+	}
+}
+
+// WithSampleSourceDir sets the directories whose Go files are parsed for the
+// doc comments of the config structs. Default: the current directory, which
+// is the package directory when Sample runs from go generate.
+func WithSampleSourceDir(dirs ...string) SampleOption {
+	return func(o *sampleOptions) {
+		o.sourceDirs = dirs
+	}
+}
+
+// WithSampleEnvPrefix sets the prefix of the environment variable names shown
+// in the comments, as WithEnvPrefix does for Load.
+func WithSampleEnvPrefix(prefix string) SampleOption {
+	return func(o *sampleOptions) {
+		o.envPrefix = prefix
+	}
+}
+
+// WithoutSampleEnv omits environment variable names from the comments, for
+// configs loaded with WithoutEnv.
+func WithoutSampleEnv() SampleOption {
+	return func(o *sampleOptions) {
+		o.envEnabled = false
+	}
+}
+
+// Sample generates a sample config file for the struct type of target, so the
+// reference config is generated from the code instead of drifting from it:
+//
+//	//go:generate go run ./cmd/sampleconfig
+//	out, err := config.Sample(&AppConfig{}, config.WithSampleEnvPrefix("APP"))
+//	os.WriteFile("config.sample.yaml", out, 0o644)
+//
+// Keys follow the same tags as Load. Values are the defaults: the values set
+// by SetDefaults methods (see Defaulter), then envDefault tags, then the
+// values already in target. Nil struct pointers are expanded so every key is
+// listed. In YAML, each key is preceded by the doc comment of its field
+// (parsed from the Go sources, see WithSampleSourceDir) and its environment
+// variable.
+func Sample(target any, opts ...SampleOption) ([]byte, error) {
+	o := sampleOptions{format: FormatYAML, sourceDirs: []string{"."}, envEnabled: true}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	typ := reflect.TypeOf(target)
+	if typ == nil || derefType(typ).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: sample target must be a struct or a pointer to one (got %T)", target)
+	}
+
+	// Work on a copy so target is left untouched by SetDefaults.
+	val := reflect.New(derefType(typ)).Elem()
+	if src := reflect.ValueOf(target); src.Kind() != reflect.Pointer || !src.IsNil() {
+		val.Set(reflect.Indirect(src))
+	}
+	callSetDefaults(val)
+
+	docs, err := parseFieldDocs(o.sourceDirs)
+	if err != nil {
+		return nil, err
+	}
+	g := sampleGenerator{opts: o, docs: docs}
+	root := g.structNode(val, nil, typeDocKey(val.Type()))
+
+	switch o.format {
+	case FormatYAML:
+		var buf strings.Builder
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(root); err != nil {
+			return nil, fmt.Errorf("config: encode sample: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return nil, fmt.Errorf("config: encode sample: %w", err)
+		}
+		return []byte(buf.String()), nil
+	case FormatJSON:
+		var values any
+		if err := root.Decode(&values); err != nil {
+			return nil, fmt.Errorf("config: encode sample: %w", err)
+		}
+		return json.MarshalIndent(values, "", "  ")
+	default:
+		return nil, fmt.Errorf("config: unsupported sample format %q", o.format)
+	}
+}
+
+type sampleGenerator struct {
+	opts sampleOptions
+	// docs maps "<package>.<Type>[.<InlineField>...]" to field doc comments,
+	// with the type's own doc comment under "".
+	docs map[string]map[string]string
+}
+
+// structNode renders the struct v as a YAML mapping. docKey identifies the
+// struct type in g.docs.
+func (g sampleGenerator) structNode(v reflect.Value, keyPath []string, docKey string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := baseFieldName(field)
+		if key == "" {
+			continue
+		}
+		currentPath := withPath(keyPath, key)
+		fv := v.Field(i)
+
+		var comment []string
+		if doc := g.docs[docKey][field.Name]; doc != "" {
+			comment = append(comment, strings.Split(doc, "\n")...)
+		}
+
+		var valueNode *yaml.Node
+		if shouldDescend(field.Type) {
+			for fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv = reflect.New(fv.Type().Elem()).Elem()
+					callSetDefaults(fv)
+					continue
+				}
+				fv = fv.Elem()
+			}
+			childKey := typeDocKey(fv.Type())
+			if childKey == "" {
+				childKey = docKey + "." + field.Name
+			} else if len(comment) == 0 && g.docs[childKey][""] != "" {
+				comment = strings.Split(g.docs[childKey][""], "\n")
+			}
+			valueNode = g.structNode(fv, currentPath, childKey)
+		} else {
+			valueNode = g.leafNode(field, fv)
+			if g.opts.envEnabled && isSupportedLeaf(field.Type) {
+				if env := buildEnvKey(currentPath, field, g.opts.envPrefix); env != "" {
+					comment = append(comment, "env: "+env)
+				}
+			}
+		}
+
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: key}
+		if len(comment) > 0 {
+			keyNode.HeadComment = "# " + strings.Join(comment, "\n# ")
+		}
+		node.Content = append(node.Content, keyNode, valueNode)
+	}
+	return node
+}
+
+// leafNode renders the value of a leaf field, falling back to its envDefault
+// tag when the value is zero.
+func (g sampleGenerator) leafNode(field reflect.StructField, v reflect.Value) *yaml.Node {
+	if def := field.Tag.Get("envDefault"); def != "" && v.IsZero() && isSupportedLeaf(field.Type) {
+		parsed := reflect.New(field.Type).Elem()
+		sep := field.Tag.Get("envSeparator")
+		if sep == "" {
+			sep = ","
+		}
+		if err := setFieldValue(parsed, def, sep, field.Tag.Get("timeFormat")); err == nil {
+			v = parsed
+		}
+	}
+
+	node := &yaml.Node{}
+	if err := node.Encode(dumpValue(v, dumpOptions{timeFormat: field.Tag.Get("timeFormat")})); err != nil {
+		node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+	return node
+}
+
+// typeDocKey returns the g.docs key of a named struct type, or "" for
+// anonymous structs.
+func typeDocKey(t reflect.Type) string {
+	if t.Name() == "" {
+		return ""
+	}
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+// parseFieldDocs collects the doc comments of struct types and the doc and line
+// comments of their fields declared in the Go files of dirs, keyed like
+// typeDocKey. Fields of inline struct
+// types are keyed by the path to them, e.g. "main.AppConfig.Server".
+func parseFieldDocs(dirs []string) (map[string]map[string]string, error) {
+	docs := make(map[string]map[string]string)
+	fset := token.NewFileSet()
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			return nil, fmt.Errorf("config: sample sources: %w", err)
+		}
+		for _, name := range files {
+			if strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			file, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+			if err != nil {
+				return nil, fmt.Errorf("config: sample sources: %w", err)
+			}
+			pkg := file.Name.Name
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					spec := spec.(*ast.TypeSpec)
+					st, ok := spec.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					key := pkg + "." + spec.Name.Name
+					doc := spec.Doc
+					if doc == nil && len(gen.Specs) == 1 {
+						doc = gen.Doc
+					}
+					if text := strings.TrimSpace(doc.Text()); text != "" {
+						docs[key] = map[string]string{"": text}
+					}
+					collectFieldDocs(docs, key, st)
+				}
+			}
+		}
+	}
+	return docs, nil
+}
+
+func collectFieldDocs(docs map[string]map[string]string, key string, st *ast.StructType) {
+	for _, field := range st.Fields.List {
+		text := strings.TrimSpace(field.Doc.Text())
+		if text == "" {
+			text = strings.TrimSpace(field.Comment.Text())
+		}
+		for _, name := range field.Names {
+			if text != "" {
+				if docs[key] == nil {
+					docs[key] = make(map[string]string)
+				}
+				docs[key][name.Name] = text
+			}
+			if inner, ok := field.Type.(*ast.StructType); ok {
+				collectFieldDocs(docs, key+"."+name.Name, inner)
+			}
+			if star, ok := field.Type.(*ast.StarExpr); ok {
+				if inner, ok := star.X.(*ast.StructType); ok {
+					collectFieldDocs(docs, key+"."+name.Name, inner)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StdinPath is the path that makes Load read the config from standard input
+// (see WithStdin). The format cannot be detected and must be set with
+// WithFormat.
+const StdinPath = "-"
+
+const (
+	defaultHTTPTimeout      = 10 * time.Second
+	defaultHTTPPollInterval = 30 * time.Second
+)
+
+// HTTPConfig configures HTTPSource.
+type HTTPConfig struct {
+	// Timeout bounds each request. Default: 10s.
+	Timeout time.Duration
+	// Authorization is sent as the Authorization header, e.g. "Bearer <token>".
+	Authorization string
+	// Header holds additional request headers.
+	Header http.Header
+	// PollInterval is how often Watch polls the URL. Default: 30s.
+	PollInterval time.Duration
+	// HTTPClient sends the requests. Default: http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// HTTPSource reads config files from HTTP(S) URLs. It remembers the ETag and
+// body of every URL and sends If-None-Match on later reads, so polling an
+// unchanged file costs a 304 response. It is safe for concurrent use.
+type HTTPSource struct {
+	cfg HTTPConfig
+
+	mu    sync.Mutex
+	cache map[string]httpCacheEntry
+}
+
+type httpCacheEntry struct {
+	etag string
+	body []byte
+}
+
+// NewHTTPSource returns an HTTPSource for WithHTTP.
+func NewHTTPSource(cfg HTTPConfig) *HTTPSource {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultHTTPTimeout
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultHTTPPollInterval
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	return &HTTPSource{cfg: cfg, cache: make(map[string]httpCacheEntry)}
+}
+
+// WithHTTP sets the source used when the path passed to Load is an http:// or
+// https:// URL, e.g. a config server or sidecar. Share the source between
+// loads to reuse its ETag cache; Watch does. Without WithHTTP, URLs are read
+// with the HTTPConfig defaults and no cache.
+func WithHTTP(source *HTTPSource) Option {
+	return func(o *options) {
+		o.http = source
+	}
+}
+
+// WithStdin sets the reader used for StdinPath. Default: os.Stdin.
+func WithStdin(r io.Reader) Option {
+	return func(o *options) {
+		if r != nil {
+			o.stdin = r
+		}
+	}
+}
+
+// ReadFile fetches rawURL, returning the cached body when the server answers
+// 304 Not Modified.
+func (s *HTTPSource) ReadFile(rawURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range s.cfg.Header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if s.cfg.Authorization != "" {
+		req.Header.Set("Authorization", s.cfg.Authorization)
+	}
+
+	s.mu.Lock()
+	cached, ok := s.cache[rawURL]
+	s.mu.Unlock()
+	if ok {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := s.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		if ok {
+			return cached.body, nil
+		}
+		fallthrough
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		s.cache[rawURL] = httpCacheEntry{etag: etag, body: body}
+	} else {
+		delete(s.cache, rawURL)
+	}
+	s.mu.Unlock()
+	return body, nil
+}
+
+// isURL reports whether path is an http:// or https:// URL.
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// readConfig reads path from the source it names: a URL, StdinPath or the
+// file system.
+func readConfig(path string, o options) ([]byte, error) {
+	switch {
+	case isURL(path):
+		source := o.http
+		if source == nil {
+			source = NewHTTPSource(HTTPConfig{})
+		}
+		return source.ReadFile(path)
+	case path == StdinPath:
+		stdin := o.stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		return io.ReadAll(stdin)
+	default:
+		return o.fileReader(path)
+	}
+}
+
+// formatPath returns the part of path whose extension names the format,
+// i.e. the URL path without query for URLs.
+func formatPath(path string) string {
+	if !isURL(path) {
+		return path
+	}
+	u, err := url.Parse(path)
+	if err != nil {
+		return path
+	}
+	return u.Path
+}
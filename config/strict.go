@@ -0,0 +1,146 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// WithStrict makes Load fail with an *UnknownKeysError when the file or remote
+// sources contain keys that do not map to any field of the target, so typos
+// such as max_connctions do not silently fall back to defaults. Keys below
+// map and interface fields are always accepted.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// UnknownKey is a config key that does not map to a struct field.
+type UnknownKey struct {
+	Key string
+	// Suggestion is the closest known key, if one is similar enough.
+	Suggestion string
+}
+
+// UnknownKeysError reports the keys rejected by WithStrict.
+type UnknownKeysError struct {
+	Keys []UnknownKey
+}
+
+func (e *UnknownKeysError) Error() string {
+	parts := make([]string, len(e.Keys))
+	for i, key := range e.Keys {
+		parts[i] = key.Key
+		if key.Suggestion != "" {
+			parts[i] += " (did you mean " + key.Suggestion + "?)"
+		}
+	}
+	return fmt.Sprintf("config: unknown keys: %s", strings.Join(parts, ", "))
+}
+
+// checkUnknownKeys returns an *UnknownKeysError for the keys at or below
+// section that do not map to a field of typ.
+func checkUnknownKeys(keys []string, section string, typ reflect.Type) error {
+	var prefix []string
+	if section != "" {
+		prefix = strings.Split(section, ".")
+	}
+	known := make(map[string]bool)
+	collectKnownKeys(typ, prefix, known)
+
+	var unknown []UnknownKey
+	for _, key := range keys {
+		if section != "" && key != section && !strings.HasPrefix(key, section+".") {
+			continue
+		}
+		if knownKey(strings.ToLower(key), known) {
+			continue
+		}
+		unknown = append(unknown, UnknownKey{Key: key, Suggestion: suggestKey(key, known)})
+	}
+	if len(unknown) > 0 {
+		sort.Slice(unknown, func(i, j int) bool { return unknown[i].Key < unknown[j].Key })
+		return &UnknownKeysError{Keys: unknown}
+	}
+	return nil
+}
+
+// collectKnownKeys adds the lower-cased key of every leaf field of typ to
+// known. Fields are matched case-insensitively, as when unmarshaling.
+func collectKnownKeys(typ reflect.Type, path []string, known map[string]bool) {
+	typ = derefType(typ)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := baseFieldName(field)
+		if name == "" {
+			continue
+		}
+		currentPath := withPath(path, strings.ToLower(name))
+		if shouldDescend(field.Type) && !reflect.PointerTo(derefType(field.Type)).Implements(textUnmarshalerType) {
+			collectKnownKeys(field.Type, currentPath, known)
+			continue
+		}
+		known[strings.Join(currentPath, ".")] = true
+	}
+}
+
+// knownKey reports whether key is a known leaf or lies below one, such as a
+// map entry.
+func knownKey(key string, known map[string]bool) bool {
+	for {
+		if known[key] {
+			return true
+		}
+		i := strings.LastIndexByte(key, '.')
+		if i < 0 {
+			return false
+		}
+		key = key[:i]
+	}
+}
+
+// suggestKey returns the known key closest to key by edit distance, or "" when
+// none is close enough to be a likely typo.
+func suggestKey(key string, known map[string]bool) string {
+	key = strings.ToLower(key)
+	best, bestDist := "", -1
+	for candidate := range known {
+		d := editDistance(key, candidate)
+		if bestDist < 0 || d < bestDist || d == bestDist && candidate < best {
+			best, bestDist = candidate, d
+		}
+	}
+	if bestDist < 0 || bestDist > max(2, len(key)/4) {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,11 @@
+server {
+  host = "0.0.0.0"
+  port = 8080
+}
+
+database {
+  url             = "postgres://localhost:5432/app"
+  max_connections = 20
+}
+
+features = ["trace", "metrics"]
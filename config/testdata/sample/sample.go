@@ -0,0 +1,28 @@
+package sample
+
+import "time"
+
+// AppConfig is the sample application config.
+type AppConfig struct {
+	// Server configures the HTTP listener.
+	Server struct {
+		// Host is the address to bind.
+		Host string `yaml:"host" envDefault:"0.0.0.0"`
+		Port int    `yaml:"port" envDefault:"8080"` // Port to listen on.
+	} `yaml:"server"`
+	Database *DatabaseConfig `yaml:"database"`
+	// Features lists the enabled feature flags.
+	Features []string `yaml:"features"`
+}
+
+// DatabaseConfig configures the connection pool.
+type DatabaseConfig struct {
+	// URL is the connection string.
+	URL string `yaml:"url"`
+	// IdleTimeout closes idle connections.
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+}
+
+func (c *DatabaseConfig) SetDefaults() {
+	c.IdleTimeout = 5 * time.Minute
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/v2"
+)
+
+// Special timeFormat tag values, for timestamps stored as numbers.
+const (
+	TimeFormatUnix      = "unix"
+	TimeFormatUnixMilli = "unixmilli"
+)
+
+// parseTime parses a time.Time value of a field with the given timeFormat
+// tag: a Go layout such as "2006-01-02", TimeFormatUnix or
+// TimeFormatUnixMilli. Without a tag, RFC3339 and date-only (2006-01-02)
+// values are accepted.
+func parseTime(raw, layout string) (time.Time, error) {
+	switch layout {
+	case "":
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			if d, dateErr := time.Parse(time.DateOnly, raw); dateErr == nil {
+				return d, nil
+			}
+		}
+		return t, err
+	case TimeFormatUnix, TimeFormatUnixMilli:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parse %q as %s time: %w", raw, layout, err)
+		}
+		if layout == TimeFormatUnix {
+			return time.Unix(n, 0).UTC(), nil
+		}
+		return time.UnixMilli(n).UTC(), nil
+	default:
+		return time.Parse(layout, raw)
+	}
+}
+
+// formatTime formats t the way parseTime parses it with layout.
+func formatTime(t time.Time, layout string) string {
+	switch layout {
+	case "":
+		return t.Format(time.RFC3339)
+	case TimeFormatUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case TimeFormatUnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	default:
+		return t.Format(layout)
+	}
+}
+
+// timeFormatName describes layout for EnvExample.
+func timeFormatName(layout string) string {
+	switch layout {
+	case "":
+		return "RFC3339 or 2006-01-02"
+	case TimeFormatUnix:
+		return "unix seconds"
+	case TimeFormatUnixMilli:
+		return "unix milliseconds"
+	default:
+		return layout
+	}
+}
+
+// isTimeLeaf reports whether t is a time.Time, a pointer to one or a slice of
+// them.
+func isTimeLeaf(t reflect.Type) bool {
+	t = derefType(t)
+	if t.Kind() == reflect.Slice {
+		t = derefType(t.Elem())
+	}
+	return t == timeType
+}
+
+// normalizeTimes parses the file and remote values of time.Time fields with
+// their timeFormat, since the decoder only understands RFC3339 strings and
+// YAML timestamps.
+func normalizeTimes(k *koanf.Koanf, metas []fieldMeta) error {
+	parsed := make(map[string]any)
+	for _, meta := range metas {
+		if !isTimeLeaf(meta.fieldType) || !k.Exists(meta.key) {
+			continue
+		}
+		value, err := normalizeTime(k.Get(meta.key), meta.timeFormat)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", meta.key, err)
+		}
+		parsed[meta.key] = value
+	}
+	if len(parsed) == 0 {
+		return nil
+	}
+	return k.Load(confmap.Provider(parsed, "."), nil)
+}
+
+func normalizeTime(value any, layout string) (any, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return parseTime(v, layout)
+	case int:
+		return normalizeUnixTime(int64(v), layout)
+	case int64:
+		return normalizeUnixTime(v, layout)
+	case float64:
+		// JSON numbers.
+		if v != math.Trunc(v) {
+			return nil, fmt.Errorf("%v is not a whole number", v)
+		}
+		return normalizeUnixTime(int64(v), layout)
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			t, err := normalizeTime(elem, layout)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			out[i] = t
+		}
+		return out, nil
+	default:
+		return value, nil
+	}
+}
+
+func normalizeUnixTime(n int64, layout string) (time.Time, error) {
+	if layout != TimeFormatUnix && layout != TimeFormatUnixMilli {
+		return time.Time{}, fmt.Errorf("number %d is not a time; set timeFormat:%q", n, TimeFormatUnix)
+	}
+	return parseTime(strconv.FormatInt(n, 10), layout)
+}
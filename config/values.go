@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// Values holds a configuration read once from the file and remote providers,
+// to be decoded into several structs, e.g. one per module of a monolith:
+//
+//	vals, err := config.Open("config.yaml", config.WithEnvPrefix("APP"))
+//	...
+//	var db kpgx.Config
+//	err = vals.Unmarshal("database", &db)
+//	var logCfg klog.Config
+//	err = vals.Unmarshal("log", &logCfg)
+//
+// Values is safe for concurrent use as long as the koanf instance returned by
+// Koanf is not modified.
+type Values struct {
+	k *koanf.Koanf
+	o options
+}
+
+// Open reads the config file and remote providers with the options of Load.
+// Environment overrides, secrets and defaults are applied per struct by
+// Unmarshal.
+func Open(path string, opts ...Option) (*Values, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	k := koanf.New(".")
+	if path != "" || len(o.remotes) == 0 {
+		if err := loadFile(k, path, o); err != nil {
+			return nil, err
+		}
+	}
+	if err := mergeRemotes(k, o); err != nil {
+		return nil, err
+	}
+	if o.expandEnv {
+		if err := expandPlaceholders(k, o); err != nil {
+			return nil, err
+		}
+	}
+	return &Values{k: k, o: o}, nil
+}
+
+// Unmarshal decodes the section at key, or everything when key is empty, into
+// target like Load: environment overrides, Vault and AWS secrets, defaults and
+// required fields apply to the fields of target, with environment variables
+// named after their full path (DATABASE_URL for the field URL at key
+// "database").
+func (v *Values) Unmarshal(key string, target any) error {
+	if target == nil {
+		return fmt.Errorf("config: target cannot be nil")
+	}
+
+	var prefix []string
+	if key != "" {
+		prefix = strings.Split(key, ".")
+	}
+	metas, err := prepareFieldMetaAt(target, prefix, v.o)
+	if err != nil {
+		return err
+	}
+	if v.o.strict {
+		if err := checkUnknownKeys(v.k.Keys(), key, reflect.TypeOf(target)); err != nil {
+			return err
+		}
+	}
+	callSetDefaults(reflect.ValueOf(target).Elem())
+
+	// Overrides are merged into a copy, so they don't leak into other
+	// sections or later calls.
+	k := v.k.Copy()
+	o := v.o
+	if o.vault != nil {
+		if err := setVaultRefs(k, metas); err != nil {
+			return err
+		}
+	}
+	if o.aws != nil {
+		if err := setAWSValues(k, metas, o); err != nil {
+			return err
+		}
+	}
+	if o.envEnabled {
+		if err := mergeEnv(k, metas, o); err != nil {
+			return err
+		}
+	}
+	if o.vault != nil {
+		if err := resolveVaultRefs(k, key, o); err != nil {
+			return err
+		}
+	}
+	if err := normalizeTimes(k, metas); err != nil {
+		return err
+	}
+	if err := restoreBlockLists(k, key, reflect.TypeOf(target)); err != nil {
+		return err
+	}
+
+	conf := koanf.UnmarshalConf{Tag: unmarshalTag(reflect.TypeOf(target))}
+	if err := k.UnmarshalWithConf(key, target, conf); err != nil {
+		return fmt.Errorf("config: unmarshal: %w", err)
+	}
+
+	if err := applyDefaults(target, metas); err != nil {
+		return err
+	}
+
+	return checkRequired(target, metas, o)
+}
+
+// Get returns the value at key, e.g. "database.url" or "database" for a whole
+// section, as read from the file and remote providers. Environment overrides
+// are not included, as they are resolved per struct by Unmarshal.
+func (v *Values) Get(key string) any {
+	return v.k.Get(key)
+}
+
+// Exists reports whether key is set in the file or remote providers.
+func (v *Values) Exists(key string) bool {
+	return v.k.Exists(key)
+}
+
+// Keys returns the keys of every value, sorted.
+func (v *Values) Keys() []string {
+	return v.k.Keys()
+}
+
+// Koanf returns the underlying koanf instance, e.g. to use koanf features
+// this package does not wrap. It must not be modified while Values is in use.
+func (v *Values) Koanf() *koanf.Koanf {
+	return v.k
+}
@@ -0,0 +1,346 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// vaultRefPrefix marks config values that reference a Vault secret.
+const vaultRefPrefix = "vault:"
+
+// VaultConfig configures a Vault client (see NewVault).
+type VaultConfig struct {
+	// Address is the Vault address. Default: $VAULT_ADDR, or
+	// http://127.0.0.1:8200.
+	Address string
+	// Token authenticates with a static token. Default: $VAULT_TOKEN when no
+	// AppRole is configured.
+	Token string
+	// RoleID and SecretID authenticate with AppRole instead of a token.
+	RoleID   string
+	SecretID string
+	// AppRoleMount is the mount path of the AppRole auth method. Default:
+	// "approle".
+	AppRoleMount string
+	// Namespace is sent as X-Vault-Namespace (Vault Enterprise).
+	Namespace string
+	// HTTPClient sends the requests. Default: http.DefaultClient.
+	HTTPClient *http.Client
+	// OnRenew is called by KeepAlive after the token was renewed or, for
+	// AppRole, logged in again, with the new token TTL.
+	OnRenew func(ttl time.Duration)
+	// OnRenewError is called by KeepAlive when renewing the token fails.
+	OnRenewError func(err error)
+}
+
+// Vault reads secrets from HashiCorp Vault for Load (see WithVault). It is
+// safe for concurrent use.
+type Vault struct {
+	cfg VaultConfig
+
+	mu        sync.Mutex
+	token     string
+	ttl       time.Duration
+	renewable bool
+}
+
+// NewVault returns a Vault client. AppRole logins happen on first use.
+func NewVault(cfg VaultConfig) *Vault {
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("VAULT_ADDR")
+	}
+	if cfg.Address == "" {
+		cfg.Address = "http://127.0.0.1:8200"
+	}
+	if cfg.Token == "" && cfg.RoleID == "" {
+		cfg.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if cfg.AppRoleMount == "" {
+		cfg.AppRoleMount = "approle"
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	return &Vault{cfg: cfg, token: cfg.Token}
+}
+
+// WithVault resolves Vault secret references with v. References are values of
+// the form "vault:<path>#<key>", in the file, remote sources or environment
+// variables, or set on fields with a vault tag:
+//
+//	type DatabaseConfig struct {
+//	    Password string `yaml:"password" vault:"secret/data/app#db_password"`
+//	}
+//
+// A vault tag sets the reference over the file and remote values; environment
+// variables still override it. <path> is the API path of the secret; KV
+// version 1 and 2 secrets are both supported. Without WithVault, references
+// and vault tags are ignored, so local setups can use plain values.
+func WithVault(v *Vault) Option {
+	return func(o *options) {
+		o.vault = v
+	}
+}
+
+// Login authenticates with AppRole. It is called automatically by the first
+// read and does nothing for token authentication.
+func (v *Vault) Login(ctx context.Context) error {
+	if v.cfg.RoleID == "" {
+		return nil
+	}
+	var resp vaultResponse
+	body := map[string]string{"role_id": v.cfg.RoleID, "secret_id": v.cfg.SecretID}
+	if err := v.do(ctx, http.MethodPost, "auth/"+v.cfg.AppRoleMount+"/login", "", body, &resp); err != nil {
+		return fmt.Errorf("vault: approle login: %w", err)
+	}
+	v.setAuth(resp.Auth)
+	return nil
+}
+
+// Renew renews the token. Tokens that are not renewable are logged in again
+// when AppRole is configured.
+func (v *Vault) Renew(ctx context.Context) error {
+	v.mu.Lock()
+	token, renewable := v.token, v.renewable
+	v.mu.Unlock()
+
+	if !renewable && v.cfg.RoleID != "" {
+		return v.Login(ctx)
+	}
+	var resp vaultResponse
+	if err := v.do(ctx, http.MethodPost, "auth/token/renew-self", token, map[string]any{}, &resp); err != nil {
+		if v.cfg.RoleID != "" {
+			return v.Login(ctx)
+		}
+		return fmt.Errorf("vault: renew token: %w", err)
+	}
+	v.setAuth(resp.Auth)
+	return nil
+}
+
+// KeepAlive renews the token when two thirds of its TTL have passed, until
+// ctx is done, and retries failed renewals after a few seconds. Run it in its
+// own goroutine for long-running processes that reload the configuration,
+// e.g. with Watch. It returns immediately for tokens without a TTL.
+func (v *Vault) KeepAlive(ctx context.Context) {
+	if err := v.lookupToken(ctx); err != nil {
+		v.renewFailed(err)
+	}
+
+	wait := v.renewIn()
+	for wait > 0 {
+		if !sleepContext(ctx, wait) {
+			return
+		}
+		if err := v.Renew(ctx); err != nil {
+			v.renewFailed(err)
+			wait = remoteRetryDelay
+			continue
+		}
+		if v.cfg.OnRenew != nil {
+			v.mu.Lock()
+			ttl := v.ttl
+			v.mu.Unlock()
+			v.cfg.OnRenew(ttl)
+		}
+		wait = v.renewIn()
+	}
+}
+
+// lookupToken logs in with AppRole, or reads the TTL of a static token.
+func (v *Vault) lookupToken(ctx context.Context) error {
+	if v.cfg.RoleID != "" {
+		return v.Login(ctx)
+	}
+	var resp vaultResponse
+	if err := v.do(ctx, http.MethodGet, "auth/token/lookup-self", v.cfg.Token, nil, &resp); err != nil {
+		return fmt.Errorf("vault: look up token: %w", err)
+	}
+	ttl, _ := resp.Data["ttl"].(float64)
+	renewable, _ := resp.Data["renewable"].(bool)
+	v.setAuth(&vaultAuth{LeaseDuration: int(ttl), Renewable: renewable})
+	return nil
+}
+
+func (v *Vault) renewIn() time.Duration {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.ttl * 2 / 3
+}
+
+// Secret returns the value of key in the secret at path.
+func (v *Vault) Secret(ctx context.Context, path, key string) (any, error) {
+	data, err := v.readSecret(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := data[key]
+	if !ok {
+		return nil, fmt.Errorf("vault: secret %q has no key %q", path, key)
+	}
+	return value, nil
+}
+
+func (v *Vault) readSecret(ctx context.Context, path string) (map[string]any, error) {
+	if err := v.ensureToken(ctx); err != nil {
+		return nil, err
+	}
+	v.mu.Lock()
+	token := v.token
+	v.mu.Unlock()
+
+	var resp vaultResponse
+	if err := v.do(ctx, http.MethodGet, strings.Trim(path, "/"), token, nil, &resp); err != nil {
+		return nil, fmt.Errorf("vault: read %q: %w", path, err)
+	}
+	// KV version 2 nests the secret in data.data next to data.metadata.
+	if inner, ok := resp.Data["data"].(map[string]any); ok {
+		if _, ok := resp.Data["metadata"]; ok {
+			return inner, nil
+		}
+	}
+	return resp.Data, nil
+}
+
+func (v *Vault) ensureToken(ctx context.Context) error {
+	v.mu.Lock()
+	token := v.token
+	v.mu.Unlock()
+	if token != "" {
+		return nil
+	}
+	if v.cfg.RoleID == "" {
+		return fmt.Errorf("vault: no token or AppRole configured")
+	}
+	return v.Login(ctx)
+}
+
+func (v *Vault) setAuth(auth *vaultAuth) {
+	if auth == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if auth.ClientToken != "" {
+		v.token = auth.ClientToken
+	}
+	v.ttl = time.Duration(auth.LeaseDuration) * time.Second
+	v.renewable = auth.Renewable
+}
+
+func (v *Vault) renewFailed(err error) {
+	if v.cfg.OnRenewError != nil {
+		v.cfg.OnRenewError(err)
+	}
+}
+
+type vaultResponse struct {
+	Data   map[string]any `json:"data"`
+	Auth   *vaultAuth     `json:"auth"`
+	Errors []string       `json:"errors"`
+}
+
+type vaultAuth struct {
+	ClientToken   string `json:"client_token"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+}
+
+func (v *Vault) do(ctx context.Context, method, path, token string, in any, out *vaultResponse) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(v.cfg.Address, "/")+"/v1/"+path, body)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.cfg.Namespace)
+	}
+	resp, err := v.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(out.Errors) > 0 {
+			return fmt.Errorf("%s: %s", resp.Status, strings.Join(out.Errors, "; "))
+		}
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
+
+// setVaultRefs sets the references of fields with a vault tag.
+func setVaultRefs(k *koanf.Koanf, metas []fieldMeta) error {
+	for _, meta := range metas {
+		if meta.vaultRef == "" {
+			continue
+		}
+		if err := k.Set(meta.key, vaultRefPrefix+meta.vaultRef); err != nil {
+			return fmt.Errorf("config: vault reference for %s: %w", meta.key, err)
+		}
+	}
+	return nil
+}
+
+// resolveVaultRefs replaces the "vault:<path>#<key>" values loaded into k at
+// or below section (all values when empty) with the secrets, reading every
+// secret path once.
+func resolveVaultRefs(k *koanf.Koanf, section string, opt options) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opt.remoteTimeout)
+	defer cancel()
+
+	secrets := make(map[string]map[string]any)
+	for key, value := range k.All() {
+		if section != "" && key != section && !strings.HasPrefix(key, section+".") {
+			continue
+		}
+		ref, ok := value.(string)
+		if !ok || !strings.HasPrefix(ref, vaultRefPrefix) {
+			continue
+		}
+		path, secretKey, ok := strings.Cut(strings.TrimPrefix(ref, vaultRefPrefix), "#")
+		if !ok || path == "" || secretKey == "" {
+			return fmt.Errorf("config: %s: invalid Vault reference %q, want vault:<path>#<key>", key, ref)
+		}
+		data, ok := secrets[path]
+		if !ok {
+			var err error
+			if data, err = opt.vault.readSecret(ctx, path); err != nil {
+				return fmt.Errorf("config: %s: %w", key, err)
+			}
+			secrets[path] = data
+		}
+		secret, ok := data[secretKey]
+		if !ok {
+			return fmt.Errorf("config: %s: vault secret %q has no key %q", key, path, secretKey)
+		}
+		if err := k.Set(key, secret); err != nil {
+			return fmt.Errorf("config: %s: %w", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultWatchDebounce is how long Watch waits for file events to settle
+// before reloading, since editors and deployments often write a file in
+// several steps.
+const defaultWatchDebounce = 100 * time.Millisecond
+
+// WithWatchDebounce sets how long Watch waits after the last file event before
+// reloading. Default: 100ms.
+func WithWatchDebounce(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.watchDebounce = d
+		}
+	}
+}
+
+// WithWatchErrorHandler sets a function called when a reload fails to read,
+// parse or validate the file, or is rejected by onChange. The previous
+// configuration stays active. By default such errors are dropped.
+func WithWatchErrorHandler(fn func(error)) Option {
+	return func(o *options) {
+		o.watchErrorHandler = fn
+	}
+}
+
+// WithWatchChanges sets a function called with the changed keys (see Diff)
+// after a reload was applied, e.g. to publish an audit event.
+func WithWatchChanges(fn func(changes []Change)) Option {
+	return func(o *options) {
+		o.watchChanges = fn
+	}
+}
+
+// WithWatchLogger logs every applied reload to logger, with one attribute per
+// changed key ("old -> new", secrets masked), so operators can audit which
+// settings changed.
+func WithWatchLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.watchLogger = logger
+	}
+}
+
+// Watcher reloads a configuration file when it changes (see Watch).
+type Watcher[T any] struct {
+	path      string
+	opts      []Option
+	o         options
+	onChange  func(old, new T) error
+	current   atomic.Pointer[T]
+	watcher   *fsnotify.Watcher
+	remote    chan error
+	cancel    context.CancelFunc
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+
+	// reloadMu serializes reloads, so onChange always receives the active
+	// value as old and changes are applied in the order they were accepted.
+	reloadMu sync.Mutex
+}
+
+// Watch loads path into target like Load, then reloads it whenever the file
+// changes, so settings such as log levels or pool sizes can be tuned without a
+// restart:
+//
+//	var cfg AppConfig
+//	w, err := config.Watch("config.yaml", &cfg, func(old, new AppConfig) error {
+//	    if new.Database.URL != old.Database.URL {
+//	        return errors.New("database URL cannot change at runtime")
+//	    }
+//	    logLevel.Set(new.LogLevel)
+//	    return nil
+//	})
+//	defer w.Close()
+//	...
+//	cfg := w.Get()
+//
+// A reload loads the file into a new value with the same environment
+// overrides and defaults, validates it with its Validate() error method when T
+// has one, and calls onChange with the active and the new value (see Diff
+// for the changed keys). The new value is applied only when onChange (which
+// may be nil) returns nil; Get then returns it, and the changes are passed to
+// WithWatchChanges and WithWatchLogger. Reloads that do not change the value
+// are skipped.
+//
+// target only receives the initial load; read later values with Get, which is
+// safe for concurrent use. The directory of path is watched, so files
+// replaced by rename (editors, Kubernetes ConfigMap updates) are followed.
+// Watch reads from the operating system file system; WithFileSystem is not
+// supported. An http:// or https:// path is polled at the HTTPConfig
+// PollInterval instead, with conditional requests (see WithHTTP), and
+// StdinPath cannot be watched. Remote providers implementing RemoteWatcher (see WithRemote) are
+// watched as well; with an empty path, only they are.
+func Watch[T any](path string, target *T, onChange func(old, new T) error, opts ...Option) (*Watcher[T], error) {
+	if target == nil {
+		return nil, fmt.Errorf("config: target cannot be nil")
+	}
+	if path == StdinPath {
+		return nil, fmt.Errorf("config: cannot watch stdin")
+	}
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if isURL(path) && o.http == nil {
+		// Share one source between reloads for its ETag cache.
+		o.http = NewHTTPSource(HTTPConfig{})
+		opts = append(opts[:len(opts):len(opts)], WithHTTP(o.http))
+	}
+
+	if err := Load(path, target, opts...); err != nil {
+		return nil, err
+	}
+	if err := validate(target); err != nil {
+		return nil, err
+	}
+
+	var fw *fsnotify.Watcher
+	if path != "" && !isURL(path) {
+		var err error
+		if fw, err = fsnotify.NewWatcher(); err != nil {
+			return nil, fmt.Errorf("config: watch %q: %w", path, err)
+		}
+		if err := fw.Add(filepath.Dir(path)); err != nil {
+			fw.Close()
+			return nil, fmt.Errorf("config: watch %q: %w", path, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Watcher[T]{
+		path:     path,
+		opts:     opts,
+		o:        o,
+		onChange: onChange,
+		watcher:  fw,
+		remote:   make(chan error),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	initial := *target
+	w.current.Store(&initial)
+
+	w.wg.Add(1)
+	go w.run()
+	if isURL(path) {
+		w.wg.Add(1)
+		go w.poll(ctx, o.http.cfg.PollInterval)
+	}
+	for _, provider := range o.remotes {
+		if rw, ok := provider.(RemoteWatcher); ok {
+			w.wg.Add(1)
+			go w.watchRemote(ctx, rw)
+		}
+	}
+	return w, nil
+}
+
+// Get returns the active configuration.
+func (w *Watcher[T]) Get() T {
+	return *w.current.Load()
+}
+
+// Reload reloads the file immediately, as done on file changes. It returns
+// the error that would be passed to the WithWatchErrorHandler function.
+// Concurrent calls, and the reloads triggered by file events, run one at a
+// time.
+func (w *Watcher[T]) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	var next T
+	if err := Load(w.path, &next, w.opts...); err != nil {
+		return err
+	}
+	if err := validate(&next); err != nil {
+		return err
+	}
+
+	old := w.Get()
+	if reflect.DeepEqual(old, next) {
+		return nil
+	}
+	if w.onChange != nil {
+		if err := w.onChange(old, next); err != nil {
+			return fmt.Errorf("config: change rejected: %w", err)
+		}
+	}
+	w.current.Store(&next)
+
+	if w.o.watchChanges != nil || w.o.watchLogger != nil {
+		changes := Diff(old, next)
+		if w.o.watchChanges != nil {
+			w.o.watchChanges(changes)
+		}
+		if w.o.watchLogger != nil {
+			attrs := make([]any, len(changes))
+			for i, c := range changes {
+				attrs[i] = slog.String(c.Key, fmt.Sprintf("%v -> %v", c.Old, c.New))
+			}
+			w.o.watchLogger.Info("config reloaded", slog.Group("changes", attrs...))
+		}
+	}
+	return nil
+}
+
+// Close stops watching the file and the remote providers.
+func (w *Watcher[T]) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		w.cancel()
+		close(w.done)
+		if w.watcher != nil {
+			err = w.watcher.Close()
+		}
+		w.wg.Wait()
+	})
+	return err
+}
+
+// watchRemote forwards the notifications of a remote provider to run.
+func (w *Watcher[T]) watchRemote(ctx context.Context, rw RemoteWatcher) {
+	defer w.wg.Done()
+
+	notify := func(err error) {
+		select {
+		case w.remote <- err:
+		case <-ctx.Done():
+		}
+	}
+	if err := rw.Watch(ctx, notify); err != nil && ctx.Err() == nil {
+		notify(err)
+	}
+}
+
+// poll asks run to reload every interval. Reloads of an unchanged URL are
+// answered from the ETag cache and skipped as unchanged.
+func (w *Watcher[T]) poll(ctx context.Context, interval time.Duration) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			select {
+			case w.remote <- nil:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (w *Watcher[T]) run() {
+	defer w.wg.Done()
+
+	var (
+		timer   *time.Timer
+		pending <-chan time.Time
+		events  <-chan fsnotify.Event
+		errs    <-chan error
+	)
+	if w.watcher != nil {
+		events, errs = w.watcher.Events, w.watcher.Errors
+	}
+	schedule := func() {
+		if timer == nil {
+			timer = time.NewTimer(w.o.watchDebounce)
+		} else {
+			timer.Reset(w.o.watchDebounce)
+		}
+		pending = timer.C
+	}
+	for {
+		select {
+		case <-w.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if w.relevant(event) {
+				schedule()
+			}
+		case err, ok := <-errs:
+			if !ok {
+				return
+			}
+			w.handleError(fmt.Errorf("config: watch %q: %w", w.path, err))
+		case err := <-w.remote:
+			if err != nil {
+				w.handleError(fmt.Errorf("config: watch remote: %w", err))
+				continue
+			}
+			schedule()
+		case <-pending:
+			pending = nil
+			if err := w.Reload(); err != nil {
+				w.handleError(err)
+			}
+		}
+	}
+}
+
+// relevant reports whether event may have changed the file: a write, create
+// or rename of the file itself, or of a Kubernetes ConfigMap data link
+// ("..data").
+func (w *Watcher[T]) relevant(event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+	name := filepath.Base(event.Name)
+	return filepath.Clean(event.Name) == filepath.Clean(w.path) || strings.HasPrefix(name, "..")
+}
+
+func (w *Watcher[T]) handleError(err error) {
+	if w.o.watchErrorHandler != nil {
+		w.o.watchErrorHandler(err)
+	}
+}
+
+// validate calls the Validate method of target, if any.
+func validate(target any) error {
+	v, ok := target.(interface{ Validate() error })
+	if !ok {
+		return nil
+	}
+	if err := v.Validate(); err != nil {
+		return fmt.Errorf("config: validate: %w", err)
+	}
+	return nil
+}
@@ -21,7 +21,8 @@ func ToCMDError(err error) string {
 	return fmt.Sprintf("[%s] %s", CodeInternal, err.Error())
 }
 
-// ToCMDErrorWithStack returns the error message with stack trace
+// ToCMDErrorWithStack returns the error message with stack trace, including
+// the source lines captured with SetSourceContext.
 func ToCMDErrorWithStack(err error) string {
 	msg := ToCMDError(err)
 	if msg == "" {
@@ -35,6 +36,13 @@ func ToCMDErrorWithStack(err error) string {
 		sb.WriteString("\nStack Trace:\n")
 		for _, frame := range customErr.StackTrace {
 			sb.WriteString(fmt.Sprintf("  at %s:%d %s\n", frame.File, frame.Line, frame.Function))
+			for _, source := range frame.Source {
+				marker := " "
+				if source.Line == frame.Line {
+					marker = ">"
+				}
+				sb.WriteString(fmt.Sprintf("    %s %5d | %s\n", marker, source.Line, source.Text))
+			}
 		}
 		return sb.String()
 	}
@@ -35,8 +35,13 @@ func (c Code) String() string {
 	return string(c)
 }
 
-// HTTPStatusCode returns the HTTP status code for the error code
+// HTTPStatusCode returns the HTTP status code for the error code.
+// Codes registered with RegisterCode use their registered status.
 func (c Code) HTTPStatusCode() int {
+	if mapping, ok := registeredCode(c); ok {
+		return mapping.httpStatus
+	}
+
 	switch c {
 	case CodeInvalidArgument:
 		return 400 // Bad Request
@@ -66,6 +71,43 @@ func (c Code) HTTPStatusCode() int {
 	}
 }
 
+// CodeFromHTTPStatus returns the error code for an HTTP status code, the
+// reverse of HTTPStatusCode. Statuses without a direct equivalent map to the
+// closest code: 429 and 502 to CodeUnavailable, 504 to CodeTimeout, other 4xx
+// to CodeInvalidArgument and other 5xx to CodeInternal.
+func CodeFromHTTPStatus(status int) Code {
+	switch status {
+	case 400:
+		return CodeInvalidArgument
+	case 401:
+		return CodeUnauthenticated
+	case 403:
+		return CodePermission
+	case 404:
+		return CodeNotFound
+	case 408, 504:
+		return CodeTimeout
+	case 409:
+		return CodeConflict
+	case 422:
+		return CodeInvalidState
+	case 429, 502, 503:
+		return CodeUnavailable
+	case 499:
+		return CodeCancelled
+	case 501:
+		return CodeUnimplemented
+	}
+	switch {
+	case status >= 400 && status < 500:
+		return CodeInvalidArgument
+	case status >= 500 && status < 600:
+		return CodeInternal
+	default:
+		return CodeUnknown
+	}
+}
+
 // IsClientError returns true if the error is a client error (4xx)
 func (c Code) IsClientError() bool {
 	status := c.HTTPStatusCode()
@@ -79,8 +121,13 @@ func (c Code) IsServerError() bool {
 }
 
 // GRPCCode returns the gRPC status code for the error code
-// This is useful if you're also using gRPC
+// This is useful if you're also using gRPC.
+// Codes registered with RegisterCode use their registered gRPC code.
 func (c Code) GRPCCode() int {
+	if mapping, ok := registeredCode(c); ok {
+		return int(mapping.grpcCode)
+	}
+
 	switch c {
 	case CodeInvalidArgument:
 		return 3 // InvalidArgument
@@ -111,3 +158,38 @@ func (c Code) GRPCCode() int {
 		return 2 // Unknown
 	}
 }
+
+// CodeFromGRPCCode returns the error code for a gRPC status code, the reverse
+// of GRPCCode. gRPC codes without a direct equivalent map to the closest code:
+// Aborted to CodeConflict, OutOfRange to CodeInvalidArgument,
+// ResourceExhausted to CodeUnavailable and DataLoss to CodeInternal.
+func CodeFromGRPCCode(code int) Code {
+	switch code {
+	case 1: // Cancelled
+		return CodeCancelled
+	case 3, 11: // InvalidArgument, OutOfRange
+		return CodeInvalidArgument
+	case 4: // DeadlineExceeded
+		return CodeTimeout
+	case 5: // NotFound
+		return CodeNotFound
+	case 6: // AlreadyExists
+		return CodeAlreadyExists
+	case 7: // PermissionDenied
+		return CodePermission
+	case 8, 14: // ResourceExhausted, Unavailable
+		return CodeUnavailable
+	case 9: // FailedPrecondition
+		return CodeInvalidState
+	case 10: // Aborted
+		return CodeConflict
+	case 12: // Unimplemented
+		return CodeUnimplemented
+	case 13, 15: // Internal, DataLoss
+		return CodeInternal
+	case 16: // Unauthenticated
+		return CodeUnauthenticated
+	default:
+		return CodeUnknown
+	}
+}
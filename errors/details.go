@@ -0,0 +1,147 @@
+package errors
+
+import (
+	"errors"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// WithDetails adds every entry of details as an internal detail (see
+// WithDetail).
+func (e *Error) WithDetails(details map[string]any) *Error {
+	e = e.mutable()
+	for k, v := range details {
+		e.WithDetail(k, v)
+	}
+	return e
+}
+
+// DetailString returns the detail key of err (see GetDetails) if it is a
+// string.
+func DetailString(err error, key string) (string, bool) {
+	s, ok := GetDetails(err)[key].(string)
+	return s, ok
+}
+
+// DetailInt returns the detail key of err (see GetDetails) as an int. Besides
+// Go integers it accepts whole float64 values and decimal strings, as found
+// in errors restored from JSON or gRPC.
+func DetailInt(err error, key string) (int, bool) {
+	switch v := GetDetails(err)[key].(type) {
+	case int:
+		return v, true
+	case int8:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case uint:
+		return int(v), true
+	case uint8:
+		return int(v), true
+	case uint16:
+		return int(v), true
+	case uint32:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, false
+		}
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// DetailTime returns the detail key of err (see GetDetails) as a time.Time.
+// Besides time.Time values it accepts RFC 3339 strings, as found in errors
+// restored from JSON or gRPC.
+func DetailTime(err error, key string) (time.Time, bool) {
+	switch v := GetDetails(err)[key].(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+// WithPublicDetail adds a detail that is safe to show to clients. Details
+// added with WithDetail are internal: they are logged, reported and sent over
+// gRPC, but ToHTTPError and ToProblemDetails only render public details
+// unless asked to include everything (see HTTPOptions.IncludeInternalDetails).
+func (e *Error) WithPublicDetail(key string, value any) *Error {
+	e = e.WithDetail(key, value)
+	if e.public == nil {
+		e.public = make(map[string]bool)
+	}
+	e.public[key] = true
+	return e
+}
+
+// PublicDetails returns the public details of the first *Error in the chain
+// (see WithPublicDetail).
+func PublicDetails(err error) map[string]any {
+	var customErr *Error
+	if errors.As(err, &customErr) {
+		return customErr.publicDetails()
+	}
+	return nil
+}
+
+func (e *Error) publicDetails() map[string]any {
+	var details map[string]any
+	for k, v := range e.Details {
+		if !e.public[k] {
+			continue
+		}
+		if details == nil {
+			details = make(map[string]any, len(e.public))
+		}
+		details[k] = v
+	}
+	return details
+}
+
+// publicKeys returns the sorted keys of the public details, for encoding.
+func (e *Error) publicKeys() []string {
+	var keys []string
+	for k := range e.Details {
+		if e.public[k] {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// markPublic marks every current detail as public, for errors restored from a
+// client-facing representation.
+func (e *Error) markPublic() {
+	for k := range e.Details {
+		if e.public == nil {
+			e.public = make(map[string]bool, len(e.Details))
+		}
+		e.public[k] = true
+	}
+}
+
+func copyPublic(public map[string]bool) map[string]bool {
+	if len(public) == 0 {
+		return nil
+	}
+	return maps.Clone(public)
+}
@@ -3,7 +3,9 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"runtime"
+	"slices"
 )
 
 // Error represents a custom error with additional context
@@ -13,13 +15,32 @@ type Error struct {
 	Cause      error
 	StackTrace []StackFrame
 	Details    map[string]any
+	// MessageKey identifies the message for translation (see Translator).
+	MessageKey string
+	// Op is the operation that produced the error, e.g. "userservice.Create"
+	// (see WithOp and Ops).
+	Op string
+
+	// retryable overrides Code.Retryable when set (see Retryable).
+	retryable *bool
+	// public holds the keys of the Details that may be shown to clients (see
+	// WithPublicDetail).
+	public map[string]bool
+	// sentinel marks errors created by NewSentinel, which builder methods
+	// never mutate.
+	sentinel bool
+	// origin is the sentinel this error was cloned from, so that Is matches it.
+	origin *Error
 }
 
 // StackFrame represents a single frame in the stack trace
 type StackFrame struct {
-	File     string
-	Line     int
-	Function string
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+	// Source holds the surrounding source lines when enabled with
+	// SetSourceContext.
+	Source []SourceLine `json:"source,omitempty"`
 }
 
 // String returns a string representation of the stack frame
@@ -29,21 +50,66 @@ func (sf StackFrame) String() string {
 
 // Error implements the error interface
 func (e *Error) Error() string {
+	msg := fmt.Sprintf("[%s] %s", e.Code, e.Message)
+	if e.Op != "" {
+		msg = e.Op + ": " + msg
+	}
 	if e.Cause != nil {
-		return fmt.Sprintf("[%s] %s: %v", e.Code, e.Message, e.Cause)
+		return fmt.Sprintf("%s: %v", msg, e.Cause)
 	}
-	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+	return msg
 }
 
 func (e *Error) Unwrap() error {
 	return e.Cause
 }
 
+// Is reports whether target is the sentinel e was derived from, so that
+// errors.Is(ErrNotFound.WithDetail("id", id), ErrNotFound) holds.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && e.origin != nil && e.origin == t
+}
+
+// Clone returns a copy of the error with its own details and stack trace, so
+// it can be modified without affecting e. The cause is shared. A clone of a
+// sentinel (or of a clone) still matches the sentinel with errors.Is.
+func (e *Error) Clone() *Error {
+	clone := *e
+	clone.Details = maps.Clone(e.Details)
+	if clone.Details == nil {
+		clone.Details = make(map[string]any)
+	}
+	clone.public = copyPublic(e.public)
+	clone.StackTrace = slices.Clone(e.StackTrace)
+	if e.retryable != nil {
+		retryable := *e.retryable
+		clone.retryable = &retryable
+	}
+	clone.sentinel = false
+	if e.sentinel {
+		clone.origin = e
+	}
+	return &clone
+}
+
+// mutable returns e, or a clone of e when it is a sentinel, for builder
+// methods to modify.
+func (e *Error) mutable() *Error {
+	if e.sentinel {
+		return e.Clone()
+	}
+	return e
+}
+
+// WithDetail adds an internal detail (see WithPublicDetail).
 func (e *Error) WithDetail(key string, value any) *Error {
+	e = e.mutable()
 	if e.Details == nil {
 		e.Details = make(map[string]any)
 	}
 	e.Details[key] = value
+	delete(e.public, key)
 	return e
 }
 
@@ -51,6 +117,7 @@ func (e *Error) WithDetail(key string, value any) *Error {
 // Returns the error for chaining.
 func (e *Error) WithStackTrace() *Error {
 	if len(e.StackTrace) == 0 {
+		e = e.mutable()
 		e.StackTrace = captureStackTrace(1)
 	}
 	return e
@@ -67,32 +134,52 @@ func NewError(code Code, message string) *Error {
 	}
 }
 
-// NewSentinel creates a new error without a stack trace.
-// Alias to NewError for semantic clarity when defining package-level sentinel errors.
+// NewSentinel creates a new error without a stack trace for defining
+// package-level sentinel errors. Sentinels are copy-on-write: builder methods
+// such as WithDetail and WithStackTrace return a clone (see Clone) instead of
+// modifying the shared error, and the clone still matches the sentinel with
+// errors.Is.
 func NewSentinel(code Code, message string) *Error {
-	return NewError(code, message)
+	e := NewError(code, message)
+	e.sentinel = true
+	return e
 }
 
 // New creates a new error with a stack trace.
 func New(code Code, message string) *Error {
-	return &Error{
+	return created(&Error{
 		Code:       code,
 		Message:    message,
 		StackTrace: captureStackTrace(1),
 		Details:    make(map[string]any),
-	}
+	}, "")
 }
 
 func Newf(code Code, format string, args ...any) *Error {
-	return &Error{
+	return created(&Error{
 		Code:       code,
 		Message:    fmt.Sprintf(format, args...),
 		StackTrace: captureStackTrace(1),
 		Details:    make(map[string]any),
-	}
+	}, "")
 }
 
 func Wrap(err error, code Code, message string) *Error {
+	return wrap(err, code, message, "", 1)
+}
+
+func Wrapf(err error, code Code, format string, args ...any) *Error {
+	if err == nil {
+		return nil
+	}
+
+	return wrap(err, code, fmt.Sprintf(format, args...), "", 1)
+}
+
+// wrap implements Wrap, recording the error under component (see created).
+// skip is passed to captureStackTrace on behalf of the caller of wrap (1 = its
+// caller).
+func wrap(err error, code Code, message, component string, skip int) *Error {
 	if err == nil {
 		return nil
 	}
@@ -109,33 +196,26 @@ func Wrap(err error, code Code, message string) *Error {
 		// But for now, we trust the original stack trace or capture new if missing.
 		stackTrace := originalErr.StackTrace
 		if len(stackTrace) == 0 {
-			stackTrace = captureStackTrace(1)
+			stackTrace = captureStackTrace(skip + 1)
 		}
 
-		return &Error{
+		return created(&Error{
 			Code:       code,
 			Message:    message,
 			Cause:      err,
 			StackTrace: stackTrace,
 			Details:    details,
-		}
+			public:     copyPublic(originalErr.public),
+		}, component)
 	}
 
-	return &Error{
+	return created(&Error{
 		Code:       code,
 		Message:    message,
 		Cause:      err,
-		StackTrace: captureStackTrace(1),
+		StackTrace: captureStackTrace(skip + 1),
 		Details:    make(map[string]any),
-	}
-}
-
-func Wrapf(err error, code Code, format string, args ...any) *Error {
-	if err == nil {
-		return nil
-	}
-
-	return Wrap(err, code, fmt.Sprintf(format, args...))
+	}, component)
 }
 
 func Cause(err error) error {
@@ -157,6 +237,10 @@ func As(err error, target any) bool {
 	return errors.As(err, target)
 }
 
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
+
 func HasCode(err error, code Code) bool {
 	var customErr *Error
 	if errors.As(err, &customErr) {
@@ -177,6 +261,36 @@ func IsCode(err error, code Code) bool {
 	return HasCode(err, code)
 }
 
+// IsAnyCode reports whether err has one of codes, as checked by IsCode.
+//
+//	if errors.IsAnyCode(err, errors.CodeNotFound, errors.CodePermission) { ... }
+func IsAnyCode(err error, codes ...Code) bool {
+	var customErr *Error
+	if !errors.As(err, &customErr) {
+		return false
+	}
+	for _, code := range codes {
+		if customErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsClientError reports whether err maps to a 4xx HTTP status (see
+// HTTPStatusCode), i.e. the caller is at fault.
+func IsClientError(err error) bool {
+	status := HTTPStatusCode(err)
+	return status >= 400 && status < 500
+}
+
+// IsServerError reports whether err maps to a 5xx HTTP status (see
+// HTTPStatusCode). Errors that are not *Error count as server errors.
+func IsServerError(err error) bool {
+	status := HTTPStatusCode(err)
+	return status >= 500 && status < 600
+}
+
 func GetDetails(err error) map[string]any {
 	var customErr *Error
 	if errors.As(err, &customErr) {
@@ -186,29 +300,33 @@ func GetDetails(err error) map[string]any {
 }
 
 // captureStackTrace captures the current call stack.
-// skip indicates how many stack frames to skip (0 = captureStackTrace itself, 1 = caller, etc.)
+// skip indicates how many stack frames to skip (0 = the caller of captureStackTrace, 1 = its caller, etc.)
 func captureStackTrace(skip int) []StackFrame {
 	const maxDepth = 32
 	var pcs [maxDepth]uintptr
 	// runtime.Callers skip: 0 = Callers itself, 1 = captureStackTrace, 2 = caller of captureStackTrace
 	// We add 2 to skip to account for runtime.Callers and captureStackTrace itself
 	n := runtime.Callers(2+skip, pcs[:])
+	if n == 0 {
+		return nil
+	}
 
+	// CallersFrames expands inlined calls, which FuncForPC would attribute to
+	// the function they were inlined into.
 	frames := make([]StackFrame, 0, n)
-	for i := 0; i < n; i++ {
-		pc := pcs[i]
-		fn := runtime.FuncForPC(pc)
-		if fn == nil {
-			continue
-		}
-
-		file, line := fn.FileLine(pc)
+	iter := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := iter.Next()
 		frames = append(frames, StackFrame{
-			File:     file,
-			Line:     line,
-			Function: fn.Name(),
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
 		})
+		if !more {
+			break
+		}
 	}
+	attachSource(frames)
 
 	return frames
 }
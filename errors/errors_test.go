@@ -1,10 +1,20 @@
 package errors
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -241,3 +251,979 @@ func TestWrapWithStackTraceDepth(t *testing.T) {
 		t.Error("Stack trace should contain TestWrapWithStackTraceDepth function")
 	}
 }
+
+func TestToProblemDetails(t *testing.T) {
+	err := New(CodeNotFound, "user not found").
+		WithPublicDetail("user_id", "42").
+		WithPublicDetail("status", "ignored").
+		WithDetail("constraint", "users_pkey")
+
+	problem := ToProblemDetails(err, ProblemOptions{
+		TypeBaseURI: "https://errors.example.com/",
+		Instance:    "/users/42",
+	})
+
+	if problem.Type != "https://errors.example.com/NOT_FOUND" {
+		t.Errorf("unexpected type %q", problem.Type)
+	}
+	if problem.Title != "Not Found" || problem.Status != 404 {
+		t.Errorf("unexpected title/status %q/%d", problem.Title, problem.Status)
+	}
+	if problem.Detail != "user not found" || problem.Instance != "/users/42" {
+		t.Errorf("unexpected detail/instance %q/%q", problem.Detail, problem.Instance)
+	}
+	if problem.Extensions["code"] != "NOT_FOUND" || problem.Extensions["user_id"] != "42" {
+		t.Errorf("unexpected extensions %v", problem.Extensions)
+	}
+	if _, ok := problem.Extensions["constraint"]; ok {
+		t.Error("internal details must not be rendered")
+	}
+	if _, ok := problem.Extensions["status"]; ok {
+		t.Error("details must not override standard members")
+	}
+
+	data, marshalErr := json.Marshal(problem)
+	if marshalErr != nil {
+		t.Fatalf("marshal: %v", marshalErr)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded["status"] != float64(404) || decoded["user_id"] != "42" || decoded["code"] != "NOT_FOUND" {
+		t.Errorf("unexpected JSON %s", data)
+	}
+
+	var roundTrip ProblemDetails
+	if err := json.Unmarshal(data, &roundTrip); err != nil {
+		t.Fatalf("unmarshal problem: %v", err)
+	}
+	if roundTrip.Status != 404 || roundTrip.Extensions["user_id"] != "42" {
+		t.Errorf("unexpected round trip %+v", roundTrip)
+	}
+}
+
+func TestToProblemDetailsDefaults(t *testing.T) {
+	problem := ToProblemDetails(New(CodeCancelled, "client went away"), ProblemOptions{})
+	if problem.Type != "about:blank" {
+		t.Errorf("expected about:blank type, got %q", problem.Type)
+	}
+	if problem.Status != 499 || problem.Title != "CANCELLED" {
+		t.Errorf("unexpected status/title %d/%q", problem.Status, problem.Title)
+	}
+
+	problem = ToProblemDetails(nil, ProblemOptions{})
+	if problem.Status != 500 {
+		t.Errorf("expected status 500 for nil error, got %d", problem.Status)
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/orders/7", nil)
+
+	if err := WriteProblem(rec, req, New(CodeInvalidArgument, "bad order"), ProblemOptions{}); err != nil {
+		t.Fatalf("WriteProblem: %v", err)
+	}
+
+	if rec.Code != 400 {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	var problem ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if problem.Instance != "/orders/7" || problem.Detail != "bad order" {
+		t.Errorf("unexpected problem %+v", problem)
+	}
+}
+
+func TestErrorJSONRoundTrip(t *testing.T) {
+	base := Wrap(errors.New("connection refused"), CodeDatabase, "query failed").
+		WithDetail("table", "users")
+	err := Wrap(base, CodeUnavailable, "cannot load user").WithDetail("user_id", 42)
+
+	data, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatalf("marshal: %v", marshalErr)
+	}
+
+	var decoded *Error
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if decoded.Code != CodeUnavailable || decoded.Message != "cannot load user" {
+		t.Errorf("unexpected top-level error %v", decoded)
+	}
+	if decoded.Details["user_id"] != float64(42) {
+		t.Errorf("unexpected details %v", decoded.Details)
+	}
+	if len(decoded.StackTrace) != len(err.StackTrace) {
+		t.Errorf("expected %d frames, got %d", len(err.StackTrace), len(decoded.StackTrace))
+	}
+	if !HasCode(decoded.Cause, CodeDatabase) {
+		t.Errorf("expected database cause, got %v", decoded.Cause)
+	}
+	if GetDetails(decoded.Cause)["table"] != "users" {
+		t.Errorf("expected cause details to survive, got %v", GetDetails(decoded.Cause))
+	}
+	if decoded.Error() != err.Error() {
+		t.Errorf("expected %q, got %q", err.Error(), decoded.Error())
+	}
+	if root := Cause(decoded); root.Error() != "connection refused" {
+		t.Errorf("unexpected root cause %v", root)
+	}
+}
+
+func TestFromGRPCErrorRoundTrip(t *testing.T) {
+	err := New(CodeInvalidState, "order already shipped").WithDetail("order_id", 7)
+
+	got := FromGRPCError(ToGRPCError(err))
+	if got.Code != CodeInvalidState {
+		t.Errorf("expected code %s, got %s", CodeInvalidState, got.Code)
+	}
+	if got.Message != "order already shipped" {
+		t.Errorf("unexpected message %q", got.Message)
+	}
+	if got.Details["order_id"] != "7" {
+		t.Errorf("unexpected details %v", got.Details)
+	}
+}
+
+func TestFromGRPCError(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "bad request").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "email", Description: "must be a valid email"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("with details: %v", err)
+	}
+
+	got := FromGRPCError(st.Err())
+	if got.Code != CodeInvalidArgument {
+		t.Errorf("expected code %s, got %s", CodeInvalidArgument, got.Code)
+	}
+	violations := FieldViolations(got)
+	if len(violations) != 1 || violations[0].Field != "email" {
+		t.Errorf("unexpected field violations %v", violations)
+	}
+
+	if got := FromGRPCError(status.Error(codes.Aborted, "retry")); got.Code != CodeConflict {
+		t.Errorf("expected code %s for Aborted, got %s", CodeConflict, got.Code)
+	}
+	if got := FromGRPCError(status.Error(codes.OK, "")); got != nil {
+		t.Errorf("expected nil for OK status, got %v", got)
+	}
+	plain := errors.New("not a status")
+	if got := FromGRPCError(plain); got.Code != CodeUnknown || got.Cause != plain {
+		t.Errorf("unexpected conversion of plain error %v", got)
+	}
+}
+
+func TestFromHTTPResponse(t *testing.T) {
+	resp := ToHTTPResponse(New(CodeNotFound, "user not found").WithPublicDetail("user_id", "42"), false)
+	body, err := resp.WriteJSON()
+	if err != nil {
+		t.Fatalf("write json: %v", err)
+	}
+	problem, marshalErr := json.Marshal(ToProblemDetails(New(CodeConflict, "version mismatch").WithPublicDetail("version", 3), ProblemOptions{}))
+	if marshalErr != nil {
+		t.Fatalf("marshal problem: %v", marshalErr)
+	}
+
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantCode    Code
+		wantMessage string
+		wantDetail  string
+		wantValue   any
+	}{
+		{
+			name: "http response", status: 404, body: body,
+			wantCode: CodeNotFound, wantMessage: "user not found", wantDetail: "user_id", wantValue: "42",
+		},
+		{
+			name: "http error", status: 429, body: `{"code":"QUOTA_EXCEEDED","message":"slow down"}`,
+			wantCode: "QUOTA_EXCEEDED", wantMessage: "slow down",
+		},
+		{
+			name: "problem details", status: 409, body: string(problem),
+			wantCode: CodeConflict, wantMessage: "version mismatch", wantDetail: "version", wantValue: float64(3),
+		},
+		{
+			name: "problem without code", status: 403, body: `{"type":"about:blank","title":"Forbidden","status":403}`,
+			wantCode: CodePermission, wantMessage: "Forbidden",
+		},
+		{
+			name: "raw body", status: 502, body: "<html>bad gateway</html>",
+			wantCode: CodeUnavailable, wantMessage: "Bad Gateway", wantDetail: "body", wantValue: "<html>bad gateway</html>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromHTTPResponse(tt.status, []byte(tt.body))
+			if got.Code != tt.wantCode || got.Message != tt.wantMessage {
+				t.Errorf("got [%s] %q, want [%s] %q", got.Code, got.Message, tt.wantCode, tt.wantMessage)
+			}
+			if tt.wantDetail != "" && got.Details[tt.wantDetail] != tt.wantValue {
+				t.Errorf("detail %q = %v, want %v", tt.wantDetail, got.Details[tt.wantDetail], tt.wantValue)
+			}
+		})
+	}
+
+	if got := FromHTTPResponse(200, []byte(`{}`)); got != nil {
+		t.Errorf("expected nil for status 200, got %v", got)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	var v Validation
+	if v.Err() != nil {
+		t.Fatal("expected nil error without violations")
+	}
+
+	v.Add("email", "required", "is required")
+	v.AddViolation(FieldViolation{Field: "name", Code: "too_long", Description: "must be at most 3 characters", Params: map[string]any{"max": 3}})
+	err := v.Err()
+
+	if !IsCode(err, CodeInvalidArgument) {
+		t.Fatalf("expected invalid argument, got %v", err)
+	}
+	if err.Error() != "[INVALID_ARGUMENT] validation failed: email, name" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	wrapped := Wrap(err, CodeInvalidArgument, "create user")
+	violations := FieldViolations(wrapped)
+	if len(violations) != 2 || violations[1].Params["max"] != 3 {
+		t.Fatalf("unexpected violations %+v", violations)
+	}
+
+	data, marshalErr := json.Marshal(wrapped)
+	if marshalErr != nil {
+		t.Fatalf("marshal: %v", marshalErr)
+	}
+	var decoded *Error
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if violations := FieldViolations(decoded); len(violations) != 2 || violations[0].Code != "required" {
+		t.Errorf("unexpected violations after JSON round trip %+v", violations)
+	}
+}
+
+func TestWithFieldViolationDerived(t *testing.T) {
+	sentinel := NewSentinel(CodeInvalidArgument, "invalid order")
+	// Violations with spare capacity, as left by append.
+	sentinel.Details[fieldViolationsKey] = append(make([]FieldViolation, 0, 4), FieldViolation{Field: "id", Description: "is required"})
+
+	first := sentinel.WithFieldViolation(FieldViolation{Field: "quantity", Description: "must be positive"})
+	second := sentinel.WithFieldViolation(FieldViolation{Field: "price", Description: "must be positive"})
+
+	for _, tt := range []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"sentinel", sentinel, []string{"id"}},
+		{"first", first, []string{"id", "quantity"}},
+		{"second", second, []string{"id", "price"}},
+	} {
+		violations := FieldViolations(tt.err)
+		fields := make([]string, len(violations))
+		for i, violation := range violations {
+			fields[i] = violation.Field
+		}
+		if !slices.Equal(fields, tt.want) {
+			t.Errorf("%s violations = %v, want %v", tt.name, fields, tt.want)
+		}
+	}
+
+	FieldViolations(first)[0].Field = "changed"
+	if got := FieldViolations(first)[0].Field; got != "id" {
+		t.Errorf("expected FieldViolations to return a copy, got field %q", got)
+	}
+}
+
+func TestInvalidFieldGRPC(t *testing.T) {
+	err := InvalidField("email", "must be a valid email")
+	if len(err.StackTrace) == 0 {
+		t.Error("InvalidField should capture stack trace")
+	}
+
+	st, ok := status.FromError(ToGRPCError(err))
+	if !ok {
+		t.Fatal("expected grpc status error")
+	}
+	var badRequest *errdetails.BadRequest
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*errdetails.BadRequest); ok {
+			badRequest = d
+		}
+	}
+	if badRequest == nil || len(badRequest.GetFieldViolations()) != 1 ||
+		badRequest.GetFieldViolations()[0].GetReason() != ViolationInvalid {
+		t.Fatalf("unexpected BadRequest detail %v", badRequest)
+	}
+
+	violations := FieldViolations(FromGRPCError(st.Err()))
+	if len(violations) != 1 || violations[0].Field != "email" || violations[0].Description != "must be a valid email" {
+		t.Errorf("unexpected violations %+v", violations)
+	}
+}
+
+type retryableErr bool
+
+func (r retryableErr) Error() string   { return "retryable" }
+func (r retryableErr) Retryable() bool { return bool(r) }
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string { return "i/o timeout" }
+func (timeoutErr) Timeout() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "unavailable", err: New(CodeUnavailable, "down"), want: true},
+		{name: "timeout", err: New(CodeTimeout, "slow"), want: true},
+		{name: "invalid argument", err: New(CodeInvalidArgument, "bad"), want: false},
+		{name: "explicit override", err: New(CodeInvalidArgument, "bad").Retryable(true), want: true},
+		{name: "explicit false", err: New(CodeUnavailable, "down").Retryable(false), want: false},
+		{name: "inner flag wins over outer code", err: Wrap(New(CodeDatabase, "deadlock").Retryable(true), CodeInternal, "save"), want: true},
+		{name: "outer code", err: Wrap(New(CodeInvalidArgument, "bad"), CodeUnavailable, "proxy"), want: true},
+		{name: "retryable reporter", err: Wrap(retryableErr(true), CodeDatabase, "query"), want: true},
+		{name: "timeout reporter", err: timeoutErr{}, want: true},
+		{name: "plain error", err: errors.New("boom"), want: false},
+		{name: "canceled", err: Wrap(context.Canceled, CodeUnavailable, "stop"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryableJSONRoundTrip(t *testing.T) {
+	data, err := json.Marshal(New(CodeInvalidArgument, "bad").Retryable(true))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded *Error
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !IsRetryable(decoded) {
+		t.Error("expected retryable flag to survive JSON round trip")
+	}
+}
+
+var codeQuotaExceeded = RegisterCode("QUOTA_EXCEEDED", 429, codes.ResourceExhausted)
+
+func TestRegisterCode(t *testing.T) {
+	err := New(codeQuotaExceeded, "quota exceeded")
+
+	if status := HTTPStatusCode(err); status != 429 {
+		t.Errorf("expected status 429, got %d", status)
+	}
+	if !codeQuotaExceeded.IsClientError() {
+		t.Error("expected registered 429 code to be a client error")
+	}
+	st, _ := status.FromError(ToGRPCError(err))
+	if st.Code() != codes.ResourceExhausted {
+		t.Errorf("expected ResourceExhausted, got %s", st.Code())
+	}
+	if got := FromGRPCError(st.Err()); got.Code != codeQuotaExceeded {
+		t.Errorf("expected code to survive gRPC round trip, got %s", got.Code)
+	}
+	if status := Code("NOT_REGISTERED").HTTPStatusCode(); status != 500 {
+		t.Errorf("expected fallback status 500, got %d", status)
+	}
+}
+
+func TestRegisterCodeInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   Code
+		status int
+		grpc   codes.Code
+	}{
+		{name: "empty code", code: "", status: 400, grpc: codes.InvalidArgument},
+		{name: "success status", code: "BAD_STATUS", status: 200, grpc: codes.InvalidArgument},
+		{name: "ok grpc code", code: "BAD_GRPC", status: 400, grpc: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			RegisterCode(tt.code, tt.status, tt.grpc)
+		})
+	}
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	SetTranslator(TranslatorFunc(func(lang, key string, params map[string]any) (string, bool) {
+		switch {
+		case key == "user.not_found" && lang == "fr":
+			return fmt.Sprintf("utilisateur %v introuvable", params["user_id"]), true
+		case key == "user.not_found" && lang == "":
+			return "user not found (default)", true
+		case key == "NOT_FOUND" && lang == "fr":
+			return "introuvable", true
+		}
+		return "", false
+	}))
+	t.Cleanup(func() { SetTranslator(nil) })
+
+	err := New(CodeNotFound, "user 42 not found").WithMessageKey("user.not_found").WithDetail("user_id", 42)
+
+	if got := LocalizedMessage(err, "fr"); got != "utilisateur 42 introuvable" {
+		t.Errorf("unexpected French message %q", got)
+	}
+	if got := LocalizedMessage(err, "de"); got != "user 42 not found" {
+		t.Errorf("expected English fallback, got %q", got)
+	}
+	if got := LocalizedMessage(New(CodeNotFound, "missing"), "fr"); got != "introuvable" {
+		t.Errorf("expected translation by code, got %q", got)
+	}
+	if got := ToHTTPError(err, false).Message; got != "user not found (default)" {
+		t.Errorf("expected default-language HTTP message, got %q", got)
+	}
+	if !strings.Contains(err.Error(), "user 42 not found") {
+		t.Errorf("internal message must stay untranslated, got %q", err.Error())
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set("Accept-Language", "fr, en;q=0.8")
+	if err := WriteProblem(rec, req, err, ProblemOptions{}); err != nil {
+		t.Fatalf("WriteProblem: %v", err)
+	}
+	var problem ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if problem.Detail != "utilisateur 42 introuvable" {
+		t.Errorf("expected localized problem detail, got %q", problem.Detail)
+	}
+}
+
+func TestHTTPOptionsWithAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		fallback       string
+		want           string
+	}{
+		{"fr-CH, fr;q=0.9, en;q=0.8", "", "fr-CH"},
+		{"de", "en", "de"},
+		{"", "en", "en"},
+		{"*", "en", "en"},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		opts := HTTPOptions{Language: tt.fallback, IncludeStackTrace: true}.WithAcceptLanguage(tt.acceptLanguage)
+		if opts.Language != tt.want || !opts.IncludeStackTrace {
+			t.Errorf("WithAcceptLanguage(%q) with default %q = %+v, want Language %q", tt.acceptLanguage, tt.fallback, opts, tt.want)
+		}
+	}
+}
+
+func TestOps(t *testing.T) {
+	base := New(CodeNotFound, "user not found").WithOp("userrepo.Get")
+	err := WithOp(WithOp(base, "userservice.Get"), "api.GetUser")
+
+	want := []string{"api.GetUser", "userservice.Get", "userrepo.Get"}
+	got := Ops(err)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Ops() = %v, want %v", got, want)
+	}
+	if err.Error() != "api.GetUser: userservice.Get: userrepo.Get: [NOT_FOUND] user not found" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if GetCode(err) != CodeNotFound || !Is(err, base) {
+		t.Error("WithOp must not hide the wrapped error")
+	}
+	if HTTPStatusCode(err) != 404 {
+		t.Errorf("expected status 404, got %d", HTTPStatusCode(err))
+	}
+	if WithOp(nil, "noop") != nil {
+		t.Error("WithOp(nil) should return nil")
+	}
+
+	wrapped := Wrap(err, CodeInternal, "request failed")
+	data, marshalErr := json.Marshal(wrapped)
+	if marshalErr != nil {
+		t.Fatalf("marshal: %v", marshalErr)
+	}
+	var decoded *Error
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got := Ops(decoded); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Ops() after JSON round trip = %v, want %v", got, want)
+	}
+	if decoded.Error() != wrapped.Error() {
+		t.Errorf("expected %q, got %q", wrapped.Error(), decoded.Error())
+	}
+}
+
+func TestReport(t *testing.T) {
+	Report(New(CodeInternal, "no reporter configured"))
+
+	var reported []error
+	SetReporter(ReporterFunc(func(ctx context.Context, err error) {
+		reported = append(reported, err)
+	}))
+	t.Cleanup(func() { SetReporter(nil) })
+
+	err := New(CodeInternal, "boom")
+	Report(err)
+	ReportContext(context.Background(), nil)
+
+	if len(reported) != 1 || reported[0] != err {
+		t.Fatalf("unexpected reported errors %v", reported)
+	}
+}
+
+func panicky(v any) (err error) {
+	defer Recover(&err)
+	panic(v)
+}
+
+func TestRecover(t *testing.T) {
+	err := panicky("boom")
+	if !IsCode(err, CodeInternal) {
+		t.Fatalf("expected internal error, got %v", err)
+	}
+	if GetDetails(err)["panic"] != "boom" {
+		t.Errorf("unexpected details %v", GetDetails(err))
+	}
+	var customErr *Error
+	As(err, &customErr)
+	if len(customErr.StackTrace) == 0 || !strings.Contains(customErr.StackTrace[0].Function, "panicky") {
+		t.Errorf("expected stack to start at the panicking function, got %v", customErr.StackTrace)
+	}
+
+	cause := errors.New("bad state")
+	if err := panicky(cause); !errors.Is(err, cause) {
+		t.Errorf("expected panic error as cause, got %v", err)
+	}
+
+	err = RecoverFunc(func() error {
+		var m map[string]int
+		m["x"] = 1
+		return nil
+	})
+	if !IsCode(err, CodeInternal) || !strings.Contains(err.Error(), "nil map") {
+		t.Errorf("unexpected RecoverFunc error %v", err)
+	}
+	if err := RecoverFunc(func() error { return nil }); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	defer func() {
+		if recover() != http.ErrAbortHandler {
+			t.Error("expected http.ErrAbortHandler to be re-panicked")
+		}
+	}()
+	_ = panicky(http.ErrAbortHandler)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	var recorded []Code
+	interceptor := UnaryServerInterceptor(WithInterceptorMetrics(func(_ context.Context, method string, code Code) {
+		if method != "/pkg.Service/Method" {
+			t.Errorf("unexpected method %q", method)
+		}
+		recorded = append(recorded, code)
+	}))
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Service/Method"}
+	call := func(handler grpc.UnaryHandler) error {
+		_, err := interceptor(context.Background(), nil, info, handler)
+		return err
+	}
+
+	err := call(func(context.Context, any) (any, error) {
+		return nil, New(CodeNotFound, "user not found")
+	})
+	if got := FromGRPCError(err); got.Code != CodeNotFound || got.Message != "user not found" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	err = call(func(context.Context, any) (any, error) {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected Internal for a panic, got %v", err)
+	}
+
+	err = call(func(context.Context, any) (any, error) {
+		return nil, fmt.Errorf("query: %w", context.DeadlineExceeded)
+	})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+
+	resp, err := interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	if resp != "ok" || err != nil {
+		t.Errorf("unexpected result %v, %v", resp, err)
+	}
+
+	want := []Code{CodeNotFound, CodeInternal, CodeTimeout}
+	if fmt.Sprint(recorded) != fmt.Sprint(want) {
+		t.Errorf("expected recorded codes %v, got %v", want, recorded)
+	}
+}
+
+func TestPublicDetails(t *testing.T) {
+	err := New(CodeConflict, "email already registered").
+		WithPublicDetail("field", "email").
+		WithDetail("constraint", "users_email_key")
+
+	httpErr := ToHTTPError(Wrap(err, CodeConflict, "cannot create user"), false)
+	if len(httpErr.Details) != 1 || httpErr.Details["field"] != "email" {
+		t.Errorf("expected only public details, got %v", httpErr.Details)
+	}
+	httpErr = ToHTTPErrorWithOptions(err, HTTPOptions{IncludeInternalDetails: true})
+	if httpErr.Details["constraint"] != "users_email_key" {
+		t.Errorf("expected internal details, got %v", httpErr.Details)
+	}
+	problem := ToProblemDetails(err, ProblemOptions{IncludeInternalDetails: true})
+	if problem.Extensions["constraint"] != "users_email_key" {
+		t.Errorf("expected internal details, got %v", problem.Extensions)
+	}
+
+	// Overwriting a public detail with WithDetail makes it internal.
+	err.WithDetail("field", "email_address")
+	if details := PublicDetails(err); len(details) != 0 {
+		t.Errorf("expected no public details, got %v", details)
+	}
+
+	data, marshalErr := json.Marshal(New(CodeNotFound, "missing").WithPublicDetail("id", 1).WithDetail("host", "db-1"))
+	if marshalErr != nil {
+		t.Fatalf("marshal: %v", marshalErr)
+	}
+	var decoded *Error
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if details := PublicDetails(decoded); len(details) != 1 || details["id"] != float64(1) {
+		t.Errorf("expected the public keys to round-trip, got %v", details)
+	}
+
+	if details := PublicDetails(InvalidField("email", "is required")); details[fieldViolationsKey] == nil {
+		t.Errorf("expected field violations to be public, got %v", details)
+	}
+}
+
+func TestTypedDetails(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	err := Wrap(New(CodeConflict, "version mismatch").WithDetails(map[string]any{
+		"resource":   "order",
+		"version":    3,
+		"expires_at": at,
+	}), CodeConflict, "cannot update order")
+
+	if s, ok := DetailString(err, "resource"); !ok || s != "order" {
+		t.Errorf("DetailString = %q, %v", s, ok)
+	}
+	if n, ok := DetailInt(err, "version"); !ok || n != 3 {
+		t.Errorf("DetailInt = %d, %v", n, ok)
+	}
+	if got, ok := DetailTime(err, "expires_at"); !ok || !got.Equal(at) {
+		t.Errorf("DetailTime = %v, %v", got, ok)
+	}
+	if _, ok := DetailInt(err, "resource"); ok {
+		t.Error("expected DetailInt to reject a non-numeric string")
+	}
+	if _, ok := DetailString(err, "missing"); ok {
+		t.Error("expected a missing detail to be reported")
+	}
+
+	data, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatalf("marshal: %v", marshalErr)
+	}
+	var decoded *Error
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if n, ok := DetailInt(decoded, "version"); !ok || n != 3 {
+		t.Errorf("DetailInt after JSON = %d, %v", n, ok)
+	}
+	if got, ok := DetailTime(decoded, "expires_at"); !ok || !got.Equal(at) {
+		t.Errorf("DetailTime after JSON = %v, %v", got, ok)
+	}
+	if n, ok := DetailInt(FromGRPCError(ToGRPCError(err)), "version"); !ok || n != 3 {
+		t.Errorf("DetailInt after gRPC = %d, %v", n, ok)
+	}
+}
+
+func TestSetCounter(t *testing.T) {
+	counts := make(map[string]int)
+	SetCounter(CounterFunc(func(code Code, component string) {
+		counts[component+"/"+code.String()]++
+	}))
+	t.Cleanup(func() { SetCounter(nil) })
+
+	_ = New(CodeNotFound, "user not found")
+	_ = Wrapf(errors.New("refused"), CodeUnavailable, "cannot reach %s", "db")
+	_ = Wrap(nil, CodeInternal, "ignored")
+	_ = NewSentinel(CodeNotFound, "sentinel")
+	_ = InvalidField("email", "is required")
+
+	billing := Component("billing")
+	err := billing.Wrap(errors.New("declined"), CodeThirdParty, "cannot charge card")
+	_ = billing.New(CodeNotFound, "invoice not found")
+
+	want := map[string]int{
+		"/NOT_FOUND":                1,
+		"/UNAVAILABLE":              1,
+		"/INVALID_ARGUMENT":         1,
+		"billing/THIRD_PARTY_ERROR": 1,
+		"billing/NOT_FOUND":         1,
+	}
+	if fmt.Sprint(counts) != fmt.Sprint(want) {
+		t.Errorf("expected counts %v, got %v", want, counts)
+	}
+	if err.Code != CodeThirdParty || len(err.StackTrace) == 0 {
+		t.Errorf("unexpected component error %#v", err)
+	}
+}
+
+func TestSetSourceContext(t *testing.T) {
+	SetSourceContext(1)
+	t.Cleanup(func() { SetSourceContext(0) })
+
+	err := New(CodeInternal, "snippet") // marker line
+	top := err.StackTrace[0]
+	if len(top.Source) != 3 {
+		t.Fatalf("expected 3 source lines, got %v", top.Source)
+	}
+	if top.Source[1].Line != top.Line || !strings.Contains(top.Source[1].Text, "// marker line") {
+		t.Errorf("unexpected source %v", top.Source)
+	}
+	if len(err.StackTrace) > 1 && err.StackTrace[1].Source != nil {
+		t.Error("expected only the top application frame to carry source")
+	}
+	if !strings.Contains(ToCMDErrorWithStack(err), "> ") {
+		t.Errorf("expected the source to be rendered, got %s", ToCMDErrorWithStack(err))
+	}
+
+	SetSourceContext(0)
+	if err := New(CodeInternal, "no snippet"); err.StackTrace[0].Source != nil {
+		t.Error("expected no source when disabled")
+	}
+}
+
+func TestSentinelCopyOnWrite(t *testing.T) {
+	sentinel := NewSentinel(CodeNotFound, "user not found")
+
+	err := sentinel.WithStackTrace().WithDetail("user_id", 42).Retryable(false)
+	if err == sentinel {
+		t.Fatal("expected builder methods to clone the sentinel")
+	}
+	if len(sentinel.Details) != 0 || len(sentinel.StackTrace) != 0 || sentinel.retryable != nil {
+		t.Errorf("sentinel was mutated: %#v", sentinel)
+	}
+	if !errors.Is(err, sentinel) || !errors.Is(Wrap(err, CodeInternal, "lookup failed"), sentinel) {
+		t.Error("expected the clone to match the sentinel")
+	}
+	if errors.Is(err, NewSentinel(CodeNotFound, "user not found")) {
+		t.Error("expected other sentinels not to match")
+	}
+	if err.Details["user_id"] != 42 || len(err.StackTrace) == 0 {
+		t.Errorf("unexpected clone %#v", err)
+	}
+
+	// Errors from New keep the builder pattern.
+	plain := New(CodeInternal, "plain")
+	if plain.WithDetail("k", "v") != plain {
+		t.Error("expected non-sentinel errors to be modified in place")
+	}
+
+	clone := plain.Clone()
+	clone.WithDetail("k", "changed")
+	if plain.Details["k"] != "v" {
+		t.Error("expected Clone to copy the details")
+	}
+}
+
+func TestCodeMatchingHelpers(t *testing.T) {
+	err := WithOp(Wrap(errors.New("no rows"), CodeNotFound, "user not found"), "userservice.Get")
+
+	if !IsAnyCode(err, CodeConflict, CodeNotFound) {
+		t.Error("expected IsAnyCode to match CodeNotFound")
+	}
+	if IsAnyCode(err, CodeConflict) || IsAnyCode(err) || IsAnyCode(errors.New("plain"), CodeInternal) {
+		t.Error("unexpected IsAnyCode match")
+	}
+
+	tests := []struct {
+		err            error
+		client, server bool
+	}{
+		{err, true, false},
+		{New(CodeUnavailable, "down"), false, true},
+		{errors.New("plain"), false, true},
+		{nil, false, false},
+	}
+	for _, tt := range tests {
+		if got := IsClientError(tt.err); got != tt.client {
+			t.Errorf("IsClientError(%v) = %v, want %v", tt.err, got, tt.client)
+		}
+		if got := IsServerError(tt.err); got != tt.server {
+			t.Errorf("IsServerError(%v) = %v, want %v", tt.err, got, tt.server)
+		}
+	}
+}
+
+func TestErrorTracker(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tracker := NewErrorTracker(10 * time.Second)
+	tracker.now = func() time.Time { return now }
+
+	var fired []int
+	tracker.OnThreshold(CodeUnavailable, 3, func(code Code, count int) {
+		fired = append(fired, count)
+	})
+
+	for range 3 {
+		tracker.Track(New(CodeUnavailable, "down"))
+	}
+	tracker.Track(New(CodeNotFound, "missing"))
+	tracker.Track(nil)
+	tracker.Track(New(CodeUnavailable, "still down"))
+
+	snapshot := tracker.Snapshot()
+	if snapshot.Counts[CodeUnavailable] != 4 || snapshot.Total != 5 {
+		t.Errorf("unexpected snapshot %+v", snapshot)
+	}
+	if rate := tracker.Rate(CodeUnavailable); rate != 0.4 {
+		t.Errorf("expected 0.4 errors/s, got %v", rate)
+	}
+	if fmt.Sprint(fired) != "[3]" {
+		t.Errorf("expected the threshold to fire once, got %v", fired)
+	}
+
+	// The counts expire once they leave the window, re-arming the threshold.
+	now = now.Add(11 * time.Second)
+	if total := tracker.Snapshot().Total; total != 0 {
+		t.Errorf("expected counts to expire, got %d", total)
+	}
+	for range 3 {
+		tracker.Count(CodeUnavailable, "")
+	}
+	if fmt.Sprint(fired) != "[3 3]" {
+		t.Errorf("expected the threshold to fire again, got %v", fired)
+	}
+
+	now = now.Add(5 * time.Second)
+	tracker.Count(CodeUnavailable, "")
+	if n := tracker.Snapshot().Counts[CodeUnavailable]; n != 4 {
+		t.Errorf("expected counts within the window to be kept, got %d", n)
+	}
+}
+
+func TestAddObserver(t *testing.T) {
+	var seen []Code
+	remove := AddObserver(func(e *Error) {
+		seen = append(seen, e.Code)
+		e.WithDetail("region", "eu-west-1")
+	})
+	t.Cleanup(remove)
+
+	err := New(CodeNotFound, "user not found")
+	if err.Details["region"] != "eu-west-1" {
+		t.Errorf("expected the observer to enrich the error, got %v", err.Details)
+	}
+	_ = Wrap(errors.New("refused"), CodeUnavailable, "cannot reach db")
+	_ = NewSentinel(CodeInternal, "not observed")
+
+	remove()
+	_ = New(CodeInternal, "after removal")
+	if fmt.Sprint(seen) != fmt.Sprint([]Code{CodeNotFound, CodeUnavailable}) {
+		t.Errorf("unexpected observed codes %v", seen)
+	}
+}
+
+func TestToPrettyCMDError(t *testing.T) {
+	cause := fmt.Errorf("query failed: %w", errors.New("connection refused"))
+	inner := Wrap(cause, CodeDatabase, "cannot select order").WithDetail("table", "orders")
+	err := Wrap(inner, CodeNotFound, "cannot load order").WithDetail("order_id", 42).WithOp("orders.Get")
+
+	got := ToPrettyCMDError(err, PrettyOptions{NoColor: true})
+	want := `[NOT_FOUND] cannot load order
+  op: orders.Get
+  order_id  42
+  └─ [DATABASE_ERROR] cannot select order
+       table  orders
+       └─ query failed
+            └─ connection refused
+`
+	if got != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+
+	withStack := ToPrettyCMDError(err, PrettyOptions{NoColor: true, StackTrace: true, Project: "github.com/karu-codes/karu-kits"})
+	if !strings.Contains(withStack, "  > github.com/karu-codes/karu-kits/errors.TestToPrettyCMDError") {
+		t.Errorf("expected the project frame to be highlighted, got:\n%s", withStack)
+	}
+
+	joined := ToPrettyCMDError(errors.Join(New(CodeTimeout, "slow"), errors.New("closed")), PrettyOptions{NoColor: true})
+	if joined != "2 errors\n  ├─ [TIMEOUT] slow\n  └─ closed\n" {
+		t.Errorf("unexpected joined output:\n%s", joined)
+	}
+	if colored := ToPrettyCMDError(err, PrettyOptions{}); os.Getenv("NO_COLOR") == "" && !strings.Contains(colored, "\x1b[") {
+		t.Error("expected ANSI colors")
+	}
+}
+
+func TestToTree(t *testing.T) {
+	cause := fmt.Errorf("query failed: %w", errors.New("connection refused"))
+	inner := Wrap(cause, CodeDatabase, "cannot select order").WithDetail("table", "orders")
+	err := errors.Join(
+		Wrap(WithOp(inner, "orders.repo.Get"), CodeNotFound, "cannot load order").WithDetail("order_id", 42),
+		New(CodeTimeout, "slow"),
+	)
+
+	data, marshalErr := json.Marshal(ToTree(err))
+	if marshalErr != nil {
+		t.Fatalf("marshal: %v", marshalErr)
+	}
+	want := `{"message":"2 errors","type":"*errors.joinError","children":[` +
+		`{"code":"NOT_FOUND","message":"cannot load order","details":{"order_id":42},"children":[` +
+		`{"op":"orders.repo.Get","children":[` +
+		`{"code":"DATABASE_ERROR","message":"cannot select order","details":{"table":"orders"},"children":[` +
+		`{"message":"query failed","type":"*fmt.wrapError","children":[` +
+		`{"message":"connection refused","type":"*errors.errorString"}]}]}]}]},` +
+		`{"code":"TIMEOUT","message":"slow"}]}`
+	if string(data) != want {
+		t.Errorf("unexpected tree:\n%s\nwant:\n%s", data, want)
+	}
+	if ToTree(nil) != nil {
+		t.Error("expected nil for a nil error")
+	}
+}
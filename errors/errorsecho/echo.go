@@ -0,0 +1,73 @@
+// Package errorsecho renders errors as errors.HTTPResponse JSON bodies in
+// Echo.
+//
+//	e := echo.New()
+//	errorsecho.Register(e, errors.HTTPOptions{})
+//	e.GET("/users/:id", func(c echo.Context) error {
+//	    user, err := svc.Get(c.Request().Context(), c.Param("id"))
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return c.JSON(http.StatusOK, user)
+//	})
+package errorsecho
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+// Register installs HTTPErrorHandler as the error handler of e.
+func Register(e *echo.Echo, opts kerrors.HTTPOptions) {
+	e.HTTPErrorHandler = HTTPErrorHandler(opts)
+}
+
+// HTTPErrorHandler returns an echo.HTTPErrorHandler that writes errors
+// returned by handlers as JSON with the matching HTTP status code. Echo's own
+// errors (*echo.HTTPError, e.g. 404 for unknown routes) get the code for
+// their status (see errors.CodeFromHTTPStatus). HEAD requests get the status
+// only.
+//
+// opts.Language is the default language when the request's Accept-Language
+// names none. Set IncludeStackTrace and IncludeInternalDetails only for
+// internal endpoints.
+func HTTPErrorHandler(opts kerrors.HTTPOptions) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		err = fromEcho(err)
+		status := kerrors.HTTPStatusCode(err)
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(status)
+		} else {
+			opts := opts.WithAcceptLanguage(c.Request().Header.Get("Accept-Language"))
+			err = c.JSON(status, kerrors.ToHTTPResponseWithOptions(err, opts))
+		}
+		if err != nil {
+			c.Logger().Error(err)
+		}
+	}
+}
+
+func fromEcho(err error) error {
+	var customErr *kerrors.Error
+	if errors.As(err, &customErr) {
+		return err
+	}
+	var httpErr *echo.HTTPError
+	if !errors.As(err, &httpErr) {
+		return err
+	}
+	message := http.StatusText(httpErr.Code)
+	if httpErr.Message != nil {
+		message = fmt.Sprint(httpErr.Message)
+	}
+	return kerrors.Wrap(err, kerrors.CodeFromHTTPStatus(httpErr.Code), message)
+}
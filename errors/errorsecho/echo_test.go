@@ -0,0 +1,198 @@
+package errorsecho
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+// setTranslator translates messages into "<lang>:<key>" for any non-empty
+// language.
+func setTranslator(t *testing.T) {
+	t.Helper()
+
+	kerrors.SetTranslator(kerrors.TranslatorFunc(func(lang, key string, _ map[string]any) (string, bool) {
+		if lang == "" {
+			return "", false
+		}
+		return lang + ":" + key, true
+	}))
+	t.Cleanup(func() { kerrors.SetTranslator(nil) })
+}
+
+func TestHTTPErrorHandler(t *testing.T) {
+	setTranslator(t)
+
+	type user struct {
+		Age int `json:"age"`
+	}
+
+	tests := []struct {
+		name           string
+		opts           kerrors.HTTPOptions
+		method         string
+		path           string
+		acceptLanguage string
+		body           string
+		handler        echo.HandlerFunc
+		wantStatus     int
+		wantCode       kerrors.Code
+		wantMessage    string
+		wantStack      bool
+	}{
+		{
+			name: "error",
+			handler: func(c echo.Context) error {
+				return kerrors.New(kerrors.CodeNotFound, "user not found")
+			},
+			wantStatus:  http.StatusNotFound,
+			wantCode:    kerrors.CodeNotFound,
+			wantMessage: "user not found",
+		},
+		{
+			name: "plain error",
+			handler: func(c echo.Context) error {
+				return errors.New("boom")
+			},
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    kerrors.CodeInternal,
+			wantMessage: "boom",
+		},
+		{
+			name: "echo.HTTPError",
+			handler: func(c echo.Context) error {
+				return echo.NewHTTPError(http.StatusConflict, "version mismatch")
+			},
+			wantStatus:  http.StatusConflict,
+			wantCode:    kerrors.CodeConflict,
+			wantMessage: "version mismatch",
+		},
+		{
+			name:        "unknown route",
+			path:        "/missing",
+			wantStatus:  http.StatusNotFound,
+			wantCode:    kerrors.CodeNotFound,
+			wantMessage: "Not Found",
+		},
+		{
+			name:   "bind error",
+			method: http.MethodPost,
+			body:   `{"age":"old"}`,
+			handler: func(c echo.Context) error {
+				var u user
+				return c.Bind(&u)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   kerrors.CodeInvalidArgument,
+		},
+		{
+			name:           "Accept-Language",
+			acceptLanguage: "fr-CH, fr;q=0.9",
+			handler: func(c echo.Context) error {
+				return kerrors.New(kerrors.CodeNotFound, "user not found")
+			},
+			wantStatus:  http.StatusNotFound,
+			wantCode:    kerrors.CodeNotFound,
+			wantMessage: "fr-CH:NOT_FOUND",
+		},
+		{
+			name: "default language",
+			opts: kerrors.HTTPOptions{Language: "de"},
+			handler: func(c echo.Context) error {
+				return kerrors.New(kerrors.CodeNotFound, "user not found")
+			},
+			wantStatus:  http.StatusNotFound,
+			wantCode:    kerrors.CodeNotFound,
+			wantMessage: "de:NOT_FOUND",
+		},
+		{
+			name: "stack trace",
+			opts: kerrors.HTTPOptions{IncludeStackTrace: true},
+			handler: func(c echo.Context) error {
+				return kerrors.New(kerrors.CodeDatabase, "query failed")
+			},
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    kerrors.CodeDatabase,
+			wantMessage: "query failed",
+			wantStack:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, path := tt.method, tt.path
+			if method == "" {
+				method = http.MethodGet
+			}
+			if path == "" {
+				path = "/"
+			}
+			e := echo.New()
+			Register(e, tt.opts)
+			if tt.handler != nil {
+				e.Add(method, "/", tt.handler)
+			}
+
+			req := httptest.NewRequest(method, path, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			var resp kerrors.HTTPResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode %q: %v", rec.Body.String(), err)
+			}
+			if resp.Error.Code != tt.wantCode.String() {
+				t.Errorf("Expected code %s, got %s", tt.wantCode, resp.Error.Code)
+			}
+			if tt.wantMessage != "" && resp.Error.Message != tt.wantMessage {
+				t.Errorf("Expected message %q, got %q", tt.wantMessage, resp.Error.Message)
+			}
+			if (len(resp.Error.StackTrace) > 0) != tt.wantStack {
+				t.Errorf("Expected stack trace %v, got %v", tt.wantStack, resp.Error.StackTrace)
+			}
+		})
+	}
+}
+
+func TestHTTPErrorHandlerHead(t *testing.T) {
+	e := echo.New()
+	Register(e, kerrors.HTTPOptions{})
+	e.HEAD("/", func(c echo.Context) error {
+		return kerrors.New(kerrors.CodeNotFound, "user not found")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/", nil))
+	if rec.Code != http.StatusNotFound || rec.Body.Len() != 0 {
+		t.Errorf("Expected status 404 without body, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHTTPErrorHandlerCommitted(t *testing.T) {
+	e := echo.New()
+	Register(e, kerrors.HTTPOptions{})
+	e.GET("/", func(c echo.Context) error {
+		_ = c.String(http.StatusOK, "done")
+		return errors.New("after write")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "done" {
+		t.Errorf("Expected the committed response to be kept, got %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,54 @@
+// Package errorsfiber renders errors as errors.HTTPResponse JSON bodies in
+// Fiber.
+//
+//	app := fiber.New(fiber.Config{ErrorHandler: errorsfiber.ErrorHandler(errors.HTTPOptions{})})
+//	app.Get("/users/:id", func(c *fiber.Ctx) error {
+//	    user, err := svc.Get(c.UserContext(), c.Params("id"))
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return c.JSON(user)
+//	})
+package errorsfiber
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+// ErrorHandler returns a fiber.ErrorHandler, to be set as
+// fiber.Config.ErrorHandler, that writes errors returned by handlers as JSON
+// with the matching HTTP status code. Fiber's own errors (*fiber.Error, e.g.
+// 404 for unknown routes) get the code for their status (see
+// errors.CodeFromHTTPStatus). HEAD requests get the status only.
+//
+// opts.Language is the default language when the request's Accept-Language
+// names none. Set IncludeStackTrace and IncludeInternalDetails only for
+// internal endpoints.
+func ErrorHandler(opts kerrors.HTTPOptions) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		err = fromFiber(err)
+		status := kerrors.HTTPStatusCode(err)
+		if c.Method() == fiber.MethodHead {
+			c.Status(status)
+			return nil
+		}
+		opts := opts.WithAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+		return c.Status(status).JSON(kerrors.ToHTTPResponseWithOptions(err, opts))
+	}
+}
+
+func fromFiber(err error) error {
+	var customErr *kerrors.Error
+	if errors.As(err, &customErr) {
+		return err
+	}
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) {
+		return err
+	}
+	return kerrors.Wrap(err, kerrors.CodeFromHTTPStatus(fiberErr.Code), fiberErr.Message)
+}
@@ -0,0 +1,194 @@
+package errorsfiber
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+// setTranslator translates messages into "<lang>:<key>" for any non-empty
+// language.
+func setTranslator(t *testing.T) {
+	t.Helper()
+
+	kerrors.SetTranslator(kerrors.TranslatorFunc(func(lang, key string, _ map[string]any) (string, bool) {
+		if lang == "" {
+			return "", false
+		}
+		return lang + ":" + key, true
+	}))
+	t.Cleanup(func() { kerrors.SetTranslator(nil) })
+}
+
+func TestErrorHandler(t *testing.T) {
+	setTranslator(t)
+
+	type user struct {
+		Age int `json:"age"`
+	}
+
+	tests := []struct {
+		name           string
+		opts           kerrors.HTTPOptions
+		method         string
+		path           string
+		acceptLanguage string
+		contentType    string
+		body           string
+		handler        fiber.Handler
+		wantStatus     int
+		wantCode       kerrors.Code
+		wantMessage    string
+		wantStack      bool
+	}{
+		{
+			name: "error",
+			handler: func(c *fiber.Ctx) error {
+				return kerrors.New(kerrors.CodeNotFound, "user not found")
+			},
+			wantStatus:  http.StatusNotFound,
+			wantCode:    kerrors.CodeNotFound,
+			wantMessage: "user not found",
+		},
+		{
+			name: "plain error",
+			handler: func(c *fiber.Ctx) error {
+				return errors.New("boom")
+			},
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    kerrors.CodeInternal,
+			wantMessage: "boom",
+		},
+		{
+			name: "fiber.Error",
+			handler: func(c *fiber.Ctx) error {
+				return fiber.NewError(http.StatusConflict, "version mismatch")
+			},
+			wantStatus:  http.StatusConflict,
+			wantCode:    kerrors.CodeConflict,
+			wantMessage: "version mismatch",
+		},
+		{
+			name:       "unknown route",
+			path:       "/missing",
+			wantStatus: http.StatusNotFound,
+			wantCode:   kerrors.CodeNotFound,
+		},
+		{
+			name:        "bind error",
+			method:      http.MethodPost,
+			contentType: fiber.MIMETextPlain,
+			body:        `age=old`,
+			handler: func(c *fiber.Ctx) error {
+				var u user
+				return c.BodyParser(&u)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   kerrors.CodeFromHTTPStatus(http.StatusUnprocessableEntity),
+		},
+		{
+			name:           "Accept-Language",
+			acceptLanguage: "fr-CH, fr;q=0.9",
+			handler: func(c *fiber.Ctx) error {
+				return kerrors.New(kerrors.CodeNotFound, "user not found")
+			},
+			wantStatus:  http.StatusNotFound,
+			wantCode:    kerrors.CodeNotFound,
+			wantMessage: "fr-CH:NOT_FOUND",
+		},
+		{
+			name: "default language",
+			opts: kerrors.HTTPOptions{Language: "de"},
+			handler: func(c *fiber.Ctx) error {
+				return kerrors.New(kerrors.CodeNotFound, "user not found")
+			},
+			wantStatus:  http.StatusNotFound,
+			wantCode:    kerrors.CodeNotFound,
+			wantMessage: "de:NOT_FOUND",
+		},
+		{
+			name: "stack trace",
+			opts: kerrors.HTTPOptions{IncludeStackTrace: true},
+			handler: func(c *fiber.Ctx) error {
+				return kerrors.New(kerrors.CodeDatabase, "query failed")
+			},
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    kerrors.CodeDatabase,
+			wantMessage: "query failed",
+			wantStack:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, path, contentType := tt.method, tt.path, tt.contentType
+			if method == "" {
+				method = http.MethodGet
+			}
+			if path == "" {
+				path = "/"
+			}
+			if contentType == "" {
+				contentType = fiber.MIMEApplicationJSON
+			}
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler(tt.opts)})
+			if tt.handler != nil {
+				app.Add(method, "/", tt.handler)
+			}
+
+			req := httptest.NewRequest(method, path, strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, contentType)
+			if tt.acceptLanguage != "" {
+				req.Header.Set(fiber.HeaderAcceptLanguage, tt.acceptLanguage)
+			}
+			res, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Test() error = %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, res.StatusCode)
+			}
+			var resp kerrors.HTTPResponse
+			if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode the body: %v", err)
+			}
+			if resp.Error.Code != tt.wantCode.String() {
+				t.Errorf("Expected code %s, got %s", tt.wantCode, resp.Error.Code)
+			}
+			if tt.wantMessage != "" && resp.Error.Message != tt.wantMessage {
+				t.Errorf("Expected message %q, got %q", tt.wantMessage, resp.Error.Message)
+			}
+			if (len(resp.Error.StackTrace) > 0) != tt.wantStack {
+				t.Errorf("Expected stack trace %v, got %v", tt.wantStack, resp.Error.StackTrace)
+			}
+		})
+	}
+}
+
+func TestErrorHandlerHead(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler(kerrors.HTTPOptions{})})
+	app.Head("/", func(c *fiber.Ctx) error {
+		return kerrors.New(kerrors.CodeNotFound, "user not found")
+	})
+
+	res, err := app.Test(httptest.NewRequest(http.MethodHead, "/", nil))
+	if err != nil {
+		t.Fatalf("Test() error = %v", err)
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusNotFound || len(body) != 0 {
+		t.Errorf("Expected status 404 without body, got %d %q", res.StatusCode, body)
+	}
+}
@@ -0,0 +1,92 @@
+// Package errorsgin renders errors as errors.HTTPResponse JSON bodies in Gin
+// handlers.
+//
+//	r := gin.New()
+//	r.Use(errorsgin.Middleware(errors.HTTPOptions{}))
+//	r.GET("/users/:id", func(c *gin.Context) {
+//	    user, err := svc.Get(c, c.Param("id"))
+//	    if err != nil {
+//	        errorsgin.Abort(c, err)
+//	        return
+//	    }
+//	    c.JSON(http.StatusOK, user)
+//	})
+//
+// Handlers may also record the error with c.Error(err) and return; the
+// middleware renders the last recorded error.
+package errorsgin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+// optionsKey stores the options of Middleware in the gin context for Abort.
+const optionsKey = "errorsgin.options"
+
+// Middleware returns a global error handler. After the handlers ran, it
+// renders the last error recorded with c.Error unless a response was already
+// written, and converts panics into CodeInternal errors (see errors.Recover).
+// A panic after the response was started aborts the connection instead, so
+// the client does not take a truncated response for a complete one. Bind
+// errors (gin.ErrorTypeBind) are rendered as CodeInvalidArgument. HEAD
+// requests get the status only.
+//
+// opts.Language is the default language when the request's Accept-Language
+// names none. Set IncludeStackTrace and IncludeInternalDetails only for
+// internal endpoints.
+func Middleware(opts kerrors.HTTPOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(optionsKey, opts)
+		err := kerrors.RecoverFunc(func() error {
+			c.Next()
+			return nil
+		})
+		if err != nil {
+			_ = c.Error(err)
+			if c.Writer.Written() {
+				panic(http.ErrAbortHandler)
+			}
+		} else if last := c.Errors.Last(); last != nil {
+			err = fromGin(last)
+		}
+		if err == nil || c.Writer.Written() {
+			return
+		}
+		abort(c, err, opts)
+	}
+}
+
+// Abort stops the handler chain and writes err as JSON with the matching HTTP
+// status code, with the options of Middleware if it runs for the request. The
+// error is also recorded with c.Error for logging middlewares.
+func Abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	opts, _ := c.Value(optionsKey).(kerrors.HTTPOptions)
+	abort(c, err, opts)
+}
+
+func abort(c *gin.Context, err error, opts kerrors.HTTPOptions) {
+	status := kerrors.HTTPStatusCode(err)
+	if c.Request.Method == http.MethodHead {
+		c.AbortWithStatus(status)
+		return
+	}
+	opts = opts.WithAcceptLanguage(c.GetHeader("Accept-Language"))
+	c.AbortWithStatusJSON(status, kerrors.ToHTTPResponseWithOptions(err, opts))
+}
+
+func fromGin(ginErr *gin.Error) error {
+	var customErr *kerrors.Error
+	if errors.As(ginErr.Err, &customErr) {
+		return ginErr.Err
+	}
+	if ginErr.IsType(gin.ErrorTypeBind) {
+		return kerrors.Wrap(ginErr.Err, kerrors.CodeInvalidArgument, ginErr.Err.Error())
+	}
+	return ginErr.Err
+}
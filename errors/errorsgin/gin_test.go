@@ -0,0 +1,239 @@
+package errorsgin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// setTranslator translates messages into "<lang>:<key>" for any non-empty
+// language.
+func setTranslator(t *testing.T) {
+	t.Helper()
+
+	kerrors.SetTranslator(kerrors.TranslatorFunc(func(lang, key string, _ map[string]any) (string, bool) {
+		if lang == "" {
+			return "", false
+		}
+		return lang + ":" + key, true
+	}))
+	t.Cleanup(func() { kerrors.SetTranslator(nil) })
+}
+
+func serve(r *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	setTranslator(t)
+
+	type user struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	tests := []struct {
+		name           string
+		opts           kerrors.HTTPOptions
+		method         string
+		acceptLanguage string
+		body           string
+		handler        gin.HandlerFunc
+		wantStatus     int
+		wantCode       kerrors.Code
+		wantMessage    string
+		wantDetails    bool
+		wantStack      bool
+	}{
+		{
+			name: "Abort",
+			handler: func(c *gin.Context) {
+				Abort(c, kerrors.New(kerrors.CodeNotFound, "user not found").WithPublicDetail("user_id", "42"))
+			},
+			wantStatus:  http.StatusNotFound,
+			wantCode:    kerrors.CodeNotFound,
+			wantMessage: "user not found",
+			wantDetails: true,
+		},
+		{
+			name: "c.Error",
+			handler: func(c *gin.Context) {
+				_ = c.Error(kerrors.New(kerrors.CodeConflict, "version mismatch"))
+			},
+			wantStatus:  http.StatusConflict,
+			wantCode:    kerrors.CodeConflict,
+			wantMessage: "version mismatch",
+		},
+		{
+			name: "plain error",
+			handler: func(c *gin.Context) {
+				_ = c.Error(errors.New("boom"))
+			},
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    kerrors.CodeInternal,
+			wantMessage: "boom",
+		},
+		{
+			name:   "bind error",
+			method: http.MethodPost,
+			body:   `{}`,
+			handler: func(c *gin.Context) {
+				var u user
+				if err := c.ShouldBindJSON(&u); err != nil {
+					_ = c.Error(err).SetType(gin.ErrorTypeBind)
+					return
+				}
+				c.Status(http.StatusCreated)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   kerrors.CodeInvalidArgument,
+		},
+		{
+			name: "panic",
+			handler: func(c *gin.Context) {
+				panic("boom")
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   kerrors.CodeInternal,
+		},
+		{
+			name:           "Accept-Language",
+			acceptLanguage: "fr-CH, fr;q=0.9",
+			handler: func(c *gin.Context) {
+				Abort(c, kerrors.New(kerrors.CodeNotFound, "user not found"))
+			},
+			wantStatus:  http.StatusNotFound,
+			wantCode:    kerrors.CodeNotFound,
+			wantMessage: "fr-CH:NOT_FOUND",
+		},
+		{
+			name: "default language",
+			opts: kerrors.HTTPOptions{Language: "de"},
+			handler: func(c *gin.Context) {
+				Abort(c, kerrors.New(kerrors.CodeNotFound, "user not found"))
+			},
+			wantStatus:  http.StatusNotFound,
+			wantCode:    kerrors.CodeNotFound,
+			wantMessage: "de:NOT_FOUND",
+		},
+		{
+			name: "internal options",
+			opts: kerrors.HTTPOptions{IncludeStackTrace: true, IncludeInternalDetails: true},
+			handler: func(c *gin.Context) {
+				Abort(c, kerrors.New(kerrors.CodeDatabase, "query failed").WithDetail("table", "users"))
+			},
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    kerrors.CodeDatabase,
+			wantMessage: "query failed",
+			wantDetails: true,
+			wantStack:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			r := gin.New()
+			r.Use(Middleware(tt.opts))
+			r.Handle(method, "/", tt.handler)
+
+			req := httptest.NewRequest(method, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rec := serve(r, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			var resp kerrors.HTTPResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode %q: %v", rec.Body.String(), err)
+			}
+			if resp.Error.Code != tt.wantCode.String() {
+				t.Errorf("Expected code %s, got %s", tt.wantCode, resp.Error.Code)
+			}
+			if tt.wantMessage != "" && resp.Error.Message != tt.wantMessage {
+				t.Errorf("Expected message %q, got %q", tt.wantMessage, resp.Error.Message)
+			}
+			if (len(resp.Error.Details) > 0) != tt.wantDetails {
+				t.Errorf("Expected details %v, got %v", tt.wantDetails, resp.Error.Details)
+			}
+			if (len(resp.Error.StackTrace) > 0) != tt.wantStack {
+				t.Errorf("Expected stack trace %v, got %v", tt.wantStack, resp.Error.StackTrace)
+			}
+		})
+	}
+}
+
+func TestMiddlewareWrittenResponse(t *testing.T) {
+	r := gin.New()
+	r.Use(Middleware(kerrors.HTTPOptions{}))
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "done")
+		_ = c.Error(errors.New("after write"))
+	})
+
+	rec := serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "done" {
+		t.Errorf("Expected the written response to be kept, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestMiddlewarePanicAfterWrite(t *testing.T) {
+	r := gin.New()
+	r.Use(Middleware(kerrors.HTTPOptions{}))
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("Expected panic(http.ErrAbortHandler), got %v", v)
+		}
+	}()
+	serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+	t.Error("Expected the connection to be aborted")
+}
+
+func TestMiddlewareHead(t *testing.T) {
+	r := gin.New()
+	r.Use(Middleware(kerrors.HTTPOptions{}))
+	r.HEAD("/", func(c *gin.Context) {
+		Abort(c, kerrors.New(kerrors.CodeNotFound, "user not found"))
+	})
+
+	rec := serve(r, httptest.NewRequest(http.MethodHead, "/", nil))
+	if rec.Code != http.StatusNotFound || rec.Body.Len() != 0 {
+		t.Errorf("Expected status 404 without body, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAbortWithoutMiddleware(t *testing.T) {
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) {
+		Abort(c, kerrors.New(kerrors.CodePermission, "forbidden"))
+	})
+
+	rec := serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), `"PERMISSION_DENIED"`) {
+		t.Errorf("Expected a 403 PERMISSION_DENIED body, got %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,167 @@
+// Package errorssentry reports errors to Sentry.
+//
+//	errors.SetReporter(errorssentry.NewReporter(errorssentry.WithTagKeys("tenant_id")))
+//	...
+//	errors.ReportContext(ctx, err)
+//
+// The Sentry client must be initialised with sentry.Init beforehand.
+package errorssentry
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/getsentry/sentry-go"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+type Option func(*options)
+
+type options struct {
+	hub              *sentry.Hub
+	tagKeys          map[string]bool
+	skipClientErrors bool
+}
+
+// WithHub sets the hub events are sent to when the context carries none.
+// Default: sentry.CurrentHub().
+func WithHub(hub *sentry.Hub) Option {
+	return func(o *options) {
+		if hub != nil {
+			o.hub = hub
+		}
+	}
+}
+
+// WithTagKeys sends the given details as Sentry tags (indexed and searchable)
+// instead of extra data.
+func WithTagKeys(keys ...string) Option {
+	return func(o *options) {
+		for _, key := range keys {
+			o.tagKeys[key] = true
+		}
+	}
+}
+
+// SkipClientErrors drops errors whose code maps to a 4xx status (invalid
+// arguments, not found, ...), which are usually not actionable.
+func SkipClientErrors() Option {
+	return func(o *options) {
+		o.skipClientErrors = true
+	}
+}
+
+// Reporter captures errors as Sentry events.
+type Reporter struct {
+	opts *options
+}
+
+var _ kerrors.Reporter = (*Reporter)(nil)
+
+// NewReporter returns a Reporter. The hub is taken from the context
+// (sentry.GetHubFromContext) when present, so request-scoped scope data set by
+// the Sentry middlewares is kept.
+func NewReporter(opts ...Option) *Reporter {
+	o := &options{tagKeys: make(map[string]bool)}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return &Reporter{opts: o}
+}
+
+// Report captures err as a Sentry event.
+func (r *Reporter) Report(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	code := kerrors.GetCode(err)
+	if r.opts.skipClientErrors && code.IsClientError() {
+		return
+	}
+
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = r.opts.hub
+	}
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+
+	hub.CaptureEvent(r.Event(err))
+}
+
+// Event converts err to a Sentry event: the level is derived from the code,
+// the code is sent as the "error.code" tag, details as tags or extra data, the
+// operations (see errors.Ops) as the "ops" extra, and the stack trace as the
+// exception stack trace.
+func (r *Reporter) Event(err error) *sentry.Event {
+	event := sentry.NewEvent()
+	event.Message = err.Error()
+	event.Logger = "errors"
+
+	var kerr *kerrors.Error
+	if !kerrors.As(err, &kerr) {
+		event.Level = sentry.LevelError
+		event.Exception = []sentry.Exception{{
+			Type:  reflect.TypeOf(err).String(),
+			Value: err.Error(),
+		}}
+		return event
+	}
+
+	event.Level = Level(kerr.Code)
+	event.Tags["error.code"] = kerr.Code.String()
+	for key, value := range kerr.Details {
+		if r.opts.tagKeys[key] {
+			event.Tags[key] = fmt.Sprint(value)
+			continue
+		}
+		event.Extra[key] = value
+	}
+	if ops := kerrors.Ops(err); len(ops) > 0 {
+		event.Extra["ops"] = ops
+	}
+
+	exception := sentry.Exception{
+		Type:  kerr.Code.String(),
+		Value: kerr.Message,
+	}
+	if len(kerr.StackTrace) > 0 {
+		exception.Stacktrace = Stacktrace(kerr.StackTrace)
+	}
+	event.Exception = []sentry.Exception{exception}
+
+	return event
+}
+
+// Level maps an error code to a Sentry level: client errors (4xx) are
+// warnings, CodeCancelled is info and everything else is an error.
+func Level(code kerrors.Code) sentry.Level {
+	switch {
+	case code == kerrors.CodeCancelled:
+		return sentry.LevelInfo
+	case code.IsClientError():
+		return sentry.LevelWarning
+	default:
+		return sentry.LevelError
+	}
+}
+
+// Stacktrace converts an errors.Error stack (innermost call first) to a Sentry
+// stack trace, which lists the outermost call first.
+func Stacktrace(frames []kerrors.StackFrame) *sentry.Stacktrace {
+	st := &sentry.Stacktrace{Frames: make([]sentry.Frame, 0, len(frames))}
+	for i := len(frames) - 1; i >= 0; i-- {
+		st.Frames = append(st.Frames, sentry.NewFrame(runtime.Frame{
+			Function: frames[i].Function,
+			File:     frames[i].File,
+			Line:     frames[i].Line,
+		}))
+	}
+	return st
+}
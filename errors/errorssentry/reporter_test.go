@@ -0,0 +1,145 @@
+package errorssentry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+func newTestHub(t *testing.T) (*sentry.Hub, *sentry.MockTransport) {
+	t.Helper()
+
+	transport := &sentry.MockTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://key@sentry.example.com/1", Transport: transport})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return sentry.NewHub(client, sentry.NewScope()), transport
+}
+
+func TestReporter(t *testing.T) {
+	hub, transport := newTestHub(t)
+	reporter := NewReporter(WithHub(hub), WithTagKeys("tenant_id"))
+
+	cause := kerrors.New(kerrors.CodeDatabase, "connection refused").
+		WithOp("userrepo.List").
+		WithDetail("tenant_id", 7).
+		WithDetail("table", "users")
+	err := kerrors.WithOp(fmt.Errorf("list users: %w", cause), "userservice.List")
+
+	reporter.Report(context.Background(), err)
+
+	events := transport.Events()
+	if len(events) != 1 {
+		t.Fatalf("Expected one event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Level != sentry.LevelError || event.Message != err.Error() || event.Logger != "errors" {
+		t.Errorf("Unexpected event level %q, message %q, logger %q", event.Level, event.Message, event.Logger)
+	}
+	if event.Tags["error.code"] != kerrors.CodeDatabase.String() || event.Tags["tenant_id"] != "7" {
+		t.Errorf("Expected error.code and tenant_id tags, got %v", event.Tags)
+	}
+	if _, ok := event.Extra["tenant_id"]; ok {
+		t.Error("Expected tags not to be repeated as extra data")
+	}
+	if event.Extra["table"] != "users" {
+		t.Errorf("Expected table as extra data, got %v", event.Extra)
+	}
+	if ops, _ := event.Extra["ops"].([]string); len(ops) != 2 || ops[0] != "userservice.List" || ops[1] != "userrepo.List" {
+		t.Errorf("Expected ops [userservice.List userrepo.List], got %v", event.Extra["ops"])
+	}
+
+	if len(event.Exception) != 1 {
+		t.Fatalf("Expected one exception, got %v", event.Exception)
+	}
+	exception := event.Exception[0]
+	if exception.Type != kerrors.CodeDatabase.String() || exception.Value != "connection refused" {
+		t.Errorf("Unexpected exception %q: %q", exception.Type, exception.Value)
+	}
+	if exception.Stacktrace == nil || len(exception.Stacktrace.Frames) != len(cause.StackTrace) {
+		t.Fatalf("Expected the stack of the *errors.Error, got %+v", exception.Stacktrace)
+	}
+	// Sentry lists the outermost call first.
+	frames := exception.Stacktrace.Frames
+	if last, top := frames[len(frames)-1], cause.StackTrace[0]; last.Lineno != top.Line || last.AbsPath != top.File {
+		t.Errorf("Expected the innermost frame last, got %s:%d, want %s:%d", last.AbsPath, last.Lineno, top.File, top.Line)
+	}
+}
+
+func TestReporterLevel(t *testing.T) {
+	tests := []struct {
+		code kerrors.Code
+		want sentry.Level
+	}{
+		{kerrors.CodeInternal, sentry.LevelError},
+		{kerrors.CodeDatabase, sentry.LevelError},
+		{kerrors.CodeUnavailable, sentry.LevelError},
+		{kerrors.CodeNotFound, sentry.LevelWarning},
+		{kerrors.CodeInvalidArgument, sentry.LevelWarning},
+		{kerrors.CodeCancelled, sentry.LevelInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			if got := Level(tt.code); got != tt.want {
+				t.Errorf("Level(%s) = %v, want %v", tt.code, got, tt.want)
+			}
+
+			hub, transport := newTestHub(t)
+			NewReporter(WithHub(hub)).Report(context.Background(), kerrors.New(tt.code, "failed"))
+			if events := transport.Events(); len(events) != 1 || events[0].Level != tt.want {
+				t.Errorf("Expected one %v event, got %v", tt.want, events)
+			}
+		})
+	}
+}
+
+func TestReporterPlainError(t *testing.T) {
+	hub, transport := newTestHub(t)
+	NewReporter(WithHub(hub)).Report(context.Background(), errors.New("boom"))
+
+	events := transport.Events()
+	if len(events) != 1 || len(events[0].Exception) != 1 {
+		t.Fatalf("Expected one event with an exception, got %v", events)
+	}
+	event := events[0]
+	if event.Level != sentry.LevelError || event.Exception[0].Type != "*errors.errorString" || event.Exception[0].Value != "boom" {
+		t.Errorf("Unexpected event %q with exception %+v", event.Level, event.Exception[0])
+	}
+	if _, ok := event.Tags["error.code"]; ok {
+		t.Errorf("Expected no error.code tag, got %v", event.Tags)
+	}
+}
+
+func TestReporterNoStack(t *testing.T) {
+	event := NewReporter().Event(kerrors.NewError(kerrors.CodeNotFound, "user not found"))
+	if len(event.Exception) != 1 || event.Exception[0].Stacktrace != nil {
+		t.Errorf("Expected an exception without stack trace, got %+v", event.Exception)
+	}
+}
+
+func TestReporterHub(t *testing.T) {
+	optionHub, optionTransport := newTestHub(t)
+	ctxHub, ctxTransport := newTestHub(t)
+	reporter := NewReporter(WithHub(optionHub), SkipClientErrors())
+
+	reporter.Report(context.Background(), nil)
+	reporter.Report(context.Background(), kerrors.New(kerrors.CodeNotFound, "user not found"))
+	errOption := kerrors.New(kerrors.CodeInternal, "option hub")
+	reporter.Report(context.Background(), errOption)
+	errCtx := kerrors.New(kerrors.CodeInternal, "context hub")
+	reporter.Report(sentry.SetHubOnContext(context.Background(), ctxHub), errCtx)
+
+	if events := optionTransport.Events(); len(events) != 1 || events[0].Message != errOption.Error() {
+		t.Errorf("Expected only the server error on the option hub, got %v", events)
+	}
+	if events := ctxTransport.Events(); len(events) != 1 || events[0].Message != errCtx.Error() {
+		t.Errorf("Expected the context hub to win, got %v", events)
+	}
+}
@@ -1,11 +1,21 @@
 package errors
 
 import (
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
 )
 
-// ToGRPCError converts an error to a gRPC error
+// GRPCErrorDomain is the ErrorInfo domain set by ToGRPCError.
+const GRPCErrorDomain = "karu-kits"
+
+// ToGRPCError converts an error to a gRPC error.
+// The error code and details are attached as an ErrorInfo (reason = code,
+// metadata = details formatted with fmt.Sprint) and field violations as a
+// BadRequest, so FromGRPCError can restore them on the other side.
 func ToGRPCError(err error) error {
 	if err == nil {
 		return nil
@@ -16,11 +26,94 @@ func ToGRPCError(err error) error {
 		code := codes.Code(customErr.Code.GRPCCode())
 		st := status.New(code, customErr.Message)
 
-		// Ideally we would add details here, but we need to convert map[string]any to proto.Message
-		// which is non-trivial without knowing the specific proto messages.
-		// For now we just return the status with code and message.
+		info := &errdetails.ErrorInfo{
+			Reason: customErr.Code.String(),
+			Domain: GRPCErrorDomain,
+		}
+		for k, v := range customErr.Details {
+			if k == fieldViolationsKey {
+				continue
+			}
+			if info.Metadata == nil {
+				info.Metadata = make(map[string]string, len(customErr.Details))
+			}
+			info.Metadata[k] = fmt.Sprint(v)
+		}
+		details := []protoadapt.MessageV1{info}
+
+		if violations := fieldViolationsOf(customErr.Details[fieldViolationsKey]); len(violations) > 0 {
+			badRequest := &errdetails.BadRequest{}
+			for _, v := range violations {
+				badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+					Field:       v.Field,
+					Reason:      v.Code,
+					Description: v.Description,
+				})
+			}
+			details = append(details, badRequest)
+		}
+
+		if withDetails, detailsErr := st.WithDetails(details...); detailsErr == nil {
+			st = withDetails
+		}
 		return st.Err()
 	}
 
 	return status.Error(codes.Internal, err.Error())
 }
+
+// FromGRPCError converts a gRPC status error back into an *Error. The code is
+// taken from an ErrorInfo reason when present, otherwise from the gRPC code
+// (see CodeFromGRPCCode). ErrorInfo metadata becomes details, and BadRequest
+// field violations are restored as FieldViolations (see FieldViolations).
+//
+// It returns nil for a nil error or an OK status, and an error with
+// CodeUnknown wrapping err when err is not a gRPC status error.
+func FromGRPCError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return &Error{
+			Code:    CodeUnknown,
+			Message: err.Error(),
+			Cause:   err,
+			Details: make(map[string]any),
+		}
+	}
+	if st.Code() == codes.OK {
+		return nil
+	}
+
+	customErr := &Error{
+		Code:    CodeFromGRPCCode(int(st.Code())),
+		Message: st.Message(),
+		Details: make(map[string]any),
+	}
+
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetReason() != "" {
+				customErr.Code = Code(d.GetReason())
+			}
+			for k, v := range d.GetMetadata() {
+				customErr.Details[k] = v
+			}
+		case *errdetails.BadRequest:
+			violations := make([]FieldViolation, 0, len(d.GetFieldViolations()))
+			for _, v := range d.GetFieldViolations() {
+				violations = append(violations, FieldViolation{
+					Field:       v.GetField(),
+					Code:        v.GetReason(),
+					Description: v.GetDescription(),
+				})
+			}
+			customErr.WithPublicDetail(fieldViolationsKey, violations)
+		}
+	}
+
+	return customErr
+}
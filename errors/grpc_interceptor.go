@@ -0,0 +1,120 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+type InterceptorOption func(*interceptorOptions)
+
+type interceptorOptions struct {
+	logger *slog.Logger
+	record func(ctx context.Context, fullMethod string, code Code)
+}
+
+// WithInterceptorLogger logs every failed RPC with its method, code and error:
+// client errors (see Code.IsClientError) at warn level, others at error level.
+func WithInterceptorLogger(logger *slog.Logger) InterceptorOption {
+	return func(o *interceptorOptions) {
+		o.logger = logger
+	}
+}
+
+// WithInterceptorMetrics calls record with the error code of every failed
+// RPC, e.g. to increment a counter labeled by method and code.
+func WithInterceptorMetrics(record func(ctx context.Context, fullMethod string, code Code)) InterceptorOption {
+	return func(o *interceptorOptions) {
+		o.record = record
+	}
+}
+
+func newInterceptorOptions(opts []InterceptorOption) *interceptorOptions {
+	o := &interceptorOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// UnaryServerInterceptor converts the errors returned by unary handlers with
+// ToGRPCError, and panics into CodeInternal errors (see Recover), so handlers
+// can return *Error values directly:
+//
+//	grpc.NewServer(grpc.ChainUnaryInterceptor(
+//	    errors.UnaryServerInterceptor(errors.WithInterceptorLogger(logger)),
+//	))
+//
+// Errors that already are gRPC status errors are returned unchanged, and
+// context cancellation and deadline errors get the matching gRPC code.
+func UnaryServerInterceptor(opts ...InterceptorOption) grpc.UnaryServerInterceptor {
+	o := newInterceptorOptions(opts)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var resp any
+		err := RecoverFunc(func() error {
+			var err error
+			resp, err = handler(ctx, req)
+			return err
+		})
+		if err != nil {
+			return nil, o.handle(ctx, info.FullMethod, err)
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs.
+func StreamServerInterceptor(opts ...InterceptorOption) grpc.StreamServerInterceptor {
+	o := newInterceptorOptions(opts)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := RecoverFunc(func() error {
+			return handler(srv, ss)
+		})
+		if err != nil {
+			return o.handle(ss.Context(), info.FullMethod, err)
+		}
+		return nil
+	}
+}
+
+func (o *interceptorOptions) handle(ctx context.Context, fullMethod string, err error) error {
+	code, grpcErr := toGRPCStatusError(err)
+	if o.record != nil {
+		o.record(ctx, fullMethod, code)
+	}
+	if o.logger != nil {
+		level := slog.LevelError
+		if code.IsClientError() {
+			level = slog.LevelWarn
+		}
+		o.logger.LogAttrs(ctx, level, "grpc call failed",
+			slog.String("grpc.method", fullMethod),
+			slog.String("error.code", code.String()),
+			slog.Any("error", err),
+		)
+	}
+	return grpcErr
+}
+
+// toGRPCStatusError returns the code err is recorded under and err converted
+// for a gRPC response.
+func toGRPCStatusError(err error) (Code, error) {
+	var customErr *Error
+	if As(err, &customErr) {
+		return customErr.Code, ToGRPCError(err)
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		err = status.FromContextError(err).Err()
+	}
+	if st, ok := status.FromError(err); ok {
+		return CodeFromGRPCCode(int(st.Code())), err
+	}
+	return CodeInternal, ToGRPCError(err)
+}
@@ -1,6 +1,10 @@
 package errors
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
 
 type HTTPError struct {
 	Code       string         `json:"code"`
@@ -9,7 +13,45 @@ type HTTPError struct {
 	StackTrace []string       `json:"stack_trace,omitempty"`
 }
 
+// HTTPOptions configures ToHTTPErrorWithOptions and ToHTTPResponseWithOptions.
+type HTTPOptions struct {
+	// Language is used to translate the message when a translator is
+	// configured (see LocalizedMessage).
+	Language string
+	// IncludeStackTrace adds the stack trace.
+	IncludeStackTrace bool
+	// IncludeInternalDetails renders every detail instead of only the public
+	// ones (see WithPublicDetail). Only use it for internal endpoints.
+	IncludeInternalDetails bool
+}
+
+// WithAcceptLanguage returns o with Language set to the preferred language of
+// an Accept-Language header (see PreferredLanguage). Language is left as is
+// when the header names none, so it serves as the default.
+func (o HTTPOptions) WithAcceptLanguage(acceptLanguage string) HTTPOptions {
+	if lang := PreferredLanguage(acceptLanguage); lang != "" {
+		o.Language = lang
+	}
+	return o
+}
+
+// ToHTTPError converts an error to an HTTPError with the public details of the
+// error (see WithPublicDetail). When a translator is configured (see
+// SetTranslator), the message is translated into its default language; use
+// ToLocalizedHTTPError to pick the language.
 func ToHTTPError(err error, includeStackTrace bool) HTTPError {
+	return ToHTTPErrorWithOptions(err, HTTPOptions{IncludeStackTrace: includeStackTrace})
+}
+
+// ToLocalizedHTTPError is ToHTTPError with the message translated into lang
+// (see LocalizedMessage).
+func ToLocalizedHTTPError(err error, lang string, includeStackTrace bool) HTTPError {
+	return ToHTTPErrorWithOptions(err, HTTPOptions{Language: lang, IncludeStackTrace: includeStackTrace})
+}
+
+// ToHTTPErrorWithOptions converts an error to an HTTPError as configured by
+// opts.
+func ToHTTPErrorWithOptions(err error, opts HTTPOptions) HTTPError {
 	if err == nil {
 		return HTTPError{
 			Code:    CodeInternal.String(),
@@ -21,11 +63,14 @@ func ToHTTPError(err error, includeStackTrace bool) HTTPError {
 	if As(err, &customErr) {
 		httpErr := HTTPError{
 			Code:    customErr.Code.String(),
-			Message: customErr.Message,
-			Details: customErr.Details,
+			Message: LocalizedMessage(customErr, opts.Language),
+			Details: customErr.publicDetails(),
+		}
+		if opts.IncludeInternalDetails {
+			httpErr.Details = customErr.Details
 		}
 
-		if includeStackTrace && len(customErr.StackTrace) > 0 {
+		if opts.IncludeStackTrace && len(customErr.StackTrace) > 0 {
 			traces := make([]string, 0, len(customErr.StackTrace))
 			for _, frame := range customErr.StackTrace {
 				traces = append(traces, frame.String())
@@ -69,12 +114,20 @@ type HTTPResponse struct {
 }
 
 func ToHTTPResponse(err error, includeStackTrace bool) HTTPResponse {
-	httpErr := ToHTTPError(err, includeStackTrace)
-	statusCode := HTTPStatusCode(err)
+	return ToHTTPResponseWithOptions(err, HTTPOptions{IncludeStackTrace: includeStackTrace})
+}
+
+// ToLocalizedHTTPResponse is ToHTTPResponse with the message translated into
+// lang (see LocalizedMessage).
+func ToLocalizedHTTPResponse(err error, lang string, includeStackTrace bool) HTTPResponse {
+	return ToHTTPResponseWithOptions(err, HTTPOptions{Language: lang, IncludeStackTrace: includeStackTrace})
+}
 
+// ToHTTPResponseWithOptions is ToHTTPResponse configured by opts.
+func ToHTTPResponseWithOptions(err error, opts HTTPOptions) HTTPResponse {
 	return HTTPResponse{
-		StatusCode: statusCode,
-		Error:      httpErr,
+		StatusCode: HTTPStatusCode(err),
+		Error:      ToHTTPErrorWithOptions(err, opts),
 	}
 }
 
@@ -85,3 +138,110 @@ func (r HTTPResponse) WriteJSON() (string, error) {
 	}
 	return string(bytes), nil
 }
+
+// maxRawBodyDetail is the longest unrecognized response body kept in the
+// "body" detail by FromHTTPResponse.
+const maxRawBodyDetail = 512
+
+// FromHTTPResponse reconstructs the error returned by a downstream service
+// from its response status code and body. It recognizes the HTTPResponse and
+// HTTPError JSON shapes and RFC 7807 problem details, restoring the code,
+// message and details (as public details, see WithPublicDetail). Other bodies give an error with the code for the
+// status (see CodeFromHTTPStatus), the status text as message and the start
+// of the body as the "body" detail.
+//
+// It returns nil for statuses below 400.
+func FromHTTPResponse(statusCode int, body []byte) *Error {
+	if statusCode < 400 {
+		return nil
+	}
+
+	customErr := &Error{
+		Code:    CodeFromHTTPStatus(statusCode),
+		Message: http.StatusText(statusCode),
+		Details: make(map[string]any),
+	}
+
+	if decodeErrorBody(customErr, body) {
+		// The details were rendered in a client-facing response.
+		customErr.markPublic()
+	} else {
+		if raw := bytes.TrimSpace(body); len(raw) > 0 {
+			if len(raw) > maxRawBodyDetail {
+				raw = raw[:maxRawBodyDetail]
+			}
+			customErr.Details["body"] = string(raw)
+		}
+	}
+
+	return customErr
+}
+
+// decodeErrorBody applies a recognized error body to customErr and reports
+// whether the body was recognized.
+func decodeErrorBody(customErr *Error, body []byte) bool {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil {
+		return false
+	}
+
+	if members["error"] != nil {
+		var resp HTTPResponse
+		if err := json.Unmarshal(body, &resp); err == nil && resp.Error.Code != "" {
+			applyHTTPError(customErr, resp.Error)
+			return true
+		}
+	}
+
+	if members["code"] != nil && members["message"] != nil {
+		var httpErr HTTPError
+		if err := json.Unmarshal(body, &httpErr); err == nil {
+			applyHTTPError(customErr, httpErr)
+			return true
+		}
+	}
+
+	if isProblem(members) {
+		var problem ProblemDetails
+		if err := json.Unmarshal(body, &problem); err != nil {
+			return false
+		}
+		if code, ok := problem.Extensions["code"].(string); ok && code != "" {
+			customErr.Code = Code(code)
+		}
+		if problem.Detail != "" {
+			customErr.Message = problem.Detail
+		} else if problem.Title != "" {
+			customErr.Message = problem.Title
+		}
+		for k, v := range problem.Extensions {
+			if k != "code" && k != "stack_trace" {
+				customErr.Details[k] = v
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func applyHTTPError(customErr *Error, httpErr HTTPError) {
+	if httpErr.Code != "" {
+		customErr.Code = Code(httpErr.Code)
+	}
+	if httpErr.Message != "" {
+		customErr.Message = httpErr.Message
+	}
+	for k, v := range httpErr.Details {
+		customErr.Details[k] = v
+	}
+}
+
+func isProblem(members map[string]json.RawMessage) bool {
+	for _, k := range []string{"type", "title", "status", "detail"} {
+		if members[k] != nil {
+			return true
+		}
+	}
+	return false
+}
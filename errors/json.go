@@ -0,0 +1,109 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// errorJSON is the wire format of *Error.
+type errorJSON struct {
+	Code       Code           `json:"code,omitempty"`
+	Message    string         `json:"message"`
+	MessageKey string         `json:"message_key,omitempty"`
+	Op         string         `json:"op,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+	Public     []string       `json:"public_details,omitempty"`
+	StackTrace []StackFrame   `json:"stack_trace,omitempty"`
+	Cause      *errorJSON     `json:"cause,omitempty"`
+	Retryable  *bool          `json:"retryable,omitempty"`
+}
+
+// MarshalJSON encodes the error with its code, message and message key,
+// details (with the keys of the public ones), stack trace, retryable flag and
+// cause chain, so it can cross service boundaries or be stored (e.g. in an
+// outbox or dead-letter payload) and be reconstructed with UnmarshalJSON.
+// WithOp annotations are kept; other causes that are not *Error are encoded by
+// their message only. Clear StackTrace before marshaling errors for untrusted
+// receivers.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toErrorJSON(e))
+}
+
+// UnmarshalJSON decodes an error encoded by MarshalJSON. Causes encoded
+// without a code are restored as plain errors carrying the message. Detail
+// values are restored as JSON types (e.g. numbers become float64).
+func (e *Error) UnmarshalJSON(data []byte) error {
+	var v errorJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*e = *fromErrorJSON(&v)
+	return nil
+}
+
+func toErrorJSON(e *Error) *errorJSON {
+	v := &errorJSON{
+		Code:       e.Code,
+		Message:    e.Message,
+		MessageKey: e.MessageKey,
+		Op:         e.Op,
+		Details:    e.Details,
+		Public:     e.publicKeys(),
+		StackTrace: e.StackTrace,
+		Retryable:  e.retryable,
+	}
+	if e.Cause != nil {
+		v.Cause = causeToJSON(e.Cause)
+	}
+	return v
+}
+
+func causeToJSON(err error) *errorJSON {
+	switch e := err.(type) {
+	case *Error:
+		return toErrorJSON(e)
+	case *opError:
+		// Encoded as {"op": ..., "cause": ...} without code and message.
+		return &errorJSON{Op: e.op, Cause: causeToJSON(e.err)}
+	default:
+		return &errorJSON{Message: err.Error()}
+	}
+}
+
+func fromErrorJSON(v *errorJSON) *Error {
+	e := &Error{
+		Code:       v.Code,
+		Message:    v.Message,
+		MessageKey: v.MessageKey,
+		Op:         v.Op,
+		StackTrace: v.StackTrace,
+		Details:    v.Details,
+		retryable:  v.Retryable,
+	}
+	if e.Details == nil {
+		e.Details = make(map[string]any)
+	}
+	for _, k := range v.Public {
+		if _, ok := e.Details[k]; ok {
+			if e.public == nil {
+				e.public = make(map[string]bool, len(v.Public))
+			}
+			e.public[k] = true
+		}
+	}
+	if v.Cause != nil {
+		e.Cause = causeFromJSON(v.Cause)
+	}
+	return e
+}
+
+func causeFromJSON(v *errorJSON) error {
+	switch {
+	case v.Code != "":
+		return fromErrorJSON(v)
+	case v.Op != "" && v.Cause != nil:
+		return &opError{op: v.Op, err: causeFromJSON(v.Cause)}
+	default:
+		return errors.New(v.Message)
+	}
+}
@@ -0,0 +1,93 @@
+package errors
+
+import (
+	"strings"
+	"sync"
+)
+
+// Translator translates user-facing error messages. key is the error's
+// MessageKey, or its code when no key is set; params are the error details.
+// It reports false when it has no translation, in which case the English
+// Message is used. An empty lang selects the translator's default language.
+type Translator interface {
+	Translate(lang, key string, params map[string]any) (string, bool)
+}
+
+// TranslatorFunc adapts a function to the Translator interface.
+type TranslatorFunc func(lang, key string, params map[string]any) (string, bool)
+
+// Translate calls f.
+func (f TranslatorFunc) Translate(lang, key string, params map[string]any) (string, bool) {
+	return f(lang, key, params)
+}
+
+var (
+	translatorMu sync.RWMutex
+	translator   Translator
+)
+
+// SetTranslator configures the translator used by LocalizedMessage and the
+// HTTP and problem details adapters. Pass nil to disable translation.
+func SetTranslator(t Translator) {
+	translatorMu.Lock()
+	defer translatorMu.Unlock()
+	translator = t
+}
+
+func currentTranslator() Translator {
+	translatorMu.RLock()
+	defer translatorMu.RUnlock()
+	return translator
+}
+
+// WithMessageKey sets the key used to translate the message (see Translator).
+// Returns the error for chaining.
+func (e *Error) WithMessageKey(key string) *Error {
+	e = e.mutable()
+	e.MessageKey = key
+	return e
+}
+
+// LocalizedMessage returns the user-facing message of err in lang: the
+// translation of the outermost *Error when a translator is configured and has
+// one, otherwise its Message. Internal messages (Error(), logs) are not
+// affected. Errors that are not *Error return err.Error().
+func LocalizedMessage(err error, lang string) string {
+	if err == nil {
+		return ""
+	}
+
+	var customErr *Error
+	if !As(err, &customErr) {
+		return err.Error()
+	}
+	if msg, ok := translate(customErr, lang); ok {
+		return msg
+	}
+	return customErr.Message
+}
+
+func translate(e *Error, lang string) (string, bool) {
+	t := currentTranslator()
+	if t == nil {
+		return "", false
+	}
+	key := e.MessageKey
+	if key == "" {
+		key = e.Code.String()
+	}
+	return t.Translate(lang, key, e.Details)
+}
+
+// PreferredLanguage returns the first language tag of an Accept-Language
+// header, e.g. "fr-CH" for "fr-CH, fr;q=0.9, en;q=0.8", to pick the language
+// of localized responses.
+func PreferredLanguage(acceptLanguage string) string {
+	tag, _, _ := strings.Cut(acceptLanguage, ",")
+	tag, _, _ = strings.Cut(tag, ";")
+	tag = strings.TrimSpace(tag)
+	if tag == "*" {
+		return ""
+	}
+	return tag
+}
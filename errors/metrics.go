@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Counter counts created errors by code, e.g. with a Prometheus counter
+// vector, so error rates per code can be graphed without log-based metrics.
+// component is empty unless the error was created through a Component.
+type Counter interface {
+	Count(code Code, component string)
+}
+
+// CounterFunc adapts a function to the Counter interface.
+type CounterFunc func(code Code, component string)
+
+// Count calls f.
+func (f CounterFunc) Count(code Code, component string) {
+	f(code, component)
+}
+
+var (
+	counterMu sync.RWMutex
+	counter   Counter
+)
+
+// SetCounter configures the counter notified by New, Newf, Wrap, Wrapf,
+// InvalidField, Validation.Err and Recover. Sentinels and errors restored from
+// JSON, HTTP or gRPC are not counted. Pass nil to disable counting.
+//
+//	errors.SetCounter(errors.CounterFunc(func(code errors.Code, component string) {
+//	    errorsTotal.WithLabelValues(code.String(), component).Inc()
+//	}))
+func SetCounter(c Counter) {
+	counterMu.Lock()
+	defer counterMu.Unlock()
+	counter = c
+}
+
+func currentCounter() Counter {
+	counterMu.RLock()
+	defer counterMu.RUnlock()
+	return counter
+}
+
+// Component creates errors counted under a component label (see SetCounter),
+// e.g. per service or package:
+//
+//	var errs = errors.Component("billing")
+//
+//	return errs.Wrap(err, errors.CodeUnavailable, "cannot charge card")
+type Component string
+
+// New is New counted under c.
+func (c Component) New(code Code, message string) *Error {
+	return created(&Error{
+		Code:       code,
+		Message:    message,
+		StackTrace: captureStackTrace(1),
+		Details:    make(map[string]any),
+	}, string(c))
+}
+
+// Newf is Newf counted under c.
+func (c Component) Newf(code Code, format string, args ...any) *Error {
+	return created(&Error{
+		Code:       code,
+		Message:    fmt.Sprintf(format, args...),
+		StackTrace: captureStackTrace(1),
+		Details:    make(map[string]any),
+	}, string(c))
+}
+
+// Wrap is Wrap counted under c.
+func (c Component) Wrap(err error, code Code, message string) *Error {
+	return wrap(err, code, message, string(c), 1)
+}
+
+// Wrapf is Wrapf counted under c.
+func (c Component) Wrapf(err error, code Code, format string, args ...any) *Error {
+	if err == nil {
+		return nil
+	}
+	return wrap(err, code, fmt.Sprintf(format, args...), string(c), 1)
+}
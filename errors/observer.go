@@ -0,0 +1,66 @@
+package errors
+
+import "sync"
+
+// Observer is called with every error created by New, Newf, Wrap, Wrapf,
+// InvalidField, Validation.Err and Recover before it is returned, for
+// cross-cutting concerns such as adding deployment metadata, sampling stack
+// traces or forwarding errors to a debug buffer. Observers may modify the
+// error; they run on the creating goroutine and must be fast.
+type Observer func(e *Error)
+
+type observerEntry struct {
+	id int
+	fn Observer
+}
+
+var (
+	observersMu    sync.RWMutex
+	observers      []observerEntry
+	nextObserverID int
+)
+
+// AddObserver registers o and returns a function that unregisters it.
+// Observers run in registration order.
+//
+//	errors.AddObserver(func(e *errors.Error) {
+//	    e.WithDetail("version", buildinfo.Version)
+//	})
+func AddObserver(o Observer) (remove func()) {
+	observersMu.Lock()
+	defer observersMu.Unlock()
+
+	nextObserverID++
+	id := nextObserverID
+	// Copy on write so created can iterate without holding the lock.
+	observers = append(observers[:len(observers):len(observers)], observerEntry{id: id, fn: o})
+
+	return func() {
+		observersMu.Lock()
+		defer observersMu.Unlock()
+		for i, entry := range observers {
+			if entry.id == id {
+				observers = append(observers[:i:i], observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func currentObservers() []observerEntry {
+	observersMu.RLock()
+	defer observersMu.RUnlock()
+	return observers
+}
+
+// created runs the observers and the counter (see SetCounter) on a newly
+// created error and returns it.
+func created(e *Error, component string) *Error {
+	for _, entry := range currentObservers() {
+		entry.fn(e)
+	}
+	if c := currentCounter(); c != nil {
+		c.Count(e.Code, component)
+	}
+	return e
+}
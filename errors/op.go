@@ -0,0 +1,60 @@
+package errors
+
+// opError annotates an error with the operation it passed through without
+// changing its code, message or details.
+type opError struct {
+	op  string
+	err error
+}
+
+func (e *opError) Error() string {
+	return e.op + ": " + e.err.Error()
+}
+
+func (e *opError) Unwrap() error {
+	return e.err
+}
+
+// WithOp sets the operation that produced the error, e.g.
+// "userservice.Create". Returns the error for chaining.
+func (e *Error) WithOp(op string) *Error {
+	e = e.mutable()
+	e.Op = op
+	return e
+}
+
+// WithOp annotates err with the operation it is passing through, e.g.
+// "userservice.Create", as a lightweight breadcrumb that costs no stack
+// capture. Code, message and details of err are unchanged: GetCode, As and the
+// protocol adapters see through the annotation. Returns nil if err is nil.
+//
+//	func (s *UserService) Create(ctx context.Context, u User) error {
+//	    if err := s.repo.Insert(ctx, u); err != nil {
+//	        return errors.WithOp(err, "userservice.Create")
+//	    }
+//	    return nil
+//	}
+func WithOp(err error, op string) error {
+	if err == nil {
+		return nil
+	}
+	return &opError{op: op, err: err}
+}
+
+// Ops returns the operations recorded in the error chain by WithOp and the Op
+// field of *Error, outermost (most recent) first.
+func Ops(err error) []string {
+	var ops []string
+	for err != nil {
+		switch e := err.(type) {
+		case *opError:
+			ops = append(ops, e.op)
+		case *Error:
+			if e.Op != "" {
+				ops = append(ops, e.Op)
+			}
+		}
+		err = Unwrap(err)
+	}
+	return ops
+}
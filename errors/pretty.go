@@ -0,0 +1,185 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
+)
+
+const (
+	ansiReset       = "\x1b[0m"
+	ansiBold        = "\x1b[1m"
+	ansiDim         = "\x1b[2m"
+	ansiCyan        = "\x1b[36m"
+	ansiBadgeRed    = "\x1b[1;97;41m"
+	ansiBadgeYellow = "\x1b[1;30;43m"
+	ansiBadgeGray   = "\x1b[1;97;100m"
+)
+
+// defaultPrettyFrames is the number of stack frames ToPrettyCMDError prints
+// by default.
+const defaultPrettyFrames = 10
+
+// PrettyOptions configures ToPrettyCMDError.
+type PrettyOptions struct {
+	// NoColor disables ANSI colors. Colors are also disabled when the
+	// NO_COLOR environment variable is set.
+	NoColor bool
+	// StackTrace adds the stack trace of the innermost error that has one.
+	StackTrace bool
+	// MaxFrames limits the printed stack frames. Default: 10.
+	MaxFrames int
+	// Project is the import path prefix of the application's packages, whose
+	// frames are highlighted, e.g. "github.com/acme/shop". Default: the path
+	// of the main module.
+	Project string
+}
+
+// ToPrettyCMDError renders an error for terminals as a tree: a code badge and
+// message per error in the chain, with its operation and details, and each
+// cause indented below it. Without colors, it looks like:
+//
+//	[NOT_FOUND] cannot load order
+//	  op: orders.Get
+//	  order_id  42
+//	  └─ [DATABASE_ERROR] query failed
+//	       └─ connection refused
+//
+// With StackTrace, the stack is printed below the tree, with the frames of
+// the project and of package main highlighted. Runtime frames are omitted.
+func ToPrettyCMDError(err error, opts PrettyOptions) string {
+	if err == nil {
+		return ""
+	}
+	if !opts.NoColor {
+		if _, ok := os.LookupEnv("NO_COLOR"); ok {
+			opts.NoColor = true
+		}
+	}
+	if opts.MaxFrames <= 0 {
+		opts.MaxFrames = defaultPrettyFrames
+	}
+	if opts.Project == "" {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			opts.Project = info.Main.Path
+		}
+	}
+
+	p := &prettyPrinter{opts: opts}
+	p.writeNode(*ToTree(err), "", "")
+	if opts.StackTrace {
+		if frames := innermostStackTrace(err); len(frames) > 0 {
+			p.writeStack(frames)
+		}
+	}
+	return p.sb.String()
+}
+
+type prettyPrinter struct {
+	sb   strings.Builder
+	opts PrettyOptions
+}
+
+func (p *prettyPrinter) color(style, s string) string {
+	if p.opts.NoColor {
+		return s
+	}
+	return style + s + ansiReset
+}
+
+// writeNode writes node and its children. head prefixes the first line of
+// node and body the following ones.
+func (p *prettyPrinter) writeNode(node ErrorTree, head, body string) {
+	switch {
+	case node.Code != "":
+		p.sb.WriteString(head + p.badge(node.Code) + " " + node.Message + "\n")
+		if node.Op != "" {
+			p.sb.WriteString(body + "  " + p.color(ansiDim, "op: ") + node.Op + "\n")
+		}
+		p.writeDetails(body+"  ", node.Details)
+	case node.Op != "":
+		p.sb.WriteString(head + p.color(ansiDim, "op: ") + node.Op + "\n")
+	default:
+		p.sb.WriteString(head + node.Message + "\n")
+	}
+
+	for i, child := range node.Children {
+		if i == len(node.Children)-1 {
+			p.writeNode(child, body+"  └─ ", body+"     ")
+		} else {
+			p.writeNode(child, body+"  ├─ ", body+"  │  ")
+		}
+	}
+}
+
+func (p *prettyPrinter) badge(code Code) string {
+	label := " " + string(code) + " "
+	if p.opts.NoColor {
+		return "[" + string(code) + "]"
+	}
+	switch {
+	case code.IsClientError():
+		return p.color(ansiBadgeYellow, label)
+	case code.IsServerError():
+		return p.color(ansiBadgeRed, label)
+	default:
+		return p.color(ansiBadgeGray, label)
+	}
+}
+
+func (p *prettyPrinter) writeDetails(indent string, details map[string]any) {
+	keys := slices.Sorted(maps.Keys(details))
+	width := 0
+	for _, k := range keys {
+		width = max(width, len(k))
+	}
+	for _, k := range keys {
+		p.sb.WriteString(fmt.Sprintf("%s%s  %v\n", indent, p.color(ansiCyan, fmt.Sprintf("%-*s", width, k)), details[k]))
+	}
+}
+
+func (p *prettyPrinter) writeStack(frames []StackFrame) {
+	p.sb.WriteString(p.color(ansiBold, "Stack Trace:") + "\n")
+	printed := 0
+	for i, frame := range frames {
+		if strings.HasPrefix(frame.Function, "runtime.") {
+			continue
+		}
+		if printed == p.opts.MaxFrames {
+			p.sb.WriteString(p.color(ansiDim, fmt.Sprintf("  ... %d more frames", len(frames)-i)) + "\n")
+			break
+		}
+		printed++
+
+		line := fmt.Sprintf("%s\n      %s:%d", frame.Function, frame.File, frame.Line)
+		if p.isProjectFrame(frame) {
+			p.sb.WriteString("  > " + p.color(ansiBold+ansiCyan, line) + "\n")
+		} else {
+			p.sb.WriteString("    " + p.color(ansiDim, line) + "\n")
+		}
+	}
+}
+
+func (p *prettyPrinter) isProjectFrame(frame StackFrame) bool {
+	if strings.HasPrefix(frame.Function, "main.") {
+		return true
+	}
+	return p.opts.Project != "" && strings.HasPrefix(frame.Function, p.opts.Project)
+}
+
+// innermostStackTrace returns the stack trace of the innermost *Error that
+// has one, which is where the failure happened.
+func innermostStackTrace(err error) []StackFrame {
+	var frames []StackFrame
+	for err != nil {
+		if e, ok := err.(*Error); ok && len(e.StackTrace) > 0 {
+			frames = e.StackTrace
+		}
+		err = errors.Unwrap(err)
+	}
+	return frames
+}
@@ -0,0 +1,179 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem details object. Extensions are
+// marshaled as top-level members next to the standard ones.
+type ProblemDetails struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+// ProblemOptions configures ToProblemDetails.
+type ProblemOptions struct {
+	// TypeBaseURI is prefixed to the error code to build the problem type,
+	// e.g. "https://errors.example.com/" gives
+	// "https://errors.example.com/NOT_FOUND". When empty, the type is
+	// "about:blank".
+	TypeBaseURI string
+	// Instance identifies this occurrence of the problem, e.g. the request path.
+	Instance string
+	// IncludeStackTrace adds the stack trace as the "stack_trace" member.
+	IncludeStackTrace bool
+	// IncludeInternalDetails adds every detail instead of only the public
+	// ones (see WithPublicDetail). Only use it for internal endpoints.
+	IncludeInternalDetails bool
+	// Language is used to translate the detail when a translator is
+	// configured (see SetTranslator). WriteProblem defaults it to the
+	// request's preferred Accept-Language.
+	Language string
+}
+
+// problemMembers are the standard members that Details cannot override.
+var problemMembers = map[string]bool{
+	"type":     true,
+	"title":    true,
+	"status":   true,
+	"detail":   true,
+	"instance": true,
+}
+
+// ToProblemDetails converts an error to RFC 7807 problem details. The title is
+// the HTTP status text, the detail is the error message, and the error code and
+// public details are added as extension members ("code" and one member per
+// detail).
+func ToProblemDetails(err error, opts ProblemOptions) ProblemDetails {
+	httpErr := ToHTTPErrorWithOptions(err, HTTPOptions{
+		Language:               opts.Language,
+		IncludeStackTrace:      opts.IncludeStackTrace,
+		IncludeInternalDetails: opts.IncludeInternalDetails,
+	})
+	status := HTTPStatusCode(err)
+	if err == nil {
+		status = http.StatusInternalServerError
+	}
+
+	problem := ProblemDetails{
+		Type:       "about:blank",
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     httpErr.Message,
+		Instance:   opts.Instance,
+		Extensions: make(map[string]any, len(httpErr.Details)+2),
+	}
+	if problem.Title == "" {
+		// Non-standard statuses such as 499 have no status text.
+		problem.Title = httpErr.Code
+	}
+	if opts.TypeBaseURI != "" {
+		problem.Type = opts.TypeBaseURI + httpErr.Code
+	}
+
+	for k, v := range httpErr.Details {
+		if !problemMembers[k] {
+			problem.Extensions[k] = v
+		}
+	}
+	problem.Extensions["code"] = httpErr.Code
+	if len(httpErr.StackTrace) > 0 {
+		problem.Extensions["stack_trace"] = httpErr.StackTrace
+	}
+
+	return problem
+}
+
+// MarshalJSON flattens Extensions into the problem object.
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		if !problemMembers[k] {
+			members[k] = v
+		}
+	}
+	if p.Type != "" {
+		members["type"] = p.Type
+	}
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON reads the standard members and collects the others into
+// Extensions.
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	*p = ProblemDetails{}
+	fields := map[string]any{
+		"type":     &p.Type,
+		"title":    &p.Title,
+		"status":   &p.Status,
+		"detail":   &p.Detail,
+		"instance": &p.Instance,
+	}
+	for k, raw := range members {
+		if field, ok := fields[k]; ok {
+			if err := json.Unmarshal(raw, field); err != nil {
+				return err
+			}
+			continue
+		}
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]any)
+		}
+		p.Extensions[k] = v
+	}
+	return nil
+}
+
+// WriteProblem writes err to w as an application/problem+json response with
+// the matching HTTP status code. When r is not nil, opts.Instance defaults to
+// the request path and opts.Language to the preferred Accept-Language.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error, opts ProblemOptions) error {
+	if r != nil {
+		if opts.Instance == "" && r.URL != nil {
+			opts.Instance = r.URL.Path
+		}
+		if opts.Language == "" {
+			opts.Language = PreferredLanguage(r.Header.Get("Accept-Language"))
+		}
+	}
+	problem := ToProblemDetails(err, opts)
+
+	body, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	_, err = w.Write(body)
+	return err
+}
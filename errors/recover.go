@@ -0,0 +1,58 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Recover converts a panic into a CodeInternal error stored in *errp. It must
+// be deferred directly:
+//
+//	func (w *Worker) process(job Job) (err error) {
+//	    defer errors.Recover(&err)
+//	    ...
+//	}
+//
+// The error carries the panic value as the "panic" detail (and as Cause when
+// it is an error) and the stack of the panicking goroutine. http.ErrAbortHandler
+// is re-panicked, since net/http uses it to abort a response on purpose.
+func Recover(errp *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+	*errp = created(panicError(v, 1), "")
+}
+
+// RecoverFunc calls fn and returns its error, or the panic converted as by
+// Recover, e.g. for worker goroutines and transaction callbacks.
+func RecoverFunc(fn func() error) (err error) {
+	defer Recover(&err)
+	return fn()
+}
+
+func panicError(v any, skip int) *Error {
+	e := &Error{
+		Code:       CodeInternal,
+		Message:    fmt.Sprintf("panic: %v", v),
+		StackTrace: trimRuntimeFrames(captureStackTrace(skip + 1)),
+		Details:    map[string]any{"panic": fmt.Sprint(v)},
+	}
+	if cause, ok := v.(error); ok {
+		e.Cause = cause
+	}
+	return e
+}
+
+// trimRuntimeFrames drops the leading runtime frames (gopanic, panicmem, ...)
+// so the stack starts at the code that panicked.
+func trimRuntimeFrames(frames []StackFrame) []StackFrame {
+	for len(frames) > 0 && strings.HasPrefix(frames[0].Function, "runtime.") {
+		frames = frames[1:]
+	}
+	return frames
+}
@@ -0,0 +1,50 @@
+package errors
+
+import (
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+type codeMapping struct {
+	httpStatus int
+	grpcCode   codes.Code
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[Code]codeMapping)
+)
+
+// RegisterCode registers an application-specific code with its HTTP status
+// and gRPC code, used by HTTPStatusCode, GRPCCode and the protocol adapters
+// instead of the 500/Unknown fallback. It can also remap a built-in code.
+// Register codes at init time, typically as package-level variables:
+//
+//	var CodeQuotaExceeded = errors.RegisterCode("QUOTA_EXCEEDED", 429, codes.ResourceExhausted)
+//
+// It panics if the code is empty, the status is not a 4xx or 5xx status or
+// the gRPC code is invalid, since these are programming errors.
+func RegisterCode(code Code, httpStatus int, grpcCode codes.Code) Code {
+	switch {
+	case code == "":
+		panic("errors: RegisterCode with empty code")
+	case httpStatus < 400 || httpStatus > 599:
+		panic(fmt.Sprintf("errors: RegisterCode %s with non-error HTTP status %d", code, httpStatus))
+	case grpcCode == codes.OK || grpcCode > codes.Unauthenticated:
+		panic(fmt.Sprintf("errors: RegisterCode %s with invalid gRPC code %d", code, grpcCode))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[code] = codeMapping{httpStatus: httpStatus, grpcCode: grpcCode}
+	return code
+}
+
+func registeredCode(code Code) (codeMapping, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	mapping, ok := registry[code]
+	return mapping, ok
+}
@@ -0,0 +1,56 @@
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// Reporter sends errors to an error tracking service such as Sentry (see the
+// errorssentry package).
+type Reporter interface {
+	Report(ctx context.Context, err error)
+}
+
+// ReporterFunc adapts a function to the Reporter interface.
+type ReporterFunc func(ctx context.Context, err error)
+
+// Report calls f.
+func (f ReporterFunc) Report(ctx context.Context, err error) {
+	f(ctx, err)
+}
+
+var (
+	reporterMu sync.RWMutex
+	reporter   Reporter
+)
+
+// SetReporter configures the reporter used by Report and ReportContext. Pass
+// nil to disable reporting.
+func SetReporter(r Reporter) {
+	reporterMu.Lock()
+	defer reporterMu.Unlock()
+	reporter = r
+}
+
+func currentReporter() Reporter {
+	reporterMu.RLock()
+	defer reporterMu.RUnlock()
+	return reporter
+}
+
+// Report sends err to the configured reporter. It does nothing when err is nil
+// or no reporter is configured, so it can be called unconditionally.
+func Report(err error) {
+	ReportContext(context.Background(), err)
+}
+
+// ReportContext is Report with a context, e.g. to let the reporter pick up
+// request-scoped data.
+func ReportContext(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	if r := currentReporter(); r != nil {
+		r.Report(ctx, err)
+	}
+}
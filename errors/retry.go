@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"context"
+	"errors"
+)
+
+// Retryable marks the error as safe (or not) to retry, overriding the default
+// derived from its code (see Code.Retryable). Returns the error for chaining.
+func (e *Error) Retryable(retryable bool) *Error {
+	e = e.mutable()
+	e.retryable = &retryable
+	return e
+}
+
+// Retryable reports whether errors with this code are transient by default:
+// true for CodeUnavailable, CodeTimeout and CodeNetwork.
+func (c Code) Retryable() bool {
+	switch c {
+	case CodeUnavailable, CodeTimeout, CodeNetwork:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRetryable reports whether err is transient and the operation may be
+// retried. It is the canonical check for retry loops and clients:
+//   - context cancellation is never retryable;
+//   - the first error in the chain that is an *Error marked with
+//     (*Error).Retryable, or that implements Retryable() bool (e.g.
+//     kdbx.DatabaseError), decides;
+//   - otherwise the code of the outermost *Error decides (Code.Retryable);
+//   - other errors are retryable if they report a timeout (e.g. net.Error).
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var outer *Error
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		switch v := e.(type) {
+		case *Error:
+			if v.retryable != nil {
+				return *v.retryable
+			}
+			if outer == nil {
+				outer = v
+			}
+		case interface{ Retryable() bool }:
+			return v.Retryable()
+		}
+	}
+	if outer != nil {
+		return outer.Code.Retryable()
+	}
+
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) {
+		return timeout.Timeout()
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// SourceLine is a line of source code captured around a stack frame (see
+// SetSourceContext).
+type SourceLine struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+var sourceContextLines atomic.Int32
+
+// SetSourceContext makes stack capture read n lines of source before and
+// after the top application frame (the first frame outside the standard
+// library) into StackFrame.Source, which ToCMDErrorWithStack renders. Pass 0,
+// the default, to disable it.
+//
+// Reading source files makes error creation slow and needs the sources on
+// disk, so only enable it in development or staging.
+func SetSourceContext(n int) {
+	sourceContextLines.Store(int32(max(n, 0)))
+}
+
+// attachSource adds the source context of the top application frame.
+func attachSource(frames []StackFrame) {
+	n := int(sourceContextLines.Load())
+	if n == 0 {
+		return
+	}
+	for i := range frames {
+		if isStdlibFunction(frames[i].Function) {
+			continue
+		}
+		frames[i].Source = readSource(frames[i].File, frames[i].Line, n)
+		return
+	}
+}
+
+// isStdlibFunction reports whether fn, a fully qualified function name such
+// as "net/http.(*conn).serve", belongs to the standard library: its import
+// path has no dot in the first element, and it is not package main.
+func isStdlibFunction(fn string) bool {
+	if fn == "" || strings.HasPrefix(fn, "main.") {
+		return false
+	}
+	first, _, _ := strings.Cut(fn, "/")
+	if !strings.Contains(fn, "/") {
+		first, _, _ = strings.Cut(fn, ".")
+	}
+	return !strings.Contains(first, ".")
+}
+
+// readSource returns the lines of file within n lines of line, or nil when the
+// file cannot be read.
+func readSource(file string, line, n int) []SourceLine {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []SourceLine
+	scanner := bufio.NewScanner(f)
+	for current := 1; scanner.Scan() && current <= line+n; current++ {
+		if current >= line-n {
+			lines = append(lines, SourceLine{Line: current, Text: scanner.Text()})
+		}
+	}
+	return lines
+}
@@ -0,0 +1,204 @@
+package errors
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultTrackerBuckets is the number of buckets a tracker window is split
+// into; counts expire one bucket at a time.
+const defaultTrackerBuckets = 10
+
+// ErrorTracker counts errors by code over a sliding window, for in-process
+// decisions (e.g. opening a circuit) and burn alerts without a metrics
+// backend. It implements Counter, so it can observe every created error:
+//
+//	tracker := errors.NewErrorTracker(time.Minute)
+//	tracker.OnThreshold(errors.CodeUnavailable, 50, func(code errors.Code, count int) {
+//	    alert("too many %s errors: %d in the last minute", code, count)
+//	})
+//	errors.SetCounter(tracker)
+//
+// or be fed explicitly with Track. It is safe for concurrent use.
+type ErrorTracker struct {
+	mu         sync.Mutex
+	window     time.Duration
+	resolution time.Duration
+	buckets    []trackerBucket
+	thresholds []*threshold
+	now        func() time.Time
+}
+
+type trackerBucket struct {
+	slot   int64
+	counts map[Code]int
+}
+
+type threshold struct {
+	code  Code
+	limit int
+	fn    func(code Code, count int)
+	fired bool
+}
+
+// TrackerSnapshot is the state of an ErrorTracker at a point in time.
+type TrackerSnapshot struct {
+	At     time.Time
+	Window time.Duration
+	// Counts holds the number of errors per code within the window.
+	Counts map[Code]int
+	// Total is the sum of Counts.
+	Total int
+}
+
+// Rate returns the errors per second of code within the window.
+func (s TrackerSnapshot) Rate(code Code) float64 {
+	return float64(s.Counts[code]) / s.Window.Seconds()
+}
+
+type TrackerOption func(*ErrorTracker)
+
+// WithTrackerBuckets sets the number of buckets the window is split into.
+// More buckets make the window slide more smoothly. Default: 10.
+func WithTrackerBuckets(n int) TrackerOption {
+	return func(t *ErrorTracker) {
+		if n > 0 {
+			t.buckets = make([]trackerBucket, n)
+		}
+	}
+}
+
+// NewErrorTracker returns a tracker counting errors over the last window. It
+// panics if window is not positive.
+func NewErrorTracker(window time.Duration, opts ...TrackerOption) *ErrorTracker {
+	if window <= 0 {
+		panic("errors: tracker window must be positive")
+	}
+	t := &ErrorTracker{
+		window:  window,
+		buckets: make([]trackerBucket, defaultTrackerBuckets),
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(t)
+		}
+	}
+	t.resolution = max(window/time.Duration(len(t.buckets)), 1)
+	return t
+}
+
+// Track records err under its code (see GetCode). Nil errors are ignored.
+func (t *ErrorTracker) Track(err error) {
+	if err == nil {
+		return
+	}
+	t.Count(GetCode(err), "")
+}
+
+// Count records an error with code. It implements Counter; the component is
+// ignored.
+func (t *ErrorTracker) Count(code Code, _ string) {
+	t.mu.Lock()
+	slot := t.slot()
+	b := &t.buckets[slot%int64(len(t.buckets))]
+	if b.slot != slot || b.counts == nil {
+		b.slot = slot
+		b.counts = make(map[Code]int)
+	}
+	b.counts[code]++
+
+	var fire []*threshold
+	var counts map[Code]int
+	for _, th := range t.thresholds {
+		if th.code != code && th.code != "" {
+			continue
+		}
+		if counts == nil {
+			counts = t.countsLocked(slot)
+		}
+		n := thresholdCount(th, counts)
+		switch {
+		case n >= th.limit && !th.fired:
+			th.fired = true
+			fire = append(fire, th)
+		case n < th.limit:
+			th.fired = false
+		}
+	}
+	t.mu.Unlock()
+
+	for _, th := range fire {
+		th.fn(code, thresholdCount(th, counts))
+	}
+}
+
+// OnThreshold calls fn once the number of errors with code within the window
+// reaches limit. It fires again only after the count dropped below limit. An
+// empty code matches errors of any code. fn is called synchronously by the
+// goroutine recording the error and must not block.
+func (t *ErrorTracker) OnThreshold(code Code, limit int, fn func(code Code, count int)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.thresholds = append(t.thresholds, &threshold{code: code, limit: limit, fn: fn})
+}
+
+// Rate returns the errors per second of code within the window.
+func (t *ErrorTracker) Rate(code Code) float64 {
+	return t.Snapshot().Rate(code)
+}
+
+// Snapshot returns the current counts within the window.
+func (t *ErrorTracker) Snapshot() TrackerSnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := TrackerSnapshot{
+		At:     t.now(),
+		Window: t.window,
+		Counts: t.countsLocked(t.slot()),
+	}
+	for _, n := range s.Counts {
+		s.Total += n
+	}
+	return s
+}
+
+// Reset clears all counts.
+func (t *ErrorTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.buckets)
+	for _, th := range t.thresholds {
+		th.fired = false
+	}
+}
+
+func (t *ErrorTracker) slot() int64 {
+	return t.now().UnixNano() / int64(t.resolution)
+}
+
+func (t *ErrorTracker) countsLocked(slot int64) map[Code]int {
+	counts := make(map[Code]int)
+	oldest := slot - int64(len(t.buckets))
+	for _, b := range t.buckets {
+		if b.slot <= oldest || b.counts == nil {
+			continue
+		}
+		for code, n := range b.counts {
+			counts[code] += n
+		}
+	}
+	return counts
+}
+
+func thresholdCount(th *threshold, counts map[Code]int) int {
+	if th.code != "" {
+		return counts[th.code]
+	}
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
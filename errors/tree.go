@@ -0,0 +1,101 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorTree is a node of the cause tree of an error (see ToTree).
+type ErrorTree struct {
+	// Code and Message are set for *Error nodes; other errors only have a
+	// Message, without the text of their causes.
+	Code    Code   `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Op is set for *Error nodes with an operation and for WithOp
+	// annotations, which have no message.
+	Op string `json:"op,omitempty"`
+	// Type is the Go type of errors that are not *Error, e.g. "*fs.PathError".
+	Type string `json:"type,omitempty"`
+	// Details holds the details the error added, excluding the ones copied by
+	// Wrap from the wrapped error.
+	Details map[string]any `json:"details,omitempty"`
+	// Children are the wrapped errors: one for Wrap and fmt.Errorf("%w"),
+	// several for errors.Join.
+	Children []ErrorTree `json:"children,omitempty"`
+}
+
+// ToTree returns the full wrap hierarchy of err, which HTTPError flattens, to
+// be marshaled for debugging dashboards and admin endpoints. It returns nil
+// for a nil error. Stack traces are left out; marshal the *Error itself (see
+// MarshalJSON) to keep them.
+func ToTree(err error) *ErrorTree {
+	if err == nil {
+		return nil
+	}
+	tree := toTree(err)
+	return &tree
+}
+
+func toTree(err error) ErrorTree {
+	var node ErrorTree
+	switch e := err.(type) {
+	case *Error:
+		node = ErrorTree{Code: e.Code, Message: e.Message, Op: e.Op}
+		if details := ownDetails(e); len(details) > 0 {
+			node.Details = details
+		}
+	case *opError:
+		node = ErrorTree{Op: e.op}
+	default:
+		node = ErrorTree{Message: ownMessage(err), Type: fmt.Sprintf("%T", err)}
+	}
+	for _, cause := range causes(err) {
+		node.Children = append(node.Children, toTree(cause))
+	}
+	return node
+}
+
+// causes returns the errors wrapped by err.
+func causes(err error) []error {
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		return u.Unwrap()
+	case interface{ Unwrap() error }:
+		if cause := u.Unwrap(); cause != nil {
+			return []error{cause}
+		}
+	}
+	return nil
+}
+
+// ownMessage returns the message of an error that is not an *Error without
+// the text of its cause, e.g. "query failed" for fmt.Errorf("query failed:
+// %w", cause).
+func ownMessage(err error) string {
+	switch c := causes(err); len(c) {
+	case 0:
+		return err.Error()
+	case 1:
+		return strings.TrimSuffix(err.Error(), ": "+c[0].Error())
+	default:
+		// errors.Join and similar: the message is the list of the causes.
+		return fmt.Sprintf("%d errors", len(c))
+	}
+}
+
+// ownDetails returns the details of e that its innermost wrapped *Error does
+// not have with the same value, since Wrap copies the details of the wrapped
+// error.
+func ownDetails(e *Error) map[string]any {
+	var inner *Error
+	if e.Cause == nil || !As(e.Cause, &inner) {
+		return e.Details
+	}
+	details := make(map[string]any, len(e.Details))
+	for k, v := range e.Details {
+		if innerValue, ok := inner.Details[k]; !ok || fmt.Sprint(innerValue) != fmt.Sprint(v) {
+			details[k] = v
+		}
+	}
+	return details
+}
@@ -0,0 +1,143 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// ViolationInvalid is the FieldViolation code used by InvalidField.
+const ViolationInvalid = "invalid"
+
+// fieldViolationsKey is the detail key holding the field violations.
+const fieldViolationsKey = "field_violations"
+
+// FieldViolation describes why a single request field is invalid. Violations
+// are stored in the "field_violations" detail, rendered as a JSON array by the
+// HTTP adapters and as a BadRequest detail by ToGRPCError.
+type FieldViolation struct {
+	// Field is the path of the field, e.g. "email" or "items[2].quantity".
+	Field string `json:"field"`
+	// Code is a machine-readable reason such as "required" or "too_long",
+	// e.g. to pick a localized message.
+	Code string `json:"code,omitempty"`
+	// Description is the human-readable reason.
+	Description string `json:"description"`
+	// Params holds the values the rule was checked against, e.g.
+	// {"max": 255}. They are not carried over gRPC.
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// InvalidField creates a CodeInvalidArgument error with a stack trace and a
+// single violation of field.
+func InvalidField(field, description string) *Error {
+	err := created(&Error{
+		Code:       CodeInvalidArgument,
+		Message:    "invalid " + field + ": " + description,
+		StackTrace: captureStackTrace(1),
+		Details:    make(map[string]any),
+	}, "")
+	return err.WithFieldViolation(FieldViolation{
+		Field:       field,
+		Code:        ViolationInvalid,
+		Description: description,
+	})
+}
+
+// WithFieldViolation appends violations to the "field_violations" detail,
+// which is public (see WithPublicDetail). The detail gets a new slice, so
+// errors derived from the same error or sentinel never share violations.
+func (e *Error) WithFieldViolation(violations ...FieldViolation) *Error {
+	existing := fieldViolationsOf(e.Details[fieldViolationsKey])
+	return e.WithPublicDetail(fieldViolationsKey, slices.Concat(existing, violations))
+}
+
+// Validation collects field violations, e.g. while validating a request, and
+// turns them into a single error.
+//
+//	var v errors.Validation
+//	if req.Email == "" {
+//	    v.Add("email", "required", "is required")
+//	}
+//	if len(req.Name) > 255 {
+//	    v.AddViolation(errors.FieldViolation{Field: "name", Code: "too_long",
+//	        Description: "must be at most 255 characters", Params: map[string]any{"max": 255}})
+//	}
+//	return v.Err()
+type Validation struct {
+	violations []FieldViolation
+}
+
+// Add records a violation of field.
+func (v *Validation) Add(field, code, description string) {
+	v.violations = append(v.violations, FieldViolation{
+		Field:       field,
+		Code:        code,
+		Description: description,
+	})
+}
+
+// AddViolation records a violation.
+func (v *Validation) AddViolation(violation FieldViolation) {
+	v.violations = append(v.violations, violation)
+}
+
+// Violations returns the recorded violations.
+func (v *Validation) Violations() []FieldViolation {
+	return v.violations
+}
+
+// Err returns nil when no violation was recorded, otherwise a
+// CodeInvalidArgument error with a stack trace listing every violation.
+func (v *Validation) Err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+
+	fields := make([]string, len(v.violations))
+	for i, violation := range v.violations {
+		fields[i] = violation.Field
+	}
+	err := created(&Error{
+		Code:       CodeInvalidArgument,
+		Message:    "validation failed: " + strings.Join(fields, ", "),
+		StackTrace: captureStackTrace(1),
+		Details:    make(map[string]any),
+	}, "")
+	return err.WithFieldViolation(v.violations...)
+}
+
+// FieldViolations returns the field violations of the first *Error in the
+// chain that has any. It also decodes violations of errors restored from JSON.
+func FieldViolations(err error) []FieldViolation {
+	for err != nil {
+		if customErr, ok := err.(*Error); ok {
+			if violations := fieldViolationsOf(customErr.Details[fieldViolationsKey]); len(violations) > 0 {
+				return slices.Clone(violations)
+			}
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil
+}
+
+func fieldViolationsOf(value any) []FieldViolation {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []FieldViolation:
+		return v
+	default:
+		// Decoded from JSON as []any of map[string]any.
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var violations []FieldViolation
+		if err := json.Unmarshal(data, &violations); err != nil {
+			return nil
+		}
+		return violations
+	}
+}
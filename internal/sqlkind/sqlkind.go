@@ -0,0 +1,60 @@
+// Package sqlkind classifies SQL statements by their leading keyword, so kdbx
+// and kpgx report and guard statements the same way.
+package sqlkind
+
+import "strings"
+
+// LeadingKeyword returns the first keyword of a statement in upper case,
+// skipping leading whitespace, comments and opening parentheses.
+func LeadingKeyword(query string) string {
+	i := 0
+	for i < len(query) {
+		c := query[i]
+		switch {
+		case c == ' ', c == '\t', c == '\n', c == '\r', c == '\f', c == '\v', c == '(':
+			i++
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return ""
+			}
+			i += end + 4
+		default:
+			start := i
+			for i < len(query) && ((query[i] >= 'a' && query[i] <= 'z') || (query[i] >= 'A' && query[i] <= 'Z')) {
+				i++
+			}
+			return strings.ToUpper(query[start:i])
+		}
+	}
+	return ""
+}
+
+// IsQuery reports whether a statement returns rows, for statements that have
+// no command tag to tell, e.g. because they failed.
+func IsQuery(query string) bool {
+	switch LeadingKeyword(query) {
+	case "SELECT", "WITH", "SHOW", "VALUES", "TABLE", "EXPLAIN", "FETCH":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsWrite reports whether a statement modifies data or schema.
+// It only looks at the leading keyword, so writes wrapped in a CTE
+// (WITH ... INSERT) are not detected; the server-side read-only session
+// setting still rejects those.
+func IsWrite(query string) bool {
+	switch LeadingKeyword(query) {
+	case "INSERT", "UPDATE", "DELETE", "MERGE", "REPLACE", "UPSERT", "TRUNCATE",
+		"CREATE", "ALTER", "DROP", "RENAME", "GRANT", "REVOKE":
+		return true
+	default:
+		return false
+	}
+}
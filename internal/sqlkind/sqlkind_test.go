@@ -0,0 +1,109 @@
+package sqlkind
+
+import "testing"
+
+func TestLeadingKeyword(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"empty", "", ""},
+		{"whitespace only", " \t\r\n", ""},
+		{"simple", "SELECT 1", "SELECT"},
+		{"lowercase", "select 1", "SELECT"},
+		{"mixed case", "InSeRt INTO t VALUES (1)", "INSERT"},
+		{"leading whitespace", "\n\t  UPDATE t SET a = 1", "UPDATE"},
+		{"leading line comment", "-- fetch users\nSELECT * FROM users", "SELECT"},
+		{"leading block comment", "/* audit */ DELETE FROM t", "DELETE"},
+		{"multi-line block comment", "/* a\n * b\n */\ndelete FROM t", "DELETE"},
+		{"several comments", "-- a\n/* b */ -- c\n  with x AS (SELECT 1) SELECT * FROM x", "WITH"},
+		{"parenthesized", "((SELECT 1) UNION (SELECT 2))", "SELECT"},
+		{"keyword ends at punctuation", "SELECT*FROM t", "SELECT"},
+		{"unterminated block comment", "/* SELECT 1", ""},
+		{"comment only", "-- SELECT 1", ""},
+		{"no keyword", "$1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LeadingKeyword(tt.query); got != tt.want {
+				t.Errorf("LeadingKeyword(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{"select", "SELECT 1", true},
+		{"lowercase select", "select * from t", true},
+		{"leading comment", "-- list\nSELECT * FROM t", true},
+		{"parenthesized select", "(SELECT 1)", true},
+		{"cte select", "WITH x AS (SELECT 1) SELECT * FROM x", true},
+		{"cte insert", "WITH x AS (SELECT 1) INSERT INTO t SELECT * FROM x RETURNING id", true},
+		{"show", "show tables", true},
+		{"values", "VALUES (1), (2)", true},
+		{"table", "TABLE users", true},
+		{"explain", "EXPLAIN ANALYZE SELECT 1", true},
+		{"fetch", "FETCH 10 FROM cur", true},
+		{"insert", "INSERT INTO t VALUES (1)", false},
+		{"lowercase update", "update t set a = 1", false},
+		{"delete after comment", "/* cleanup */ DELETE FROM t", false},
+		{"ddl", "CREATE TABLE t (id int)", false},
+		{"set", "SET statement_timeout = 0", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsQuery(tt.query); got != tt.want {
+				t.Errorf("IsQuery(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsWrite(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{"select", "SELECT * FROM t", false},
+		{"lowercase select", "select 1", false},
+		{"show", "SHOW search_path", false},
+		{"explain", "EXPLAIN SELECT 1", false},
+		{"set", "SET statement_timeout = 0", false},
+		{"empty", "", false},
+		{"insert", "INSERT INTO t VALUES (1)", true},
+		{"lowercase insert", "insert into t values (1)", true},
+		{"update", "UPDATE t SET a = 1", true},
+		{"delete after line comment", "-- cleanup\nDELETE FROM t", true},
+		{"truncate after block comment", "/* reset */ truncate t", true},
+		{"merge", "MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN DELETE", true},
+		{"mysql replace", "REPLACE INTO t VALUES (1)", true},
+		{"ddl", "CREATE TABLE t (id int)", true},
+		{"alter", "alter table t add column b int", true},
+		{"drop", "DROP TABLE t", true},
+		{"rename", "RENAME TABLE a TO b", true},
+		{"grant", "GRANT SELECT ON t TO r", true},
+		{"revoke", "REVOKE SELECT ON t FROM r", true},
+		// Only the leading keyword is inspected; the server-side read-only
+		// session setting rejects writes inside CTEs.
+		{"cte insert not detected", "WITH x AS (SELECT 1) INSERT INTO t SELECT * FROM x", false},
+		{"word containing keyword", "SELECT inserted FROM t", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsWrite(tt.query); got != tt.want {
+				t.Errorf("IsWrite(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
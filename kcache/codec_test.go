@@ -0,0 +1,61 @@
+package kcache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type codecValue struct {
+	ID        int64             `json:"id"`
+	Name      string            `json:"name"`
+	Tags      []string          `json:"tags,omitempty"`
+	Attrs     map[string]string `json:"attrs,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	in := codecValue{
+		ID:        42,
+		Name:      "alice",
+		Tags:      []string{"admin"},
+		Attrs:     map[string]string{"team": "core"},
+		CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	for name, codec := range map[string]Codec{"json": JSONCodec, "msgpack": MsgpackCodec} {
+		t.Run(name, func(t *testing.T) {
+			data, err := codec.Marshal(in)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			var out codecValue
+			if err := codec.Unmarshal(data, &out); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			out.CreatedAt = out.CreatedAt.UTC()
+			if !reflect.DeepEqual(in, out) {
+				t.Errorf("Round trip = %+v, want %+v", out, in)
+			}
+		})
+	}
+}
+
+func TestMsgpackCodecUsesJSONTags(t *testing.T) {
+	data, err := MsgpackCodec.Marshal(codecValue{ID: 1})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var fields map[string]any
+	if err := MsgpackCodec.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if _, ok := fields["id"]; !ok {
+		t.Errorf("Expected the json field names, got %v", fields)
+	}
+	if _, ok := fields["tags"]; ok {
+		t.Errorf("Expected omitempty to apply, got %v", fields)
+	}
+}
@@ -0,0 +1,186 @@
+package kcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrorCode represents the type of cache error.
+type ErrorCode string
+
+const (
+	// Configuration errors
+	CodeInvalidArgument ErrorCode = "INVALID_ARGUMENT"
+	CodeInvalidConfig   ErrorCode = "INVALID_CONFIG"
+
+	// Connection errors
+	CodeUnavailable     ErrorCode = "UNAVAILABLE"
+	CodeTimeout         ErrorCode = "TIMEOUT"
+	CodeUnauthenticated ErrorCode = "UNAUTHENTICATED"
+	CodePermission      ErrorCode = "PERMISSION_DENIED"
+
+	// Command errors
+	CodeNotFound          ErrorCode = "NOT_FOUND"
+	CodeInvalidState      ErrorCode = "INVALID_STATE"
+	CodeSerialization     ErrorCode = "SERIALIZATION_ERROR"
+	CodeCache             ErrorCode = "CACHE_ERROR"
+	CodeCancelled         ErrorCode = "CANCELLED"
+	CodeResourceExhausted ErrorCode = "RESOURCE_EXHAUSTED"
+)
+
+// CacheError represents a structured cache error.
+type CacheError struct {
+	Code    ErrorCode
+	Message string
+	Cause   error
+}
+
+// Error implements the error interface.
+func (e *CacheError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying cause error.
+func (e *CacheError) Unwrap() error {
+	return e.Cause
+}
+
+// Is checks if the error matches the target.
+func (e *CacheError) Is(target error) bool {
+	t, ok := target.(*CacheError)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code
+}
+
+// Retryable reports whether the error is transient: Redis is temporarily
+// unavailable (including while loading its dataset) or timed out.
+func (e *CacheError) Retryable() bool {
+	switch e.Code {
+	case CodeUnavailable, CodeTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Predefined errors for cache operations.
+var (
+	// Configuration errors
+	ErrMissingURL         = &CacheError{Code: CodeInvalidArgument, Message: "redis URL is required"}
+	ErrInvalidConfig      = &CacheError{Code: CodeInvalidConfig, Message: "invalid cache configuration"}
+	ErrInvalidPoolConfig  = &CacheError{Code: CodeInvalidConfig, Message: "invalid connection pool configuration"}
+	ErrInvalidRetryConfig = &CacheError{Code: CodeInvalidConfig, Message: "invalid retry configuration"}
+
+	// Connection errors
+	ErrConnectionFailed = &CacheError{Code: CodeUnavailable, Message: "failed to connect to redis"}
+	ErrCacheUnavailable = &CacheError{Code: CodeUnavailable, Message: "cache is unavailable"}
+	ErrClosed           = &CacheError{Code: CodeInvalidState, Message: "cache is closed"}
+
+	// Command errors
+	ErrCacheMiss     = &CacheError{Code: CodeNotFound, Message: "cache miss"}
+	ErrSerialization = &CacheError{Code: CodeSerialization, Message: "failed to encode or decode value"}
+
+	// Context errors
+	ErrContextCancelled = &CacheError{Code: CodeCancelled, Message: "operation cancelled"}
+	ErrContextTimeout   = &CacheError{Code: CodeTimeout, Message: "operation timeout"}
+)
+
+// WrapError wraps a Redis error with context and classification.
+func WrapError(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+
+	// Already classified
+	var cacheErr *CacheError
+	if errors.As(err, &cacheErr) {
+		return &CacheError{Code: cacheErr.Code, Message: msg, Cause: err}
+	}
+
+	return &CacheError{Code: classifyError(err), Message: msg, Cause: err}
+}
+
+// classifyError maps Redis, network and context errors to an ErrorCode.
+func classifyError(err error) ErrorCode {
+	switch {
+	case errors.Is(err, redis.Nil):
+		return CodeNotFound
+	case errors.Is(err, context.Canceled):
+		return CodeCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, redis.ErrClosed):
+		return CodeInvalidState
+	case errors.Is(err, redis.ErrPoolTimeout):
+		return CodeTimeout
+	case errors.Is(err, redis.ErrPoolExhausted):
+		return CodeResourceExhausted
+	case redis.IsAuthError(err):
+		return CodeUnauthenticated
+	case redis.IsPermissionError(err):
+		return CodePermission
+	case redis.IsLoadingError(err), redis.IsTryAgainError(err),
+		redis.IsMasterDownError(err), redis.IsClusterDownError(err),
+		redis.IsMaxClientsError(err):
+		return CodeUnavailable
+	case redis.IsOOMError(err):
+		return CodeResourceExhausted
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return CodeTimeout
+		}
+		return CodeUnavailable
+	}
+
+	// The server closed the connection
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return CodeUnavailable
+	}
+
+	return CodeCache
+}
+
+// IsRetryable determines if an error is safe to retry.
+// It delegates to the errors package (see errors.IsRetryable), so an explicit
+// (*errors.Error).Retryable flag in the chain takes precedence and other error
+// types are classified by (*CacheError).Retryable.
+func IsRetryable(err error) bool {
+	return kerrors.IsRetryable(err)
+}
+
+// IsCacheMiss checks if the error reports a key that does not exist.
+func IsCacheMiss(err error) bool {
+	return errors.Is(err, redis.Nil) || errors.Is(err, ErrCacheMiss)
+}
+
+// IsTimeout checks if the error is a timeout error.
+func IsTimeout(err error) bool {
+	var cacheErr *CacheError
+	if errors.As(err, &cacheErr) {
+		return cacheErr.Code == CodeTimeout
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsUnavailable checks if the error reports that Redis could not be reached.
+func IsUnavailable(err error) bool {
+	var cacheErr *CacheError
+	if errors.As(err, &cacheErr) {
+		return cacheErr.Code == CodeUnavailable
+	}
+	return false
+}
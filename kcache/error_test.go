@@ -0,0 +1,75 @@
+package kcache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+func TestWrapErrorClassification(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		code      ErrorCode
+		retryable bool
+	}{
+		{name: "miss", err: redis.Nil, code: CodeNotFound},
+		{name: "canceled", err: context.Canceled, code: CodeCancelled},
+		{name: "deadline", err: context.DeadlineExceeded, code: CodeTimeout, retryable: true},
+		{name: "closed", err: redis.ErrClosed, code: CodeInvalidState},
+		{name: "pool timeout", err: redis.ErrPoolTimeout, code: CodeTimeout, retryable: true},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, code: CodeUnavailable, retryable: true},
+		{name: "connection closed", err: io.EOF, code: CodeUnavailable, retryable: true},
+		{name: "server error", err: errors.New("ERR wrong number of arguments"), code: CodeCache},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WrapError(tt.err, "redis get failed")
+
+			var cacheErr *CacheError
+			if !errors.As(err, &cacheErr) || cacheErr.Code != tt.code {
+				t.Fatalf("WrapError() = %v, want code %s", err, tt.code)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("Expected the cause to be kept")
+			}
+			if got := IsRetryable(err); got != tt.retryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.retryable)
+			}
+		})
+	}
+
+	if WrapError(nil, "nothing") != nil {
+		t.Error("Expected nil for a nil error")
+	}
+}
+
+func TestWrapErrorKeepsCode(t *testing.T) {
+	err := WrapError(WrapError(io.EOF, "redis set failed"), "maximum retry attempts exceeded")
+	if !IsUnavailable(err) {
+		t.Errorf("Expected the inner code, got %v", err)
+	}
+}
+
+func TestIsRetryableHonorsOverride(t *testing.T) {
+	err := kerrors.Wrap(WrapError(io.EOF, "redis get failed"), kerrors.CodeInternal, "load profile").Retryable(false)
+	if IsRetryable(err) {
+		t.Error("Expected the explicit flag to win")
+	}
+}
+
+func TestIsCacheMiss(t *testing.T) {
+	if !IsCacheMiss(ErrCacheMiss) || !IsCacheMiss(redis.Nil) {
+		t.Error("Expected misses to be detected")
+	}
+	if IsCacheMiss(WrapError(io.EOF, "redis get failed")) {
+		t.Error("Expected connection errors not to be misses")
+	}
+}
@@ -0,0 +1,128 @@
+package kcache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/karu-codes/karu-kits/kdbx"
+)
+
+// HealthStatus and HealthCheck are the kdbx types, so database and cache
+// checks are reported (and served over HTTP) the same way.
+type (
+	HealthStatus = kdbx.HealthStatus
+	HealthCheck  = kdbx.HealthCheck
+)
+
+const (
+	// HealthStatusHealthy indicates Redis is healthy and ready.
+	HealthStatusHealthy = kdbx.HealthStatusHealthy
+
+	// HealthStatusDegraded indicates Redis is accessible but may have issues.
+	HealthStatusDegraded = kdbx.HealthStatusDegraded
+
+	// HealthStatusUnhealthy indicates Redis is not accessible.
+	HealthStatusUnhealthy = kdbx.HealthStatusUnhealthy
+)
+
+// HealthChecker provides health check functionality for a cache.
+type HealthChecker struct {
+	cache *Cache
+
+	// Cache settings
+	cacheDuration time.Duration
+
+	// Mutex protects cache fields from concurrent access
+	mu            sync.RWMutex
+	lastCheck     *HealthCheck
+	lastCheckTime time.Time
+}
+
+// NewHealthChecker creates a new health checker for a cache.
+func NewHealthChecker(c *Cache) *HealthChecker {
+	return &HealthChecker{
+		cache:         c,
+		cacheDuration: 1 * time.Second, // Cache for 1 second to prevent DoS
+	}
+}
+
+// WithCacheDuration sets the cache duration for health checks.
+func (h *HealthChecker) WithCacheDuration(d time.Duration) *HealthChecker {
+	h.cacheDuration = d
+	return h
+}
+
+// Check performs a basic health check (liveness).
+// Results are cached for the configured cache duration.
+func (h *HealthChecker) Check(ctx context.Context) *HealthCheck {
+	// Check cache with read lock first
+	h.mu.RLock()
+	if h.lastCheck != nil && time.Since(h.lastCheckTime) < h.cacheDuration {
+		cachedCheck := h.lastCheck
+		h.mu.RUnlock()
+		return cachedCheck
+	}
+	h.mu.RUnlock()
+
+	start := time.Now()
+	err := h.cache.Health(ctx)
+	check := h.newCheck(start, err, "cache health check failed", "cache is healthy")
+
+	// Cache the result with write lock
+	h.mu.Lock()
+	h.lastCheck = check
+	h.lastCheckTime = start
+	h.mu.Unlock()
+
+	return check
+}
+
+// CheckDetailed performs a detailed health check (readiness).
+// This includes a write and read of a test key and is not cached.
+func (h *HealthChecker) CheckDetailed(ctx context.Context) *HealthCheck {
+	start := time.Now()
+	err := h.cache.HealthDetailed(ctx)
+	check := h.newCheck(start, err, "cache readiness check failed", "cache is ready")
+
+	// Degrade when more than 80% of the pool is in use
+	stats := h.cache.Stats()
+	if check.Status == HealthStatusHealthy && h.cache.config.PoolSize > 0 {
+		busy := int(stats.TotalConns) - int(stats.IdleConns)
+		if float64(busy) > 0.8*float64(h.cache.config.PoolSize) {
+			check.Status = HealthStatusDegraded
+			check.Message = "cache is accessible but connection pool may be stressed"
+		}
+	}
+
+	return check
+}
+
+func (h *HealthChecker) newCheck(start time.Time, err error, failure, success string) *HealthCheck {
+	check := &HealthCheck{
+		Timestamp: start,
+		Duration:  time.Since(start),
+		Details:   make(map[string]interface{}),
+	}
+
+	if err != nil {
+		check.Status = HealthStatusUnhealthy
+		check.Message = fmt.Sprintf("%s: %v", failure, err)
+	} else {
+		check.Status = HealthStatusHealthy
+		check.Message = success
+	}
+
+	// Add pool statistics
+	stats := h.cache.Stats()
+	check.Details["pool"] = map[string]interface{}{
+		"total_conns": stats.TotalConns,
+		"idle_conns":  stats.IdleConns,
+		"stale_conns": stats.StaleConns,
+		"timeouts":    stats.Timeouts,
+		"pool_size":   h.cache.config.PoolSize,
+	}
+
+	return check
+}
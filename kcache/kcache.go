@@ -0,0 +1,477 @@
+// Package kcache provides a Redis cache client with connection pooling,
+// typed Get/Set through pluggable codecs, retries for transient failures,
+// health checks, and observability.
+package kcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// KeepTTL can be passed as the ttl of Set to keep the expiration of an
+// existing key (Redis 6.0+).
+const KeepTTL = redis.KeepTTL
+
+// healthCheckKey is written and read back by HealthDetailed, under KeyPrefix.
+const healthCheckKey = "kcache:health"
+
+// PoolStats represents connection pool statistics.
+type PoolStats struct {
+	// Hits is the number of times a free connection was found in the pool.
+	Hits uint32
+	// Misses is the number of times a free connection was not found in the pool.
+	Misses uint32
+	// Timeouts is the number of times waiting for a connection timed out.
+	Timeouts uint32
+	// TotalConns is the number of connections in the pool.
+	TotalConns uint32
+	// IdleConns is the number of idle connections in the pool.
+	IdleConns uint32
+	// StaleConns is the number of stale connections removed from the pool.
+	StaleConns uint32
+}
+
+// Cache is a Redis cache. It is safe for concurrent use.
+type Cache struct {
+	client *redis.Client
+
+	config  *Config
+	codec   Codec
+	logger  *slog.Logger
+	metrics MetricsCollector
+
+	// Health check management; stopHealth cancels the checks and waits for them.
+	stopHealth   func()
+	healthMu     sync.RWMutex
+	lastHealth   error
+	lastHealthAt time.Time
+
+	// Pool stats reporting; stopStats cancels the reporter and waits for it.
+	stopStats func()
+
+	closeOnce sync.Once
+}
+
+// New connects to Redis and verifies the connection with a PING.
+func New(ctx context.Context, config *Config) (*Cache, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	options, err := redisOptions(config)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(options)
+
+	// Verify connection
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, WrapError(err, "failed to ping redis")
+	}
+
+	codec := config.Codec
+	if codec == nil {
+		codec = JSONCodec
+	}
+
+	c := &Cache{
+		client:  client,
+		config:  config,
+		codec:   codec,
+		logger:  config.Logger,
+		metrics: config.Metrics,
+	}
+
+	// Start background health checks if configured
+	if config.HealthCheckInterval > 0 {
+		c.startHealthChecks()
+	}
+
+	// Start background pool stats reporting if configured
+	if config.PoolStatsInterval > 0 && config.Metrics != nil {
+		c.startPoolStatsReporter()
+	}
+
+	if c.logger != nil {
+		c.logger.Info("Redis connection established",
+			slog.String("url", config.MaskedURL()),
+			slog.Int("db", options.DB),
+			slog.Int("pool_size", options.PoolSize),
+		)
+	}
+
+	return c, nil
+}
+
+// redisOptions parses the URL of config and applies the pool and timeout
+// settings that are set.
+func redisOptions(config *Config) (*redis.Options, error) {
+	options, err := redis.ParseURL(config.URL)
+	if err != nil {
+		return nil, &CacheError{Code: CodeInvalidArgument, Message: "failed to parse redis URL", Cause: err}
+	}
+
+	if config.PoolSize > 0 {
+		options.PoolSize = config.PoolSize
+	}
+	if config.MinIdleConns > 0 {
+		options.MinIdleConns = config.MinIdleConns
+	}
+	if config.ConnMaxLifetime > 0 {
+		options.ConnMaxLifetime = config.ConnMaxLifetime
+	}
+	if config.ConnMaxIdleTime > 0 {
+		options.ConnMaxIdleTime = config.ConnMaxIdleTime
+	}
+	if config.DialTimeout > 0 {
+		options.DialTimeout = config.DialTimeout
+	}
+	if config.ReadTimeout > 0 {
+		options.ReadTimeout = config.ReadTimeout
+	}
+	if config.WriteTimeout > 0 {
+		options.WriteTimeout = config.WriteTimeout
+	}
+
+	// Retries are done by withRetry, with backoff and logging
+	options.MaxRetries = -1
+
+	return options, nil
+}
+
+// Get reads the value of key and decodes it into dest with the codec.
+// Returns ErrCacheMiss (see IsCacheMiss) if the key does not exist.
+func (c *Cache) Get(ctx context.Context, key string, dest any) error {
+	data, err := c.GetBytes(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := c.codec.Unmarshal(data, dest); err != nil {
+		return &CacheError{
+			Code:    CodeSerialization,
+			Message: fmt.Sprintf("failed to decode value of key %q", key),
+			Cause:   err,
+		}
+	}
+
+	return nil
+}
+
+// GetBytes reads the raw value of key.
+// Returns ErrCacheMiss (see IsCacheMiss) if the key does not exist.
+func (c *Cache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	var data []byte
+	err := c.do(ctx, "get", func(ctx context.Context) error {
+		var err error
+		data, err = c.client.Get(ctx, c.key(key)).Bytes()
+		return err
+	})
+
+	if errors.Is(err, redis.Nil) {
+		c.recordLookup(ctx, false)
+		return nil, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.recordLookup(ctx, true)
+	return data, nil
+}
+
+// Set encodes value with the codec and stores it under key.
+// A ttl of 0 uses Config.DefaultTTL; pass KeepTTL to keep the expiration of
+// an existing key.
+func (c *Cache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return &CacheError{
+			Code:    CodeSerialization,
+			Message: fmt.Sprintf("failed to encode value of key %q", key),
+			Cause:   err,
+		}
+	}
+
+	return c.SetBytes(ctx, key, data, ttl)
+}
+
+// SetBytes stores the raw value under key. The ttl is handled as by Set.
+func (c *Cache) SetBytes(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return c.do(ctx, "set", func(ctx context.Context) error {
+		return c.client.Set(ctx, c.key(key), data, c.expiration(ttl)).Err()
+	})
+}
+
+// Delete removes the keys and returns how many existed.
+func (c *Cache) Delete(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.key(key)
+	}
+
+	var deleted int64
+	err := c.do(ctx, "del", func(ctx context.Context) error {
+		var err error
+		deleted, err = c.client.Del(ctx, prefixed...).Result()
+		return err
+	})
+	return deleted, err
+}
+
+// Exists reports whether key exists.
+func (c *Cache) Exists(ctx context.Context, key string) (bool, error) {
+	var n int64
+	err := c.do(ctx, "exists", func(ctx context.Context) error {
+		var err error
+		n, err = c.client.Exists(ctx, c.key(key)).Result()
+		return err
+	})
+	return n > 0, err
+}
+
+// key returns key with the configured prefix.
+func (c *Cache) key(key string) string {
+	return c.config.KeyPrefix + key
+}
+
+// expiration resolves the ttl passed to Set.
+func (c *Cache) expiration(ttl time.Duration) time.Duration {
+	if ttl == 0 {
+		return c.config.DefaultTTL
+	}
+	return ttl
+}
+
+// do runs a Redis command with retries and reports it to the metrics
+// collector. Errors other than redis.Nil are returned as *CacheError.
+func (c *Cache) do(ctx context.Context, command string, fn func(context.Context) error) error {
+	start := time.Now()
+
+	err := withRetry(ctx, c.config, command, func(ctx context.Context) error {
+		err := fn(ctx)
+		if err == nil || errors.Is(err, redis.Nil) {
+			return err
+		}
+		return WrapError(err, fmt.Sprintf("redis %s failed", command))
+	})
+
+	if c.metrics != nil {
+		recorded := err
+		if errors.Is(err, redis.Nil) {
+			recorded = nil
+		}
+		c.metrics.RecordCommand(ctx, command, time.Since(start), recorded)
+	}
+
+	return err
+}
+
+func (c *Cache) recordLookup(ctx context.Context, hit bool) {
+	if c.metrics != nil {
+		c.metrics.RecordLookup(ctx, hit)
+	}
+}
+
+// Health checks if Redis is reachable (liveness check).
+func (c *Cache) Health(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		return WrapError(err, "cache health check failed")
+	}
+
+	return nil
+}
+
+// HealthDetailed performs a more thorough health check (readiness check):
+// besides the ping it writes a short-lived key and reads it back, so it also
+// fails when Redis rejects writes (read-only replica, out of memory).
+func (c *Cache) HealthDetailed(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	// First, do a basic ping
+	if err := c.Health(ctx); err != nil {
+		return err
+	}
+
+	// Then round-trip a value
+	key := c.key(healthCheckKey)
+	want := time.Now().UTC().Format(time.RFC3339Nano)
+	if err := c.client.Set(ctx, key, want, 10*time.Second).Err(); err != nil {
+		return WrapError(err, "cache readiness check failed")
+	}
+
+	got, err := c.client.Get(ctx, key).Result()
+	if err != nil {
+		return WrapError(err, "cache readiness check failed")
+	}
+
+	if got != want {
+		return &CacheError{
+			Code:    CodeInvalidState,
+			Message: fmt.Sprintf("unexpected value from readiness check: %q", got),
+		}
+	}
+
+	return nil
+}
+
+// Stats returns connection pool statistics.
+func (c *Cache) Stats() PoolStats {
+	stats := c.client.PoolStats()
+	return PoolStats{
+		Hits:       stats.Hits,
+		Misses:     stats.Misses,
+		Timeouts:   stats.Timeouts,
+		TotalConns: stats.TotalConns,
+		IdleConns:  stats.IdleConns,
+		StaleConns: stats.StaleConns,
+	}
+}
+
+// Client returns the underlying Redis client for commands kcache does not
+// wrap. Keys used through it do not get the KeyPrefix.
+func (c *Cache) Client() *redis.Client {
+	return c.client
+}
+
+// Close stops the background goroutines, waiting for them to return, and
+// closes the connection pool.
+func (c *Cache) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		// Stop health checks
+		if c.stopHealth != nil {
+			c.stopHealth()
+		}
+
+		// Stop pool stats reporting
+		if c.stopStats != nil {
+			c.stopStats()
+		}
+
+		err = c.client.Close()
+
+		if c.logger != nil {
+			c.logger.Info("Redis connection closed")
+		}
+	})
+	return err
+}
+
+// Shutdown gracefully closes the cache with a timeout.
+func (c *Cache) Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- c.Close()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LastHealthCheck returns the result of the last background health check.
+func (c *Cache) LastHealthCheck() (err error, at time.Time) {
+	c.healthMu.RLock()
+	defer c.healthMu.RUnlock()
+	return c.lastHealth, c.lastHealthAt
+}
+
+// startHealthChecks starts background health checks.
+func (c *Cache) startHealthChecks() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	c.stopHealth = func() {
+		cancel()
+		<-done
+	}
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(c.config.HealthCheckInterval)
+		defer ticker.Stop()
+		defer func() {
+			if r := recover(); r != nil {
+				if c.logger != nil {
+					c.logger.Error("health check goroutine panic recovered",
+						slog.Any("panic", r))
+				}
+			}
+		}()
+
+		for {
+			select {
+			case <-ticker.C:
+				err := c.Health(ctx)
+
+				c.healthMu.Lock()
+				c.lastHealth = err
+				c.lastHealthAt = time.Now()
+				c.healthMu.Unlock()
+
+				if err != nil && c.logger != nil {
+					c.logger.Error("cache health check failed", slog.Any("error", err))
+				}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// startPoolStatsReporter periodically reports Stats() to the metrics collector.
+func (c *Cache) startPoolStatsReporter() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	c.stopStats = func() {
+		cancel()
+		<-done
+	}
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(c.config.PoolStatsInterval)
+		defer ticker.Stop()
+		defer func() {
+			if r := recover(); r != nil {
+				if c.logger != nil {
+					c.logger.Error("pool stats goroutine panic recovered",
+						slog.Any("panic", r))
+				}
+			}
+		}()
+
+		c.metrics.RecordPoolStats(c.Stats())
+
+		for {
+			select {
+			case <-ticker.C:
+				c.metrics.RecordPoolStats(c.Stats())
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
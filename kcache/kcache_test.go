@@ -0,0 +1,344 @@
+package kcache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestCache(t *testing.T, opts ...Option) (*Cache, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	cfg := DefaultConfig("redis://" + server.Addr() + "/0")
+	cfg.HealthCheckInterval = 0
+	cfg.ApplyOptions(opts...)
+
+	c, err := New(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c, server
+}
+
+func TestNewFailsWhenUnreachable(t *testing.T) {
+	cfg := DefaultConfig("redis://127.0.0.1:1/0")
+	cfg.DialTimeout = 100 * time.Millisecond
+
+	_, err := New(context.Background(), cfg)
+	if !IsUnavailable(err) {
+		t.Fatalf("Expected an unavailable error, got %v", err)
+	}
+}
+
+func TestCacheGetSet(t *testing.T) {
+	metrics := NewInMemoryMetricsCollector()
+	c, server := newTestCache(t, WithKeyPrefix("svc:"), WithMetrics(metrics))
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "user:1", map[string]string{"name": "alice"}, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, _ := server.Get("svc:user:1"); got != `{"name":"alice"}` {
+		t.Errorf("Expected the prefixed JSON value, got %q", got)
+	}
+	if ttl := server.TTL("svc:user:1"); ttl != time.Minute {
+		t.Errorf("Expected a one minute TTL, got %v", ttl)
+	}
+
+	var got map[string]string
+	if err := c.Get(ctx, "user:1", &got); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got["name"] != "alice" {
+		t.Errorf("Get() = %v", got)
+	}
+
+	err := c.Get(ctx, "user:2", &got)
+	if !errors.Is(err, ErrCacheMiss) || !IsCacheMiss(err) {
+		t.Fatalf("Expected a cache miss, got %v", err)
+	}
+
+	m := metrics.Metrics()
+	if m.CommandCount != 3 || m.CommandErrorCount != 0 {
+		t.Errorf("Expected 3 successful commands, got %+v", m)
+	}
+	if m.HitCount != 1 || m.MissCount != 1 || m.HitRatio != 0.5 {
+		t.Errorf("Expected one hit and one miss, got %+v", m)
+	}
+}
+
+func TestCacheDefaultTTL(t *testing.T) {
+	c, server := newTestCache(t, WithDefaultTTL(time.Hour))
+	ctx := context.Background()
+
+	if err := c.SetBytes(ctx, "a", []byte("1"), 0); err != nil {
+		t.Fatalf("SetBytes() error = %v", err)
+	}
+	if ttl := server.TTL("a"); ttl != time.Hour {
+		t.Errorf("Expected the default TTL, got %v", ttl)
+	}
+
+	if err := c.SetBytes(ctx, "a", []byte("2"), KeepTTL); err != nil {
+		t.Fatalf("SetBytes() error = %v", err)
+	}
+	if ttl := server.TTL("a"); ttl != time.Hour {
+		t.Errorf("Expected KeepTTL to keep the expiration, got %v", ttl)
+	}
+}
+
+func TestCacheDeleteExists(t *testing.T) {
+	c, server := newTestCache(t, WithKeyPrefix("p:"))
+	ctx := context.Background()
+	_ = server.Set("p:a", "1")
+	_ = server.Set("p:b", "2")
+
+	if ok, err := c.Exists(ctx, "a"); err != nil || !ok {
+		t.Fatalf("Exists() = %v, %v", ok, err)
+	}
+
+	n, err := c.Delete(ctx, "a", "b", "c")
+	if err != nil || n != 2 {
+		t.Fatalf("Delete() = %d, %v", n, err)
+	}
+
+	if ok, err := c.Exists(ctx, "a"); err != nil || ok {
+		t.Fatalf("Exists() after Delete = %v, %v", ok, err)
+	}
+}
+
+func TestCacheDecodeError(t *testing.T) {
+	c, server := newTestCache(t)
+	_ = server.Set("bad", "not json")
+
+	var v map[string]any
+	err := c.Get(context.Background(), "bad", &v)
+
+	var cacheErr *CacheError
+	if !errors.As(err, &cacheErr) || cacheErr.Code != CodeSerialization {
+		t.Fatalf("Expected a serialization error, got %v", err)
+	}
+}
+
+func TestCacheHealth(t *testing.T) {
+	c, server := newTestCache(t)
+	ctx := context.Background()
+
+	if err := c.HealthDetailed(ctx); err != nil {
+		t.Fatalf("HealthDetailed() error = %v", err)
+	}
+
+	checker := NewHealthChecker(c).WithCacheDuration(0)
+	if check := checker.CheckDetailed(ctx); !check.IsHealthy() {
+		t.Errorf("Expected a healthy check, got %s", check)
+	}
+
+	server.SetError("LOADING Redis is loading the dataset in memory")
+	err := c.Health(ctx)
+	if !IsUnavailable(err) {
+		t.Errorf("Expected an unavailable error, got %v", err)
+	}
+	check := checker.Check(ctx)
+	if !check.IsUnhealthy() || check.HTTPStatusCode() != 503 {
+		t.Errorf("Expected an unhealthy check, got %s", check)
+	}
+}
+
+func TestCacheCloseAndShutdown(t *testing.T) {
+	c, _ := newTestCache(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Expected Close to be idempotent, got %v", err)
+	}
+
+	err := c.SetBytes(context.Background(), "a", []byte("1"), 0)
+	if !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected a closed error, got %v", err)
+	}
+}
+
+// blockingStatsCollector blocks in RecordPoolStats until release is closed.
+type blockingStatsCollector struct {
+	NoOpMetricsCollector
+	called  chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingStatsCollector) RecordPoolStats(PoolStats) {
+	select {
+	case c.called <- struct{}{}:
+	default:
+	}
+	<-c.release
+}
+
+func TestCacheCloseWaitsForBackgroundGoroutines(t *testing.T) {
+	metrics := &blockingStatsCollector{called: make(chan struct{}, 1), release: make(chan struct{})}
+	c, _ := newTestCache(t,
+		WithMetrics(metrics),
+		WithPoolStatsInterval(time.Hour),
+		WithHealthCheckInterval(time.Millisecond),
+	)
+	<-metrics.called
+
+	// Shutdown gives up on its context while Close is still waiting.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected Shutdown to time out while the reporter runs, got %v", err)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		_ = c.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("Expected Close to wait for the pool stats reporter")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(metrics.release)
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close to return once the goroutines stopped")
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	cfg := DefaultConfig("redis://localhost")
+	cfg.RetryInitialBackoff = time.Millisecond
+	cfg.RetryMaxBackoff = time.Millisecond
+
+	calls := 0
+	err := withRetry(context.Background(), cfg, "get", func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return WrapError(io.EOF, "redis get failed")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("withRetry() = %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = withRetry(context.Background(), cfg, "get", func(context.Context) error {
+		calls++
+		return WrapError(io.EOF, "redis get failed")
+	})
+	if !IsUnavailable(err) || calls != cfg.RetryAttempts+1 {
+		t.Fatalf("withRetry() = %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	permanent := WrapError(errors.New("WRONGTYPE"), "redis get failed")
+	err = withRetry(context.Background(), cfg, "get", func(context.Context) error {
+		calls++
+		return permanent
+	})
+	if err != permanent || calls != 1 {
+		t.Fatalf("Expected no retries for permanent errors, got %v after %d calls", err, calls)
+	}
+}
+
+func TestWithRetryCancelled(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       func() (context.Context, context.CancelFunc)
+		cancelIn  bool
+		wantCalls int
+		wantCode  ErrorCode
+		wantCause error
+		lastErr   bool
+	}{
+		{
+			name: "cancelled before the first attempt",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			wantCalls: 0,
+			wantCode:  CodeCancelled,
+			wantCause: context.Canceled,
+		},
+		{
+			name: "cancelled during backoff",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			cancelIn:  true,
+			wantCalls: 1,
+			wantCode:  CodeCancelled,
+			wantCause: context.Canceled,
+			lastErr:   true,
+		},
+		{
+			name: "deadline during backoff",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 20*time.Millisecond)
+			},
+			wantCalls: 1,
+			wantCode:  CodeTimeout,
+			wantCause: context.DeadlineExceeded,
+			lastErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig("redis://localhost")
+			cfg.RetryInitialBackoff = time.Hour
+			cfg.RetryMaxBackoff = time.Hour
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			calls := 0
+			err := withRetry(ctx, cfg, "get", func(context.Context) error {
+				calls++
+				if tt.cancelIn {
+					cancel()
+				}
+				return WrapError(io.EOF, "redis get failed")
+			})
+
+			if calls != tt.wantCalls {
+				t.Errorf("Expected %d calls, got %d", tt.wantCalls, calls)
+			}
+			var cacheErr *CacheError
+			if !errors.As(err, &cacheErr) || cacheErr.Code != tt.wantCode {
+				t.Fatalf("Expected a %s error, got %v", tt.wantCode, err)
+			}
+			if !errors.Is(err, tt.wantCause) {
+				t.Errorf("Expected the context error in %v", err)
+			}
+			if got := errors.Is(err, io.EOF); got != tt.lastErr {
+				t.Errorf("errors.Is(%v, io.EOF) = %v, want %v", err, got, tt.lastErr)
+			}
+		})
+	}
+}
+
+func TestCalculateBackoff(t *testing.T) {
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second} {
+		got := calculateBackoff(100*time.Millisecond, attempt, time.Second)
+		if got < want*9/10 || got > want*11/10 {
+			t.Errorf("calculateBackoff(attempt %d) = %v, want %v ±10%%", attempt, got, want)
+		}
+	}
+}
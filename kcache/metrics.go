@@ -0,0 +1,287 @@
+package kcache
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MetricsCollector is an interface for collecting cache metrics.
+type MetricsCollector interface {
+	// RecordCommand records metrics for a Redis command (e.g. "get", "set",
+	// "del"), including its retries. Cache misses are not errors.
+	RecordCommand(ctx context.Context, command string, duration time.Duration, err error)
+
+	// RecordLookup records whether a Get found its key.
+	RecordLookup(ctx context.Context, hit bool)
+
+	// RecordPoolStats records connection pool statistics.
+	RecordPoolStats(stats PoolStats)
+}
+
+// NoOpMetricsCollector is a metrics collector that does nothing.
+// Use this when metrics collection is not needed.
+type NoOpMetricsCollector struct{}
+
+func (n *NoOpMetricsCollector) RecordCommand(ctx context.Context, command string, duration time.Duration, err error) {
+}
+
+func (n *NoOpMetricsCollector) RecordLookup(ctx context.Context, hit bool) {
+}
+
+func (n *NoOpMetricsCollector) RecordPoolStats(stats PoolStats) {
+}
+
+// LoggingMetricsCollector is a metrics collector that logs metrics using slog.
+type LoggingMetricsCollector struct {
+	logger *slog.Logger
+}
+
+// NewLoggingMetricsCollector creates a new logging metrics collector.
+func NewLoggingMetricsCollector(logger *slog.Logger) *LoggingMetricsCollector {
+	return &LoggingMetricsCollector{
+		logger: logger,
+	}
+}
+
+func (l *LoggingMetricsCollector) RecordCommand(ctx context.Context, command string, duration time.Duration, err error) {
+	if err != nil {
+		l.logger.ErrorContext(ctx, "cache command failed",
+			slog.String("command", command),
+			slog.Duration("duration", duration),
+			slog.Any("error", err),
+		)
+	} else {
+		l.logger.DebugContext(ctx, "cache command completed",
+			slog.String("command", command),
+			slog.Duration("duration", duration),
+		)
+	}
+}
+
+func (l *LoggingMetricsCollector) RecordLookup(ctx context.Context, hit bool) {
+	l.logger.DebugContext(ctx, "cache lookup",
+		slog.Bool("hit", hit),
+	)
+}
+
+func (l *LoggingMetricsCollector) RecordPoolStats(stats PoolStats) {
+	l.logger.Debug("pool stats",
+		slog.Int("total_conns", int(stats.TotalConns)),
+		slog.Int("idle_conns", int(stats.IdleConns)),
+		slog.Int("stale_conns", int(stats.StaleConns)),
+		slog.Int("timeouts", int(stats.Timeouts)),
+	)
+}
+
+// InMemoryMetricsCollector collects metrics in memory for monitoring and debugging.
+// This is useful for development and testing but should not be used in production
+// for high-traffic applications due to memory usage.
+type InMemoryMetricsCollector struct {
+	mu sync.RWMutex
+
+	// Command metrics
+	commandCount      int64
+	commandErrorCount int64
+	commandDurations  []time.Duration
+
+	// Lookup metrics
+	hitCount  int64
+	missCount int64
+
+	// Pool stats
+	lastPoolStats PoolStats
+	poolStatsTime time.Time
+
+	// Memory limit: maximum number of duration samples to keep
+	maxDurationSamples int
+}
+
+// NewInMemoryMetricsCollector creates a new in-memory metrics collector that
+// keeps the last 10000 command durations.
+func NewInMemoryMetricsCollector() *InMemoryMetricsCollector {
+	return &InMemoryMetricsCollector{
+		commandDurations:   make([]time.Duration, 0),
+		maxDurationSamples: 10000,
+	}
+}
+
+// WithMaxDurationSamples sets the maximum number of duration samples to keep.
+func (m *InMemoryMetricsCollector) WithMaxDurationSamples(max int) *InMemoryMetricsCollector {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxDurationSamples = max
+	return m
+}
+
+func (m *InMemoryMetricsCollector) RecordCommand(ctx context.Context, command string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.commandCount++
+	m.commandDurations = append(m.commandDurations, duration)
+
+	// Enforce memory limit for duration samples (keep most recent)
+	if len(m.commandDurations) > m.maxDurationSamples {
+		// Remove oldest 10% when limit is reached
+		removeCount := max(m.maxDurationSamples/10, 1)
+		m.commandDurations = m.commandDurations[removeCount:]
+	}
+
+	if err != nil {
+		m.commandErrorCount++
+	}
+}
+
+func (m *InMemoryMetricsCollector) RecordLookup(ctx context.Context, hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if hit {
+		m.hitCount++
+	} else {
+		m.missCount++
+	}
+}
+
+func (m *InMemoryMetricsCollector) RecordPoolStats(stats PoolStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastPoolStats = stats
+	m.poolStatsTime = time.Now()
+}
+
+// Metrics returns a snapshot of collected metrics.
+func (m *InMemoryMetricsCollector) Metrics() *Metrics {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var hitRatio float64
+	if lookups := m.hitCount + m.missCount; lookups > 0 {
+		hitRatio = float64(m.hitCount) / float64(lookups)
+	}
+
+	return &Metrics{
+		CommandCount:       m.commandCount,
+		CommandErrorCount:  m.commandErrorCount,
+		CommandAvgDuration: calculateAverage(m.commandDurations),
+		CommandP50Duration: calculatePercentile(m.commandDurations, 0.50),
+		CommandP95Duration: calculatePercentile(m.commandDurations, 0.95),
+		CommandP99Duration: calculatePercentile(m.commandDurations, 0.99),
+		HitCount:           m.hitCount,
+		MissCount:          m.missCount,
+		HitRatio:           hitRatio,
+		PoolStats:          m.lastPoolStats,
+		PoolStatsTimestamp: m.poolStatsTime,
+	}
+}
+
+// Reset resets all metrics.
+func (m *InMemoryMetricsCollector) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.commandCount = 0
+	m.commandErrorCount = 0
+	m.commandDurations = m.commandDurations[:0]
+	m.hitCount = 0
+	m.missCount = 0
+}
+
+// Metrics represents a snapshot of cache metrics.
+type Metrics struct {
+	// Command metrics
+	CommandCount       int64
+	CommandErrorCount  int64
+	CommandAvgDuration time.Duration
+	CommandP50Duration time.Duration
+	CommandP95Duration time.Duration
+	CommandP99Duration time.Duration
+
+	// Lookup metrics
+	HitCount  int64
+	MissCount int64
+	HitRatio  float64
+
+	// Pool stats
+	PoolStats          PoolStats
+	PoolStatsTimestamp time.Time
+}
+
+// calculateAverage calculates the average duration.
+func calculateAverage(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+
+	return total / time.Duration(len(durations))
+}
+
+// calculatePercentile calculates the percentile duration using the nearest-rank method.
+func calculatePercentile(durations []time.Duration, percentile float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	// Create a sorted copy to avoid modifying the original slice
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+
+	index := int(float64(len(sorted)) * percentile)
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+
+	return sorted[index]
+}
+
+// CompositeMetricsCollector combines multiple metrics collectors.
+type CompositeMetricsCollector struct {
+	collectors []MetricsCollector
+}
+
+// NewCompositeMetricsCollector creates a new composite metrics collector.
+func NewCompositeMetricsCollector(collectors ...MetricsCollector) *CompositeMetricsCollector {
+	return &CompositeMetricsCollector{
+		collectors: collectors,
+	}
+}
+
+func (c *CompositeMetricsCollector) RecordCommand(ctx context.Context, command string, duration time.Duration, err error) {
+	for _, collector := range c.collectors {
+		collector.RecordCommand(ctx, command, duration, err)
+	}
+}
+
+func (c *CompositeMetricsCollector) RecordLookup(ctx context.Context, hit bool) {
+	for _, collector := range c.collectors {
+		collector.RecordLookup(ctx, hit)
+	}
+}
+
+func (c *CompositeMetricsCollector) RecordPoolStats(stats PoolStats) {
+	for _, collector := range c.collectors {
+		collector.RecordPoolStats(stats)
+	}
+}
+
+// Add adds a metrics collector to the composite.
+func (c *CompositeMetricsCollector) Add(collector MetricsCollector) {
+	c.collectors = append(c.collectors, collector)
+}
+
+// Ensure the collectors implement MetricsCollector at compile time.
+var (
+	_ MetricsCollector = (*NoOpMetricsCollector)(nil)
+	_ MetricsCollector = (*LoggingMetricsCollector)(nil)
+	_ MetricsCollector = (*InMemoryMetricsCollector)(nil)
+	_ MetricsCollector = (*CompositeMetricsCollector)(nil)
+)
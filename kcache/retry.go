@@ -0,0 +1,94 @@
+package kcache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// withRetry executes a command with exponential backoff retry logic.
+// It only retries on transient errors (timeouts, unavailable).
+func withRetry(ctx context.Context, config *Config, command string, fn func(context.Context) error) error {
+	if config.RetryAttempts <= 0 {
+		// No retries configured, execute once
+		return fn(ctx)
+	}
+
+	var lastErr error
+
+	for attempt := 0; attempt <= config.RetryAttempts; attempt++ {
+		// Check if context is already cancelled
+		select {
+		case <-ctx.Done():
+			return retryCancelled(ctx, "context cancelled before retry", lastErr)
+		default:
+		}
+
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+
+		// Check if error is retryable
+		if !IsRetryable(err) {
+			return err
+		}
+
+		// Don't sleep after last attempt
+		if attempt >= config.RetryAttempts {
+			break
+		}
+
+		sleepDuration := calculateBackoff(config.RetryInitialBackoff, attempt, config.RetryMaxBackoff)
+
+		if config.Logger != nil {
+			config.Logger.Warn("retrying cache command",
+				"command", command,
+				"attempt", attempt+1,
+				"max_attempts", config.RetryAttempts+1,
+				"backoff", sleepDuration,
+				"error", err,
+			)
+		}
+
+		// Sleep with context cancellation support
+		timer := time.NewTimer(sleepDuration)
+		select {
+		case <-timer.C:
+			// Continue to next attempt
+		case <-ctx.Done():
+			timer.Stop()
+			return retryCancelled(ctx, "context cancelled during retry backoff", lastErr)
+		}
+	}
+
+	return WrapError(lastErr, "maximum retry attempts exceeded")
+}
+
+// retryCancelled reports a retry loop stopped by ctx. It is classified by the
+// context error and keeps the last command error, like kpgx.RunInTx does.
+func retryCancelled(ctx context.Context, msg string, lastErr error) error {
+	err := ctx.Err()
+	return &CacheError{Code: classifyError(err), Message: msg, Cause: errors.Join(err, lastErr)}
+}
+
+// calculateBackoff calculates the backoff duration using exponential backoff with jitter.
+func calculateBackoff(initialBackoff time.Duration, attempt int, maxBackoff time.Duration) time.Duration {
+	// Exponential backoff: initial * 2^attempt
+	backoff := float64(initialBackoff) * math.Pow(2, float64(attempt))
+
+	// Cap at max backoff
+	if backoff > float64(maxBackoff) {
+		backoff = float64(maxBackoff)
+	}
+
+	// Add jitter (±10%) to prevent thundering herd
+	jitter := backoff * 0.1 * (2*rand.Float64() - 1)
+	backoff += jitter
+
+	return time.Duration(backoff)
+}
@@ -0,0 +1,65 @@
+package kcache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Get reads the value of key and decodes it into a T.
+// Returns ErrCacheMiss (see IsCacheMiss) if the key does not exist.
+//
+//	user, err := kcache.Get[User](ctx, cache, "user:42")
+func Get[T any](ctx context.Context, c *Cache, key string) (T, error) {
+	var value T
+	if err := c.Get(ctx, key, &value); err != nil {
+		var zero T
+		return zero, err
+	}
+	return value, nil
+}
+
+// Set encodes value and stores it under key. The ttl is handled as by
+// Cache.Set.
+func Set[T any](ctx context.Context, c *Cache, key string, value T, ttl time.Duration) error {
+	return c.Set(ctx, key, value, ttl)
+}
+
+// GetOrSet returns the cached value of key, or calls load and caches its
+// result for ttl on a miss (cache-aside).
+//
+// The cache is treated as an optimization: when Redis fails or the cached
+// value cannot be decoded, the error is logged and load is called instead, and
+// a failure to store the loaded value is logged but not returned. Only errors
+// of load are returned. Concurrent misses of the same key each call load.
+//
+//	user, err := kcache.GetOrSet(ctx, cache, "user:42", time.Hour, func(ctx context.Context) (User, error) {
+//	    return repo.FindUser(ctx, 42)
+//	})
+func GetOrSet[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load func(context.Context) (T, error)) (T, error) {
+	value, err := Get[T](ctx, c, key)
+	if err == nil {
+		return value, nil
+	}
+	if !IsCacheMiss(err) && c.logger != nil {
+		c.logger.WarnContext(ctx, "cache read failed, loading value",
+			slog.String("key", key),
+			slog.Any("error", err),
+		)
+	}
+
+	value, err = load(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	if err := Set(ctx, c, key, value, ttl); err != nil && c.logger != nil {
+		c.logger.WarnContext(ctx, "cache write failed",
+			slog.String("key", key),
+			slog.Any("error", err),
+		)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,78 @@
+package kcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type typedUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestTypedGetSet(t *testing.T) {
+	for name, codec := range map[string]Codec{"json": JSONCodec, "msgpack": MsgpackCodec} {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newTestCache(t, WithCodec(codec))
+			ctx := context.Background()
+
+			if err := Set(ctx, c, "user:1", typedUser{ID: 1, Name: "alice"}, time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			got, err := Get[typedUser](ctx, c, "user:1")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got != (typedUser{ID: 1, Name: "alice"}) {
+				t.Errorf("Get() = %+v", got)
+			}
+
+			if _, err := Get[typedUser](ctx, c, "user:2"); !IsCacheMiss(err) {
+				t.Errorf("Expected a cache miss, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGetOrSet(t *testing.T) {
+	c, server := newTestCache(t)
+	ctx := context.Background()
+
+	loads := 0
+	load := func(context.Context) (typedUser, error) {
+		loads++
+		return typedUser{ID: 7, Name: "bob"}, nil
+	}
+
+	for range 2 {
+		got, err := GetOrSet(ctx, c, "user:7", time.Minute, load)
+		if err != nil || got.Name != "bob" {
+			t.Fatalf("GetOrSet() = %+v, %v", got, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("Expected one load, got %d", loads)
+	}
+
+	// A broken cache falls back to load
+	server.SetError("ERR server is broken")
+	got, err := GetOrSet(ctx, c, "user:7", time.Minute, load)
+	if err != nil || got.ID != 7 || loads != 2 {
+		t.Fatalf("GetOrSet() with a failing cache = %+v, %v after %d loads", got, err, loads)
+	}
+	server.SetError("")
+
+	failed := errors.New("not found")
+	_, err = GetOrSet(ctx, c, "user:8", time.Minute, func(context.Context) (typedUser, error) {
+		return typedUser{}, failed
+	})
+	if !errors.Is(err, failed) {
+		t.Errorf("Expected the load error, got %v", err)
+	}
+	if server.Exists("user:8") {
+		t.Error("Expected failed loads not to be cached")
+	}
+}
@@ -45,11 +45,25 @@ type Config struct {
 	// Individual queries can override this with their own context timeout.
 	QueryTimeout time.Duration
 
+	// StatementTimeout sets a server-side limit on statement execution time for
+	// every session (PostgreSQL statement_timeout, MySQL max_execution_time).
+	// Default: 0 (no limit)
+	// Unlike context deadlines this is enforced by the server even if the client
+	// disappears. MySQL only applies it to read-only SELECT statements.
+	// Override per call with ContextWithStatementTimeout.
+	StatementTimeout time.Duration
+
 	// HealthCheckInterval sets how often to perform background health checks.
 	// Default: 30 seconds
 	// Set to 0 to disable background health checks.
 	HealthCheckInterval time.Duration
 
+	// PoolStatsInterval sets how often connection pool statistics are reported to
+	// Metrics via RecordPoolStats.
+	// Default: 0 (disabled)
+	// Requires Metrics. Without it pool stats are only seen when Stats() is called.
+	PoolStatsInterval time.Duration
+
 	// RetryAttempts sets the maximum number of retry attempts for transient errors.
 	// Default: 3
 	RetryAttempts int
@@ -63,6 +77,11 @@ type Config struct {
 	// Default: 5 seconds
 	RetryMaxBackoff time.Duration
 
+	// RetryBudget caps automatic retries across all transactions using it.
+	// Default: nil (retries limited only by RetryAttempts)
+	// Share one budget between databases to cap their combined retry rate.
+	RetryBudget *RetryBudget
+
 	// Logger is the structured logger for database operations.
 	// If nil, logging is disabled.
 	Logger *slog.Logger
@@ -76,10 +95,32 @@ type Config struct {
 	// Warning: This can be verbose and impact performance in high-traffic applications.
 	LogQueries bool
 
+	// SlowQueryThreshold logs a warning for statements that take at least this long.
+	// Default: 0 (disabled)
+	// Requires Logger. The threshold is independent of LogQueries.
+	SlowQueryThreshold time.Duration
+
+	// QueryHooks are called after every statement with its SQL, arguments,
+	// duration and error. On PostgreSQL they also see statements executed in
+	// transactions and via QueryRow.
+	// Default: nil
+	QueryHooks []QueryHook
+
 	// ReadOnly opens the database in read-only mode.
 	// Default: false
+	// Sessions and transactions are opened read-only (PostgreSQL
+	// default_transaction_read_only, MySQL transaction_read_only) and Exec
+	// rejects write statements (INSERT, UPDATE, DELETE, DDL, ...) with ErrReadOnly
+	// before they reach the server.
 	ReadOnly bool
 
+	// CancelOnContextDone asks the server to stop executing a statement when its
+	// context is canceled or times out, instead of only dropping the client side.
+	// PostgreSQL sends a protocol cancel request; MySQL issues KILL QUERY for
+	// statements executed outside a transaction.
+	// Default: false
+	CancelOnContextDone bool
+
 	// PostgresPreferSimpleProtocol disables prepared statement cache for PostgreSQL.
 	// Default: false
 	// Set to true if you have queries with dynamic table/column names.
@@ -110,14 +151,20 @@ func DefaultConfig(driver Driver, databaseURL string) *Config {
 		ConnMaxIdleTime:              10 * time.Minute,
 		ConnectTimeout:               10 * time.Second,
 		QueryTimeout:                 30 * time.Second,
+		StatementTimeout:             0,
 		HealthCheckInterval:          30 * time.Second,
+		PoolStatsInterval:            0,
 		RetryAttempts:                3,
 		RetryInitialBackoff:          100 * time.Millisecond,
 		RetryMaxBackoff:              5 * time.Second,
+		RetryBudget:                  nil,
 		Logger:                       nil,
 		Metrics:                      nil,
 		LogQueries:                   false,
+		SlowQueryThreshold:           0,
+		QueryHooks:                   nil,
 		ReadOnly:                     false,
+		CancelOnContextDone:          false,
 		PostgresPreferSimpleProtocol: false,
 		MySQLParseTime:               true,
 		MySQLLocation:                time.UTC,
@@ -159,6 +206,18 @@ func (c *Config) Validate() error {
 		return ErrInvalidPoolConfig
 	}
 
+	if c.StatementTimeout < 0 {
+		return ErrInvalidPoolConfig
+	}
+
+	if c.PoolStatsInterval < 0 {
+		return ErrInvalidPoolConfig
+	}
+
+	if c.SlowQueryThreshold < 0 {
+		return ErrInvalidPoolConfig
+	}
+
 	if c.RetryAttempts < 0 {
 		return ErrInvalidRetryConfig
 	}
@@ -219,6 +278,13 @@ func WithQueryTimeout(d time.Duration) Option {
 	}
 }
 
+// WithStatementTimeout sets the server-side statement timeout for every session.
+func WithStatementTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.StatementTimeout = d
+	}
+}
+
 // WithHealthCheckInterval sets the health check interval.
 func WithHealthCheckInterval(d time.Duration) Option {
 	return func(c *Config) {
@@ -226,6 +292,13 @@ func WithHealthCheckInterval(d time.Duration) Option {
 	}
 }
 
+// WithPoolStatsInterval sets how often pool statistics are reported to Metrics.
+func WithPoolStatsInterval(d time.Duration) Option {
+	return func(c *Config) {
+		c.PoolStatsInterval = d
+	}
+}
+
 // WithRetryAttempts sets the maximum retry attempts.
 func WithRetryAttempts(n int) Option {
 	return func(c *Config) {
@@ -241,6 +314,13 @@ func WithRetryBackoff(initial, max time.Duration) Option {
 	}
 }
 
+// WithRetryBudget sets a shared token-bucket budget for automatic retries.
+func WithRetryBudget(budget *RetryBudget) Option {
+	return func(c *Config) {
+		c.RetryBudget = budget
+	}
+}
+
 // WithLogger sets the structured logger.
 func WithLogger(logger *slog.Logger) Option {
 	return func(c *Config) {
@@ -262,6 +342,20 @@ func WithLogQueries(enabled bool) Option {
 	}
 }
 
+// WithSlowQueryThreshold sets the duration above which statements are logged as slow.
+func WithSlowQueryThreshold(d time.Duration) Option {
+	return func(c *Config) {
+		c.SlowQueryThreshold = d
+	}
+}
+
+// WithQueryHook adds a hook called after every statement.
+func WithQueryHook(hook QueryHook) Option {
+	return func(c *Config) {
+		c.QueryHooks = append(c.QueryHooks, hook)
+	}
+}
+
 // WithReadOnly enables read-only mode.
 func WithReadOnly(enabled bool) Option {
 	return func(c *Config) {
@@ -269,6 +363,13 @@ func WithReadOnly(enabled bool) Option {
 	}
 }
 
+// WithCancelOnContextDone enables server-side cancellation of statements whose context is done.
+func WithCancelOnContextDone(enabled bool) Option {
+	return func(c *Config) {
+		c.CancelOnContextDone = enabled
+	}
+}
+
 // WithPostgresSimpleProtocol enables simple protocol for PostgreSQL.
 func WithPostgresSimpleProtocol(enabled bool) Option {
 	return func(c *Config) {
@@ -10,6 +10,7 @@ import (
 	"github.com/go-sql-driver/mysql"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	kerrors "github.com/karu-codes/karu-kits/errors"
 )
 
 // ErrorCode represents the type of database error.
@@ -21,21 +22,22 @@ const (
 	CodeInvalidConfig   ErrorCode = "INVALID_CONFIG"
 
 	// Connection errors
-	CodeUnavailable      ErrorCode = "UNAVAILABLE"
-	CodeTimeout          ErrorCode = "TIMEOUT"
-	CodeUnauthenticated  ErrorCode = "UNAUTHENTICATED"
-	CodePermission       ErrorCode = "PERMISSION_DENIED"
+	CodeUnavailable     ErrorCode = "UNAVAILABLE"
+	CodeTimeout         ErrorCode = "TIMEOUT"
+	CodeUnauthenticated ErrorCode = "UNAUTHENTICATED"
+	CodePermission      ErrorCode = "PERMISSION_DENIED"
 
 	// Query errors
-	CodeNotFound     ErrorCode = "NOT_FOUND"
+	CodeNotFound      ErrorCode = "NOT_FOUND"
 	CodeAlreadyExists ErrorCode = "ALREADY_EXISTS"
-	CodeConflict     ErrorCode = "CONFLICT"
-	CodeInvalidState ErrorCode = "INVALID_STATE"
+	CodeConflict      ErrorCode = "CONFLICT"
+	CodeInvalidState  ErrorCode = "INVALID_STATE"
 
 	// Transaction errors
-	CodeDatabase ErrorCode = "DATABASE_ERROR"
-	CodeInternal ErrorCode = "INTERNAL_ERROR"
-	CodeCancelled ErrorCode = "CANCELLED"
+	CodeDatabase          ErrorCode = "DATABASE_ERROR"
+	CodeInternal          ErrorCode = "INTERNAL_ERROR"
+	CodeCancelled         ErrorCode = "CANCELLED"
+	CodeResourceExhausted ErrorCode = "RESOURCE_EXHAUSTED"
 )
 
 // DatabaseError represents a structured database error.
@@ -76,15 +78,17 @@ var (
 	ErrInvalidRetryConfig = &DatabaseError{Code: CodeInvalidConfig, Message: "invalid retry configuration"}
 
 	// Connection errors
-	ErrConnectionFailed    = &DatabaseError{Code: CodeUnavailable, Message: "failed to connect to database"}
-	ErrConnectionTimeout   = &DatabaseError{Code: CodeTimeout, Message: "database connection timeout"}
-	ErrMaxRetriesExceeded  = &DatabaseError{Code: CodeUnavailable, Message: "maximum retry attempts exceeded"}
-	ErrDatabaseUnavailable = &DatabaseError{Code: CodeUnavailable, Message: "database is unavailable"}
+	ErrConnectionFailed     = &DatabaseError{Code: CodeUnavailable, Message: "failed to connect to database"}
+	ErrConnectionTimeout    = &DatabaseError{Code: CodeTimeout, Message: "database connection timeout"}
+	ErrMaxRetriesExceeded   = &DatabaseError{Code: CodeUnavailable, Message: "maximum retry attempts exceeded"}
+	ErrRetryBudgetExhausted = &DatabaseError{Code: CodeResourceExhausted, Message: "retry budget exhausted"}
+	ErrDatabaseUnavailable  = &DatabaseError{Code: CodeUnavailable, Message: "database is unavailable"}
 
 	// Query errors
 	ErrQueryFailed = &DatabaseError{Code: CodeDatabase, Message: "query execution failed"}
 	ErrNoRows      = &DatabaseError{Code: CodeNotFound, Message: "no rows found"}
 	ErrTooManyRows = &DatabaseError{Code: CodeInvalidState, Message: "query returned too many rows"}
+	ErrReadOnly    = &DatabaseError{Code: CodePermission, Message: "write statement rejected: database is read-only"}
 
 	// Transaction errors
 	ErrTransactionFailed = &DatabaseError{Code: CodeDatabase, Message: "transaction failed"}
@@ -203,6 +207,10 @@ func classifyPostgresError(err error) (ErrorCode, bool) {
 	case "23P01": // exclusion_violation
 		return CodeInvalidArgument, true
 
+	// Class 25: Invalid Transaction State
+	case "25006": // read_only_sql_transaction
+		return CodePermission, true
+
 	// Class 40: Transaction Rollback
 	case "40001": // serialization_failure
 		return CodeConflict, true
@@ -312,6 +320,8 @@ func classifyMySQLError(err error) (ErrorCode, bool) {
 		return CodeAlreadyExists, true
 	case 1064: // ER_PARSE_ERROR (SQL syntax error)
 		return CodeInvalidArgument, true
+	case 1094: // ER_NO_SUCH_THREAD (Unknown thread id)
+		return CodeNotFound, true
 
 	// Constraint violations
 	case 1216: // ER_NO_REFERENCED_ROW (Cannot add or update a child row)
@@ -348,6 +358,10 @@ func classifyMySQLError(err error) (ErrorCode, bool) {
 		return CodePermission, true
 	case 1143: // ER_COLUMNACCESS_DENIED_ERROR
 		return CodePermission, true
+	case 1290: // ER_OPTION_PREVENTS_STATEMENT (e.g. --read-only server)
+		return CodePermission, true
+	case 1792: // ER_CANT_EXECUTE_IN_READ_ONLY_TRANSACTION
+		return CodePermission, true
 
 	default:
 		// Unknown MySQL error
@@ -356,24 +370,18 @@ func classifyMySQLError(err error) (ErrorCode, bool) {
 }
 
 // IsRetryable determines if an error is safe to retry.
+// It delegates to the errors package (see errors.IsRetryable), so an explicit
+// (*errors.Error).Retryable flag in the chain takes precedence and other error
+// types are classified the same way everywhere.
 func IsRetryable(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	// Check for context cancellation (never retry)
-	if errors.Is(err, context.Canceled) {
-		return false
-	}
-
-	// Unwrap to get the underlying error code
-	var dbErr *DatabaseError
-	if !errors.As(err, &dbErr) {
-		return false
-	}
+	return kerrors.IsRetryable(err)
+}
 
-	// Only retry transient errors
-	switch dbErr.Code {
+// Retryable reports whether the error is transient: the database is
+// temporarily unavailable, timed out, or hit a deadlock or serialization
+// failure.
+func (e *DatabaseError) Retryable() bool {
+	switch e.Code {
 	case CodeUnavailable: // Database temporarily unavailable
 		return true
 	case CodeTimeout: // Timeout (may succeed on retry)
@@ -385,6 +393,16 @@ func IsRetryable(err error) bool {
 	}
 }
 
+// IsRetryBudgetExhausted checks if a retry was skipped because the retry budget ran out.
+// The original error is available via errors.Unwrap.
+func IsRetryBudgetExhausted(err error) bool {
+	var dbErr *DatabaseError
+	if errors.As(err, &dbErr) {
+		return dbErr.Code == CodeResourceExhausted
+	}
+	return false
+}
+
 // IsNoRows checks if the error is a "no rows" error.
 func IsNoRows(err error) bool {
 	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
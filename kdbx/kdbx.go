@@ -5,7 +5,9 @@ package kdbx
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -35,6 +37,11 @@ type Database interface {
 	// Exec executes a query that doesn't return rows (INSERT, UPDATE, DELETE).
 	Exec(ctx context.Context, query string, args ...interface{}) (Result, error)
 
+	// ExecScript executes a multi-statement SQL script (schema bootstrap, fixtures).
+	// The script is split with SplitScript and statements run sequentially; a failure
+	// is reported as a *ScriptError identifying the statement.
+	ExecScript(ctx context.Context, script string, opts ...ScriptOption) error
+
 	// Begin starts a new transaction.
 	Begin(ctx context.Context) (Tx, error)
 
@@ -167,11 +174,15 @@ type pgxTxAdapter struct {
 // pgxRowsAdapter adapts pgx.Rows to the Rows interface.
 type pgxRowsAdapter struct {
 	rows pgx.Rows
+	// release, if set, is called once when the rows are closed.
+	release func()
 }
 
 // pgxRowAdapter adapts pgx.Row to the Row interface.
 type pgxRowAdapter struct {
 	row pgx.Row
+	// release, if set, is called once after Scan.
+	release func()
 }
 
 // sqlDBAdapter adapts *sql.DB to the Database interface.
@@ -189,11 +200,20 @@ type sqlTxAdapter struct {
 // sqlRowsAdapter adapts *sql.Rows to the Rows interface.
 type sqlRowsAdapter struct {
 	rows *sql.Rows
+	// release, if set, is called once when the rows are closed.
+	release func()
 }
 
 // sqlRowAdapter adapts *sql.Row to the Row interface.
 type sqlRowAdapter struct {
 	row *sql.Row
+	// release, if set, is called once after Scan.
+	release func()
+}
+
+// errRow is a Row that always fails with err.
+type errRow struct {
+	err error
 }
 
 // sqlResultAdapter adapts sql.Result to the Result interface.
@@ -206,6 +226,40 @@ type pgxCommandTagAdapter struct {
 	tag pgconn.CommandTag
 }
 
+// pgxQueryer is implemented by both *pgxpool.Pool and *pgxpool.Conn.
+type pgxQueryer interface {
+	Query(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, query string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// sqlQueryer is implemented by both *sql.DB and *sql.Conn.
+type sqlQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// discardConn marks a pinned *sql.Conn as broken so database/sql closes it
+// instead of returning it to the pool (e.g. when session state could not be reset).
+func discardConn(conn *sql.Conn) {
+	_ = conn.Raw(func(interface{}) error {
+		return driver.ErrBadConn
+	})
+}
+
+// releaseOnDone returns a release function that runs release once, when it is
+// called or when ctx is done, whichever comes first. It keeps a Row that is
+// never scanned from holding its pinned connection past the caller's context.
+func releaseOnDone(ctx context.Context, release func()) func() {
+	var once sync.Once
+	stop := context.AfterFunc(ctx, func() { once.Do(release) })
+	return func() {
+		stop()
+		once.Do(release)
+	}
+}
+
 // Ensure interfaces are implemented at compile time.
 var (
 	_ Rows   = (*pgxRowsAdapter)(nil)
@@ -214,4 +268,5 @@ var (
 	_ Result = (*sqlResultAdapter)(nil)
 	_ Rows   = (*sqlRowsAdapter)(nil)
 	_ Row    = (*sqlRowAdapter)(nil)
+	_ Row    = (*errRow)(nil)
 )
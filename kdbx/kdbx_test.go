@@ -0,0 +1,100 @@
+package kdbx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestReleaseOnDone(t *testing.T) {
+	tests := []struct {
+		name   string
+		scan   bool
+		cancel bool
+		want   int
+	}{
+		{"released by scan", true, false, 1},
+		{"released when ctx is done", false, true, 1},
+		{"scan then cancel releases once", true, true, 1},
+		{"held until scan or cancel", false, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			released := make(chan struct{}, 2)
+			release := releaseOnDone(ctx, func() { released <- struct{}{} })
+
+			if tt.scan {
+				release()
+				release()
+			}
+			if tt.cancel {
+				cancel()
+			}
+
+			// AfterFunc runs the release in its own goroutine.
+			time.Sleep(20 * time.Millisecond)
+			got := len(released)
+			if got != tt.want {
+				t.Errorf("Expected %d releases, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+type fakePgxRow struct{}
+
+func (fakePgxRow) Scan(...interface{}) error { return nil }
+
+// fakePgxQueryer returns fakePgxRow from QueryRow.
+type fakePgxQueryer struct {
+	pgxQueryer
+}
+
+func (fakePgxQueryer) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return fakePgxRow{}
+}
+
+func TestQueryRowPgxRelease(t *testing.T) {
+	tests := []struct {
+		name   string
+		scan   bool
+		cancel bool
+		want   int
+	}{
+		{"released by scan", true, false, 1},
+		{"released when ctx is done without scan", false, true, 1},
+		{"scan then cancel releases once", true, true, 1},
+		{"held until scan or cancel", false, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			released := make(chan struct{}, 2)
+			row := queryRowPgx(ctx, fakePgxQueryer{}, func() { released <- struct{}{} }, "SELECT 1")
+
+			if tt.scan {
+				if err := row.Scan(); err != nil {
+					t.Fatalf("Scan() error = %v", err)
+				}
+			}
+			if tt.cancel {
+				cancel()
+			}
+
+			// AfterFunc runs the release in its own goroutine.
+			time.Sleep(20 * time.Millisecond)
+			if got := len(released); got != tt.want {
+				t.Errorf("Expected %d releases, got %d", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package kdbxtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/karu-codes/karu-kits/kdbx"
+)
+
+const (
+	// DefaultPostgresImage is the image used by StartPostgres and NewPostgres.
+	DefaultPostgresImage = "postgres:16-alpine"
+
+	// DefaultMySQLImage is the image used by StartMySQL and NewMySQL.
+	DefaultMySQLImage = "mysql:8.4"
+
+	postgresPort = nat.Port("5432/tcp")
+	mysqlPort    = nat.Port("3306/tcp")
+)
+
+// Option configures a test database container.
+type Option func(*options)
+
+type options struct {
+	image          string
+	database       string
+	username       string
+	password       string
+	schemas        []string
+	schemaFiles    []string
+	dbOptions      []kdbx.Option
+	startupTimeout time.Duration
+}
+
+func defaultOptions(image string) *options {
+	return &options{
+		image:          image,
+		database:       "test",
+		username:       "test",
+		password:       "test",
+		startupTimeout: 2 * time.Minute,
+	}
+}
+
+// WithImage overrides the container image (e.g. "postgres:17", "mysql:8.0").
+func WithImage(image string) Option {
+	return func(o *options) {
+		o.image = image
+	}
+}
+
+// WithDatabase sets the database name. Default: "test".
+func WithDatabase(name string) Option {
+	return func(o *options) {
+		o.database = name
+	}
+}
+
+// WithCredentials sets the database user and password. Default: "test"/"test".
+func WithCredentials(username, password string) Option {
+	return func(o *options) {
+		o.username = username
+		o.password = password
+	}
+}
+
+// WithSchema adds a SQL script that is executed once the container is ready.
+func WithSchema(script string) Option {
+	return func(o *options) {
+		o.schemas = append(o.schemas, script)
+	}
+}
+
+// WithSchemaFiles adds SQL script files that are executed, in order, once the
+// container is ready (after any WithSchema scripts).
+func WithSchemaFiles(paths ...string) Option {
+	return func(o *options) {
+		o.schemaFiles = append(o.schemaFiles, paths...)
+	}
+}
+
+// WithDBOptions sets kdbx options applied to every database opened on the container.
+func WithDBOptions(opts ...kdbx.Option) Option {
+	return func(o *options) {
+		o.dbOptions = append(o.dbOptions, opts...)
+	}
+}
+
+// WithStartupTimeout sets how long to wait for the server to accept connections.
+// Default: 2 minutes.
+func WithStartupTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.startupTimeout = d
+	}
+}
+
+// Container is a running database container.
+type Container struct {
+	driver    kdbx.Driver
+	url       string
+	dbOptions []kdbx.Option
+	container testcontainers.Container
+}
+
+// StartPostgres starts a PostgreSQL container and applies the configured schema.
+// Use it from TestMain to share one container across a package; call Terminate
+// when done.
+func StartPostgres(ctx context.Context, opts ...Option) (*Container, error) {
+	o := defaultOptions(DefaultPostgresImage)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	dsn := func(host string, port nat.Port) string {
+		return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", o.username, o.password, host, port.Port(), o.database)
+	}
+
+	ctr, err := testcontainers.Run(ctx, o.image,
+		testcontainers.WithExposedPorts(string(postgresPort)),
+		testcontainers.WithEnv(map[string]string{
+			"POSTGRES_DB":       o.database,
+			"POSTGRES_USER":     o.username,
+			"POSTGRES_PASSWORD": o.password,
+		}),
+		testcontainers.WithTmpfs(map[string]string{"/var/lib/postgresql/data": "rw"}),
+		testcontainers.WithWaitStrategy(
+			wait.ForSQL(postgresPort, "pgx", dsn).WithStartupTimeout(o.startupTimeout),
+		),
+	)
+	if err != nil {
+		return nil, withTerminate(ctr, fmt.Errorf("kdbxtest: start postgres container: %w", err))
+	}
+
+	return newContainer(ctx, ctr, kdbx.DriverPostgres, postgresPort, dsn, o)
+}
+
+// StartMySQL starts a MySQL container and applies the configured schema.
+// Use it from TestMain to share one container across a package; call Terminate
+// when done.
+func StartMySQL(ctx context.Context, opts ...Option) (*Container, error) {
+	o := defaultOptions(DefaultMySQLImage)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	dsn := func(host string, port nat.Port) string {
+		return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", o.username, o.password, host, port.Port(), o.database)
+	}
+
+	env := map[string]string{
+		"MYSQL_DATABASE":      o.database,
+		"MYSQL_ROOT_PASSWORD": o.password,
+	}
+	if o.username != "root" {
+		env["MYSQL_USER"] = o.username
+		env["MYSQL_PASSWORD"] = o.password
+	}
+
+	ctr, err := testcontainers.Run(ctx, o.image,
+		testcontainers.WithExposedPorts(string(mysqlPort)),
+		testcontainers.WithEnv(env),
+		testcontainers.WithTmpfs(map[string]string{"/var/lib/mysql": "rw"}),
+		testcontainers.WithWaitStrategy(
+			wait.ForSQL(mysqlPort, "mysql", dsn).WithStartupTimeout(o.startupTimeout),
+		),
+	)
+	if err != nil {
+		return nil, withTerminate(ctr, fmt.Errorf("kdbxtest: start mysql container: %w", err))
+	}
+
+	return newContainer(ctx, ctr, kdbx.DriverMySQL, mysqlPort, dsn, o)
+}
+
+func newContainer(ctx context.Context, ctr testcontainers.Container, driver kdbx.Driver, port nat.Port, dsn func(string, nat.Port) string, o *options) (*Container, error) {
+	host, err := ctr.Host(ctx)
+	if err != nil {
+		return nil, withTerminate(ctr, fmt.Errorf("kdbxtest: container host: %w", err))
+	}
+	mapped, err := ctr.MappedPort(ctx, port)
+	if err != nil {
+		return nil, withTerminate(ctr, fmt.Errorf("kdbxtest: container port: %w", err))
+	}
+
+	c := &Container{
+		driver:    driver,
+		url:       dsn(host, mapped),
+		dbOptions: o.dbOptions,
+		container: ctr,
+	}
+
+	if err := c.applySchema(ctx, o); err != nil {
+		return nil, withTerminate(ctr, err)
+	}
+
+	return c, nil
+}
+
+func (c *Container) applySchema(ctx context.Context, o *options) error {
+	if len(o.schemas) == 0 && len(o.schemaFiles) == 0 {
+		return nil
+	}
+
+	db, err := c.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for i, script := range o.schemas {
+		if err := loadSchema(ctx, db, script); err != nil {
+			return fmt.Errorf("kdbxtest: apply schema %d: %w", i, err)
+		}
+	}
+	return loadSchemaFiles(ctx, db, o.schemaFiles)
+}
+
+// Driver returns the database driver of the container.
+func (c *Container) Driver() kdbx.Driver {
+	return c.driver
+}
+
+// URL returns the connection URL (PostgreSQL) or DSN (MySQL) of the container.
+func (c *Container) URL() string {
+	return c.url
+}
+
+// Open connects to the container and closes the connection when the test ends.
+// opts are applied after the container's WithDBOptions.
+func (c *Container) Open(t testing.TB, opts ...kdbx.Option) kdbx.Database {
+	t.Helper()
+
+	db, err := c.open(context.Background(), opts...)
+	if err != nil {
+		t.Fatalf("kdbxtest: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	return db
+}
+
+func (c *Container) open(ctx context.Context, opts ...kdbx.Option) (kdbx.Database, error) {
+	config := kdbx.DefaultConfig(c.driver, c.url)
+	config.ApplyOptions(c.dbOptions...)
+	config.ApplyOptions(opts...)
+
+	// Return explicit nils so a failed open never yields a non-nil interface
+	// holding a nil pointer.
+	switch c.driver {
+	case kdbx.DriverPostgres:
+		db, err := kdbx.NewPostgres(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	case kdbx.DriverMySQL:
+		db, err := kdbx.NewMySQL(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	default:
+		return nil, kdbx.ErrInvalidDriver
+	}
+}
+
+// Terminate stops and removes the container.
+func (c *Container) Terminate(ctx context.Context) error {
+	return c.container.Terminate(ctx)
+}
+
+// NewPostgres starts a dedicated PostgreSQL container for the test and returns a
+// database connected to it. The container is removed when the test ends.
+// The test is skipped when Docker is not available.
+func NewPostgres(t testing.TB, opts ...Option) kdbx.Database {
+	t.Helper()
+
+	skipIfNoDocker(t)
+
+	c, err := StartPostgres(context.Background(), opts...)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	t.Cleanup(func() {
+		_ = c.Terminate(context.Background())
+	})
+
+	return c.Open(t)
+}
+
+// NewMySQL starts a dedicated MySQL container for the test and returns a
+// database connected to it. The container is removed when the test ends.
+// The test is skipped when Docker is not available.
+func NewMySQL(t testing.TB, opts ...Option) kdbx.Database {
+	t.Helper()
+
+	skipIfNoDocker(t)
+
+	c, err := StartMySQL(context.Background(), opts...)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	t.Cleanup(func() {
+		_ = c.Terminate(context.Background())
+	})
+
+	return c.Open(t)
+}
+
+// skipIfNoDocker skips the test when no healthy container runtime is reachable.
+func skipIfNoDocker(t testing.TB) {
+	t.Helper()
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Skipf("kdbxtest: docker is not available: %v", r)
+		}
+	}()
+
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err != nil {
+		t.Skipf("kdbxtest: docker is not available: %v", err)
+	}
+	defer provider.Close()
+
+	if err := provider.Health(context.Background()); err != nil {
+		t.Skipf("kdbxtest: docker is not available: %v", err)
+	}
+}
+
+// withTerminate removes a partially started container and returns err.
+func withTerminate(ctr testcontainers.Container, err error) error {
+	_ = testcontainers.TerminateContainer(ctr)
+	return err
+}
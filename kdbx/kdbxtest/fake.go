@@ -0,0 +1,486 @@
+package kdbxtest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/karu-codes/karu-kits/kdbx"
+)
+
+// CallKind identifies the operation recorded by FakeDB.
+type CallKind string
+
+const (
+	CallQuery    CallKind = "query"
+	CallQueryRow CallKind = "query_row"
+	CallExec     CallKind = "exec"
+	CallBegin    CallKind = "begin"
+	CallCommit   CallKind = "commit"
+	CallRollback CallKind = "rollback"
+)
+
+// Call is an operation recorded by FakeDB.
+type Call struct {
+	Kind  CallKind
+	Query string
+	Args  []interface{}
+
+	// InTx reports whether the call was made on a transaction.
+	InTx bool
+}
+
+// Stub is a canned response registered with FakeDB.On.
+type Stub struct {
+	pattern      string
+	rows         [][]interface{}
+	lastInsertID int64
+	rowsAffected int64
+	err          error
+}
+
+// ReturnRows sets the rows returned by Query and QueryRow. Each row holds one
+// value per scanned column.
+func (s *Stub) ReturnRows(rows ...[]interface{}) *Stub {
+	s.rows = rows
+	return s
+}
+
+// ReturnResult sets the result returned by Exec.
+func (s *Stub) ReturnResult(lastInsertID, rowsAffected int64) *Stub {
+	s.lastInsertID = lastInsertID
+	s.rowsAffected = rowsAffected
+	return s
+}
+
+// ReturnError makes every matching call fail with err.
+func (s *Stub) ReturnError(err error) *Stub {
+	s.err = err
+	return s
+}
+
+// FakeDB is an in-memory kdbx.Database for unit tests that do not need a real
+// server. Responses are registered with On and every call is recorded.
+//
+// Example:
+//
+//	db := kdbxtest.NewFakeDB(kdbx.DriverPostgres)
+//	db.On("SELECT name FROM users WHERE id").ReturnRows([]interface{}{"alice"})
+//	db.On("INSERT INTO users").ReturnResult(0, 1)
+//
+//	svc := NewUserService(db)
+//	// ...
+//
+//	calls := db.Calls()
+type FakeDB struct {
+	driver kdbx.Driver
+
+	mu        sync.Mutex
+	stubs     []*Stub
+	calls     []Call
+	healthErr error
+	closed    bool
+}
+
+// NewFakeDB creates an empty FakeDB that reports the given driver.
+func NewFakeDB(driver kdbx.Driver) *FakeDB {
+	return &FakeDB{driver: driver}
+}
+
+// On registers a stub for queries containing pattern (whitespace is normalised on
+// both sides). The most recently registered matching stub wins, so an empty pattern
+// registered first acts as a catch-all. A query without a matching stub fails.
+func (f *FakeDB) On(pattern string) *Stub {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s := &Stub{pattern: normalizeQuery(pattern)}
+	f.stubs = append(f.stubs, s)
+	return s
+}
+
+// Calls returns a copy of the recorded calls in order.
+func (f *FakeDB) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Call(nil), f.calls...)
+}
+
+// Reset removes all stubs and recorded calls.
+func (f *FakeDB) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.stubs = nil
+	f.calls = nil
+	f.healthErr = nil
+}
+
+// SetHealthError sets the error returned by Health and HealthDetailed.
+func (f *FakeDB) SetHealthError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.healthErr = err
+}
+
+// Closed reports whether Close or Shutdown has been called.
+func (f *FakeDB) Closed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.closed
+}
+
+// record appends a call and returns the stub matching its query.
+func (f *FakeDB) record(kind CallKind, inTx bool, query string, args []interface{}) (*Stub, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, Call{Kind: kind, Query: query, Args: args, InTx: inTx})
+
+	if kind != CallQuery && kind != CallQueryRow && kind != CallExec {
+		return nil, nil
+	}
+
+	normalized := normalizeQuery(query)
+	for i := len(f.stubs) - 1; i >= 0; i-- {
+		if strings.Contains(normalized, f.stubs[i].pattern) {
+			return f.stubs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("kdbxtest: no stub matches query %q", query)
+}
+
+func (f *FakeDB) query(inTx bool, query string, args []interface{}) (kdbx.Rows, error) {
+	stub, err := f.record(CallQuery, inTx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if stub.err != nil {
+		return nil, stub.err
+	}
+	return &fakeRows{rows: stub.rows, pos: -1}, nil
+}
+
+func (f *FakeDB) queryRow(inTx bool, query string, args []interface{}) kdbx.Row {
+	stub, err := f.record(CallQueryRow, inTx, query, args)
+	if err != nil {
+		return &fakeRow{err: err}
+	}
+	if stub.err != nil {
+		return &fakeRow{err: stub.err}
+	}
+	if len(stub.rows) == 0 {
+		return &fakeRow{err: kdbx.WrapError(sql.ErrNoRows, "failed to scan row")}
+	}
+	return &fakeRow{values: stub.rows[0]}
+}
+
+func (f *FakeDB) exec(inTx bool, query string, args []interface{}) (kdbx.Result, error) {
+	stub, err := f.record(CallExec, inTx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if stub.err != nil {
+		return nil, stub.err
+	}
+	return &fakeResult{lastInsertID: stub.lastInsertID, rowsAffected: stub.rowsAffected}, nil
+}
+
+// Query implements kdbx.Database.
+func (f *FakeDB) Query(ctx context.Context, query string, args ...interface{}) (kdbx.Rows, error) {
+	return f.query(false, query, args)
+}
+
+// QueryRow implements kdbx.Database.
+func (f *FakeDB) QueryRow(ctx context.Context, query string, args ...interface{}) kdbx.Row {
+	return f.queryRow(false, query, args)
+}
+
+// Exec implements kdbx.Database.
+func (f *FakeDB) Exec(ctx context.Context, query string, args ...interface{}) (kdbx.Result, error) {
+	return f.exec(false, query, args)
+}
+
+// ExecScript implements kdbx.Database. Each statement is recorded as an Exec call
+// and must match a stub. Options are accepted but the statements are not recorded
+// as running in a transaction.
+func (f *FakeDB) ExecScript(ctx context.Context, script string, opts ...kdbx.ScriptOption) error {
+	for i, stmt := range kdbx.SplitScript(f.driver, script) {
+		if _, err := f.exec(false, stmt.SQL, nil); err != nil {
+			return &kdbx.ScriptError{Index: i, Line: stmt.Line, Statement: stmt.SQL, Err: err}
+		}
+	}
+	return nil
+}
+
+// Begin implements kdbx.Database.
+func (f *FakeDB) Begin(ctx context.Context) (kdbx.Tx, error) {
+	if _, err := f.record(CallBegin, false, "", nil); err != nil {
+		return nil, err
+	}
+	return &fakeTx{db: f}, nil
+}
+
+// WithTransaction implements kdbx.Database. The function runs once; FakeDB does
+// not retry.
+func (f *FakeDB) WithTransaction(ctx context.Context, fn func(tx kdbx.Tx) error) error {
+	tx, err := f.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Health implements kdbx.Database.
+func (f *FakeDB) Health(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.healthErr
+}
+
+// HealthDetailed implements kdbx.Database.
+func (f *FakeDB) HealthDetailed(ctx context.Context) error {
+	return f.Health(ctx)
+}
+
+// Stats implements kdbx.Database. FakeDB has no pool, so all values are zero.
+func (f *FakeDB) Stats() kdbx.PoolStats {
+	return kdbx.PoolStats{}
+}
+
+// Close implements kdbx.Database.
+func (f *FakeDB) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	return nil
+}
+
+// Shutdown implements kdbx.Database.
+func (f *FakeDB) Shutdown(ctx context.Context) error {
+	return f.Close()
+}
+
+// Driver implements kdbx.Database.
+func (f *FakeDB) Driver() kdbx.Driver {
+	return f.driver
+}
+
+// errTxDone is returned when a fake transaction is used after Commit or Rollback.
+var errTxDone = errors.New("kdbxtest: transaction has already been committed or rolled back")
+
+// fakeTx is the kdbx.Tx returned by FakeDB.Begin.
+type fakeTx struct {
+	db   *FakeDB
+	mu   sync.Mutex
+	done bool
+}
+
+func (tx *fakeTx) isDone() bool {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	return tx.done
+}
+
+// finish marks the transaction done and reports whether it was still open.
+func (tx *fakeTx) finish() bool {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return false
+	}
+	tx.done = true
+	return true
+}
+
+func (tx *fakeTx) Query(ctx context.Context, query string, args ...interface{}) (kdbx.Rows, error) {
+	if tx.isDone() {
+		return nil, errTxDone
+	}
+	return tx.db.query(true, query, args)
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, query string, args ...interface{}) kdbx.Row {
+	if tx.isDone() {
+		return &fakeRow{err: errTxDone}
+	}
+	return tx.db.queryRow(true, query, args)
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, query string, args ...interface{}) (kdbx.Result, error) {
+	if tx.isDone() {
+		return nil, errTxDone
+	}
+	return tx.db.exec(true, query, args)
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	if !tx.finish() {
+		return errTxDone
+	}
+	_, err := tx.db.record(CallCommit, true, "", nil)
+	return err
+}
+
+// Rollback after Commit is a no-op, so `defer tx.Rollback(ctx)` is safe.
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	if !tx.finish() {
+		return nil
+	}
+	_, err := tx.db.record(CallRollback, true, "", nil)
+	return err
+}
+
+// fakeRows iterates over stubbed rows.
+type fakeRows struct {
+	rows   [][]interface{}
+	pos    int
+	closed bool
+}
+
+func (r *fakeRows) Next() bool {
+	if r.closed || r.pos+1 >= len(r.rows) {
+		return false
+	}
+	r.pos++
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	if r.closed || r.pos < 0 || r.pos >= len(r.rows) {
+		return errors.New("kdbxtest: Scan called without a successful Next")
+	}
+	return scanValues(r.rows[r.pos], dest)
+}
+
+func (r *fakeRows) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (r *fakeRows) Err() error {
+	return nil
+}
+
+// fakeRow is a single stubbed row or a deferred error.
+type fakeRow struct {
+	values []interface{}
+	err    error
+}
+
+func (r *fakeRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	return scanValues(r.values, dest)
+}
+
+// fakeResult is a stubbed Exec result.
+type fakeResult struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r *fakeResult) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r *fakeResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// scanValues copies stubbed values into scan destinations.
+func scanValues(values []interface{}, dest []interface{}) error {
+	if len(values) != len(dest) {
+		return fmt.Errorf("kdbxtest: row has %d values but Scan got %d destinations", len(values), len(dest))
+	}
+	for i := range values {
+		if err := assign(dest[i], values[i]); err != nil {
+			return fmt.Errorf("kdbxtest: scan column %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// assign stores src in the value pointed to by dest, using sql.Scanner when
+// implemented and otherwise a direct or numeric conversion.
+func assign(dest, src interface{}) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return fmt.Errorf("destination must be a non-nil pointer, got %T", dest)
+	}
+	elem := dv.Elem()
+
+	if src == nil {
+		elem.Set(reflect.Zero(elem.Type()))
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	switch {
+	case sv.Type().AssignableTo(elem.Type()):
+		elem.Set(sv)
+	case elem.Kind() == reflect.Ptr && sv.Type().AssignableTo(elem.Type().Elem()):
+		p := reflect.New(elem.Type().Elem())
+		p.Elem().Set(sv)
+		elem.Set(p)
+	case isNumeric(sv.Kind()) && isNumeric(elem.Kind()):
+		elem.Set(sv.Convert(elem.Type()))
+	default:
+		return fmt.Errorf("cannot assign %T to %s", src, elem.Type())
+	}
+	return nil
+}
+
+func isNumeric(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// normalizeQuery collapses runs of whitespace so stubs match regardless of formatting.
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// Ensure interfaces are implemented at compile time.
+var (
+	_ kdbx.Database = (*FakeDB)(nil)
+	_ kdbx.Tx       = (*fakeTx)(nil)
+	_ kdbx.Rows     = (*fakeRows)(nil)
+	_ kdbx.Row      = (*fakeRow)(nil)
+	_ kdbx.Result   = (*fakeResult)(nil)
+)
@@ -0,0 +1,313 @@
+package kdbxtest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/karu-codes/karu-kits/kdbx"
+)
+
+func TestFakeDBStubMatching(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name    string
+		stubs   func(db *FakeDB)
+		query   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "substring match",
+			stubs: func(db *FakeDB) { db.On("FROM users").ReturnRows([]interface{}{"alice"}) },
+			query: "SELECT name FROM users WHERE id = $1",
+			want:  "alice",
+		},
+		{
+			name:  "whitespace normalized",
+			stubs: func(db *FakeDB) { db.On("SELECT name\n\tFROM users").ReturnRows([]interface{}{"alice"}) },
+			query: "SELECT   name FROM\n users WHERE id = $1",
+			want:  "alice",
+		},
+		{
+			name: "most recent stub wins",
+			stubs: func(db *FakeDB) {
+				db.On("FROM users").ReturnRows([]interface{}{"alice"})
+				db.On("FROM users").ReturnRows([]interface{}{"bob"})
+			},
+			query: "SELECT name FROM users",
+			want:  "bob",
+		},
+		{
+			name: "catch-all registered first",
+			stubs: func(db *FakeDB) {
+				db.On("").ReturnRows([]interface{}{"default"})
+				db.On("FROM users").ReturnRows([]interface{}{"alice"})
+			},
+			query: "SELECT name FROM accounts",
+			want:  "default",
+		},
+		{
+			name:    "stubbed error",
+			stubs:   func(db *FakeDB) { db.On("FROM users").ReturnError(errBoom) },
+			query:   "SELECT name FROM users",
+			wantErr: errBoom,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := NewFakeDB(kdbx.DriverPostgres)
+			tt.stubs(db)
+
+			var got string
+			err := db.QueryRow(context.Background(), tt.query).Scan(&got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFakeDBUnmatchedQuery(t *testing.T) {
+	db := NewFakeDB(kdbx.DriverPostgres)
+	db.On("FROM users").ReturnRows([]interface{}{"alice"})
+
+	ctx := context.Background()
+	if _, err := db.Query(ctx, "SELECT * FROM accounts"); err == nil || !strings.Contains(err.Error(), "no stub matches") {
+		t.Errorf("Expected Query to fail without a matching stub, got %v", err)
+	}
+	if _, err := db.Exec(ctx, "DELETE FROM accounts"); err == nil {
+		t.Error("Expected Exec to fail without a matching stub")
+	}
+	var name string
+	if err := db.QueryRow(ctx, "SELECT name FROM accounts").Scan(&name); err == nil {
+		t.Error("Expected QueryRow to fail without a matching stub")
+	}
+
+	err := db.ExecScript(ctx, "SELECT * FROM users;\nDROP TABLE accounts;")
+	var scriptErr *kdbx.ScriptError
+	if !errors.As(err, &scriptErr) || scriptErr.Index != 1 || scriptErr.Line != 2 {
+		t.Errorf("Expected the second script statement to fail, got %v", err)
+	}
+
+	if got := len(db.Calls()); got != 5 {
+		t.Errorf("Expected unmatched calls to be recorded, got %d calls", got)
+	}
+}
+
+func TestFakeDBResults(t *testing.T) {
+	db := NewFakeDB(kdbx.DriverMySQL)
+	db.On("INSERT INTO users").ReturnResult(42, 1)
+	db.On("SELECT id, age FROM users").ReturnRows(
+		[]interface{}{int64(1), 30},
+		[]interface{}{int64(2), nil},
+	)
+	db.On("SELECT name FROM users WHERE id").ReturnRows()
+
+	ctx := context.Background()
+	res, err := db.Exec(ctx, "INSERT INTO users (name) VALUES (?)", "alice")
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if id, _ := res.LastInsertId(); id != 42 {
+		t.Errorf("Expected LastInsertId 42, got %d", id)
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Errorf("Expected RowsAffected 1, got %d", n)
+	}
+
+	rows, err := db.Query(ctx, "SELECT id, age FROM users")
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	type user struct {
+		id  int
+		age *int
+	}
+	var got []user
+	for rows.Next() {
+		var u user
+		if err := rows.Scan(&u.id, &u.age); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		got = append(got, u)
+	}
+	_ = rows.Close()
+	if len(got) != 2 || got[0].id != 1 || got[0].age == nil || *got[0].age != 30 || got[1].id != 2 || got[1].age != nil {
+		t.Errorf("Unexpected rows %+v", got)
+	}
+
+	var name string
+	if err := db.QueryRow(ctx, "SELECT name FROM users WHERE id = ?", 3).Scan(&name); !kdbx.IsNotFound(err) {
+		t.Errorf("Expected a not found error without rows, got %v", err)
+	}
+	if err := db.QueryRow(ctx, "SELECT id, age FROM users").Scan(&name); err == nil {
+		t.Error("Expected a column count mismatch to fail")
+	}
+}
+
+func TestFakeDBCallOrder(t *testing.T) {
+	db := NewFakeDB(kdbx.DriverPostgres)
+	db.On("").ReturnResult(0, 1)
+
+	ctx := context.Background()
+	_, _ = db.Exec(ctx, "UPDATE a SET x = $1", 1)
+	_, _ = db.Query(ctx, "SELECT x FROM a")
+	_ = db.WithTransaction(ctx, func(tx kdbx.Tx) error {
+		_, err := tx.Exec(ctx, "DELETE FROM a WHERE x = $1", 2)
+		return err
+	})
+	_ = db.QueryRow(ctx, "SELECT count(*) FROM a")
+
+	want := []Call{
+		{Kind: CallExec, Query: "UPDATE a SET x = $1", Args: []interface{}{1}},
+		{Kind: CallQuery, Query: "SELECT x FROM a"},
+		{Kind: CallBegin},
+		{Kind: CallExec, Query: "DELETE FROM a WHERE x = $1", Args: []interface{}{2}, InTx: true},
+		{Kind: CallCommit, InTx: true},
+		{Kind: CallQueryRow, Query: "SELECT count(*) FROM a"},
+	}
+	if got := db.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls()\n got %+v\nwant %+v", got, want)
+	}
+
+	db.Reset()
+	if got := db.Calls(); len(got) != 0 {
+		t.Errorf("Expected Reset to clear the calls, got %+v", got)
+	}
+}
+
+func TestFakeDBWithTransaction(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name     string
+		fn       func(ctx context.Context, tx kdbx.Tx) error
+		wantErr  error
+		wantLast CallKind
+	}{
+		{
+			name: "commit on success",
+			fn: func(ctx context.Context, tx kdbx.Tx) error {
+				_, err := tx.Exec(ctx, "INSERT INTO a VALUES (1)")
+				return err
+			},
+			wantLast: CallCommit,
+		},
+		{
+			name: "rollback on error",
+			fn: func(ctx context.Context, tx kdbx.Tx) error {
+				_, _ = tx.Exec(ctx, "INSERT INTO a VALUES (1)")
+				return errBoom
+			},
+			wantErr:  errBoom,
+			wantLast: CallRollback,
+		},
+		{
+			name: "rollback on failed statement",
+			fn: func(ctx context.Context, tx kdbx.Tx) error {
+				_, err := tx.Exec(ctx, "INSERT INTO b VALUES (1)")
+				return err
+			},
+			wantErr:  errBoom,
+			wantLast: CallRollback,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := NewFakeDB(kdbx.DriverPostgres)
+			db.On("INSERT INTO a").ReturnResult(0, 1)
+			db.On("INSERT INTO b").ReturnError(errBoom)
+
+			ctx := context.Background()
+			err := db.WithTransaction(ctx, func(tx kdbx.Tx) error { return tt.fn(ctx, tx) })
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			calls := db.Calls()
+			if len(calls) != 3 || calls[0].Kind != CallBegin || !calls[1].InTx || calls[2].Kind != tt.wantLast {
+				t.Errorf("Expected begin, exec and %s, got %+v", tt.wantLast, calls)
+			}
+		})
+	}
+}
+
+func TestFakeDBWithTransactionPanic(t *testing.T) {
+	db := NewFakeDB(kdbx.DriverPostgres)
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("Expected the panic to be re-raised, got %v", r)
+		}
+		calls := db.Calls()
+		if len(calls) != 2 || calls[1].Kind != CallRollback {
+			t.Errorf("Expected the transaction to be rolled back, got %+v", calls)
+		}
+	}()
+
+	_ = db.WithTransaction(context.Background(), func(kdbx.Tx) error {
+		panic("boom")
+	})
+}
+
+func TestFakeTxDone(t *testing.T) {
+	db := NewFakeDB(kdbx.DriverPostgres)
+	db.On("").ReturnResult(0, 1)
+
+	ctx := context.Background()
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if err := tx.Rollback(ctx); err != nil {
+		t.Errorf("Expected Rollback after Commit to be a no-op, got %v", err)
+	}
+	if err := tx.Commit(ctx); !errors.Is(err, errTxDone) {
+		t.Errorf("Expected a second Commit to fail with errTxDone, got %v", err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM a"); !errors.Is(err, errTxDone) {
+		t.Errorf("Expected Exec after Commit to fail with errTxDone, got %v", err)
+	}
+
+	kinds := []CallKind{}
+	for _, call := range db.Calls() {
+		kinds = append(kinds, call.Kind)
+	}
+	if !reflect.DeepEqual(kinds, []CallKind{CallBegin, CallCommit}) {
+		t.Errorf("Expected only begin and commit to be recorded, got %v", kinds)
+	}
+}
+
+func TestFakeDBHealthAndClose(t *testing.T) {
+	errDown := errors.New("down")
+	db := NewFakeDB(kdbx.DriverPostgres)
+
+	ctx := context.Background()
+	if err := db.Health(ctx); err != nil {
+		t.Errorf("Expected a healthy FakeDB, got %v", err)
+	}
+	db.SetHealthError(errDown)
+	if err := db.HealthDetailed(ctx); !errors.Is(err, errDown) {
+		t.Errorf("Expected the health error, got %v", err)
+	}
+
+	if db.Closed() {
+		t.Error("Expected a new FakeDB to be open")
+	}
+	if err := db.Shutdown(ctx); err != nil || !db.Closed() {
+		t.Errorf("Expected Shutdown to close the FakeDB, got %v", err)
+	}
+}
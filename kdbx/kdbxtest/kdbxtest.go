@@ -0,0 +1,178 @@
+// Package kdbxtest provides test helpers for code built on kdbx:
+// container-backed PostgreSQL and MySQL databases, schema loading, per-test
+// truncation and an in-memory FakeDB for pure unit tests.
+//
+// Example:
+//
+//	func TestUserRepository(t *testing.T) {
+//	    db := kdbxtest.NewPostgres(t, kdbxtest.WithSchemaFiles("testdata/schema.sql"))
+//	    kdbxtest.TruncateOnCleanup(t, db)
+//
+//	    repo := NewUserRepository(db)
+//	    // ...
+//	}
+package kdbxtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/karu-codes/karu-kits/kdbx"
+)
+
+// LoadSchema executes a SQL script (see kdbx.SplitScript) in a single transaction
+// and fails the test on error.
+func LoadSchema(t testing.TB, db kdbx.Database, script string) {
+	t.Helper()
+
+	if err := loadSchema(context.Background(), db, script); err != nil {
+		t.Fatalf("kdbxtest: load schema: %v", err)
+	}
+}
+
+// LoadSchemaFiles reads and executes SQL script files in order and fails the
+// test on error.
+func LoadSchemaFiles(t testing.TB, db kdbx.Database, paths ...string) {
+	t.Helper()
+
+	if err := loadSchemaFiles(context.Background(), db, paths); err != nil {
+		t.Fatalf("%v", err)
+	}
+}
+
+func loadSchema(ctx context.Context, db kdbx.Database, script string) error {
+	return db.ExecScript(ctx, script, kdbx.WithScriptTransaction())
+}
+
+func loadSchemaFiles(ctx context.Context, db kdbx.Database, paths []string) error {
+	for _, path := range paths {
+		script, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("kdbxtest: read schema file %s: %w", path, err)
+		}
+		if err := loadSchema(ctx, db, string(script)); err != nil {
+			return fmt.Errorf("kdbxtest: load schema file %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Truncate removes all rows from the given tables, or from every table in the
+// current schema (PostgreSQL) or database (MySQL) when no table is given.
+// Identity/auto-increment counters are reset and foreign keys do not block
+// truncation.
+func Truncate(t testing.TB, db kdbx.Database, tables ...string) {
+	t.Helper()
+
+	if err := truncate(context.Background(), db, tables); err != nil {
+		t.Fatalf("kdbxtest: truncate: %v", err)
+	}
+}
+
+// TruncateOnCleanup registers a t.Cleanup that truncates the given tables (or all
+// tables, see Truncate) when the test finishes, so each test starts from the
+// schema without leftover rows.
+func TruncateOnCleanup(t testing.TB, db kdbx.Database, tables ...string) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		if err := truncate(context.Background(), db, tables); err != nil {
+			t.Errorf("kdbxtest: truncate: %v", err)
+		}
+	})
+}
+
+func truncate(ctx context.Context, db kdbx.Database, tables []string) error {
+	if len(tables) == 0 {
+		var err error
+		if tables, err = listTables(ctx, db); err != nil {
+			return err
+		}
+		if len(tables) == 0 {
+			return nil
+		}
+	}
+
+	switch db.Driver() {
+	case kdbx.DriverPostgres:
+		quoted := make([]string, len(tables))
+		for i, table := range tables {
+			quoted[i] = quoteIdentifier(kdbx.DriverPostgres, table)
+		}
+		_, err := db.Exec(ctx, "TRUNCATE TABLE "+strings.Join(quoted, ", ")+" RESTART IDENTITY CASCADE")
+		return err
+
+	case kdbx.DriverMySQL:
+		// The transaction only pins a connection so the session variable applies to
+		// every TRUNCATE; MySQL commits implicitly around each of them.
+		return db.WithTransaction(ctx, func(tx kdbx.Tx) (err error) {
+			if _, err := tx.Exec(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+				return err
+			}
+			// Restore the checks even when a TRUNCATE fails, or the connection goes
+			// back to the pool without them.
+			defer func() {
+				if _, restoreErr := tx.Exec(context.WithoutCancel(ctx), "SET FOREIGN_KEY_CHECKS = 1"); restoreErr != nil {
+					err = errors.Join(err, restoreErr)
+				}
+			}()
+
+			for _, table := range tables {
+				if _, err := tx.Exec(ctx, "TRUNCATE TABLE "+quoteIdentifier(kdbx.DriverMySQL, table)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	default:
+		return kdbx.ErrInvalidDriver
+	}
+}
+
+// listTables returns the user tables of the current schema or database.
+func listTables(ctx context.Context, db kdbx.Database) ([]string, error) {
+	var query string
+	switch db.Driver() {
+	case kdbx.DriverPostgres:
+		query = "SELECT tablename FROM pg_tables WHERE schemaname = current_schema() ORDER BY tablename"
+	case kdbx.DriverMySQL:
+		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' ORDER BY table_name"
+	default:
+		return nil, kdbx.ErrInvalidDriver
+	}
+
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// quoteIdentifier quotes a (possibly schema-qualified) table name.
+func quoteIdentifier(driver kdbx.Driver, name string) string {
+	quote := `"`
+	if driver == kdbx.DriverMySQL {
+		quote = "`"
+	}
+
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = quote + strings.ReplaceAll(part, quote, quote+quote) + quote
+	}
+	return strings.Join(parts, ".")
+}
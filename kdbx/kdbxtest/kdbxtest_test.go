@@ -0,0 +1,213 @@
+package kdbxtest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/karu-codes/karu-kits/kdbx"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver kdbx.Driver
+		table  string
+		want   string
+	}{
+		{"postgres", kdbx.DriverPostgres, "users", `"users"`},
+		{"postgres schema-qualified", kdbx.DriverPostgres, "app.users", `"app"."users"`},
+		{"postgres embedded quote", kdbx.DriverPostgres, `we"ird`, `"we""ird"`},
+		{"mysql", kdbx.DriverMySQL, "users", "`users`"},
+		{"mysql database-qualified", kdbx.DriverMySQL, "app.users", "`app`.`users`"},
+		{"mysql embedded backtick", kdbx.DriverMySQL, "we`ird", "`we``ird`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteIdentifier(tt.driver, tt.table); got != tt.want {
+				t.Errorf("quoteIdentifier(%q) = %s, want %s", tt.table, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateStatements(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name    string
+		driver  kdbx.Driver
+		failOn  string
+		want    []Call
+		wantErr error
+	}{
+		{
+			name:   "postgres",
+			driver: kdbx.DriverPostgres,
+			want: []Call{
+				{Kind: CallExec, Query: `TRUNCATE TABLE "a", "b" RESTART IDENTITY CASCADE`},
+			},
+		},
+		{
+			name:   "mysql",
+			driver: kdbx.DriverMySQL,
+			want: []Call{
+				{Kind: CallBegin},
+				{Kind: CallExec, Query: "SET FOREIGN_KEY_CHECKS = 0", InTx: true},
+				{Kind: CallExec, Query: "TRUNCATE TABLE `a`", InTx: true},
+				{Kind: CallExec, Query: "TRUNCATE TABLE `b`", InTx: true},
+				{Kind: CallExec, Query: "SET FOREIGN_KEY_CHECKS = 1", InTx: true},
+				{Kind: CallCommit, InTx: true},
+			},
+		},
+		{
+			name:   "mysql restores foreign key checks on failure",
+			driver: kdbx.DriverMySQL,
+			failOn: "TRUNCATE TABLE `a`",
+			want: []Call{
+				{Kind: CallBegin},
+				{Kind: CallExec, Query: "SET FOREIGN_KEY_CHECKS = 0", InTx: true},
+				{Kind: CallExec, Query: "TRUNCATE TABLE `a`", InTx: true},
+				{Kind: CallExec, Query: "SET FOREIGN_KEY_CHECKS = 1", InTx: true},
+				{Kind: CallRollback, InTx: true},
+			},
+			wantErr: errBoom,
+		},
+		{
+			name:   "mysql restore failure is reported",
+			driver: kdbx.DriverMySQL,
+			failOn: "SET FOREIGN_KEY_CHECKS = 1",
+			want: []Call{
+				{Kind: CallBegin},
+				{Kind: CallExec, Query: "SET FOREIGN_KEY_CHECKS = 0", InTx: true},
+				{Kind: CallExec, Query: "TRUNCATE TABLE `a`", InTx: true},
+				{Kind: CallExec, Query: "TRUNCATE TABLE `b`", InTx: true},
+				{Kind: CallExec, Query: "SET FOREIGN_KEY_CHECKS = 1", InTx: true},
+				{Kind: CallRollback, InTx: true},
+			},
+			wantErr: errBoom,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := NewFakeDB(tt.driver)
+			db.On("").ReturnResult(0, 0)
+			if tt.failOn != "" {
+				db.On(tt.failOn).ReturnError(errBoom)
+			}
+
+			err := truncate(context.Background(), db, []string{"a", "b"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got := db.Calls(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Calls()\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateListsTables(t *testing.T) {
+	db := NewFakeDB(kdbx.DriverPostgres)
+	db.On("").ReturnResult(0, 0)
+	db.On("FROM pg_tables").ReturnRows([]interface{}{"orders"}, []interface{}{"users"})
+
+	Truncate(t, db)
+
+	calls := db.Calls()
+	if len(calls) != 2 || calls[1].Query != `TRUNCATE TABLE "orders", "users" RESTART IDENTITY CASCADE` {
+		t.Errorf("Expected every listed table to be truncated, got %+v", calls)
+	}
+
+	db.Reset()
+	db.On("FROM pg_tables").ReturnRows()
+	Truncate(t, db)
+	if calls := db.Calls(); len(calls) != 1 {
+		t.Errorf("Expected no TRUNCATE without tables, got %+v", calls)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		open func(t *testing.T) kdbx.Database
+	}{
+		{
+			name: "postgres",
+			open: func(t *testing.T) kdbx.Database {
+				return NewPostgres(t, WithSchema(`
+					CREATE TABLE parents (id SERIAL PRIMARY KEY, name TEXT NOT NULL);
+					CREATE TABLE children (id SERIAL PRIMARY KEY, parent_id INT NOT NULL REFERENCES parents (id));
+				`))
+			},
+		},
+		{
+			name: "mysql",
+			open: func(t *testing.T) kdbx.Database {
+				return NewMySQL(t,
+					WithSchema(`
+						CREATE TABLE parents (id INT AUTO_INCREMENT PRIMARY KEY, name TEXT NOT NULL);
+						CREATE TABLE children (id INT AUTO_INCREMENT PRIMARY KEY, parent_id INT NOT NULL, FOREIGN KEY (parent_id) REFERENCES parents (id));
+					`),
+					// A single connection, so the session checked below is the one
+					// Truncate used.
+					WithDBOptions(kdbx.WithMaxOpenConns(1)),
+				)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := tt.open(t)
+			ctx := context.Background()
+
+			insert := func() {
+				t.Helper()
+				if _, err := db.Exec(ctx, "INSERT INTO parents (name) VALUES ('p')"); err != nil {
+					t.Fatalf("Insert parent: %v", err)
+				}
+				if _, err := db.Exec(ctx, "INSERT INTO children (parent_id) VALUES (1)"); err != nil {
+					t.Fatalf("Insert child: %v", err)
+				}
+			}
+			count := func(table string) int {
+				t.Helper()
+				var n int
+				if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM "+table).Scan(&n); err != nil {
+					t.Fatalf("Count %s: %v", table, err)
+				}
+				return n
+			}
+
+			insert()
+			Truncate(t, db)
+			if count("parents") != 0 || count("children") != 0 {
+				t.Fatal("Expected every table to be empty after Truncate")
+			}
+
+			// Identity counters restart, so the child can reference id 1 again.
+			insert()
+			Truncate(t, db, "parents", "children")
+			if count("parents") != 0 || count("children") != 0 {
+				t.Fatal("Expected the given tables to be empty after Truncate")
+			}
+
+			if err := truncate(ctx, db, []string{"parents", "missing"}); err == nil {
+				t.Fatal("Expected truncating a missing table to fail")
+			}
+			if db.Driver() == kdbx.DriverMySQL {
+				var checks int
+				if err := db.QueryRow(ctx, "SELECT @@SESSION.foreign_key_checks").Scan(&checks); err != nil {
+					t.Fatalf("Read foreign_key_checks: %v", err)
+				}
+				if checks != 1 {
+					t.Errorf("Expected foreign key checks to be restored after a failed TRUNCATE, got %d", checks)
+				}
+			}
+		})
+	}
+}
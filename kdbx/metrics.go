@@ -23,6 +23,9 @@ func (n *NoOpMetricsCollector) RecordTransaction(ctx context.Context, duration t
 func (n *NoOpMetricsCollector) RecordPoolStats(stats PoolStats) {
 }
 
+func (n *NoOpMetricsCollector) RecordRetryBudgetExhausted(err error) {
+}
+
 // LoggingMetricsCollector is a metrics collector that logs metrics using slog.
 type LoggingMetricsCollector struct {
 	logger *slog.Logger
@@ -89,6 +92,12 @@ func (l *LoggingMetricsCollector) RecordPoolStats(stats PoolStats) {
 	)
 }
 
+func (l *LoggingMetricsCollector) RecordRetryBudgetExhausted(err error) {
+	l.logger.Warn("retry budget exhausted",
+		slog.Any("error", err),
+	)
+}
+
 // InMemoryMetricsCollector collects metrics in memory for monitoring and debugging.
 // This is useful for development and testing but should not be used in production
 // for high-traffic applications due to memory usage.
@@ -116,6 +125,9 @@ type InMemoryMetricsCollector struct {
 	lastPoolStats PoolStats
 	poolStatsTime time.Time
 
+	// Retry budget
+	retryBudgetExhaustedCount int64
+
 	// Keep track of slow queries
 	slowQueries        []SlowQuery
 	slowQueryThreshold time.Duration
@@ -269,6 +281,13 @@ func (m *InMemoryMetricsCollector) RecordPoolStats(stats PoolStats) {
 	m.poolStatsTime = time.Now()
 }
 
+func (m *InMemoryMetricsCollector) RecordRetryBudgetExhausted(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retryBudgetExhaustedCount++
+}
+
 // Metrics returns a snapshot of collected metrics.
 func (m *InMemoryMetricsCollector) Metrics() *Metrics {
 	m.mu.RLock()
@@ -298,6 +317,8 @@ func (m *InMemoryMetricsCollector) Metrics() *Metrics {
 		PoolStats:          m.lastPoolStats,
 		PoolStatsTimestamp: m.poolStatsTime,
 		SlowQueryCount:     int64(len(m.slowQueries)),
+
+		RetryBudgetExhaustedCount: m.retryBudgetExhaustedCount,
 	}
 }
 
@@ -328,6 +349,7 @@ func (m *InMemoryMetricsCollector) Reset() {
 	m.txRollbackCount = 0
 	m.txErrorCount = 0
 	m.txDurations = m.txDurations[:0]
+	m.retryBudgetExhaustedCount = 0
 	m.slowQueries = m.slowQueries[:0]
 }
 
@@ -365,6 +387,9 @@ type Metrics struct {
 
 	// Slow queries
 	SlowQueryCount int64
+
+	// Retries skipped because the retry budget was exhausted
+	RetryBudgetExhaustedCount int64
 }
 
 // calculateAverage calculates the average duration.
@@ -455,7 +480,24 @@ func (c *CompositeMetricsCollector) RecordPoolStats(stats PoolStats) {
 	}
 }
 
+// RecordRetryBudgetExhausted forwards to collectors implementing RetryBudgetRecorder.
+func (c *CompositeMetricsCollector) RecordRetryBudgetExhausted(err error) {
+	for _, collector := range c.collectors {
+		if recorder, ok := collector.(RetryBudgetRecorder); ok {
+			recorder.RecordRetryBudgetExhausted(err)
+		}
+	}
+}
+
 // Add adds a metrics collector to the composite.
 func (c *CompositeMetricsCollector) Add(collector MetricsCollector) {
 	c.collectors = append(c.collectors, collector)
 }
+
+// Ensure optional interfaces are implemented at compile time.
+var (
+	_ RetryBudgetRecorder = (*NoOpMetricsCollector)(nil)
+	_ RetryBudgetRecorder = (*LoggingMetricsCollector)(nil)
+	_ RetryBudgetRecorder = (*InMemoryMetricsCollector)(nil)
+	_ RetryBudgetRecorder = (*CompositeMetricsCollector)(nil)
+)
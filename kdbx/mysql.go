@@ -6,19 +6,23 @@ import (
 	"fmt"
 	"log/slog"
 	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql" // MySQL driver
+
+	"github.com/karu-codes/karu-kits/internal/sqlkind"
 )
 
 // MySQLDB wraps a MySQL connection pool and provides database operations.
 type MySQLDB struct {
 	db *sql.DB
 
-	config  *Config
-	logger  *slog.Logger
-	metrics MetricsCollector
+	config   *Config
+	logger   *slog.Logger
+	metrics  MetricsCollector
+	observer *queryObserver
 
 	// Health check management
 	healthTicker *time.Ticker
@@ -27,6 +31,9 @@ type MySQLDB struct {
 	lastHealth   error
 	lastHealthAt time.Time
 
+	// Pool stats reporting; stopStats cancels the reporter and waits for it.
+	stopStats func()
+
 	closeOnce sync.Once
 }
 
@@ -72,10 +79,11 @@ func NewMySQL(ctx context.Context, config *Config) (*MySQLDB, error) {
 	}
 
 	mysqlDB := &MySQLDB{
-		db:      db,
-		config:  config,
-		logger:  config.Logger,
-		metrics: config.Metrics,
+		db:       db,
+		config:   config,
+		logger:   config.Logger,
+		metrics:  config.Metrics,
+		observer: newQueryObserver(config),
 	}
 
 	// Start background health checks if configured
@@ -83,6 +91,11 @@ func NewMySQL(ctx context.Context, config *Config) (*MySQLDB, error) {
 		mysqlDB.startHealthChecks()
 	}
 
+	// Start background pool stats reporting if configured
+	if config.PoolStatsInterval > 0 && config.Metrics != nil {
+		mysqlDB.startPoolStatsReporter()
+	}
+
 	if mysqlDB.logger != nil {
 		mysqlDB.logger.Info("MySQL connection established",
 			slog.String("url", config.MaskedURL()),
@@ -104,22 +117,32 @@ func (db *MySQLDB) Query(ctx context.Context, query string, args ...interface{})
 		)
 	}
 
-	rows, err := db.db.QueryContext(ctx, query, args...)
+	conn, release, err := db.acquire(ctx)
+	if err != nil {
+		return nil, WrapError(err, "failed to acquire connection")
+	}
 
-	duration := time.Since(start)
+	rows, err := conn.QueryContext(ctx, query, args...)
 
-	if db.metrics != nil {
-		db.metrics.RecordQuery(ctx, SanitizeQuery(query), duration, err)
-	}
+	db.observer.observe(ctx, queryKindQuery, QueryEvent{
+		SQL:          query,
+		Args:         args,
+		Duration:     time.Since(start),
+		RowsAffected: -1,
+		Err:          err,
+	})
 
 	if err != nil {
+		release()
 		return nil, WrapError(err, "query execution failed")
 	}
 
-	return &sqlRowsAdapter{rows: rows}, nil
+	return &sqlRowsAdapter{rows: rows, release: release}, nil
 }
 
 // QueryRow executes a query that is expected to return at most one row.
+// A connection pinned for the statement is released by Scan, or when ctx is
+// done if the row is never scanned.
 func (db *MySQLDB) QueryRow(ctx context.Context, query string, args ...interface{}) Row {
 	start := time.Now()
 
@@ -129,19 +152,32 @@ func (db *MySQLDB) QueryRow(ctx context.Context, query string, args ...interface
 		)
 	}
 
-	row := db.db.QueryRowContext(ctx, query, args...)
+	conn, release, err := db.acquire(ctx)
+	if err != nil {
+		return &errRow{err: WrapError(err, "failed to acquire connection")}
+	}
 
-	duration := time.Since(start)
+	row := conn.QueryRowContext(ctx, query, args...)
 
-	if db.metrics != nil {
-		db.metrics.RecordQuery(ctx, SanitizeQuery(query), duration, nil)
-	}
+	// database/sql runs the query eagerly; only sql.ErrNoRows is deferred to Scan.
+	rowErr := row.Err()
+	db.observer.observe(ctx, queryKindQuery, QueryEvent{
+		SQL:          query,
+		Args:         args,
+		Duration:     time.Since(start),
+		RowsAffected: -1,
+		Err:          rowErr,
+	})
 
-	return &sqlRowAdapter{row: row}
+	return &sqlRowAdapter{row: row, release: releaseOnDone(ctx, release)}
 }
 
 // Exec executes a query that doesn't return rows.
 func (db *MySQLDB) Exec(ctx context.Context, query string, args ...interface{}) (Result, error) {
+	if db.config.ReadOnly && sqlkind.IsWrite(query) {
+		return nil, ErrReadOnly
+	}
+
 	start := time.Now()
 
 	if db.config.LogQueries && db.logger != nil {
@@ -150,13 +186,27 @@ func (db *MySQLDB) Exec(ctx context.Context, query string, args ...interface{})
 		)
 	}
 
-	result, err := db.db.ExecContext(ctx, query, args...)
+	conn, release, err := db.acquire(ctx)
+	if err != nil {
+		return nil, WrapError(err, "failed to acquire connection")
+	}
 
-	duration := time.Since(start)
+	result, err := conn.ExecContext(ctx, query, args...)
+	release()
 
-	if db.metrics != nil {
-		db.metrics.RecordExec(ctx, SanitizeQuery(query), duration, err)
+	rowsAffected := int64(-1)
+	if err == nil {
+		if n, affectedErr := result.RowsAffected(); affectedErr == nil {
+			rowsAffected = n
+		}
 	}
+	db.observer.observe(ctx, queryKindExec, QueryEvent{
+		SQL:          query,
+		Args:         args,
+		Duration:     time.Since(start),
+		RowsAffected: rowsAffected,
+		Err:          err,
+	})
 
 	if err != nil {
 		return nil, WrapError(err, "exec execution failed")
@@ -165,9 +215,14 @@ func (db *MySQLDB) Exec(ctx context.Context, query string, args ...interface{})
 	return &sqlResultAdapter{result: result}, nil
 }
 
+// ExecScript executes a multi-statement SQL script.
+func (db *MySQLDB) ExecScript(ctx context.Context, script string, opts ...ScriptOption) error {
+	return execScript(ctx, db, script, opts)
+}
+
 // Begin starts a new transaction.
 func (db *MySQLDB) Begin(ctx context.Context) (Tx, error) {
-	tx, err := db.db.BeginTx(ctx, nil)
+	tx, err := db.db.BeginTx(ctx, txOptionsForConfig(db.config, nil))
 	if err != nil {
 		return nil, WrapError(err, "failed to begin transaction")
 	}
@@ -259,6 +314,11 @@ func (db *MySQLDB) Close() error {
 			db.healthTicker.Stop()
 		}
 
+		// Stop pool stats reporting before the pool goes away
+		if db.stopStats != nil {
+			db.stopStats()
+		}
+
 		// Close database connection
 		err = db.db.Close()
 
@@ -292,11 +352,141 @@ func (db *MySQLDB) Driver() Driver {
 	return DriverMySQL
 }
 
+// CancelQuery kills the statement currently running on the connection with the
+// given ID (see CONNECTION_ID()). The connection itself is left open.
+func (db *MySQLDB) CancelQuery(ctx context.Context, id int64) error {
+	// KILL does not accept placeholders; id is an integer so formatting is safe.
+	if _, err := db.db.ExecContext(ctx, "KILL QUERY "+strconv.FormatInt(id, 10)); err != nil {
+		return WrapError(err, "failed to cancel query")
+	}
+	return nil
+}
+
+// acquire returns the executor for a single statement and a release function
+// that must be called once the statement's results are consumed.
+//
+// The statement is pinned to one connection when:
+//   - CancelOnContextDone is enabled, so that KILL QUERY can be sent from
+//     another connection if ctx is done while the server is still executing;
+//   - ctx carries a per-call statement timeout, which is set on the session
+//     before the statement and restored on release.
+func (db *MySQLDB) acquire(ctx context.Context) (sqlQueryer, func(), error) {
+	timeout, hasTimeout := statementTimeoutFromContext(ctx)
+	if !db.config.CancelOnContextDone && !hasTimeout {
+		return db.db, func() {}, nil
+	}
+
+	conn, err := db.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resetTimeout := func() {}
+	if hasTimeout {
+		// SET does not accept placeholders; the value is an integer so formatting is safe.
+		if _, err := conn.ExecContext(ctx, "SET SESSION max_execution_time = "+strconv.FormatInt(timeoutMillis(timeout), 10)); err != nil {
+			_ = conn.Close()
+			return nil, nil, err
+		}
+		restore := "DEFAULT"
+		if db.config.StatementTimeout > 0 {
+			restore = strconv.FormatInt(timeoutMillis(db.config.StatementTimeout), 10)
+		}
+		resetTimeout = func() {
+			resetCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			if _, err := conn.ExecContext(resetCtx, "SET SESSION max_execution_time = "+restore); err != nil {
+				discardConn(conn)
+			}
+		}
+	}
+
+	if !db.config.CancelOnContextDone {
+		release := func() {
+			resetTimeout()
+			_ = conn.Close()
+		}
+		return conn, release, nil
+	}
+
+	var id int64
+	if err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&id); err != nil {
+		resetTimeout()
+		_ = conn.Close()
+		return nil, nil, err
+	}
+
+	killed := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(killed)
+
+		killCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		if err := db.CancelQuery(killCtx, id); err != nil && db.logger != nil {
+			db.logger.Warn("failed to cancel query after context done",
+				slog.Int64("connection_id", id),
+				slog.Any("error", err),
+			)
+		}
+	})
+
+	release := func() {
+		// Wait for an in-flight KILL so it cannot hit the next statement
+		// run on this connection after it returns to the pool.
+		if !stop() {
+			<-killed
+		}
+		resetTimeout()
+		_ = conn.Close()
+	}
+
+	return conn, release, nil
+}
+
 // DB returns the underlying *sql.DB for advanced usage.
 func (db *MySQLDB) DB() *sql.DB {
 	return db.db
 }
 
+// startPoolStatsReporter periodically reports Stats() to the metrics collector.
+func (db *MySQLDB) startPoolStatsReporter() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	db.stopStats = func() {
+		cancel()
+		<-done
+	}
+
+	ticker := time.NewTicker(db.config.PoolStatsInterval)
+
+	go func() {
+		defer close(done)
+		defer ticker.Stop()
+		defer func() {
+			if r := recover(); r != nil {
+				if db.logger != nil {
+					db.logger.Error("pool stats goroutine panic recovered",
+						slog.Any("panic", r))
+				}
+			}
+		}()
+
+		db.metrics.RecordPoolStats(db.Stats())
+
+		for {
+			select {
+			case <-ticker.C:
+				db.metrics.RecordPoolStats(db.Stats())
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
 // startHealthChecks starts background health checks.
 func (db *MySQLDB) startHealthChecks() {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -369,6 +559,16 @@ func buildMySQLDSN(config *Config) (string, error) {
 		params.Add("timeout", config.ConnectTimeout.String())
 	}
 
+	// Open every session read-only so the server rejects writes too
+	if config.ReadOnly {
+		params.Add("transaction_read_only", "1")
+	}
+
+	// Server-side statement timeout (milliseconds, applies to SELECT only)
+	if config.StatementTimeout > 0 {
+		params.Add("max_execution_time", strconv.FormatInt(timeoutMillis(config.StatementTimeout), 10))
+	}
+
 	// Set read timeout
 	if config.QueryTimeout > 0 {
 		params.Add("readTimeout", config.QueryTimeout.String())
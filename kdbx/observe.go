@@ -0,0 +1,110 @@
+package kdbx
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/karu-codes/karu-kits/internal/sqlkind"
+)
+
+// QueryEvent describes a completed statement.
+type QueryEvent struct {
+	// SQL is the statement text as sent to the driver.
+	SQL string
+
+	// Args are the statement arguments.
+	// Warning: they may contain sensitive data; never log them verbatim.
+	Args []interface{}
+
+	// Duration is the time spent executing the statement. For PostgreSQL queries
+	// returning rows it includes reading the rows, since the statement only
+	// completes when the rows are closed.
+	Duration time.Duration
+
+	// RowsAffected is the row count reported by the server, or -1 if unknown.
+	RowsAffected int64
+
+	// Err is the error returned by the statement, if any.
+	Err error
+}
+
+// QueryHook is called after every statement with its arguments and outcome.
+// Hooks run synchronously on the calling goroutine and must be fast and safe
+// for concurrent use.
+type QueryHook func(ctx context.Context, event QueryEvent)
+
+// queryKind selects the MetricsCollector method used for a statement.
+type queryKind int
+
+const (
+	queryKindQuery queryKind = iota
+	queryKindExec
+)
+
+// queryObserver records per-statement metrics, slow queries and hooks.
+// It is shared by the pgx tracer (PostgreSQL) and the MySQL methods.
+type queryObserver struct {
+	logger             *slog.Logger
+	metrics            MetricsCollector
+	slowQueryThreshold time.Duration
+	hooks              []QueryHook
+}
+
+func newQueryObserver(config *Config) *queryObserver {
+	return &queryObserver{
+		logger:             config.Logger,
+		metrics:            config.Metrics,
+		slowQueryThreshold: config.SlowQueryThreshold,
+		hooks:              config.QueryHooks,
+	}
+}
+
+// enabled reports whether observe has anything to do.
+func (o *queryObserver) enabled() bool {
+	return o.metrics != nil || len(o.hooks) > 0 || (o.slowQueryThreshold > 0 && o.logger != nil)
+}
+
+func (o *queryObserver) observe(ctx context.Context, kind queryKind, event QueryEvent) {
+	if o.metrics != nil {
+		if kind == queryKindExec {
+			o.metrics.RecordExec(ctx, SanitizeQuery(event.SQL), event.Duration, event.Err)
+		} else {
+			o.metrics.RecordQuery(ctx, SanitizeQuery(event.SQL), event.Duration, event.Err)
+		}
+	}
+
+	if o.slowQueryThreshold > 0 && event.Duration >= o.slowQueryThreshold && o.logger != nil {
+		o.logger.WarnContext(ctx, "slow query",
+			slog.String("query", SanitizeQuery(event.SQL)),
+			slog.Duration("duration", event.Duration),
+			slog.Duration("threshold", o.slowQueryThreshold),
+		)
+	}
+
+	for _, hook := range o.hooks {
+		hook(ctx, event)
+	}
+}
+
+// internalQueryKey marks statements issued by kdbx itself (session setup/reset)
+// so that they are not traced as user queries.
+type internalQueryKey struct{}
+
+func withInternalQuery(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalQueryKey{}, true)
+}
+
+func isInternalQuery(ctx context.Context) bool {
+	internal, _ := ctx.Value(internalQueryKey{}).(bool)
+	return internal
+}
+
+// classifyStatement returns the metrics kind for a statement based on its
+// leading keyword.
+func classifyStatement(query string) queryKind {
+	if sqlkind.IsQuery(query) {
+		return queryKindQuery
+	}
+	return queryKindExec
+}
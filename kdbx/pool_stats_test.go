@@ -0,0 +1,81 @@
+package kdbx
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+// blockingStatsCollector blocks in RecordPoolStats until release is closed.
+type blockingStatsCollector struct {
+	NoOpMetricsCollector
+	called  chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingStatsCollector) RecordPoolStats(PoolStats) {
+	select {
+	case c.called <- struct{}{}:
+	default:
+	}
+	<-c.release
+}
+
+func TestCloseWaitsForPoolStatsReporter(t *testing.T) {
+	tests := []struct {
+		name string
+		open func(t *testing.T, config *Config, metrics MetricsCollector) Database
+	}{
+		{
+			name: "postgres",
+			open: func(t *testing.T, config *Config, metrics MetricsCollector) Database {
+				stdDB, err := sql.Open("pgx", "postgres://localhost/test")
+				if err != nil {
+					t.Fatalf("sql.Open() error = %v", err)
+				}
+				db := &PostgresDB{stdDB: stdDB, config: config, metrics: metrics}
+				db.startPoolStatsReporter()
+				return db
+			},
+		},
+		{
+			name: "mysql",
+			open: func(t *testing.T, config *Config, metrics MetricsCollector) Database {
+				sqlDB, err := sql.Open("mysql", "test:test@tcp(localhost:3306)/test")
+				if err != nil {
+					t.Fatalf("sql.Open() error = %v", err)
+				}
+				db := &MySQLDB{db: sqlDB, config: config, metrics: metrics}
+				db.startPoolStatsReporter()
+				return db
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := &blockingStatsCollector{called: make(chan struct{}, 1), release: make(chan struct{})}
+			db := tt.open(t, &Config{PoolStatsInterval: time.Hour}, metrics)
+			<-metrics.called
+
+			closed := make(chan struct{})
+			go func() {
+				_ = db.Close()
+				close(closed)
+			}()
+
+			select {
+			case <-closed:
+				t.Fatal("Expected Close to wait for the pool stats reporter")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			close(metrics.release)
+			select {
+			case <-closed:
+			case <-time.After(time.Second):
+				t.Fatal("Expected Close to return once the reporter stopped")
+			}
+		})
+	}
+}
@@ -5,14 +5,23 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
 	"github.com/jackc/pgx/v5/pgxpool"
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/karu-codes/karu-kits/internal/sqlkind"
 )
 
+// cancelRequestDeadlineDelay bounds how long a connection waits for the server
+// to acknowledge a cancel request before the socket deadline fires.
+const cancelRequestDeadlineDelay = time.Second
+
 // PostgresDB wraps a PostgreSQL connection pool and provides database operations.
 type PostgresDB struct {
 	// Primary implementation using pgxpool for performance
@@ -32,6 +41,9 @@ type PostgresDB struct {
 	lastHealth   error
 	lastHealthAt time.Time
 
+	// Pool stats reporting; stopStats cancels the reporter and waits for it.
+	stopStats func()
+
 	closeOnce sync.Once
 }
 
@@ -60,22 +72,7 @@ func NewPostgres(ctx context.Context, config *Config) (*PostgresDB, error) {
 	poolConfig.MaxConnIdleTime = config.ConnMaxIdleTime
 	poolConfig.HealthCheckPeriod = config.HealthCheckInterval
 
-	// Configure connection timeout
-	if config.ConnectTimeout > 0 {
-		poolConfig.ConnConfig.ConnectTimeout = config.ConnectTimeout
-	}
-
-	// Configure simple protocol if requested
-	if config.PostgresPreferSimpleProtocol {
-		poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
-	}
-
-	// Configure query logging
-	if config.LogQueries && config.Logger != nil {
-		poolConfig.ConnConfig.Tracer = &queryTracer{
-			logger: config.Logger,
-		}
-	}
+	configureConnConfig(poolConfig.ConnConfig, config)
 
 	// Create connection pool
 	// Note: ConnectTimeout is already configured in poolConfig.ConnConfig.ConnectTimeout above,
@@ -103,6 +100,11 @@ func NewPostgres(ctx context.Context, config *Config) (*PostgresDB, error) {
 		db.startHealthChecks()
 	}
 
+	// Start background pool stats reporting if configured
+	if config.PoolStatsInterval > 0 && config.Metrics != nil {
+		db.startPoolStatsReporter()
+	}
+
 	if db.logger != nil {
 		db.logger.Info("PostgreSQL connection established",
 			slog.String("url", config.MaskedURL()),
@@ -125,15 +127,16 @@ func NewPostgresStd(ctx context.Context, config *Config) (*PostgresDB, error) {
 		return nil, ErrInvalidDriver
 	}
 
-	// Build connection string with options
-	connStr := config.DatabaseURL
-
-	// Register pgx as the driver
-	db, err := sql.Open("pgx/v5", connStr)
+	connConfig, err := pgx.ParseConfig(config.DatabaseURL)
 	if err != nil {
-		return nil, WrapError(err, "failed to open PostgreSQL connection")
+		return nil, WrapError(err, "failed to parse PostgreSQL connection URL")
 	}
 
+	configureConnConfig(connConfig, config)
+
+	// Register pgx as the driver
+	db := stdlib.OpenDB(*connConfig)
+
 	// Apply connection pool settings
 	db.SetMaxOpenConns(config.MaxOpenConns)
 	db.SetMaxIdleConns(config.MaxIdleConns)
@@ -165,6 +168,11 @@ func NewPostgresStd(ctx context.Context, config *Config) (*PostgresDB, error) {
 		pgdb.startHealthChecks()
 	}
 
+	// Start background pool stats reporting if configured
+	if config.PoolStatsInterval > 0 && config.Metrics != nil {
+		pgdb.startPoolStatsReporter()
+	}
+
 	if pgdb.logger != nil {
 		pgdb.logger.Info("PostgreSQL connection established (database/sql mode)",
 			slog.String("url", config.MaskedURL()),
@@ -175,10 +183,118 @@ func NewPostgresStd(ctx context.Context, config *Config) (*PostgresDB, error) {
 	return pgdb, nil
 }
 
+// configureConnConfig applies per-connection settings shared by the pgxpool and
+// database/sql modes.
+func configureConnConfig(connConfig *pgx.ConnConfig, config *Config) {
+	// Configure connection timeout
+	if config.ConnectTimeout > 0 {
+		connConfig.ConnectTimeout = config.ConnectTimeout
+	}
+
+	// Configure simple protocol if requested
+	if config.PostgresPreferSimpleProtocol {
+		connConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	}
+
+	// Apply the server-side statement timeout to every session.
+	if config.StatementTimeout > 0 {
+		connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(timeoutMillis(config.StatementTimeout), 10)
+	}
+
+	// Open every session read-only so the server rejects writes too.
+	if config.ReadOnly {
+		connConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	}
+
+	// Trace statements for logging, metrics, slow-query detection and hooks.
+	// The tracer sees every statement on the connection, including those run
+	// in transactions and via QueryRow.
+	if tracer := newQueryTracer(config); tracer != nil {
+		connConfig.Tracer = tracer
+	}
+
+	// Ask the server to cancel the running statement when the context is done.
+	// The default handler only sets a socket deadline, which leaves the query
+	// running on the server until it next tries to write to the client.
+	if config.CancelOnContextDone {
+		connConfig.BuildContextWatcherHandler = func(conn *pgconn.PgConn) ctxwatch.Handler {
+			return &pgconn.CancelRequestContextWatcherHandler{
+				Conn:          conn,
+				DeadlineDelay: cancelRequestDeadlineDelay,
+			}
+		}
+	}
+}
+
+// setStatementTimeoutSQL sets the session statement_timeout (in milliseconds).
+const setStatementTimeoutSQL = "SELECT set_config('statement_timeout', $1, false)"
+
+// acquirePool returns the pgx executor for a single statement and a release
+// function that must be called once the statement's results are consumed.
+// A per-call statement timeout pins the statement to one pooled connection.
+func (db *PostgresDB) acquirePool(ctx context.Context) (pgxQueryer, func(), error) {
+	timeout, ok := statementTimeoutFromContext(ctx)
+	if !ok {
+		return db.pool, func() {}, nil
+	}
+
+	conn, err := db.pool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := conn.Exec(withInternalQuery(ctx), setStatementTimeoutSQL, strconv.FormatInt(timeoutMillis(timeout), 10)); err != nil {
+		conn.Release()
+		return nil, nil, err
+	}
+
+	release := func() {
+		// ctx may already be done; the reset must still run so the override
+		// does not leak to the next user of this connection.
+		resetCtx, cancel := context.WithTimeout(withInternalQuery(context.Background()), 2*time.Second)
+		defer cancel()
+
+		if _, err := conn.Exec(resetCtx, "RESET statement_timeout"); err != nil {
+			_ = conn.Conn().Close(resetCtx)
+		}
+		conn.Release()
+	}
+
+	return conn, release, nil
+}
+
+// acquireStd is the database/sql counterpart of acquirePool.
+func (db *PostgresDB) acquireStd(ctx context.Context) (sqlQueryer, func(), error) {
+	timeout, ok := statementTimeoutFromContext(ctx)
+	if !ok {
+		return db.stdDB, func() {}, nil
+	}
+
+	conn, err := db.stdDB.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := conn.ExecContext(withInternalQuery(ctx), setStatementTimeoutSQL, strconv.FormatInt(timeoutMillis(timeout), 10)); err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+
+	release := func() {
+		resetCtx, cancel := context.WithTimeout(withInternalQuery(context.Background()), 2*time.Second)
+		defer cancel()
+
+		if _, err := conn.ExecContext(resetCtx, "RESET statement_timeout"); err != nil {
+			discardConn(conn)
+		}
+		_ = conn.Close()
+	}
+
+	return conn, release, nil
+}
+
 // Query executes a query that returns rows.
 func (db *PostgresDB) Query(ctx context.Context, query string, args ...interface{}) (Rows, error) {
-	start := time.Now()
-
 	if db.config.LogQueries && db.logger != nil {
 		db.logger.Debug("executing query",
 			slog.String("query", SanitizeQuery(query)),
@@ -189,25 +305,32 @@ func (db *PostgresDB) Query(ctx context.Context, query string, args ...interface
 	var err error
 
 	if db.pool != nil {
-		pgxRows, queryErr := db.pool.Query(ctx, query, args...)
+		conn, release, acquireErr := db.acquirePool(ctx)
+		if acquireErr != nil {
+			return nil, WrapError(acquireErr, "failed to acquire connection")
+		}
+		pgxRows, queryErr := conn.Query(ctx, query, args...)
 		err = queryErr
 		if err == nil {
-			rows = &pgxRowsAdapter{rows: pgxRows}
+			rows = &pgxRowsAdapter{rows: pgxRows, release: release}
+		} else {
+			release()
 		}
 	} else {
-		sqlRows, queryErr := db.stdDB.QueryContext(ctx, query, args...)
+		conn, release, acquireErr := db.acquireStd(ctx)
+		if acquireErr != nil {
+			return nil, WrapError(acquireErr, "failed to acquire connection")
+		}
+		sqlRows, queryErr := conn.QueryContext(ctx, query, args...)
 		err = queryErr
 		if err == nil {
-			rows = &sqlRowsAdapter{rows: sqlRows}
+			rows = &sqlRowsAdapter{rows: sqlRows, release: release}
+		} else {
+			release()
 		}
 	}
 
-	duration := time.Since(start)
-
-	if db.metrics != nil {
-		db.metrics.RecordQuery(ctx, SanitizeQuery(query), duration, err)
-	}
-
+	// Duration and metrics are recorded by the query tracer.
 	if err != nil {
 		return nil, WrapError(err, "query execution failed")
 	}
@@ -216,9 +339,7 @@ func (db *PostgresDB) Query(ctx context.Context, query string, args ...interface
 }
 
 // QueryRow executes a query that is expected to return at most one row.
-// Note: Metrics are not recorded for QueryRow since the actual query execution
-// happens lazily when Scan() is called, making it impossible to accurately
-// measure duration or capture errors at this point.
+// Metrics are recorded by the query tracer once the row has been scanned.
 func (db *PostgresDB) QueryRow(ctx context.Context, query string, args ...interface{}) Row {
 	if db.config.LogQueries && db.logger != nil {
 		db.logger.Debug("executing query row",
@@ -229,19 +350,35 @@ func (db *PostgresDB) QueryRow(ctx context.Context, query string, args ...interf
 	var row Row
 
 	if db.pool != nil {
-		pgxRow := db.pool.QueryRow(ctx, query, args...)
-		row = &pgxRowAdapter{row: pgxRow}
+		conn, release, err := db.acquirePool(ctx)
+		if err != nil {
+			return &errRow{err: WrapError(err, "failed to acquire connection")}
+		}
+		row = queryRowPgx(ctx, conn, release, query, args...)
 	} else {
-		sqlRow := db.stdDB.QueryRowContext(ctx, query, args...)
-		row = &sqlRowAdapter{row: sqlRow}
+		conn, release, err := db.acquireStd(ctx)
+		if err != nil {
+			return &errRow{err: WrapError(err, "failed to acquire connection")}
+		}
+		sqlRow := conn.QueryRowContext(ctx, query, args...)
+		row = &sqlRowAdapter{row: sqlRow, release: releaseOnDone(ctx, release)}
 	}
 
 	return row
 }
 
+// queryRowPgx runs QueryRow on conn. release, which returns a connection pinned
+// by acquirePool, runs after Scan or when ctx is done, so a Row that is never
+// scanned does not keep the connection and its statement timeout.
+func queryRowPgx(ctx context.Context, conn pgxQueryer, release func(), query string, args ...interface{}) Row {
+	return &pgxRowAdapter{row: conn.QueryRow(ctx, query, args...), release: releaseOnDone(ctx, release)}
+}
+
 // Exec executes a query that doesn't return rows.
 func (db *PostgresDB) Exec(ctx context.Context, query string, args ...interface{}) (Result, error) {
-	start := time.Now()
+	if db.config.ReadOnly && sqlkind.IsWrite(query) {
+		return nil, ErrReadOnly
+	}
 
 	if db.config.LogQueries && db.logger != nil {
 		db.logger.Debug("executing exec",
@@ -253,25 +390,30 @@ func (db *PostgresDB) Exec(ctx context.Context, query string, args ...interface{
 	var err error
 
 	if db.pool != nil {
-		tag, execErr := db.pool.Exec(ctx, query, args...)
+		conn, release, acquireErr := db.acquirePool(ctx)
+		if acquireErr != nil {
+			return nil, WrapError(acquireErr, "failed to acquire connection")
+		}
+		tag, execErr := conn.Exec(ctx, query, args...)
+		release()
 		err = execErr
 		if err == nil {
 			result = &pgxCommandTagAdapter{tag: tag}
 		}
 	} else {
-		sqlResult, execErr := db.stdDB.ExecContext(ctx, query, args...)
+		conn, release, acquireErr := db.acquireStd(ctx)
+		if acquireErr != nil {
+			return nil, WrapError(acquireErr, "failed to acquire connection")
+		}
+		sqlResult, execErr := conn.ExecContext(ctx, query, args...)
+		release()
 		err = execErr
 		if err == nil {
 			result = &sqlResultAdapter{result: sqlResult}
 		}
 	}
 
-	duration := time.Since(start)
-
-	if db.metrics != nil {
-		db.metrics.RecordExec(ctx, SanitizeQuery(query), duration, err)
-	}
-
+	// Duration and metrics are recorded by the query tracer.
 	if err != nil {
 		return nil, WrapError(err, "exec execution failed")
 	}
@@ -279,6 +421,11 @@ func (db *PostgresDB) Exec(ctx context.Context, query string, args ...interface{
 	return result, nil
 }
 
+// ExecScript executes a multi-statement SQL script.
+func (db *PostgresDB) ExecScript(ctx context.Context, script string, opts ...ScriptOption) error {
+	return execScript(ctx, db, script, opts)
+}
+
 // Begin starts a new transaction with default isolation level.
 func (db *PostgresDB) Begin(ctx context.Context) (Tx, error) {
 	return db.BeginTx(ctx, nil)
@@ -286,6 +433,8 @@ func (db *PostgresDB) Begin(ctx context.Context) (Tx, error) {
 
 // BeginTx starts a new transaction with custom options.
 func (db *PostgresDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	opts = txOptionsForConfig(db.config, opts)
+
 	if db.pool != nil {
 		// For pgxpool, convert sql.TxOptions to pgx.TxOptions
 		pgxOpts := pgx.TxOptions{}
@@ -309,6 +458,19 @@ func (db *PostgresDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, err
 	return &sqlTxAdapter{tx: tx, logger: db.logger, config: db.config}, nil
 }
 
+// txOptionsForConfig forces read-only transactions when Config.ReadOnly is set.
+func txOptionsForConfig(config *Config, opts *sql.TxOptions) *sql.TxOptions {
+	if !config.ReadOnly || (opts != nil && opts.ReadOnly) {
+		return opts
+	}
+
+	readOnly := sql.TxOptions{ReadOnly: true}
+	if opts != nil {
+		readOnly.Isolation = opts.Isolation
+	}
+	return &readOnly
+}
+
 // convertIsolationLevel converts sql.IsolationLevel to pgx transaction isolation level.
 func convertIsolationLevel(level sql.IsolationLevel) pgx.TxIsoLevel {
 	switch level {
@@ -443,6 +605,11 @@ func (db *PostgresDB) Close() error {
 			db.healthTicker.Stop()
 		}
 
+		// Stop pool stats reporting before the pool goes away
+		if db.stopStats != nil {
+			db.stopStats()
+		}
+
 		// Close connection pool
 		if db.pool != nil {
 			db.pool.Close()
@@ -480,6 +647,25 @@ func (db *PostgresDB) Driver() Driver {
 	return DriverPostgres
 }
 
+// CancelQuery asks the server to cancel the statement currently running on the
+// backend with the given process ID (see pg_backend_pid()).
+// Returns a CodeNotFound error if no such backend exists.
+func (db *PostgresDB) CancelQuery(ctx context.Context, pid int64) error {
+	var cancelled bool
+	if err := db.QueryRow(ctx, "SELECT pg_cancel_backend($1)", pid).Scan(&cancelled); err != nil {
+		return WrapError(err, "failed to cancel query")
+	}
+
+	if !cancelled {
+		return &DatabaseError{
+			Code:    CodeNotFound,
+			Message: fmt.Sprintf("backend process %d not found", pid),
+		}
+	}
+
+	return nil
+}
+
 // Pool returns the underlying pgxpool.Pool for advanced usage.
 // Returns nil if using database/sql mode.
 func (db *PostgresDB) Pool() *pgxpool.Pool {
@@ -492,6 +678,43 @@ func (db *PostgresDB) DB() *sql.DB {
 	return db.stdDB
 }
 
+// startPoolStatsReporter periodically reports Stats() to the metrics collector.
+func (db *PostgresDB) startPoolStatsReporter() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	db.stopStats = func() {
+		cancel()
+		<-done
+	}
+
+	ticker := time.NewTicker(db.config.PoolStatsInterval)
+
+	go func() {
+		defer close(done)
+		defer ticker.Stop()
+		defer func() {
+			if r := recover(); r != nil {
+				if db.logger != nil {
+					db.logger.Error("pool stats goroutine panic recovered",
+						slog.Any("panic", r))
+				}
+			}
+		}()
+
+		db.metrics.RecordPoolStats(db.Stats())
+
+		for {
+			select {
+			case <-ticker.C:
+				db.metrics.RecordPoolStats(db.Stats())
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
 // startHealthChecks starts background health checks.
 func (db *PostgresDB) startHealthChecks() {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -570,6 +793,10 @@ func (t *pgxTxAdapter) QueryRow(ctx context.Context, query string, args ...inter
 }
 
 func (t *pgxTxAdapter) Exec(ctx context.Context, query string, args ...interface{}) (Result, error) {
+	if t.config.ReadOnly && sqlkind.IsWrite(query) {
+		return nil, ErrReadOnly
+	}
+
 	if t.config.LogQueries && t.logger != nil {
 		t.logger.Debug("executing exec in transaction",
 			slog.String("query", SanitizeQuery(query)),
@@ -627,6 +854,10 @@ func (t *sqlTxAdapter) QueryRow(ctx context.Context, query string, args ...inter
 }
 
 func (t *sqlTxAdapter) Exec(ctx context.Context, query string, args ...interface{}) (Result, error) {
+	if t.config.ReadOnly && sqlkind.IsWrite(query) {
+		return nil, ErrReadOnly
+	}
+
 	if t.config.LogQueries && t.logger != nil {
 		t.logger.Debug("executing exec in transaction",
 			slog.String("query", SanitizeQuery(query)),
@@ -670,6 +901,10 @@ func (r *pgxRowsAdapter) Scan(dest ...interface{}) error {
 
 func (r *pgxRowsAdapter) Close() error {
 	r.rows.Close()
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
 	return nil
 }
 
@@ -681,7 +916,12 @@ func (r *pgxRowsAdapter) Err() error {
 }
 
 func (r *pgxRowAdapter) Scan(dest ...interface{}) error {
-	if err := r.row.Scan(dest...); err != nil {
+	err := r.row.Scan(dest...)
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
+	if err != nil {
 		return WrapError(err, "failed to scan row")
 	}
 	return nil
@@ -712,7 +952,12 @@ func (r *sqlRowsAdapter) Scan(dest ...interface{}) error {
 }
 
 func (r *sqlRowsAdapter) Close() error {
-	return r.rows.Close()
+	err := r.rows.Close()
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
+	return err
 }
 
 func (r *sqlRowsAdapter) Err() error {
@@ -723,12 +968,21 @@ func (r *sqlRowsAdapter) Err() error {
 }
 
 func (r *sqlRowAdapter) Scan(dest ...interface{}) error {
-	if err := r.row.Scan(dest...); err != nil {
+	err := r.row.Scan(dest...)
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
+	if err != nil {
 		return WrapError(err, "failed to scan row")
 	}
 	return nil
 }
 
+func (r *errRow) Scan(dest ...interface{}) error {
+	return r.err
+}
+
 func (r *sqlResultAdapter) LastInsertId() (int64, error) {
 	return r.result.LastInsertId()
 }
@@ -737,23 +991,93 @@ func (r *sqlResultAdapter) RowsAffected() (int64, error) {
 	return r.result.RowsAffected()
 }
 
-// queryTracer implements pgx.QueryTracer for query logging.
+// queryTracer implements pgx.QueryTracer for query logging, metrics,
+// slow-query detection and query hooks.
 type queryTracer struct {
-	logger *slog.Logger
+	logger     *slog.Logger
+	logQueries bool
+	observer   *queryObserver
+}
+
+// traceQueryKey is the context key for the state of an in-flight traced statement.
+type traceQueryKey struct{}
+
+type traceQueryData struct {
+	sql   string
+	args  []interface{}
+	start time.Time
+}
+
+// newQueryTracer returns nil when there is nothing to trace.
+func newQueryTracer(config *Config) *queryTracer {
+	t := &queryTracer{
+		logger:     config.Logger,
+		logQueries: config.LogQueries && config.Logger != nil,
+		observer:   newQueryObserver(config),
+	}
+	if !t.logQueries && !t.observer.enabled() {
+		return nil
+	}
+	return t
 }
 
 func (t *queryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
-	t.logger.Debug("query started",
-		slog.String("sql", SanitizeQuery(data.SQL)),
-	)
-	return ctx
+	if isInternalQuery(ctx) {
+		return ctx
+	}
+
+	if t.logQueries {
+		t.logger.Debug("query started",
+			slog.String("sql", SanitizeQuery(data.SQL)),
+		)
+	}
+
+	return context.WithValue(ctx, traceQueryKey{}, &traceQueryData{
+		sql:   data.SQL,
+		args:  data.Args,
+		start: time.Now(),
+	})
 }
 
 func (t *queryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
-	if data.Err != nil {
-		t.logger.Error("query failed",
-			slog.String("sql", SanitizeQuery(data.CommandTag.String())),
-			slog.Any("error", data.Err),
-		)
+	query, ok := ctx.Value(traceQueryKey{}).(*traceQueryData)
+	if !ok {
+		return
+	}
+
+	duration := time.Since(query.start)
+
+	if t.logQueries {
+		if data.Err != nil {
+			t.logger.Error("query failed",
+				slog.String("sql", SanitizeQuery(query.sql)),
+				slog.Duration("duration", duration),
+				slog.Any("error", data.Err),
+			)
+		} else {
+			t.logger.Debug("query finished",
+				slog.String("sql", SanitizeQuery(query.sql)),
+				slog.Duration("duration", duration),
+				slog.Int64("rows_affected", data.CommandTag.RowsAffected()),
+			)
+		}
+	}
+
+	kind := classifyStatement(query.sql)
+	if data.CommandTag.Select() {
+		kind = queryKindQuery
+	}
+
+	rowsAffected := int64(-1)
+	if data.Err == nil {
+		rowsAffected = data.CommandTag.RowsAffected()
 	}
+
+	t.observer.observe(ctx, kind, QueryEvent{
+		SQL:          query.sql,
+		Args:         query.args,
+		Duration:     duration,
+		RowsAffected: rowsAffected,
+		Err:          data.Err,
+	})
 }
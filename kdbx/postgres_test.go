@@ -0,0 +1,39 @@
+package kdbx
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestTxOptionsForConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		readOnly bool
+		opts     *sql.TxOptions
+		want     *sql.TxOptions
+	}{
+		{"read-write nil options", false, nil, nil},
+		{"read-write options kept", false, &sql.TxOptions{Isolation: sql.LevelSerializable}, &sql.TxOptions{Isolation: sql.LevelSerializable}},
+		{"read-only nil options", true, nil, &sql.TxOptions{ReadOnly: true}},
+		{"read-only keeps isolation", true, &sql.TxOptions{Isolation: sql.LevelRepeatableRead}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}},
+		{"read-only options kept", true, &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}, &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var original sql.TxOptions
+			if tt.opts != nil {
+				original = *tt.opts
+			}
+
+			got := txOptionsForConfig(&Config{ReadOnly: tt.readOnly}, tt.opts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("txOptionsForConfig() = %+v, want %+v", got, tt.want)
+			}
+			if tt.opts != nil && *tt.opts != original {
+				t.Errorf("Expected the caller's options not to be modified, got %+v", *tt.opts)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package kdbx
+
+import (
+	"sync"
+	"time"
+)
+
+// RetryBudget is a token bucket that caps how many automatic retries may be
+// performed, so that retries do not multiply load on a struggling database.
+//
+// A budget is safe for concurrent use and is meant to be shared: set the same
+// *RetryBudget on every Config that should draw from it. Each retry (not the
+// first attempt) consumes one token.
+type RetryBudget struct {
+	mu       sync.Mutex
+	rate     float64 // tokens added per second
+	burst    float64 // maximum tokens
+	tokens   float64
+	lastFill time.Time
+}
+
+// NewRetryBudget creates a retry budget that allows ratePerSecond retries on
+// average, with bursts of up to burst retries. The bucket starts full.
+// A burst below 1 is treated as 1.
+func NewRetryBudget(ratePerSecond float64, burst int) *RetryBudget {
+	if ratePerSecond < 0 {
+		ratePerSecond = 0
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RetryBudget{
+		rate:     ratePerSecond,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		lastFill: time.Now(),
+	}
+}
+
+// Allow consumes a token and reports whether a retry may be performed.
+func (b *RetryBudget) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Available returns the number of whole retries currently available.
+func (b *RetryBudget) Available() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	return int(b.tokens)
+}
+
+// refill adds tokens for the time elapsed since the last refill.
+// Must be called with b.mu held.
+func (b *RetryBudget) refill() {
+	now := time.Now()
+	elapsed := now.Sub(b.lastFill).Seconds()
+	b.lastFill = now
+
+	if elapsed <= 0 {
+		return
+	}
+
+	b.tokens += elapsed * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// RetryBudgetRecorder is an optional interface for MetricsCollector
+// implementations that want to count retries rejected by a RetryBudget.
+// Collectors that do not implement it are simply not notified.
+type RetryBudgetRecorder interface {
+	// RecordRetryBudgetExhausted is called when a retry is skipped because the
+	// retry budget has no tokens left.
+	RecordRetryBudgetExhausted(err error)
+}
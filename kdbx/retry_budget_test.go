@@ -0,0 +1,101 @@
+package kdbx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		rate      float64
+		burst     int
+		consume   int
+		elapsed   time.Duration
+		available int
+	}{
+		{"starts full", 1, 3, 0, 0, 3},
+		{"burst below 1 is 1", 1, 0, 0, 0, 1},
+		{"consumes tokens", 1, 3, 2, 0, 1},
+		{"refills over time", 2, 10, 10, 1500 * time.Millisecond, 3},
+		{"partial token not available", 1, 10, 10, 500 * time.Millisecond, 0},
+		{"refill capped at burst", 100, 5, 5, 10 * time.Second, 5},
+		{"zero rate never refills", 0, 2, 2, time.Hour, 0},
+		{"negative rate is zero", -5, 1, 1, time.Hour, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewRetryBudget(tt.rate, tt.burst)
+			for i := 0; i < tt.consume; i++ {
+				if !b.Allow() {
+					t.Fatalf("Expected token %d to be available", i+1)
+				}
+			}
+			b.lastFill = b.lastFill.Add(-tt.elapsed)
+
+			if got := b.Available(); got != tt.available {
+				t.Errorf("Available() = %d, want %d", got, tt.available)
+			}
+			if got := b.Allow(); got != (tt.available > 0) {
+				t.Errorf("Allow() = %v, want %v", got, tt.available > 0)
+			}
+		})
+	}
+}
+
+func TestWithRetryBudget(t *testing.T) {
+	transient := &DatabaseError{Code: CodeUnavailable, Message: "connection reset"}
+
+	tests := []struct {
+		name      string
+		budget    *RetryBudget
+		wantCalls int
+		exhausted bool
+	}{
+		{"nil budget is unlimited", nil, 4, false},
+		{"budget allows every retry", NewRetryBudget(0, 10), 4, false},
+		{"budget allows one retry", NewRetryBudget(0, 1), 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := NewInMemoryMetricsCollector(time.Second)
+			config := &Config{
+				RetryAttempts:       3,
+				RetryInitialBackoff: time.Millisecond,
+				RetryMaxBackoff:     time.Millisecond,
+				RetryBudget:         tt.budget,
+				Metrics:             metrics,
+			}
+
+			calls := 0
+			err := withRetry(context.Background(), config, func(context.Context) error {
+				calls++
+				return transient
+			})
+
+			if calls != tt.wantCalls {
+				t.Errorf("Expected %d attempts, got %d", tt.wantCalls, calls)
+			}
+			if got := IsRetryBudgetExhausted(err); got != tt.exhausted {
+				t.Errorf("IsRetryBudgetExhausted(%v) = %v, want %v", err, got, tt.exhausted)
+			}
+			if tt.exhausted {
+				if !errors.Is(err, ErrRetryBudgetExhausted) || !errors.Is(errors.Unwrap(err), transient) {
+					t.Errorf("Expected ErrRetryBudgetExhausted wrapping the last error, got %v", err)
+				}
+			}
+
+			want := int64(0)
+			if tt.exhausted {
+				want = 1
+			}
+			if got := metrics.Metrics().RetryBudgetExhaustedCount; got != want {
+				t.Errorf("RetryBudgetExhaustedCount = %d, want %d", got, want)
+			}
+		})
+	}
+}
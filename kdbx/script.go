@@ -0,0 +1,338 @@
+package kdbx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ScriptStatement is a single statement extracted from a SQL script.
+type ScriptStatement struct {
+	// SQL is the statement text without the trailing semicolon.
+	SQL string
+
+	// Line is the 1-based line in the script where the statement starts.
+	Line int
+}
+
+// ScriptError reports which statement of a script failed.
+type ScriptError struct {
+	// Index is the 0-based position of the failed statement in the script.
+	Index int
+
+	// Line is the 1-based line in the script where the statement starts.
+	Line int
+
+	// Statement is the text of the failed statement.
+	Statement string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("script statement %d (line %d) failed: %v", e.Index+1, e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error, so IsUniqueViolation, IsSyntaxError etc.
+// work on script errors.
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// ScriptOption configures ExecScript.
+type ScriptOption func(*scriptOptions)
+
+type scriptOptions struct {
+	inTransaction bool
+}
+
+// WithScriptTransaction runs the whole script in a single transaction, so either
+// every statement is applied or none is.
+//
+// Note: MySQL implicitly commits around most DDL statements, so a script mixing DDL
+// and DML is only partially rolled back on failure.
+func WithScriptTransaction() ScriptOption {
+	return func(o *scriptOptions) {
+		o.inTransaction = true
+	}
+}
+
+// execer is implemented by both Database and Tx.
+type execer interface {
+	Exec(ctx context.Context, query string, args ...interface{}) (Result, error)
+}
+
+// execScript splits script and executes its statements sequentially on db.
+func execScript(ctx context.Context, db Database, script string, opts []ScriptOption) error {
+	var o scriptOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	statements := SplitScript(db.Driver(), script)
+	if len(statements) == 0 {
+		return nil
+	}
+
+	if !o.inTransaction {
+		return execStatements(ctx, db, statements)
+	}
+
+	return db.WithTransaction(ctx, func(tx Tx) error {
+		return execStatements(ctx, tx, statements)
+	})
+}
+
+func execStatements(ctx context.Context, e execer, statements []ScriptStatement) error {
+	for i, stmt := range statements {
+		if _, err := e.Exec(ctx, stmt.SQL); err != nil {
+			return &ScriptError{
+				Index:     i,
+				Line:      stmt.Line,
+				Statement: stmt.SQL,
+				Err:       err,
+			}
+		}
+	}
+	return nil
+}
+
+// SplitScript splits a SQL script into statements on top-level semicolons.
+//
+// Semicolons inside string literals, quoted identifiers, comments and (PostgreSQL)
+// dollar-quoted bodies do not end a statement. Statements that contain only
+// whitespace or comments are dropped.
+//
+// Client-side directives such as the MySQL DELIMITER command or psql
+// meta-commands are not supported.
+func SplitScript(driver Driver, script string) []ScriptStatement {
+	s := scriptSplitter{
+		src:   script,
+		mysql: driver == DriverMySQL,
+		line:  1,
+	}
+	return s.split()
+}
+
+// scriptSplitter is a single-pass lexer that only understands enough SQL to find
+// statement boundaries.
+type scriptSplitter struct {
+	src   string
+	mysql bool
+
+	pos  int
+	line int
+
+	// Current statement state; start is only valid once hasContent is set.
+	start      int
+	startLine  int
+	hasContent bool
+
+	statements []ScriptStatement
+}
+
+func (s *scriptSplitter) split() []ScriptStatement {
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+
+		switch {
+		case c == ';':
+			s.emit(s.pos)
+			s.pos++
+			continue
+
+		case c == '-' && s.peek(1) == '-' && (!s.mysql || isSpaceOrEnd(s.peek(2))):
+			s.skipLineComment()
+			continue
+
+		case c == '#' && s.mysql:
+			s.skipLineComment()
+			continue
+
+		case c == '/' && s.peek(1) == '*':
+			// MySQL executes /*! ... */ comments (e.g. in mysqldump output).
+			if s.mysql && s.peek(2) == '!' {
+				s.markContent()
+			}
+			s.skipBlockComment()
+			continue
+
+		case c == '\n', c == ' ', c == '\t', c == '\r', c == '\f', c == '\v':
+			s.advance()
+			continue
+		}
+
+		s.markContent()
+
+		switch {
+		case c == '\'':
+			s.skipQuoted('\'', s.mysql || s.isEscapeString())
+
+		case c == '"':
+			// MySQL treats double quotes as strings (unless ANSI_QUOTES is set);
+			// PostgreSQL treats them as identifiers.
+			s.skipQuoted('"', s.mysql)
+
+		case c == '`' && s.mysql:
+			s.skipQuoted('`', false)
+
+		case c == '$' && !s.mysql:
+			if tag, ok := s.dollarTag(); ok {
+				s.skipDollarQuoted(tag)
+			} else {
+				s.advance()
+			}
+
+		default:
+			s.advance()
+		}
+	}
+
+	s.emit(len(s.src))
+	return s.statements
+}
+
+// emit records the statement ending at end if it has any content.
+func (s *scriptSplitter) emit(end int) {
+	if s.hasContent {
+		sql := strings.TrimSpace(s.src[s.start:end])
+		s.statements = append(s.statements, ScriptStatement{SQL: sql, Line: s.startLine})
+	}
+	s.hasContent = false
+}
+
+// markContent records that the current statement starts at the current position;
+// leading comments are not included in the statement text.
+func (s *scriptSplitter) markContent() {
+	if !s.hasContent {
+		s.hasContent = true
+		s.start = s.pos
+		s.startLine = s.line
+	}
+}
+
+func (s *scriptSplitter) peek(offset int) byte {
+	if s.pos+offset < len(s.src) {
+		return s.src[s.pos+offset]
+	}
+	return 0
+}
+
+func (s *scriptSplitter) advance() {
+	if s.src[s.pos] == '\n' {
+		s.line++
+	}
+	s.pos++
+}
+
+func (s *scriptSplitter) skipLineComment() {
+	for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+		s.pos++
+	}
+}
+
+// skipBlockComment skips a /* ... */ comment. PostgreSQL allows nesting; MySQL does not.
+func (s *scriptSplitter) skipBlockComment() {
+	depth := 0
+	for s.pos < len(s.src) {
+		switch {
+		case s.src[s.pos] == '/' && s.peek(1) == '*':
+			if depth == 0 || !s.mysql {
+				depth++
+			}
+			s.pos += 2
+		case s.src[s.pos] == '*' && s.peek(1) == '/':
+			depth--
+			s.pos += 2
+			if depth == 0 {
+				return
+			}
+		default:
+			s.advance()
+		}
+	}
+}
+
+// skipQuoted skips a quoted literal or identifier starting at the current quote.
+// A doubled quote is always an escaped quote; backslash escapes are honoured
+// when backslash is true.
+func (s *scriptSplitter) skipQuoted(quote byte, backslash bool) {
+	s.pos++ // opening quote
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+		switch {
+		case backslash && c == '\\':
+			s.pos++
+			if s.pos < len(s.src) {
+				s.advance()
+			}
+		case c == quote:
+			s.pos++
+			if s.pos < len(s.src) && s.src[s.pos] == quote {
+				s.pos++
+				continue
+			}
+			return
+		default:
+			s.advance()
+		}
+	}
+}
+
+// isEscapeString reports whether the quote at the current position opens a
+// PostgreSQL E'...' string, in which backslash escapes apply.
+func (s *scriptSplitter) isEscapeString() bool {
+	if s.pos == 0 {
+		return false
+	}
+	prev := s.src[s.pos-1]
+	if prev != 'E' && prev != 'e' {
+		return false
+	}
+	return s.pos < 2 || !isIdentChar(s.src[s.pos-2])
+}
+
+// dollarTag returns the $tag$ delimiter starting at the current position, if any.
+// A dollar sign that continues an identifier or starts a positional parameter ($1)
+// is not a delimiter.
+func (s *scriptSplitter) dollarTag() (string, bool) {
+	if s.pos > 0 && isIdentChar(s.src[s.pos-1]) {
+		return "", false
+	}
+
+	end := s.pos + 1
+	for end < len(s.src) && s.src[end] != '$' {
+		c := s.src[end]
+		if !isIdentChar(c) || (end == s.pos+1 && c >= '0' && c <= '9') {
+			return "", false
+		}
+		end++
+	}
+	if end >= len(s.src) {
+		return "", false
+	}
+
+	return s.src[s.pos : end+1], true
+}
+
+func (s *scriptSplitter) skipDollarQuoted(tag string) {
+	s.pos += len(tag)
+	for s.pos < len(s.src) {
+		if strings.HasPrefix(s.src[s.pos:], tag) {
+			s.pos += len(tag)
+			return
+		}
+		s.advance()
+	}
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isSpaceOrEnd(c byte) bool {
+	return c == 0 || c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
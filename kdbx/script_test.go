@@ -0,0 +1,146 @@
+package kdbx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSplitScript(t *testing.T) {
+	type stmt = ScriptStatement
+
+	tests := []struct {
+		name   string
+		driver Driver
+		script string
+		want   []ScriptStatement
+	}{
+		// Boundaries and line numbers
+		{"empty", DriverPostgres, "", nil},
+		{"whitespace only", DriverPostgres, " \n\t\r\n  ", nil},
+		{"comments only", DriverPostgres, "-- header\n/* block */\n", nil},
+		{"empty statements", DriverPostgres, ";; SELECT 1;;", []stmt{{"SELECT 1", 1}}},
+		{"no trailing semicolon", DriverPostgres, "SELECT 1", []stmt{{"SELECT 1", 1}}},
+		{"trailing statement without semicolon", DriverPostgres, "SELECT 1;\nSELECT 2", []stmt{
+			{"SELECT 1", 1}, {"SELECT 2", 2},
+		}},
+		{"line numbers", DriverPostgres, "SELECT 1;\n\nSELECT 2; SELECT 3;\n  SELECT 4", []stmt{
+			{"SELECT 1", 1}, {"SELECT 2", 3}, {"SELECT 3", 3}, {"SELECT 4", 4},
+		}},
+		{"multi-line statement", DriverPostgres, "CREATE TABLE t (\n  id int\n);\nINSERT INTO t VALUES (1);", []stmt{
+			{"CREATE TABLE t (\n  id int\n)", 1}, {"INSERT INTO t VALUES (1)", 4},
+		}},
+		{"CRLF line endings", DriverPostgres, "SELECT 1;\r\nSELECT 2;\r\n", []stmt{
+			{"SELECT 1", 1}, {"SELECT 2", 2},
+		}},
+		{"leading comment excluded", DriverPostgres, "-- header\n/* more\n */ SELECT 1;", []stmt{
+			{"SELECT 1", 3},
+		}},
+		{"unterminated string", DriverPostgres, "SELECT 'abc; SELECT 2", []stmt{{"SELECT 'abc; SELECT 2", 1}}},
+
+		// PostgreSQL strings and identifiers
+		{"semicolon in string", DriverPostgres, "SELECT 'a;b'; SELECT 2", []stmt{{"SELECT 'a;b'", 1}, {"SELECT 2", 1}}},
+		{"doubled quote", DriverPostgres, "SELECT 'it''s; fine'; SELECT 2", []stmt{{"SELECT 'it''s; fine'", 1}, {"SELECT 2", 1}}},
+		{"backslash is literal", DriverPostgres, `SELECT 'a\'; SELECT 2`, []stmt{{`SELECT 'a\'`, 1}, {"SELECT 2", 1}}},
+		{"escape string", DriverPostgres, `SELECT E'a\'; b'; SELECT 2`, []stmt{{`SELECT E'a\'; b'`, 1}, {"SELECT 2", 1}}},
+		{"lowercase escape string", DriverPostgres, `select e'\';'; select 2`, []stmt{{`select e'\';'`, 1}, {"select 2", 1}}},
+		{"escaped backslash in escape string", DriverPostgres, `SELECT E'a\\'; SELECT 2`, []stmt{{`SELECT E'a\\'`, 1}, {"SELECT 2", 1}}},
+		{"identifier ending in e", DriverPostgres, `SELECT date'a\'; SELECT 2`, []stmt{{`SELECT date'a\'`, 1}, {"SELECT 2", 1}}},
+		{"newline in string", DriverPostgres, "SELECT 'a\n;b';\nSELECT 2", []stmt{{"SELECT 'a\n;b'", 1}, {"SELECT 2", 3}}},
+		{"quoted identifier", DriverPostgres, `SELECT "a;b" FROM t; SELECT 2`, []stmt{{`SELECT "a;b" FROM t`, 1}, {"SELECT 2", 1}}},
+		{"doubled identifier quote", DriverPostgres, `SELECT "a"";b"; SELECT 2`, []stmt{{`SELECT "a"";b"`, 1}, {"SELECT 2", 1}}},
+
+		// PostgreSQL dollar quoting
+		{"dollar quoted", DriverPostgres, "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql; SELECT 2", []stmt{
+			{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql", 1}, {"SELECT 2", 1},
+		}},
+		{"tagged dollar quote", DriverPostgres, "DO $body$ BEGIN PERFORM 1; END $body$; SELECT 2", []stmt{
+			{"DO $body$ BEGIN PERFORM 1; END $body$", 1}, {"SELECT 2", 1},
+		}},
+		{"other tag inside dollar quote", DriverPostgres, "SELECT $a$ x; $$ ; $b$ ; $a$; SELECT 2", []stmt{
+			{"SELECT $a$ x; $$ ; $b$ ; $a$", 1}, {"SELECT 2", 1},
+		}},
+		{"dollar quote line numbers", DriverPostgres, "SELECT $$\n;\n$$;\nSELECT 2", []stmt{
+			{"SELECT $$\n;\n$$", 1}, {"SELECT 2", 4},
+		}},
+		{"positional parameters", DriverPostgres, "SELECT $1; SELECT $2", []stmt{{"SELECT $1", 1}, {"SELECT $2", 1}}},
+		{"dollar in identifier", DriverPostgres, "SELECT a$b$ FROM t; SELECT 2", []stmt{{"SELECT a$b$ FROM t", 1}, {"SELECT 2", 1}}},
+
+		// PostgreSQL comments
+		{"semicolon in line comment", DriverPostgres, "SELECT 1 -- a;b\n; SELECT 2", []stmt{{"SELECT 1 -- a;b", 1}, {"SELECT 2", 2}}},
+		{"line comment without space", DriverPostgres, "SELECT 1--1; SELECT 2", []stmt{{"SELECT 1--1; SELECT 2", 1}}},
+		{"semicolon in block comment", DriverPostgres, "SELECT /* ; */ 1; SELECT 2", []stmt{{"SELECT /* ; */ 1", 1}, {"SELECT 2", 1}}},
+		{"nested block comment", DriverPostgres, "SELECT /* a /* b; */ c; */ 1; SELECT 2", []stmt{
+			{"SELECT /* a /* b; */ c; */ 1", 1}, {"SELECT 2", 1},
+		}},
+		{"block comment line numbers", DriverPostgres, "SELECT 1 /* a\nb */;\nSELECT 2", []stmt{
+			{"SELECT 1 /* a\nb */", 1}, {"SELECT 2", 3},
+		}},
+
+		// MySQL
+		{"mysql backslash escape", DriverMySQL, `SELECT 'a\';b'; SELECT 2`, []stmt{{`SELECT 'a\';b'`, 1}, {"SELECT 2", 1}}},
+		{"mysql escaped newline", DriverMySQL, "SELECT 'a\\\n';\nSELECT 2", []stmt{{"SELECT 'a\\\n'", 1}, {"SELECT 2", 3}}},
+		{"mysql doubled quote", DriverMySQL, "SELECT 'it''s; fine'; SELECT 2", []stmt{{"SELECT 'it''s; fine'", 1}, {"SELECT 2", 1}}},
+		{"mysql double quoted string", DriverMySQL, `SELECT "a\";b"; SELECT 2`, []stmt{{`SELECT "a\";b"`, 1}, {"SELECT 2", 1}}},
+		{"mysql backtick", DriverMySQL, "SELECT `a;b` FROM t; SELECT 2", []stmt{{"SELECT `a;b` FROM t", 1}, {"SELECT 2", 1}}},
+		{"mysql doubled backtick", DriverMySQL, "SELECT `a``;b`; SELECT 2", []stmt{{"SELECT `a``;b`", 1}, {"SELECT 2", 1}}},
+		{"mysql hash comment", DriverMySQL, "SELECT 1 # a;b\n; SELECT 2", []stmt{{"SELECT 1 # a;b", 1}, {"SELECT 2", 2}}},
+		{"mysql dash comment", DriverMySQL, "SELECT 1 -- a;b\n; SELECT 2", []stmt{{"SELECT 1 -- a;b", 1}, {"SELECT 2", 2}}},
+		{"mysql dashes without space", DriverMySQL, "SELECT 1--1; SELECT 2", []stmt{{"SELECT 1--1", 1}, {"SELECT 2", 1}}},
+		{"mysql dash comment at end", DriverMySQL, "SELECT 1;\n--", []stmt{{"SELECT 1", 1}}},
+		{"mysql no nested comments", DriverMySQL, "SELECT /* a /* b */ 1; SELECT 2", []stmt{{"SELECT /* a /* b */ 1", 1}, {"SELECT 2", 1}}},
+		{"mysql executable comment", DriverMySQL, "/*!40101 SET NAMES utf8 */;\n/* plain */;\nSELECT 1", []stmt{
+			{"/*!40101 SET NAMES utf8 */", 1}, {"SELECT 1", 3},
+		}},
+		{"mysql semicolon in executable comment", DriverMySQL, "/*!50003 SELECT 1; */; SELECT 2", []stmt{
+			{"/*!50003 SELECT 1; */", 1}, {"SELECT 2", 1},
+		}},
+		{"mysql dollar is not a quote", DriverMySQL, "SELECT $$;$$", []stmt{{"SELECT $$", 1}, {"$$", 1}}},
+		{"postgres hash is not a comment", DriverPostgres, "SELECT 1 # 2; SELECT 3", []stmt{{"SELECT 1 # 2", 1}, {"SELECT 3", 1}}},
+		{"postgres double quote ignores backslash", DriverPostgres, `SELECT "a\"; SELECT 2`, []stmt{{`SELECT "a\"`, 1}, {"SELECT 2", 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitScript(tt.driver, tt.script)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitScript(%q)\n got %+v\nwant %+v", tt.script, got, tt.want)
+			}
+		})
+	}
+}
+
+type recordingExecer struct {
+	queries []string
+	failOn  int
+}
+
+func (e *recordingExecer) Exec(_ context.Context, query string, _ ...interface{}) (Result, error) {
+	e.queries = append(e.queries, query)
+	if len(e.queries) == e.failOn {
+		return nil, errors.New("syntax error")
+	}
+	return nil, nil
+}
+
+func TestExecStatements(t *testing.T) {
+	statements := SplitScript(DriverPostgres, "SELECT 1;\nSELECT 2;\nSELECT 3;")
+
+	e := &recordingExecer{failOn: 2}
+	err := execStatements(context.Background(), e, statements)
+
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) {
+		t.Fatalf("Expected a ScriptError, got %v", err)
+	}
+	if scriptErr.Index != 1 || scriptErr.Line != 2 || scriptErr.Statement != "SELECT 2" {
+		t.Errorf("Expected statement 2 on line 2, got %+v", scriptErr)
+	}
+	if scriptErr.Error() != "script statement 2 (line 2) failed: syntax error" {
+		t.Errorf("Unexpected message %q", scriptErr.Error())
+	}
+	if len(e.queries) != 2 {
+		t.Errorf("Expected execution to stop at the failed statement, got %q", e.queries)
+	}
+}
@@ -0,0 +1,38 @@
+package kdbx
+
+import (
+	"context"
+	"time"
+)
+
+// statementTimeoutKey is the context key for per-call statement timeouts.
+type statementTimeoutKey struct{}
+
+// ContextWithStatementTimeout returns a context that overrides
+// Config.StatementTimeout for statements executed directly on the database
+// with it. A zero duration disables the server-side timeout for those calls.
+//
+// The override pins the statement to a single connection and costs two extra
+// round-trips (set and reset). It is not applied inside transactions; use
+// SET LOCAL statement_timeout (PostgreSQL) there instead.
+func ContextWithStatementTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, statementTimeoutKey{}, d)
+}
+
+// statementTimeoutFromContext returns the per-call statement timeout, if any.
+func statementTimeoutFromContext(ctx context.Context) (time.Duration, bool) {
+	d, ok := ctx.Value(statementTimeoutKey{}).(time.Duration)
+	return d, ok
+}
+
+// timeoutMillis converts d to whole milliseconds, rounding sub-millisecond
+// positive values up so they are not treated as "no timeout".
+func timeoutMillis(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	if ms := d.Milliseconds(); ms > 0 {
+		return ms
+	}
+	return 1
+}
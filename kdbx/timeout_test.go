@@ -0,0 +1,48 @@
+package kdbx
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeoutMillis(t *testing.T) {
+	tests := []struct {
+		name string
+		d    time.Duration
+		want int64
+	}{
+		{"zero disables", 0, 0},
+		{"negative disables", -time.Second, 0},
+		{"one nanosecond rounds up", time.Nanosecond, 1},
+		{"sub-millisecond rounds up", 999 * time.Microsecond, 1},
+		{"one millisecond", time.Millisecond, 1},
+		{"fraction truncated", 1500 * time.Microsecond, 1},
+		{"seconds", 30 * time.Second, 30000},
+		{"minutes", 2 * time.Minute, 120000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := timeoutMillis(tt.d); got != tt.want {
+				t.Errorf("timeoutMillis(%v) = %d, want %d", tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatementTimeoutFromContext(t *testing.T) {
+	if _, ok := statementTimeoutFromContext(context.Background()); ok {
+		t.Error("Expected no timeout in a plain context")
+	}
+
+	ctx := ContextWithStatementTimeout(context.Background(), 0)
+	if d, ok := statementTimeoutFromContext(ctx); !ok || d != 0 {
+		t.Errorf("Expected an explicit zero timeout, got %v %v", d, ok)
+	}
+
+	ctx = ContextWithStatementTimeout(ctx, 5*time.Second)
+	if d, ok := statementTimeoutFromContext(ctx); !ok || d != 5*time.Second {
+		t.Errorf("Expected the innermost timeout, got %v %v", d, ok)
+	}
+}
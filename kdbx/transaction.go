@@ -45,6 +45,24 @@ func withRetry(ctx context.Context, config *Config, fn func(context.Context) err
 			break
 		}
 
+		// Stop retrying when the shared retry budget is exhausted
+		if config.RetryBudget != nil && !config.RetryBudget.Allow() {
+			if recorder, ok := config.Metrics.(RetryBudgetRecorder); ok {
+				recorder.RecordRetryBudgetExhausted(err)
+			}
+			if config.Logger != nil {
+				config.Logger.Warn("retry budget exhausted, not retrying",
+					"attempt", attempt+1,
+					"error", err,
+				)
+			}
+			return &DatabaseError{
+				Code:    CodeResourceExhausted,
+				Message: ErrRetryBudgetExhausted.Message,
+				Cause:   err,
+			}
+		}
+
 		// Calculate backoff with exponential growth
 		sleepDuration := calculateBackoff(backoff, attempt, config.RetryMaxBackoff)
 
@@ -15,11 +15,13 @@ import (
 
 // Argon2Config customizes the Argon2id parameters.
 type Argon2Config struct {
-	Time        uint32
-	Memory      uint32
-	Parallelism uint8
-	KeyLength   uint32
-	SaltLength  uint32
+	Time        uint32 `yaml:"time"`
+	Memory      uint32 `yaml:"memory"`
+	Parallelism uint8  `yaml:"parallelism"`
+	KeyLength   uint32 `yaml:"key_length"`
+	SaltLength  uint32 `yaml:"salt_length"`
+	// MaxPasswordLength defaults to DefaultMaxPasswordLength.
+	MaxPasswordLength int `yaml:"max_password_length"`
 }
 
 func (c *Argon2Config) setDefaults() {
@@ -38,6 +40,9 @@ func (c *Argon2Config) setDefaults() {
 	if c.SaltLength == 0 {
 		c.SaltLength = 16
 	}
+	if c.MaxPasswordLength == 0 {
+		c.MaxPasswordLength = DefaultMaxPasswordLength
+	}
 }
 
 type argon2Strategy struct {
@@ -67,11 +72,14 @@ func newArgon2Strategy(cfg Argon2Config) (strategy, error) {
 	case cfg.Time > 100:
 		return nil, errors.New("khasher: argon2 time cost must be <= 100")
 	}
+	if err := validateMaxPasswordLength(AlgorithmArgon2id, cfg.MaxPasswordLength); err != nil {
+		return nil, err
+	}
 
 	return &argon2Strategy{cfg: cfg}, nil
 }
 
-func (s *argon2Strategy) hash(ctx context.Context, password string) (string, error) {
+func (s *argon2Strategy) hash(ctx context.Context, password []byte) (string, error) {
 	if err := ctx.Err(); err != nil {
 		return "", err
 	}
@@ -81,12 +89,18 @@ func (s *argon2Strategy) hash(ctx context.Context, password string) (string, err
 		return "", fmt.Errorf("khasher: generate salt: %w", err)
 	}
 
-	hash := argon2.IDKey([]byte(password), salt, s.cfg.Time, s.cfg.Memory, s.cfg.Parallelism, s.cfg.KeyLength)
+	hash, err := runKDF(ctx, password, func(password []byte) []byte {
+		return argon2.IDKey(password, salt, s.cfg.Time, s.cfg.Memory, s.cfg.Parallelism, s.cfg.KeyLength)
+	})
+	if err != nil {
+		return "", err
+	}
+	defer wipe(hash)
 
 	return encodeArgon2Hash(salt, hash, s.cfg), nil
 }
 
-func (s *argon2Strategy) compare(ctx context.Context, encoded, password string) error {
+func (s *argon2Strategy) compare(ctx context.Context, encoded string, password []byte) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -96,7 +110,13 @@ func (s *argon2Strategy) compare(ctx context.Context, encoded, password string)
 		return err
 	}
 
-	derived := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.parallelism, params.keyLen)
+	derived, err := runKDF(ctx, password, func(password []byte) []byte {
+		return argon2.IDKey(password, salt, params.time, params.memory, params.parallelism, params.keyLen)
+	})
+	if err != nil {
+		return err
+	}
+	defer wipe(derived)
 	if subtle.ConstantTimeCompare(hash, derived) == 1 {
 		return nil
 	}
@@ -107,6 +127,10 @@ func (s *argon2Strategy) canHandle(hashed string) bool {
 	return strings.HasPrefix(hashed, "$argon2")
 }
 
+func (s *argon2Strategy) maxPasswordLength() int {
+	return s.cfg.MaxPasswordLength
+}
+
 func encodeArgon2Hash(salt, hash []byte, cfg Argon2Config) string {
 	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
 		argon2.Version,
@@ -2,6 +2,8 @@ package khasher
 
 import (
 	"context"
+	"crypto/sha512"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"strings"
@@ -9,56 +11,129 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// BcryptMaxPasswordLength is the longest password bcrypt can hash without
+// pre-hashing, in bytes.
+const BcryptMaxPasswordLength = 72
+
 // BcryptConfig customizes the bcrypt strategy.
 type BcryptConfig struct {
-	Cost int
+	Cost int `yaml:"cost"`
+	// PreHash hashes passwords with SHA-512 before bcrypt, so passwords longer
+	// than 72 bytes keep all their entropy. Such hashes are prefixed with
+	// "$bcrypt-sha512" and are verified regardless of this setting.
+	PreHash bool `yaml:"pre_hash"`
+	// MaxPasswordLength defaults to BcryptMaxPasswordLength, or to
+	// DefaultMaxPasswordLength with PreHash. It cannot exceed
+	// BcryptMaxPasswordLength without PreHash.
+	MaxPasswordLength int `yaml:"max_password_length"`
 }
 
 func (c *BcryptConfig) setDefaults() {
 	if c.Cost == 0 {
 		c.Cost = 12
 	}
+	if c.MaxPasswordLength == 0 {
+		c.MaxPasswordLength = BcryptMaxPasswordLength
+		if c.PreHash {
+			c.MaxPasswordLength = DefaultMaxPasswordLength
+		}
+	}
 }
 
+const bcryptPreHashPrefix = "$bcrypt-sha512"
+
 type bcryptStrategy struct {
-	cost int
+	cost    int
+	preHash bool
+	maxLen  int
 }
 
 func newBcryptStrategy(cfg BcryptConfig) (strategy, error) {
 	if cfg.Cost < bcrypt.MinCost || cfg.Cost > bcrypt.MaxCost {
 		return nil, fmt.Errorf("khasher: bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
 	}
-	return &bcryptStrategy{cost: cfg.Cost}, nil
+	if err := validateMaxPasswordLength(AlgorithmBcrypt, cfg.MaxPasswordLength); err != nil {
+		return nil, err
+	}
+	if !cfg.PreHash && cfg.MaxPasswordLength > BcryptMaxPasswordLength {
+		return nil, fmt.Errorf("khasher: bcrypt max password length must be <= %d bytes without PreHash", BcryptMaxPasswordLength)
+	}
+	return &bcryptStrategy{cost: cfg.Cost, preHash: cfg.PreHash, maxLen: cfg.MaxPasswordLength}, nil
 }
 
-func (s *bcryptStrategy) hash(ctx context.Context, password string) (string, error) {
+func (s *bcryptStrategy) hash(ctx context.Context, password []byte) (string, error) {
 	if err := ctx.Err(); err != nil {
 		return "", err
 	}
 
-	hashed, err := bcrypt.GenerateFromPassword([]byte(password), s.cost)
+	prefix := ""
+	if s.preHash {
+		password = bcryptPreHash(password)
+		defer wipe(password)
+		prefix = bcryptPreHashPrefix
+	}
+
+	res, err := runKDF(ctx, password, func(password []byte) kdfResult {
+		hashed, err := bcrypt.GenerateFromPassword(password, s.cost)
+		return kdfResult{hashed, err}
+	})
 	if err != nil {
-		return "", fmt.Errorf("khasher: bcrypt hash: %w", err)
+		return "", err
 	}
-	return string(hashed), nil
+	if res.err != nil {
+		return "", fmt.Errorf("khasher: bcrypt hash: %w", res.err)
+	}
+	return prefix + string(res.key), nil
 }
 
-func (s *bcryptStrategy) compare(ctx context.Context, hashed, password string) error {
+func (s *bcryptStrategy) compare(ctx context.Context, hashed string, password []byte) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password)); err != nil {
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+	if inner, ok := strings.CutPrefix(hashed, bcryptPreHashPrefix); ok {
+		hashed = inner
+		password = bcryptPreHash(password)
+		defer wipe(password)
+	}
+
+	cmpErr, err := runKDF(ctx, password, func(password []byte) error {
+		return bcrypt.CompareHashAndPassword([]byte(hashed), password)
+	})
+	if err != nil {
+		return err
+	}
+	if cmpErr != nil {
+		if errors.Is(cmpErr, bcrypt.ErrMismatchedHashAndPassword) {
 			return ErrPasswordMismatch
 		}
-		return fmt.Errorf("khasher: bcrypt compare: %w", err)
+		return fmt.Errorf("khasher: bcrypt compare: %w", cmpErr)
 	}
 	return nil
 }
 
 func (s *bcryptStrategy) canHandle(hashed string) bool {
+	return isBcryptHash(hashed)
+}
+
+func isBcryptHash(hashed string) bool {
+	hashed = strings.TrimPrefix(hashed, bcryptPreHashPrefix)
 	return strings.HasPrefix(hashed, "$2a$") ||
 		strings.HasPrefix(hashed, "$2b$") ||
 		strings.HasPrefix(hashed, "$2y$")
 }
+
+func (s *bcryptStrategy) maxPasswordLength() int {
+	return s.maxLen
+}
+
+// bcryptPreHash returns the first 54 bytes of the SHA-512 digest of password,
+// base64-encoded to exactly bcrypt's 72-byte limit and free of NUL bytes.
+func bcryptPreHash(password []byte) []byte {
+	sum := sha512.Sum512(password)
+	defer wipe(sum[:])
+
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(54))
+	base64.StdEncoding.Encode(encoded, sum[:54])
+	return encoded
+}
@@ -0,0 +1,59 @@
+package khasher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/karu-codes/karu-kits/config"
+)
+
+// ParseAlgorithm parses an algorithm name ("argon2id", "bcrypt" or "pbkdf2"),
+// ignoring case and surrounding whitespace.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	alg := Algorithm(strings.ToLower(strings.TrimSpace(name)))
+	switch alg {
+	case AlgorithmArgon2id, AlgorithmBcrypt, AlgorithmPBKDF2:
+		return alg, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, name)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseAlgorithm, so
+// config files and environment variables are validated while loading. An
+// empty value selects the default algorithm.
+func (a *Algorithm) UnmarshalText(text []byte) error {
+	if strings.TrimSpace(string(text)) == "" {
+		*a = ""
+		return nil
+	}
+	alg, err := ParseAlgorithm(string(text))
+	if err != nil {
+		return err
+	}
+	*a = alg
+	return nil
+}
+
+// FromConfig loads a Config from the file at path with config.Load and
+// constructs a Hasher from it, so hashing parameters can differ per
+// environment. Keys follow the yaml tags of Config and environment variables
+// are inferred from them, e.g.:
+//
+//	default: argon2id
+//	argon2:
+//	  memory: 65536
+//	  key_length: 32
+//	policy:
+//	  min_length: 12
+//
+// with ARGON2_MEMORY or POLICY_MIN_LENGTH (plus the config.WithEnvPrefix
+// prefix) as overrides. To embed the hashing settings in a larger application
+// config, add a Config field to it and pass that field to New instead.
+func FromConfig(path string, opts ...config.Option) (*Hasher, error) {
+	var cfg Config
+	if err := config.Load(path, &cfg, opts...); err != nil {
+		return nil, fmt.Errorf("khasher: %w", err)
+	}
+	return New(cfg)
+}
@@ -29,7 +29,7 @@ func ExampleHasher() {
 
 	// Output:
 	// default: argon2id
-	// registered: [argon2id bcrypt]
+	// registered: [argon2id bcrypt pbkdf2]
 	// argon2 hash: true
 	// compare: <nil>
 	// mismatch: true
@@ -0,0 +1,35 @@
+package khasher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNotFIPSApproved indicates that an algorithm cannot be used for hashing in
+// FIPS mode (see Config.FIPSMode).
+var ErrNotFIPSApproved = errors.New("khasher: algorithm is not FIPS-approved")
+
+// LegacyCompareFunc is called by Compare in FIPS mode before a hash made with
+// a non-approved algorithm is verified, e.g. to log a warning or count the
+// hashes still to be migrated.
+type LegacyCompareFunc func(ctx context.Context, alg Algorithm)
+
+// fipsMinSaltLength is the SP 800-132 minimum PBKDF2 salt length (128 bits).
+// The key length and iteration minimums of SP 800-132 are below the ones the
+// PBKDF2 strategy always enforces.
+const fipsMinSaltLength = 16
+
+func fipsApproved(alg Algorithm) bool {
+	return alg == AlgorithmPBKDF2
+}
+
+func validateFIPS(cfg Config) error {
+	switch {
+	case !fipsApproved(cfg.Default):
+		return fmt.Errorf("%w: %s", ErrNotFIPSApproved, cfg.Default)
+	case cfg.PBKDF2.SaltLength < fipsMinSaltLength:
+		return fmt.Errorf("khasher: pbkdf2 salt length must be >= %d bytes in FIPS mode", fipsMinSaltLength)
+	}
+	return nil
+}
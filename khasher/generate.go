@@ -0,0 +1,123 @@
+package khasher
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Character sets for GeneratePassword.
+const (
+	CharsetLower   = "abcdefghijklmnopqrstuvwxyz"
+	CharsetUpper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	CharsetDigits  = "0123456789"
+	CharsetSymbols = "!#$%&()*+,-./:;<=>?@[]^_{|}~"
+
+	// ambiguousChars are easily confused when read or typed.
+	ambiguousChars = "0O1lI|"
+)
+
+// GenerateToken returns n cryptographically random bytes encoded as unpadded
+// base64url, e.g. for API keys, session IDs and reset tokens. Use n >= 16
+// (128 bits); 32 is a good default.
+func GenerateToken(n int) (string, error) {
+	if n < 1 {
+		return "", errors.New("khasher: token length must be > 0")
+	}
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("khasher: generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// PasswordOptions configures GeneratePassword. The zero value generates
+// 16-character passwords from lowercase and uppercase letters, digits and
+// symbols, with at least one character of each.
+type PasswordOptions struct {
+	// Length defaults to 16.
+	Length int
+
+	// Charsets are the character sets to draw from; the password contains at
+	// least one character of each. Default: lower, upper, digits and symbols.
+	Charsets []string
+
+	// ExcludeAmbiguous removes characters that are easily confused (0 O 1 l I |),
+	// for passwords that are read aloud or typed from paper.
+	ExcludeAmbiguous bool
+}
+
+// GeneratePassword returns a cryptographically random, human-usable password.
+func GeneratePassword(opts PasswordOptions) (string, error) {
+	length := opts.Length
+	if length == 0 {
+		length = 16
+	}
+	charsets := opts.Charsets
+	if len(charsets) == 0 {
+		charsets = []string{CharsetLower, CharsetUpper, CharsetDigits, CharsetSymbols}
+	}
+
+	sets := make([][]rune, 0, len(charsets))
+	var all []rune
+	for _, charset := range charsets {
+		var set []rune
+		for _, r := range charset {
+			if opts.ExcludeAmbiguous && strings.ContainsRune(ambiguousChars, r) {
+				continue
+			}
+			set = append(set, r)
+		}
+		if len(set) == 0 {
+			return "", fmt.Errorf("khasher: password charset %q is empty", charset)
+		}
+		sets = append(sets, set)
+		all = append(all, set...)
+	}
+	if length < len(sets) {
+		return "", fmt.Errorf("khasher: password length %d is shorter than the %d required charsets", length, len(sets))
+	}
+
+	password := make([]rune, length)
+	// One character of each charset, then any characters, then shuffle so the
+	// required characters are not at predictable positions.
+	for i := range password {
+		set := all
+		if i < len(sets) {
+			set = sets[i]
+		}
+		r, err := randomRune(set)
+		if err != nil {
+			return "", err
+		}
+		password[i] = r
+	}
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return string(password), nil
+}
+
+func randomRune(set []rune) (rune, error) {
+	i, err := randomInt(len(set))
+	if err != nil {
+		return 0, err
+	}
+	return set[i], nil
+}
+
+func randomInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("khasher: generate password: %w", err)
+	}
+	return int(v.Int64()), nil
+}
@@ -1,5 +1,5 @@
 // Package khasher provides a flexible password hashing library with support
-// for multiple algorithms including Argon2id and bcrypt.
+// for multiple algorithms including Argon2id, bcrypt and PBKDF2.
 //
 // Features:
 //   - Multiple algorithm support via a pluggable strategy pattern
@@ -7,6 +7,7 @@
 //   - Secure defaults (Argon2id with OWASP-recommended parameters)
 //   - Context-aware operations for cancellation and timeouts
 //   - Comprehensive input validation
+//   - Optional peppering with rotatable keys (see PepperConfig)
 //
 // Basic usage:
 //
@@ -53,6 +54,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 )
 
 // Algorithm represents the hashing algorithm identifier.
@@ -63,6 +65,8 @@ const (
 	AlgorithmArgon2id Algorithm = "argon2id"
 	// AlgorithmBcrypt uses the bcrypt password hashing scheme.
 	AlgorithmBcrypt Algorithm = "bcrypt"
+	// AlgorithmPBKDF2 uses PBKDF2 with HMAC-SHA-256 or HMAC-SHA-512.
+	AlgorithmPBKDF2 Algorithm = "pbkdf2"
 )
 
 var (
@@ -74,31 +78,63 @@ var (
 	ErrPasswordMismatch = errors.New("khasher: password mismatch")
 	// ErrPasswordEmpty indicates that the password is empty.
 	ErrPasswordEmpty = errors.New("khasher: password cannot be empty")
-	// ErrPasswordTooLong indicates that the password exceeds the maximum length.
-	ErrPasswordTooLong = errors.New("khasher: password too long (max 72 bytes)")
+	// ErrPasswordTooLong indicates that the password exceeds the maximum length
+	// of the algorithm.
+	ErrPasswordTooLong = errors.New("khasher: password too long")
 )
 
+// DefaultMaxPasswordLength is the default maximum password length in bytes of
+// the algorithms without an inherent limit. It bounds the work an attacker can
+// cause with huge inputs while accepting long passphrases.
+const DefaultMaxPasswordLength = 1024
+
 // Config drives the construction of a Hasher.
 type Config struct {
 	// Default defines which algorithm Hash will use when none is specified.
-	Default Algorithm
+	Default Algorithm `yaml:"default"`
 	// Argon2 customizes the Argon2id strategy.
-	Argon2 Argon2Config
+	Argon2 Argon2Config `yaml:"argon2"`
 	// Bcrypt customizes the bcrypt strategy.
-	Bcrypt BcryptConfig
+	Bcrypt BcryptConfig `yaml:"bcrypt"`
+	// PBKDF2 customizes the PBKDF2 strategy.
+	PBKDF2 PBKDF2Config `yaml:"pbkdf2"`
+	// Pepper optionally applies a server-side secret before hashing.
+	Pepper PepperConfig `yaml:"pepper"`
+	// Policy is checked by ValidatePolicy, e.g. on signup. Hash does not
+	// enforce it, so existing passwords keep working when it changes.
+	Policy Policy `yaml:"policy"`
+
+	// FIPSMode restricts hashing to FIPS-approved primitives (PBKDF2 with
+	// HMAC-SHA-256 or HMAC-SHA-512, and HMAC-SHA-256 for the pepper). Default
+	// then defaults to AlgorithmPBKDF2 and must not be changed, and PBKDF2
+	// salts must be at least 16 bytes. Hashes made with other algorithms can
+	// still be verified by Compare, so they can be migrated on login.
+	FIPSMode bool `yaml:"fips_mode"`
+	// OnLegacyCompare is called in FIPS mode before verifying a hash made with
+	// a non-approved algorithm.
+	OnLegacyCompare LegacyCompareFunc `yaml:"-"`
 }
 
 // Hasher exposes high-level helpers to hash and compare passwords.
 type Hasher struct {
 	defaultAlg Algorithm
 	strategies map[Algorithm]strategy
+	// legacy holds the strategies that only verify hashes (FIPS mode).
+	legacy          map[Algorithm]strategy
+	onLegacyCompare LegacyCompareFunc
+	pepper          *pepper
+	policy          Policy
+
+	dummyOnce sync.Once
+	dummyHash string
+	dummyErr  error
 }
 
 // New constructs a Hasher using the provided configuration.
 func New(cfg Config) (*Hasher, error) {
 	cfg.setDefaults()
 
-	strats := make(map[Algorithm]strategy, 2)
+	strats := make(map[Algorithm]strategy, 3)
 
 	argon2Strategy, err := newArgon2Strategy(cfg.Argon2)
 	if err != nil {
@@ -112,49 +148,144 @@ func New(cfg Config) (*Hasher, error) {
 	}
 	strats[AlgorithmBcrypt] = bcryptStrategy
 
+	pbkdf2Strategy, err := newPBKDF2Strategy(cfg.PBKDF2)
+	if err != nil {
+		return nil, err
+	}
+	strats[AlgorithmPBKDF2] = pbkdf2Strategy
+
+	var legacy map[Algorithm]strategy
+	if cfg.FIPSMode {
+		if err := validateFIPS(cfg); err != nil {
+			return nil, err
+		}
+		legacy = make(map[Algorithm]strategy)
+		for alg, strat := range strats {
+			if !fipsApproved(alg) {
+				legacy[alg] = strat
+				delete(strats, alg)
+			}
+		}
+	}
+
 	if _, ok := strats[cfg.Default]; !ok {
 		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, cfg.Default)
 	}
 
+	pep, err := newPepper(cfg.Pepper)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Policy.validate(); err != nil {
+		return nil, err
+	}
+	policy := cfg.Policy
+	policy.Banned = append([]string(nil), cfg.Policy.Banned...)
+
 	return &Hasher{
-		defaultAlg: cfg.Default,
-		strategies: strats,
+		defaultAlg:      cfg.Default,
+		strategies:      strats,
+		legacy:          legacy,
+		onLegacyCompare: cfg.OnLegacyCompare,
+		pepper:          pep,
+		policy:          policy,
 	}, nil
 }
 
 // Hash produces a password hash using the default algorithm.
 func (h *Hasher) Hash(ctx context.Context, password string) (string, error) {
-	if err := validatePassword(password); err != nil {
-		return "", err
-	}
 	return h.HashWith(ctx, h.defaultAlg, password)
 }
 
 // HashWith produces a password hash using a specific algorithm.
 func (h *Hasher) HashWith(ctx context.Context, alg Algorithm, password string) (string, error) {
-	if err := validatePassword(password); err != nil {
-		return "", err
+	pw := []byte(password)
+	defer wipe(pw)
+	return h.hashWith(ctx, alg, pw)
+}
+
+// HashBytes is Hash for a password held in a byte slice. Intermediate copies
+// of the password and derived values are wiped after use; password itself is
+// left to the caller to wipe.
+func (h *Hasher) HashBytes(ctx context.Context, password []byte) (string, error) {
+	return h.hashWith(ctx, h.defaultAlg, password)
+}
+
+func (h *Hasher) hashWith(ctx context.Context, alg Algorithm, password []byte) (string, error) {
+	if len(password) == 0 {
+		return "", ErrPasswordEmpty
 	}
 	strat, ok := h.strategies[alg]
 	if !ok {
+		if _, ok := h.legacy[alg]; ok {
+			return "", fmt.Errorf("%w: %s", ErrNotFIPSApproved, alg)
+		}
 		return "", fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, alg)
 	}
-	return strat.hash(ctx, password)
+	if err := validatePassword(password, strat.maxPasswordLength()); err != nil {
+		return "", err
+	}
+	if h.pepper == nil {
+		return strat.hash(ctx, password)
+	}
+
+	peppered, err := h.pepper.apply(h.pepper.current, password)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(peppered)
+	hashed, err := strat.hash(ctx, peppered)
+	if err != nil {
+		return "", err
+	}
+	return encodePepperedHash(h.pepper.current, hashed), nil
 }
 
 // Compare attempts to match the provided password against the stored hash.
-// The hash format determines which strategy is used.
+// The hash format determines which strategy is used. Peppered hashes are
+// verified with the pepper key they were created with.
 func (h *Hasher) Compare(ctx context.Context, hashed, password string) error {
+	pw := []byte(password)
+	defer wipe(pw)
+	return h.CompareBytes(ctx, hashed, pw)
+}
+
+// CompareBytes is Compare for a password held in a byte slice, with the same
+// wiping as HashBytes.
+func (h *Hasher) CompareBytes(ctx context.Context, hashed string, password []byte) error {
 	hashed = strings.TrimSpace(hashed)
 	if hashed == "" {
 		return ErrUnknownHashFormat
 	}
 
+	if id, inner, ok := splitPepperedHash(hashed); ok {
+		if h.pepper == nil {
+			return fmt.Errorf("%w: %s", ErrUnknownPepperKey, id)
+		}
+		peppered, err := h.pepper.apply(id, password)
+		if err != nil {
+			return err
+		}
+		defer wipe(peppered)
+		return h.compare(ctx, inner, peppered)
+	}
+	return h.compare(ctx, hashed, password)
+}
+
+func (h *Hasher) compare(ctx context.Context, hashed string, password []byte) error {
 	for _, strat := range h.orderedStrategies() {
 		if strat.canHandle(hashed) {
 			return strat.compare(ctx, hashed, password)
 		}
 	}
+	for _, alg := range sortedAlgorithms(h.legacy) {
+		if strat := h.legacy[alg]; strat.canHandle(hashed) {
+			if h.onLegacyCompare != nil {
+				h.onLegacyCompare(ctx, alg)
+			}
+			return strat.compare(ctx, hashed, password)
+		}
+	}
 
 	// Fallback to the default strategy if detection failed.
 	if strat, ok := h.strategies[h.defaultAlg]; ok {
@@ -166,15 +297,45 @@ func (h *Hasher) Compare(ctx context.Context, hashed, password string) error {
 	return ErrUnknownHashFormat
 }
 
+// DummyCompare performs a full-cost comparison against a hash of a random
+// password made with the default algorithm (and pepper), and returns
+// ErrPasswordMismatch. Call it when the user does not exist, so the response
+// takes as long as a failed Compare and does not reveal which accounts exist.
+//
+// The hash is created on the first call, which therefore takes about twice as
+// long; call DummyCompare once at startup to avoid that.
+func (h *Hasher) DummyCompare(ctx context.Context) error {
+	h.dummyOnce.Do(func() {
+		var secret string
+		secret, h.dummyErr = GenerateToken(16)
+		if h.dummyErr == nil {
+			h.dummyHash, h.dummyErr = h.Hash(context.Background(), secret)
+		}
+	})
+	if h.dummyErr != nil {
+		return fmt.Errorf("khasher: dummy hash: %w", h.dummyErr)
+	}
+
+	if err := h.Compare(ctx, h.dummyHash, "dummy-password"); err != nil && !errors.Is(err, ErrPasswordMismatch) {
+		return err
+	}
+	return ErrPasswordMismatch
+}
+
 // DefaultAlgorithm returns the algorithm used for Hash.
 func (h *Hasher) DefaultAlgorithm() Algorithm {
 	return h.defaultAlg
 }
 
 // AvailableAlgorithms lists all registered algorithms in deterministic order.
+// In FIPS mode, only the approved algorithms are listed.
 func (h *Hasher) AvailableAlgorithms() []Algorithm {
-	algs := make([]Algorithm, 0, len(h.strategies))
-	for alg := range h.strategies {
+	return sortedAlgorithms(h.strategies)
+}
+
+func sortedAlgorithms(strategies map[Algorithm]strategy) []Algorithm {
+	algs := make([]Algorithm, 0, len(strategies))
+	for alg := range strategies {
 		algs = append(algs, alg)
 	}
 	sort.Slice(algs, func(i, j int) bool { return algs[i] < algs[j] })
@@ -184,8 +345,12 @@ func (h *Hasher) AvailableAlgorithms() []Algorithm {
 func (cfg *Config) setDefaults() {
 	cfg.Argon2.setDefaults()
 	cfg.Bcrypt.setDefaults()
+	cfg.PBKDF2.setDefaults()
 	if cfg.Default == "" {
 		cfg.Default = AlgorithmArgon2id
+		if cfg.FIPSMode {
+			cfg.Default = AlgorithmPBKDF2
+		}
 	}
 }
 
@@ -199,18 +364,71 @@ func (h *Hasher) orderedStrategies() []strategy {
 }
 
 type strategy interface {
-	hash(context.Context, string) (string, error)
-	compare(context.Context, string, string) error
+	// hash and compare must not retain the password: the caller wipes it.
+	hash(context.Context, []byte) (string, error)
+	compare(context.Context, string, []byte) error
 	canHandle(string) bool
+	// maxPasswordLength is the longest password hash accepts, in bytes.
+	maxPasswordLength() int
 }
 
-func validatePassword(password string) error {
-	if password == "" {
+func validatePassword(password []byte, max int) error {
+	if len(password) == 0 {
 		return ErrPasswordEmpty
 	}
-	// bcrypt has a limit of 72 bytes, so we enforce this for all algorithms
-	if len(password) > 72 {
-		return ErrPasswordTooLong
+	if len(password) > max {
+		return fmt.Errorf("%w (max %d bytes)", ErrPasswordTooLong, max)
 	}
 	return nil
 }
+
+func validateMaxPasswordLength(alg Algorithm, max int) error {
+	if max < 1 {
+		return fmt.Errorf("khasher: %s max password length must be > 0", alg)
+	}
+	return nil
+}
+
+// kdfResult is the result of a key derivation that can fail.
+type kdfResult struct {
+	key []byte
+	err error
+}
+
+// runKDF runs a key derivation over password in a goroutine and returns its
+// result, or ctx.Err() as soon as ctx is done. A canceled derivation is
+// abandoned, not stopped: it keeps using CPU and memory until it completes in
+// the background, but the caller is no longer blocked on it. The goroutine
+// works on its own copy of password, wiped when it completes, so the caller
+// can wipe password as soon as runKDF returns.
+func runKDF[T any](ctx context.Context, password []byte, kdf func(password []byte) T) (T, error) {
+	if err := ctx.Err(); err != nil {
+		var zero T
+		return zero, err
+	}
+	if ctx.Done() == nil {
+		// Not cancelable (e.g. context.Background): skip the goroutine.
+		return kdf(password), nil
+	}
+
+	pw := append([]byte(nil), password...)
+	result := make(chan T, 1)
+	go func() {
+		defer wipe(pw)
+		result <- kdf(pw)
+	}()
+
+	select {
+	case v := <-result:
+		return v, nil
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// wipe zeroes b. It is best-effort: the runtime may have copied the data
+// (e.g. when growing a slice or converting to a string).
+func wipe(b []byte) {
+	clear(b)
+}
@@ -5,6 +5,10 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/karu-codes/karu-kits/config"
 )
 
 func TestHasherHashAndCompareArgon2(t *testing.T) {
@@ -49,6 +53,493 @@ func TestHasherCompareBcrypt(t *testing.T) {
 	}
 }
 
+func TestHasherHashAndComparePBKDF2(t *testing.T) {
+	t.Parallel()
+
+	for _, digest := range []PBKDF2Digest{PBKDF2SHA256, PBKDF2SHA512} {
+		t.Run(string(digest), func(t *testing.T) {
+			h, err := New(Config{
+				Default: AlgorithmPBKDF2,
+				PBKDF2:  PBKDF2Config{Digest: digest, Iterations: 1000},
+			})
+			if err != nil {
+				t.Fatalf("create hasher: %v", err)
+			}
+
+			const password = "pbkdf2-password"
+			hash, err := h.Hash(context.Background(), password)
+			if err != nil {
+				t.Fatalf("hash: %v", err)
+			}
+			if want := "$pbkdf2-" + string(digest) + "$i=1000$"; !strings.HasPrefix(hash, want) {
+				t.Fatalf("expected %q prefix, got %q", want, hash)
+			}
+			if err := h.Compare(context.Background(), hash, password); err != nil {
+				t.Fatalf("compare: %v", err)
+			}
+			if err := h.Compare(context.Background(), hash, "mismatch"); !errors.Is(err, ErrPasswordMismatch) {
+				t.Fatalf("expected ErrPasswordMismatch, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHasherComparePBKDF2KnownVector(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(Config{})
+	if err != nil {
+		t.Fatalf("create hasher: %v", err)
+	}
+
+	// RFC 7914 section 11 test vector: PBKDF2-HMAC-SHA256("passwd", "salt", 1, 64).
+	const hash = "$pbkdf2-sha256$i=1$c2FsdA$VawEblbjCJ/sFpHCJUS2BflBhSFt3gRl5oudV8INrLxJypzM8Xm2RZkWZLOdd+8xfHG4RbHjC9UJESBB06GXgw"
+	if err := h.Compare(context.Background(), hash, "passwd"); err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+}
+
+func TestPBKDF2ConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  PBKDF2Config
+	}{
+		{name: "unknown digest", cfg: PBKDF2Config{Digest: "md5"}},
+		{name: "short salt", cfg: PBKDF2Config{SaltLength: 4}},
+		{name: "short key", cfg: PBKDF2Config{KeyLength: 8}},
+		{name: "few iterations", cfg: PBKDF2Config{Iterations: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(Config{PBKDF2: tt.cfg}); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestHasherPepper(t *testing.T) {
+	t.Parallel()
+
+	keyV1 := []byte("0123456789abcdef-v1")
+	keyV2 := []byte("0123456789abcdef-v2")
+	newHasher := func(t *testing.T, pepper PepperConfig) *Hasher {
+		t.Helper()
+		h, err := New(Config{Default: AlgorithmBcrypt, Bcrypt: BcryptConfig{Cost: 4}, Pepper: pepper})
+		if err != nil {
+			t.Fatalf("create hasher: %v", err)
+		}
+		return h
+	}
+
+	ctx := context.Background()
+	const password = "peppered-password"
+
+	v1 := newHasher(t, PepperConfig{Keys: map[string][]byte{"v1": keyV1}, Current: "v1"})
+	hashV1, err := v1.Hash(ctx, password)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !strings.HasPrefix(hashV1, "$pepper$k=v1$2a$") {
+		t.Fatalf("expected peppered bcrypt hash, got %q", hashV1)
+	}
+	if err := v1.Compare(ctx, hashV1, password); err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	if err := v1.Compare(ctx, hashV1, "mismatch"); !errors.Is(err, ErrPasswordMismatch) {
+		t.Fatalf("expected ErrPasswordMismatch, got %v", err)
+	}
+
+	// The inner hash alone must not verify the plain password.
+	plain := newHasher(t, PepperConfig{})
+	if err := plain.Compare(ctx, strings.TrimPrefix(hashV1, "$pepper$k=v1"), password); !errors.Is(err, ErrPasswordMismatch) {
+		t.Fatalf("expected ErrPasswordMismatch without pepper, got %v", err)
+	}
+	if err := plain.Compare(ctx, hashV1, password); !errors.Is(err, ErrUnknownPepperKey) {
+		t.Fatalf("expected ErrUnknownPepperKey, got %v", err)
+	}
+
+	// Rotation: v2 is current, v1 hashes still verify.
+	rotated := newHasher(t, PepperConfig{Keys: map[string][]byte{"v1": keyV1, "v2": keyV2}, Current: "v2"})
+	if err := rotated.Compare(ctx, hashV1, password); err != nil {
+		t.Fatalf("compare v1 hash after rotation: %v", err)
+	}
+	hashV2, err := rotated.Hash(ctx, password)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !strings.HasPrefix(hashV2, "$pepper$k=v2$") {
+		t.Fatalf("expected v2 key ID, got %q", hashV2)
+	}
+	if err := v1.Compare(ctx, hashV2, password); !errors.Is(err, ErrUnknownPepperKey) {
+		t.Fatalf("expected ErrUnknownPepperKey, got %v", err)
+	}
+
+	// Hashes created before peppering was enabled still verify.
+	legacy, err := plain.Hash(ctx, password)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if err := rotated.Compare(ctx, legacy, password); err != nil {
+		t.Fatalf("compare legacy hash: %v", err)
+	}
+}
+
+func TestPepperConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte("0123456789abcdef")
+	tests := []struct {
+		name string
+		cfg  PepperConfig
+	}{
+		{name: "missing current", cfg: PepperConfig{Keys: map[string][]byte{"v1": secret}}},
+		{name: "unknown current", cfg: PepperConfig{Keys: map[string][]byte{"v1": secret}, Current: "v2"}},
+		{name: "current without keys", cfg: PepperConfig{Current: "v1"}},
+		{name: "short secret", cfg: PepperConfig{Keys: map[string][]byte{"v1": []byte("short")}, Current: "v1"}},
+		{name: "invalid key ID", cfg: PepperConfig{Keys: map[string][]byte{"v$1": secret}, Current: "v$1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(Config{Pepper: tt.cfg}); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestHasherValidatePolicy(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(Config{Policy: Policy{
+		MinLength:      10,
+		RequireDigit:   true,
+		MinCharClasses: 3,
+		MaxRepeated:    2,
+		Banned:         []string{"Password123!"},
+		BreachCheck: func(_ context.Context, password string) (bool, error) {
+			return password == "Breached-pass-1", nil
+		},
+	}})
+	if err != nil {
+		t.Fatalf("create hasher: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		want     []ViolationCode
+	}{
+		{name: "valid", password: "Correct-horse-1"},
+		{name: "short", password: "Ab1!", want: []ViolationCode{ViolationTooShort}},
+		{name: "classes", password: "onlylowercase", want: []ViolationCode{ViolationMissingDigit, ViolationTooFewClasses}},
+		{name: "repeated", password: "Abc-defff-12", want: []ViolationCode{ViolationRepeated}},
+		{name: "banned", password: "password123!", want: []ViolationCode{ViolationBanned}},
+		{name: "breached", password: "Breached-pass-1", want: []ViolationCode{ViolationBreached}},
+		{name: "unicode length", password: "Пароль-1", want: []ViolationCode{ViolationTooShort}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := h.ValidatePolicy(context.Background(), tt.password)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("expected no violation, got %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrPolicyViolation) {
+				t.Fatalf("expected ErrPolicyViolation, got %v", err)
+			}
+			var policyErr *PolicyError
+			if !errors.As(err, &policyErr) {
+				t.Fatalf("expected *PolicyError, got %T", err)
+			}
+			if len(policyErr.Violations) != len(tt.want) {
+				t.Fatalf("expected violations %v, got %v", tt.want, policyErr.Violations)
+			}
+			for _, code := range tt.want {
+				if !policyErr.Has(code) {
+					t.Fatalf("expected violation %q, got %v", code, policyErr.Violations)
+				}
+			}
+		})
+	}
+}
+
+func TestHasherValidatePolicyBreachCheckError(t *testing.T) {
+	t.Parallel()
+
+	checkErr := errors.New("service unavailable")
+	h, err := New(Config{Policy: Policy{
+		BreachCheck: func(context.Context, string) (bool, error) { return false, checkErr },
+	}})
+	if err != nil {
+		t.Fatalf("create hasher: %v", err)
+	}
+
+	err = h.ValidatePolicy(context.Background(), "password")
+	if !errors.Is(err, checkErr) || errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("expected breach check error, got %v", err)
+	}
+}
+
+func TestTokenHasher(t *testing.T) {
+	t.Parallel()
+
+	th, err := NewTokenHasher(TokenConfig{Key: []byte(strings.Repeat("k", 32))})
+	if err != nil {
+		t.Fatalf("create token hasher: %v", err)
+	}
+
+	ctx := context.Background()
+	const token = "sk_live_4f9a2c7e1b"
+	hash, err := th.Hash(ctx, token)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$hmac-sha256$") {
+		t.Fatalf("expected hmac-sha256 prefix, got %q", hash)
+	}
+	again, err := th.Hash(ctx, token)
+	if err != nil || again != hash {
+		t.Fatalf("expected deterministic hash, got %q and %q (%v)", hash, again, err)
+	}
+
+	var c Comparer = th
+	if err := c.Compare(ctx, hash, token); err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	if err := c.Compare(ctx, hash, "sk_live_other"); !errors.Is(err, ErrPasswordMismatch) {
+		t.Fatalf("expected ErrPasswordMismatch, got %v", err)
+	}
+	if _, err := th.Hash(ctx, ""); !errors.Is(err, ErrTokenEmpty) {
+		t.Fatalf("expected ErrTokenEmpty, got %v", err)
+	}
+
+	other, err := NewTokenHasher(TokenConfig{Key: []byte(strings.Repeat("o", 32))})
+	if err != nil {
+		t.Fatalf("create token hasher: %v", err)
+	}
+	if err := other.Compare(ctx, hash, token); !errors.Is(err, ErrPasswordMismatch) {
+		t.Fatalf("expected ErrPasswordMismatch with another key, got %v", err)
+	}
+
+	// Token and password hashes are not interchangeable.
+	h, err := New(Config{Default: AlgorithmBcrypt, Bcrypt: BcryptConfig{Cost: 4}})
+	if err != nil {
+		t.Fatalf("create hasher: %v", err)
+	}
+	if err := h.Compare(ctx, hash, token); !errors.Is(err, ErrUnknownHashFormat) {
+		t.Fatalf("expected ErrUnknownHashFormat from Hasher, got %v", err)
+	}
+	passwordHash, err := h.Hash(ctx, token)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if err := th.Compare(ctx, passwordHash, token); !errors.Is(err, ErrUnknownHashFormat) {
+		t.Fatalf("expected ErrUnknownHashFormat from TokenHasher, got %v", err)
+	}
+
+	if _, err := NewTokenHasher(TokenConfig{Key: []byte("short")}); err == nil {
+		t.Fatal("expected error for short key")
+	}
+}
+
+func TestGenerateToken(t *testing.T) {
+	t.Parallel()
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		token, err := GenerateToken(32)
+		if err != nil {
+			t.Fatalf("generate token: %v", err)
+		}
+		if len(token) != 43 {
+			t.Fatalf("expected 43 base64url characters, got %d (%q)", len(token), token)
+		}
+		if strings.ContainsAny(token, "+/=") {
+			t.Fatalf("expected URL-safe token, got %q", token)
+		}
+		if seen[token] {
+			t.Fatalf("duplicate token %q", token)
+		}
+		seen[token] = true
+	}
+
+	if _, err := GenerateToken(0); err == nil {
+		t.Fatal("expected error for zero length")
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	t.Parallel()
+
+	for i := 0; i < 100; i++ {
+		password, err := GeneratePassword(PasswordOptions{})
+		if err != nil {
+			t.Fatalf("generate password: %v", err)
+		}
+		if len(password) != 16 {
+			t.Fatalf("expected 16 characters, got %q", password)
+		}
+		for _, set := range []string{CharsetLower, CharsetUpper, CharsetDigits, CharsetSymbols} {
+			if !strings.ContainsAny(password, set) {
+				t.Fatalf("expected a character of %q in %q", set, password)
+			}
+		}
+	}
+
+	password, err := GeneratePassword(PasswordOptions{
+		Length:           40,
+		Charsets:         []string{CharsetDigits, CharsetUpper},
+		ExcludeAmbiguous: true,
+	})
+	if err != nil {
+		t.Fatalf("generate password: %v", err)
+	}
+	if len(password) != 40 || strings.ContainsAny(password, "01OI") || strings.ContainsAny(password, CharsetLower) {
+		t.Fatalf("unexpected password %q", password)
+	}
+
+	if _, err := GeneratePassword(PasswordOptions{Length: 2, Charsets: []string{"a", "b", "c"}}); err == nil {
+		t.Fatal("expected error when length is shorter than the charsets")
+	}
+	if _, err := GeneratePassword(PasswordOptions{Charsets: []string{"0O"}, ExcludeAmbiguous: true}); err == nil {
+		t.Fatal("expected error for empty charset")
+	}
+}
+
+func TestParseHash(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(Config{
+		Argon2: Argon2Config{Time: 1, Memory: 8 * 1024, Parallelism: 1},
+		Bcrypt: BcryptConfig{Cost: 5, PreHash: true},
+		PBKDF2: PBKDF2Config{Digest: PBKDF2SHA512, Iterations: 2000},
+		Pepper: PepperConfig{Keys: map[string][]byte{"v1": []byte("0123456789abcdef")}, Current: "v1"},
+	})
+	if err != nil {
+		t.Fatalf("create hasher: %v", err)
+	}
+
+	ctx := context.Background()
+	hashWith := func(alg Algorithm) string {
+		t.Helper()
+		hash, err := h.HashWith(ctx, alg, "password")
+		if err != nil {
+			t.Fatalf("hash with %s: %v", alg, err)
+		}
+		return hash
+	}
+
+	info, err := ParseHash(hashWith(AlgorithmArgon2id))
+	if err != nil {
+		t.Fatalf("parse argon2 hash: %v", err)
+	}
+	wantArgon2 := Argon2Config{Time: 1, Memory: 8 * 1024, Parallelism: 1, KeyLength: 32, SaltLength: 16}
+	if info.Algorithm != AlgorithmArgon2id || info.PepperKeyID != "v1" || info.Argon2 != wantArgon2 {
+		t.Fatalf("unexpected argon2 info: %+v", info)
+	}
+
+	info, err = ParseHash(hashWith(AlgorithmBcrypt))
+	if err != nil {
+		t.Fatalf("parse bcrypt hash: %v", err)
+	}
+	if info.Algorithm != AlgorithmBcrypt || info.Bcrypt != (BcryptConfig{Cost: 5, PreHash: true}) {
+		t.Fatalf("unexpected bcrypt info: %+v", info)
+	}
+
+	info, err = ParseHash(hashWith(AlgorithmPBKDF2))
+	if err != nil {
+		t.Fatalf("parse pbkdf2 hash: %v", err)
+	}
+	wantPBKDF2 := PBKDF2Config{Digest: PBKDF2SHA512, Iterations: 2000, KeyLength: 64, SaltLength: 16}
+	if info.Algorithm != AlgorithmPBKDF2 || info.PBKDF2 != wantPBKDF2 {
+		t.Fatalf("unexpected pbkdf2 info: %+v", info)
+	}
+
+	for _, invalid := range []string{"", "plain-text", "$argon2id$v=19$m=1,t=1$x$y", "$2a$xx"} {
+		if _, err := ParseHash(invalid); err == nil {
+			t.Fatalf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestHasherDummyCompare(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(Config{Default: AlgorithmBcrypt, Bcrypt: BcryptConfig{Cost: 4}})
+	if err != nil {
+		t.Fatalf("create hasher: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := h.DummyCompare(context.Background()); !errors.Is(err, ErrPasswordMismatch) {
+			t.Fatalf("expected ErrPasswordMismatch, got %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := h.DummyCompare(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestHasherHashBytes(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(Config{
+		Default: AlgorithmBcrypt,
+		Bcrypt:  BcryptConfig{Cost: 4, PreHash: true},
+		Pepper:  PepperConfig{Keys: map[string][]byte{"v1": []byte("0123456789abcdef")}, Current: "v1"},
+	})
+	if err != nil {
+		t.Fatalf("create hasher: %v", err)
+	}
+
+	ctx := context.Background()
+	password := []byte("bytes-password")
+	hash, err := h.HashBytes(ctx, password)
+	if err != nil {
+		t.Fatalf("hash bytes: %v", err)
+	}
+	if string(password) != "bytes-password" {
+		t.Fatalf("HashBytes must not modify the caller's buffer, got %q", password)
+	}
+
+	if err := h.CompareBytes(ctx, hash, password); err != nil {
+		t.Fatalf("compare bytes: %v", err)
+	}
+	if err := h.Compare(ctx, hash, "bytes-password"); err != nil {
+		t.Fatalf("compare string: %v", err)
+	}
+	if err := h.CompareBytes(ctx, hash, []byte("other")); !errors.Is(err, ErrPasswordMismatch) {
+		t.Fatalf("expected ErrPasswordMismatch, got %v", err)
+	}
+	if _, err := h.HashBytes(ctx, nil); !errors.Is(err, ErrPasswordEmpty) {
+		t.Fatalf("expected ErrPasswordEmpty, got %v", err)
+	}
+}
+
+func TestWipe(t *testing.T) {
+	t.Parallel()
+
+	b := []byte("secret")
+	wipe(b)
+	for _, c := range b {
+		if c != 0 {
+			t.Fatalf("expected zeroed buffer, got %v", b)
+		}
+	}
+}
+
 func TestHasherCompareUnknownFormat(t *testing.T) {
 	t.Parallel()
 
@@ -89,11 +580,79 @@ func TestHasherPasswordTooLong(t *testing.T) {
 		t.Fatalf("create hasher: %v", err)
 	}
 
-	longPassword := string(make([]byte, 73))
-	_, err = h.Hash(context.Background(), longPassword)
+	ctx := context.Background()
+	_, err = h.HashWith(ctx, AlgorithmBcrypt, strings.Repeat("a", 73))
 	if !errors.Is(err, ErrPasswordTooLong) {
+		t.Fatalf("expected ErrPasswordTooLong for bcrypt, got %v", err)
+	}
+
+	// Only bcrypt is limited to 72 bytes.
+	passphrase := strings.Repeat("correct horse battery staple ", 4)
+	hash, err := h.Hash(ctx, passphrase)
+	if err != nil {
+		t.Fatalf("hash long passphrase with argon2: %v", err)
+	}
+	if err := h.Compare(ctx, hash, passphrase); err != nil {
+		t.Fatalf("compare long passphrase: %v", err)
+	}
+
+	_, err = h.Hash(ctx, strings.Repeat("a", DefaultMaxPasswordLength+1))
+	if !errors.Is(err, ErrPasswordTooLong) {
+		t.Fatalf("expected ErrPasswordTooLong for argon2, got %v", err)
+	}
+}
+
+func TestHasherMaxPasswordLength(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(Config{Argon2: Argon2Config{MaxPasswordLength: 16}})
+	if err != nil {
+		t.Fatalf("create hasher: %v", err)
+	}
+	if _, err := h.Hash(context.Background(), strings.Repeat("a", 17)); !errors.Is(err, ErrPasswordTooLong) {
 		t.Fatalf("expected ErrPasswordTooLong, got %v", err)
 	}
+
+	if _, err := New(Config{Bcrypt: BcryptConfig{MaxPasswordLength: 100}}); err == nil {
+		t.Fatal("expected error for bcrypt max length > 72 without PreHash")
+	}
+	if _, err := New(Config{PBKDF2: PBKDF2Config{MaxPasswordLength: -1}}); err == nil {
+		t.Fatal("expected error for negative max length")
+	}
+}
+
+func TestHasherBcryptPreHash(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(Config{Default: AlgorithmBcrypt, Bcrypt: BcryptConfig{Cost: 4, PreHash: true}})
+	if err != nil {
+		t.Fatalf("create hasher: %v", err)
+	}
+
+	ctx := context.Background()
+	long := strings.Repeat("a", 100)
+	hash, err := h.Hash(ctx, long)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$bcrypt-sha512$2a$") {
+		t.Fatalf("expected pre-hashed bcrypt hash, got %q", hash)
+	}
+	if err := h.Compare(ctx, hash, long); err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	// Without pre-hashing, bcrypt would ignore everything after byte 72.
+	if err := h.Compare(ctx, hash, strings.Repeat("a", 72)+"b"); !errors.Is(err, ErrPasswordMismatch) {
+		t.Fatalf("expected ErrPasswordMismatch, got %v", err)
+	}
+
+	plain, err := New(Config{})
+	if err != nil {
+		t.Fatalf("create hasher: %v", err)
+	}
+	if err := plain.Compare(ctx, hash, long); err != nil {
+		t.Fatalf("compare pre-hashed hash without PreHash: %v", err)
+	}
 }
 
 func TestHasherCanceledContext(t *testing.T) {
@@ -113,6 +672,28 @@ func TestHasherCanceledContext(t *testing.T) {
 	}
 }
 
+func TestHasherContextCanceledDuringHash(t *testing.T) {
+	t.Parallel()
+
+	// Expensive enough to take well over a second.
+	h, err := New(Config{Argon2: Argon2Config{Time: 20, Memory: 64 * 1024, Parallelism: 1}})
+	if err != nil {
+		t.Fatalf("create hasher: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = h.Hash(ctx, "password")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected Hash to return on cancellation, took %v", elapsed)
+	}
+}
+
 func TestArgon2ConfigValidation(t *testing.T) {
 	t.Parallel()
 
@@ -277,13 +858,13 @@ func TestHasherAvailableAlgorithms(t *testing.T) {
 	}
 
 	algs := h.AvailableAlgorithms()
-	if len(algs) != 2 {
-		t.Fatalf("expected 2 algorithms, got %d", len(algs))
+	if len(algs) != 3 {
+		t.Fatalf("expected 3 algorithms, got %d", len(algs))
 	}
 
 	// Should be sorted
-	if algs[0] != AlgorithmArgon2id || algs[1] != AlgorithmBcrypt {
-		t.Fatalf("expected [argon2id, bcrypt], got %v", algs)
+	if algs[0] != AlgorithmArgon2id || algs[1] != AlgorithmBcrypt || algs[2] != AlgorithmPBKDF2 {
+		t.Fatalf("expected [argon2id, bcrypt, pbkdf2], got %v", algs)
 	}
 }
 
@@ -342,3 +923,201 @@ func TestHasherUnsupportedAlgorithm(t *testing.T) {
 		t.Fatalf("expected ErrUnsupportedAlgorithm, got %v", err)
 	}
 }
+
+func TestParseAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    Algorithm
+		wantErr bool
+	}{
+		{in: "argon2id", want: AlgorithmArgon2id},
+		{in: " BCrypt ", want: AlgorithmBcrypt},
+		{in: "PBKDF2", want: AlgorithmPBKDF2},
+		{in: "md5", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAlgorithm(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrUnsupportedAlgorithm) {
+				t.Errorf("ParseAlgorithm(%q) error = %v, want ErrUnsupportedAlgorithm", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseAlgorithm(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"hasher.yaml": {Data: []byte(`
+default: Bcrypt
+argon2:
+  memory: 8192
+  salt_length: 24
+bcrypt:
+  cost: 10
+  pre_hash: true
+policy:
+  min_length: 12
+  banned: [password123456]
+`)},
+	}
+	env := map[string]string{"APP_BCRYPT_COST": "4"}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	h, err := FromConfig("hasher.yaml",
+		config.WithFileSystem(fsys),
+		config.WithEnvPrefix("APP"),
+		config.WithEnvLookup(lookup),
+	)
+	if err != nil {
+		t.Fatalf("FromConfig: %v", err)
+	}
+	if h.DefaultAlgorithm() != AlgorithmBcrypt {
+		t.Fatalf("DefaultAlgorithm() = %q, want bcrypt", h.DefaultAlgorithm())
+	}
+
+	ctx := context.Background()
+	hash, err := h.Hash(ctx, "correct horse battery staple")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	info, err := ParseHash(hash)
+	if err != nil {
+		t.Fatalf("parse hash: %v", err)
+	}
+	if info.Bcrypt.Cost != 4 || !info.Bcrypt.PreHash {
+		t.Fatalf("bcrypt params = %+v, want cost 4 from env and pre-hash from file", info.Bcrypt)
+	}
+
+	hash, err = h.HashWith(ctx, AlgorithmArgon2id, "correct horse battery staple")
+	if err != nil {
+		t.Fatalf("hash argon2: %v", err)
+	}
+	info, err = ParseHash(hash)
+	if err != nil {
+		t.Fatalf("parse argon2 hash: %v", err)
+	}
+	if info.Argon2.Memory != 8192 || info.Argon2.SaltLength != 24 {
+		t.Fatalf("argon2 params = %+v, want memory 8192 and salt length 24", info.Argon2)
+	}
+
+	var policyErr *PolicyError
+	if err := h.ValidatePolicy(ctx, "Password123456"); !errors.As(err, &policyErr) ||
+		!policyErr.Has(ViolationBanned) || policyErr.Has(ViolationTooShort) {
+		t.Fatalf("ValidatePolicy() = %v, want only banned", err)
+	}
+}
+
+func TestFromConfigInvalidAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"hasher.yaml": {Data: []byte("default: md5\n")},
+	}
+	_, err := FromConfig("hasher.yaml", config.WithFileSystem(fsys), config.WithoutEnv())
+	if !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Fatalf("expected ErrUnsupportedAlgorithm, got %v", err)
+	}
+}
+
+func TestHasherFIPSMode(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	legacy, err := New(Config{Default: AlgorithmBcrypt, Bcrypt: BcryptConfig{Cost: 4}})
+	if err != nil {
+		t.Fatalf("create legacy hasher: %v", err)
+	}
+	legacyHash, err := legacy.Hash(ctx, "password")
+	if err != nil {
+		t.Fatalf("legacy hash: %v", err)
+	}
+
+	var warned []Algorithm
+	h, err := New(Config{
+		FIPSMode: true,
+		PBKDF2:   PBKDF2Config{Iterations: 1000},
+		Bcrypt:   BcryptConfig{Cost: 4},
+		OnLegacyCompare: func(_ context.Context, alg Algorithm) {
+			warned = append(warned, alg)
+		},
+	})
+	if err != nil {
+		t.Fatalf("create FIPS hasher: %v", err)
+	}
+
+	if h.DefaultAlgorithm() != AlgorithmPBKDF2 {
+		t.Fatalf("DefaultAlgorithm() = %q, want pbkdf2", h.DefaultAlgorithm())
+	}
+	if algs := h.AvailableAlgorithms(); len(algs) != 1 || algs[0] != AlgorithmPBKDF2 {
+		t.Fatalf("AvailableAlgorithms() = %v, want [pbkdf2]", algs)
+	}
+	if _, err := h.HashWith(ctx, AlgorithmArgon2id, "password"); !errors.Is(err, ErrNotFIPSApproved) {
+		t.Fatalf("expected ErrNotFIPSApproved, got %v", err)
+	}
+
+	hash, err := h.Hash(ctx, "password")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if err := h.Compare(ctx, hash, "password"); err != nil {
+		t.Fatalf("compare pbkdf2: %v", err)
+	}
+	if len(warned) != 0 {
+		t.Fatalf("unexpected legacy warning for pbkdf2: %v", warned)
+	}
+
+	if err := h.Compare(ctx, legacyHash, "password"); err != nil {
+		t.Fatalf("compare legacy bcrypt: %v", err)
+	}
+	if err := h.Compare(ctx, legacyHash, "wrong"); !errors.Is(err, ErrPasswordMismatch) {
+		t.Fatalf("expected ErrPasswordMismatch, got %v", err)
+	}
+	if len(warned) != 2 || warned[0] != AlgorithmBcrypt {
+		t.Fatalf("legacy warnings = %v, want two for bcrypt", warned)
+	}
+}
+
+func TestHasherFIPSModeValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr error
+	}{
+		{
+			name:    "non-approved default",
+			cfg:     Config{FIPSMode: true, Default: AlgorithmArgon2id},
+			wantErr: ErrNotFIPSApproved,
+		},
+		{
+			name: "short salt",
+			cfg:  Config{FIPSMode: true, PBKDF2: PBKDF2Config{SaltLength: 8}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package khasher
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HashInfo describes an encoded hash, as returned by ParseHash. Only the
+// parameter struct of Algorithm is set.
+type HashInfo struct {
+	Algorithm Algorithm
+
+	// PepperKeyID is the pepper key the hash was created with, or "".
+	PepperKeyID string
+
+	// Argon2 holds the parameters of an Argon2id hash, with the key and salt
+	// lengths taken from the encoded values.
+	Argon2 Argon2Config
+	// Bcrypt holds the cost and PreHash setting of a bcrypt hash.
+	Bcrypt BcryptConfig
+	// PBKDF2 holds the parameters of a PBKDF2 hash.
+	PBKDF2 PBKDF2Config
+}
+
+// ParseHash detects the algorithm of an encoded hash and returns its
+// parameters without verifying a password, e.g. to audit stored hashes or find
+// the ones created with outdated parameters.
+func ParseHash(encoded string) (HashInfo, error) {
+	var info HashInfo
+	hashed := strings.TrimSpace(encoded)
+	if id, inner, ok := splitPepperedHash(hashed); ok {
+		info.PepperKeyID = id
+		hashed = inner
+	}
+
+	switch {
+	case strings.HasPrefix(hashed, "$argon2id$"):
+		params, salt, _, err := decodeArgon2Hash(hashed)
+		if err != nil {
+			return HashInfo{}, err
+		}
+		info.Algorithm = AlgorithmArgon2id
+		info.Argon2 = Argon2Config{
+			Time:        params.time,
+			Memory:      params.memory,
+			Parallelism: params.parallelism,
+			KeyLength:   params.keyLen,
+			SaltLength:  uint32(len(salt)),
+		}
+	case isBcryptHash(hashed):
+		inner, preHashed := strings.CutPrefix(hashed, bcryptPreHashPrefix)
+		cost, err := bcrypt.Cost([]byte(inner))
+		if err != nil {
+			return HashInfo{}, fmt.Errorf("%w: %v", ErrUnknownHashFormat, err)
+		}
+		info.Algorithm = AlgorithmBcrypt
+		info.Bcrypt = BcryptConfig{Cost: cost, PreHash: preHashed}
+	case strings.HasPrefix(hashed, "$pbkdf2-"):
+		params, salt, key, err := decodePBKDF2Hash(hashed)
+		if err != nil {
+			return HashInfo{}, err
+		}
+		info.Algorithm = AlgorithmPBKDF2
+		info.PBKDF2 = PBKDF2Config{
+			Digest:     params.digest,
+			Iterations: params.iterations,
+			KeyLength:  len(key),
+			SaltLength: len(salt),
+		}
+	default:
+		return HashInfo{}, ErrUnknownHashFormat
+	}
+
+	return info, nil
+}
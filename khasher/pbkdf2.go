@@ -0,0 +1,228 @@
+package khasher
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// PBKDF2Digest selects the HMAC hash function used by PBKDF2.
+type PBKDF2Digest string
+
+const (
+	// PBKDF2SHA256 uses HMAC-SHA-256.
+	PBKDF2SHA256 PBKDF2Digest = "sha256"
+	// PBKDF2SHA512 uses HMAC-SHA-512.
+	PBKDF2SHA512 PBKDF2Digest = "sha512"
+)
+
+// PBKDF2Config customizes the PBKDF2 parameters.
+type PBKDF2Config struct {
+	// Digest defaults to PBKDF2SHA256.
+	Digest PBKDF2Digest `yaml:"digest"`
+	// Iterations defaults to the OWASP recommendation for the digest:
+	// 600000 for SHA-256, 210000 for SHA-512.
+	Iterations int `yaml:"iterations"`
+	// KeyLength defaults to the digest size.
+	KeyLength  int `yaml:"key_length"`
+	SaltLength int `yaml:"salt_length"`
+	// MaxPasswordLength defaults to DefaultMaxPasswordLength.
+	MaxPasswordLength int `yaml:"max_password_length"`
+}
+
+func (c *PBKDF2Config) setDefaults() {
+	if c.Digest == "" {
+		c.Digest = PBKDF2SHA256
+	}
+	if c.Iterations == 0 {
+		c.Iterations = 600000
+		if c.Digest == PBKDF2SHA512 {
+			c.Iterations = 210000
+		}
+	}
+	if c.KeyLength == 0 {
+		c.KeyLength = 32
+		if c.Digest == PBKDF2SHA512 {
+			c.KeyLength = 64
+		}
+	}
+	if c.SaltLength == 0 {
+		c.SaltLength = 16
+	}
+	if c.MaxPasswordLength == 0 {
+		c.MaxPasswordLength = DefaultMaxPasswordLength
+	}
+}
+
+type pbkdf2Strategy struct {
+	cfg PBKDF2Config
+}
+
+func newPBKDF2Strategy(cfg PBKDF2Config) (strategy, error) {
+	if _, err := pbkdf2Hash(cfg.Digest); err != nil {
+		return nil, err
+	}
+	switch {
+	case cfg.SaltLength < 8:
+		return nil, errors.New("khasher: pbkdf2 salt length must be >= 8 bytes")
+	case cfg.SaltLength > 64:
+		return nil, errors.New("khasher: pbkdf2 salt length must be <= 64 bytes")
+	case cfg.KeyLength < 16:
+		return nil, errors.New("khasher: pbkdf2 key length must be >= 16 bytes")
+	case cfg.KeyLength > 128:
+		return nil, errors.New("khasher: pbkdf2 key length must be <= 128 bytes")
+	case cfg.Iterations < 1000:
+		return nil, errors.New("khasher: pbkdf2 iterations must be >= 1000")
+	case cfg.Iterations > 10000000:
+		return nil, errors.New("khasher: pbkdf2 iterations must be <= 10000000")
+	}
+	if err := validateMaxPasswordLength(AlgorithmPBKDF2, cfg.MaxPasswordLength); err != nil {
+		return nil, err
+	}
+
+	return &pbkdf2Strategy{cfg: cfg}, nil
+}
+
+func (s *pbkdf2Strategy) hash(ctx context.Context, password []byte) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	salt := make([]byte, s.cfg.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("khasher: generate salt: %w", err)
+	}
+
+	newHash, _ := pbkdf2Hash(s.cfg.Digest)
+	res, err := runKDF(ctx, password, func(password []byte) kdfResult {
+		// crypto/pbkdf2 takes the password as a string, a copy that cannot
+		// be wiped.
+		key, err := pbkdf2.Key(newHash, string(password), salt, s.cfg.Iterations, s.cfg.KeyLength)
+		return kdfResult{key, err}
+	})
+	if err != nil {
+		return "", err
+	}
+	if res.err != nil {
+		return "", fmt.Errorf("khasher: pbkdf2 hash: %w", res.err)
+	}
+	key := res.key
+	defer wipe(key)
+
+	return encodePBKDF2Hash(s.cfg.Digest, s.cfg.Iterations, salt, key), nil
+}
+
+func (s *pbkdf2Strategy) compare(ctx context.Context, encoded string, password []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	params, salt, key, err := decodePBKDF2Hash(encoded)
+	if err != nil {
+		return err
+	}
+
+	newHash, _ := pbkdf2Hash(params.digest)
+	res, err := runKDF(ctx, password, func(password []byte) kdfResult {
+		derived, err := pbkdf2.Key(newHash, string(password), salt, params.iterations, len(key))
+		return kdfResult{derived, err}
+	})
+	if err != nil {
+		return err
+	}
+	if res.err != nil {
+		return fmt.Errorf("khasher: pbkdf2 compare: %w", res.err)
+	}
+	defer wipe(res.key)
+	if subtle.ConstantTimeCompare(key, res.key) == 1 {
+		return nil
+	}
+	return ErrPasswordMismatch
+}
+
+func (s *pbkdf2Strategy) canHandle(hashed string) bool {
+	return strings.HasPrefix(hashed, "$pbkdf2-")
+}
+
+func (s *pbkdf2Strategy) maxPasswordLength() int {
+	return s.cfg.MaxPasswordLength
+}
+
+func pbkdf2Hash(digest PBKDF2Digest) (func() hash.Hash, error) {
+	switch digest {
+	case PBKDF2SHA256:
+		return sha256.New, nil
+	case PBKDF2SHA512:
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("khasher: unsupported pbkdf2 digest %q", digest)
+	}
+}
+
+// encodePBKDF2Hash uses the PHC string format: $pbkdf2-<digest>$i=<iterations>$<salt>$<key>.
+func encodePBKDF2Hash(digest PBKDF2Digest, iterations int, salt, key []byte) string {
+	return fmt.Sprintf("$pbkdf2-%s$i=%d$%s$%s",
+		digest,
+		iterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+type pbkdf2Params struct {
+	digest     PBKDF2Digest
+	iterations int
+}
+
+func decodePBKDF2Hash(encoded string) (pbkdf2Params, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 {
+		return pbkdf2Params{}, nil, nil, fmt.Errorf("%w: expected 5 parts separated by '$', got %d", ErrUnknownHashFormat, len(parts))
+	}
+
+	digest, ok := strings.CutPrefix(parts[1], "pbkdf2-")
+	if !ok {
+		return pbkdf2Params{}, nil, nil, fmt.Errorf("%w: expected 'pbkdf2' algorithm identifier, got %q", ErrUnknownHashFormat, parts[1])
+	}
+	params := pbkdf2Params{digest: PBKDF2Digest(digest)}
+	if _, err := pbkdf2Hash(params.digest); err != nil {
+		return pbkdf2Params{}, nil, nil, fmt.Errorf("%w: %v", ErrUnknownHashFormat, err)
+	}
+
+	iterations, ok := strings.CutPrefix(parts[2], "i=")
+	if !ok {
+		return pbkdf2Params{}, nil, nil, fmt.Errorf("%w: expected iteration parameter 'i=', got %q", ErrUnknownHashFormat, parts[2])
+	}
+	n, err := strconv.Atoi(iterations)
+	if err != nil || n <= 0 {
+		return pbkdf2Params{}, nil, nil, fmt.Errorf("khasher: invalid pbkdf2 iterations %q", iterations)
+	}
+	params.iterations = n
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return pbkdf2Params{}, nil, nil, fmt.Errorf("khasher: decode pbkdf2 salt from %q: %w", parts[3], err)
+	}
+	if len(salt) == 0 {
+		return pbkdf2Params{}, nil, nil, fmt.Errorf("khasher: pbkdf2 salt cannot be empty")
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return pbkdf2Params{}, nil, nil, fmt.Errorf("khasher: decode pbkdf2 hash from %q: %w", parts[4], err)
+	}
+	if len(key) == 0 {
+		return pbkdf2Params{}, nil, nil, fmt.Errorf("khasher: pbkdf2 hash cannot be empty")
+	}
+
+	return params, salt, key, nil
+}
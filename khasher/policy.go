@@ -0,0 +1,206 @@
+package khasher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrPolicyViolation is matched (errors.Is) by the *PolicyError returned when a
+// password does not satisfy the policy.
+var ErrPolicyViolation = errors.New("khasher: password policy violation")
+
+// ViolationCode identifies a failed policy rule, e.g. to pick a localized message.
+type ViolationCode string
+
+const (
+	ViolationTooShort      ViolationCode = "too_short"
+	ViolationTooLong       ViolationCode = "too_long"
+	ViolationMissingUpper  ViolationCode = "missing_upper"
+	ViolationMissingLower  ViolationCode = "missing_lower"
+	ViolationMissingDigit  ViolationCode = "missing_digit"
+	ViolationMissingSymbol ViolationCode = "missing_symbol"
+	ViolationTooFewClasses ViolationCode = "too_few_classes"
+	ViolationRepeated      ViolationCode = "repeated_chars"
+	ViolationBanned        ViolationCode = "banned"
+	ViolationBreached      ViolationCode = "breached"
+)
+
+// Violation is a failed policy rule.
+type Violation struct {
+	Code    ViolationCode
+	Message string
+}
+
+// PolicyError lists every rule a password failed.
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrPolicyViolation, strings.Join(msgs, "; "))
+}
+
+func (e *PolicyError) Is(target error) bool {
+	return target == ErrPolicyViolation
+}
+
+// Has reports whether the error contains a violation with the given code.
+func (e *PolicyError) Has(code ViolationCode) bool {
+	for _, v := range e.Violations {
+		if v.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy describes the rules new passwords must satisfy. Lengths are counted
+// in characters (runes), not bytes. The zero value accepts any password.
+type Policy struct {
+	MinLength int `yaml:"min_length"`
+	// MaxLength is 0 for no limit; the algorithm's byte limit still applies
+	// when hashing.
+	MaxLength int `yaml:"max_length"`
+
+	RequireUpper  bool `yaml:"require_upper"`
+	RequireLower  bool `yaml:"require_lower"`
+	RequireDigit  bool `yaml:"require_digit"`
+	RequireSymbol bool `yaml:"require_symbol"`
+	// MinCharClasses requires at least that many of the four classes (upper,
+	// lower, digit, symbol), e.g. 3 for "three of four" rules.
+	MinCharClasses int `yaml:"min_char_classes"`
+
+	// MaxRepeated is the longest allowed run of the same character
+	// ("aaa" is a run of 3); 0 for no limit.
+	MaxRepeated int `yaml:"max_repeated"`
+
+	// Banned lists passwords rejected regardless of the other rules, compared
+	// case-insensitively (e.g. common passwords, the product name).
+	Banned []string `yaml:"banned"`
+
+	// BreachCheck reports whether the password is known from a data breach,
+	// e.g. via the Have I Been Pwned range API. Its errors abort validation.
+	// It cannot be set from a config file.
+	BreachCheck func(ctx context.Context, password string) (bool, error) `yaml:"-"`
+}
+
+// Validate checks password against every rule and returns a *PolicyError
+// listing all violations, or nil.
+func (p Policy) Validate(ctx context.Context, password string) error {
+	var violations []Violation
+	add := func(code ViolationCode, format string, args ...any) {
+		violations = append(violations, Violation{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		add(ViolationTooShort, "must be at least %d characters", p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		add(ViolationTooLong, "must be at most %d characters", p.MaxLength)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		add(ViolationMissingUpper, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		add(ViolationMissingLower, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		add(ViolationMissingDigit, "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		add(ViolationMissingSymbol, "must contain a symbol")
+	}
+	if p.MinCharClasses > 0 {
+		classes := 0
+		for _, ok := range []bool{upper, lower, digit, symbol} {
+			if ok {
+				classes++
+			}
+		}
+		if classes < p.MinCharClasses {
+			add(ViolationTooFewClasses, "must contain at least %d of: uppercase, lowercase, digit, symbol", p.MinCharClasses)
+		}
+	}
+
+	if p.MaxRepeated > 0 && longestRun(password) > p.MaxRepeated {
+		add(ViolationRepeated, "must not repeat a character more than %d times in a row", p.MaxRepeated)
+	}
+
+	for _, banned := range p.Banned {
+		if strings.EqualFold(password, banned) {
+			add(ViolationBanned, "is too common")
+			break
+		}
+	}
+
+	if p.BreachCheck != nil {
+		breached, err := p.BreachCheck(ctx, password)
+		if err != nil {
+			return fmt.Errorf("khasher: breach check: %w", err)
+		}
+		if breached {
+			add(ViolationBreached, "has appeared in a data breach")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func (p Policy) validate() error {
+	switch {
+	case p.MinLength < 0 || p.MaxLength < 0 || p.MaxRepeated < 0:
+		return errors.New("khasher: policy lengths must be >= 0")
+	case p.MaxLength > 0 && p.MinLength > p.MaxLength:
+		return fmt.Errorf("khasher: policy min length %d exceeds max length %d", p.MinLength, p.MaxLength)
+	case p.MinCharClasses < 0 || p.MinCharClasses > 4:
+		return errors.New("khasher: policy min char classes must be between 0 and 4")
+	}
+	return nil
+}
+
+// ValidatePolicy checks password against Config.Policy (see Policy.Validate).
+func (h *Hasher) ValidatePolicy(ctx context.Context, password string) error {
+	return h.policy.Validate(ctx, password)
+}
+
+func longestRun(s string) int {
+	longest, run := 0, 0
+	var prev rune = -1
+	for _, r := range s {
+		if r == prev {
+			run++
+		} else {
+			prev, run = r, 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
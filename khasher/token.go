@@ -0,0 +1,94 @@
+package khasher
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTokenEmpty indicates that the token is empty.
+var ErrTokenEmpty = errors.New("khasher: token cannot be empty")
+
+// Comparer verifies a secret against its stored hash. It is implemented by
+// Hasher (passwords) and TokenHasher (API keys and session tokens).
+type Comparer interface {
+	Compare(ctx context.Context, hashed, secret string) error
+}
+
+var (
+	_ Comparer = (*Hasher)(nil)
+	_ Comparer = (*TokenHasher)(nil)
+)
+
+// TokenConfig configures a TokenHasher.
+type TokenConfig struct {
+	// Key is the HMAC secret, at least 32 bytes.
+	Key []byte
+}
+
+const tokenPrefix = "$hmac-sha256$"
+
+// TokenHasher hashes high-entropy random secrets (API keys, session IDs,
+// reset tokens) with HMAC-SHA-256. The hash is deterministic, so a stored
+// token can be looked up by hashing the presented one; it must never be used
+// for passwords, which need the slow, salted algorithms of Hasher.
+//
+// Hashes look like $hmac-sha256$<base64url digest>. Hasher.Compare does not
+// accept them, and TokenHasher does not accept password hashes.
+type TokenHasher struct {
+	key []byte
+}
+
+// NewTokenHasher constructs a TokenHasher.
+func NewTokenHasher(cfg TokenConfig) (*TokenHasher, error) {
+	if len(cfg.Key) < 32 {
+		return nil, errors.New("khasher: token key must be >= 32 bytes")
+	}
+	return &TokenHasher{key: append([]byte(nil), cfg.Key...)}, nil
+}
+
+// Hash returns the deterministic hash of token, suitable as a lookup key.
+func (t *TokenHasher) Hash(ctx context.Context, token string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", ErrTokenEmpty
+	}
+	return tokenPrefix + base64.RawURLEncoding.EncodeToString(t.sum(token)), nil
+}
+
+// Compare checks token against hashed in constant time. Like Hasher.Compare,
+// it returns ErrPasswordMismatch when they differ.
+func (t *TokenHasher) Compare(ctx context.Context, hashed, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if token == "" {
+		return ErrTokenEmpty
+	}
+
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(hashed), tokenPrefix)
+	if !ok {
+		return ErrUnknownHashFormat
+	}
+	want, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(want) != sha256.Size {
+		return fmt.Errorf("%w: invalid hmac-sha256 digest", ErrUnknownHashFormat)
+	}
+
+	if hmac.Equal(want, t.sum(token)) {
+		return nil
+	}
+	return ErrPasswordMismatch
+}
+
+func (t *TokenHasher) sum(token string) []byte {
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write([]byte(token))
+	return mac.Sum(nil)
+}
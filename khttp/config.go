@@ -0,0 +1,150 @@
+package khttp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/karu-codes/karu-kits/config"
+)
+
+var (
+	// ErrInvalidConfig is returned by Validate and New for an invalid Config.
+	ErrInvalidConfig = errors.New("khttp: invalid config")
+
+	// ErrServerClosed is returned by Run and Serve when Shutdown is called
+	// directly, and by Shutdown when it is called more than once.
+	ErrServerClosed = errors.New("khttp: server closed")
+)
+
+// Config configures a Server, loadable with the config package. As a
+// `yaml:"http"` field of the application config, its values can be overridden
+// with HTTP_ADDR, HTTP_READ_TIMEOUT, HTTP_SHUTDOWN_TIMEOUT, ...
+//
+//	http:
+//	  addr: :8080
+//	  write_timeout: 1m
+//	  shutdown_delay: 5s
+//
+// Zero fields take the defaults below (see SetDefaults).
+type Config struct {
+	// Addr is the TCP address to listen on. Default: ":8080".
+	Addr string `yaml:"addr"`
+
+	// ReadHeaderTimeout bounds reading the request headers. Default: 10s.
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+
+	// ReadTimeout bounds reading the whole request, body included.
+	// Default: 30s.
+	ReadTimeout time.Duration `yaml:"read_timeout"`
+
+	// WriteTimeout bounds the time from the end of the request headers to the
+	// end of the response. Default: 30s.
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+
+	// IdleTimeout bounds the wait for the next request on a keep-alive
+	// connection. Default: 2m.
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+
+	// MaxHeaderBytes limits the size of the request headers. Default: 1 MiB.
+	MaxHeaderBytes int `yaml:"max_header_bytes"`
+
+	// ShutdownDelay is how long the server keeps serving after a shutdown
+	// started, with the readiness endpoint failing, so load balancers stop
+	// routing to it before connections are closed. Default: 0.
+	ShutdownDelay time.Duration `yaml:"shutdown_delay"`
+
+	// ShutdownTimeout bounds the wait for in-flight requests on shutdown,
+	// after which the remaining connections are closed. Default: 30s.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// HealthPath serves the liveness checks. Default: "/healthz".
+	HealthPath string `yaml:"health_path"`
+
+	// ReadinessPath serves the readiness checks. Default: "/readyz".
+	ReadinessPath string `yaml:"readiness_path"`
+
+	// HealthCheckTimeout bounds each health check. Default: 2s.
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout"`
+}
+
+// DefaultConfig returns a Config with the default values.
+func DefaultConfig() Config {
+	var cfg Config
+	cfg.SetDefaults()
+	return cfg
+}
+
+// SetDefaults sets the zero fields of c to their defaults. It implements
+// config.Defaulter, so config.Load applies the defaults before the file and
+// environment values.
+func (c *Config) SetDefaults() {
+	if c.Addr == "" {
+		c.Addr = ":8080"
+	}
+	if c.ReadHeaderTimeout == 0 {
+		c.ReadHeaderTimeout = 10 * time.Second
+	}
+	if c.ReadTimeout == 0 {
+		c.ReadTimeout = 30 * time.Second
+	}
+	if c.WriteTimeout == 0 {
+		c.WriteTimeout = 30 * time.Second
+	}
+	if c.IdleTimeout == 0 {
+		c.IdleTimeout = 2 * time.Minute
+	}
+	if c.MaxHeaderBytes == 0 {
+		c.MaxHeaderBytes = 1 << 20
+	}
+	if c.ShutdownTimeout == 0 {
+		c.ShutdownTimeout = 30 * time.Second
+	}
+	if c.HealthPath == "" {
+		c.HealthPath = "/healthz"
+	}
+	if c.ReadinessPath == "" {
+		c.ReadinessPath = "/readyz"
+	}
+	if c.HealthCheckTimeout == 0 {
+		c.HealthCheckTimeout = 2 * time.Second
+	}
+}
+
+// Validate checks the configuration after defaults are applied.
+func (c *Config) Validate() error {
+	if c.ReadHeaderTimeout < 0 || c.ReadTimeout < 0 || c.WriteTimeout < 0 || c.IdleTimeout < 0 {
+		return fmt.Errorf("%w: timeouts must not be negative", ErrInvalidConfig)
+	}
+	if c.ShutdownDelay < 0 || c.ShutdownTimeout < 0 || c.HealthCheckTimeout < 0 {
+		return fmt.Errorf("%w: timeouts must not be negative", ErrInvalidConfig)
+	}
+	if c.MaxHeaderBytes < 0 {
+		return fmt.Errorf("%w: max_header_bytes must not be negative", ErrInvalidConfig)
+	}
+	if !strings.HasPrefix(c.HealthPath, "/") || !strings.HasPrefix(c.ReadinessPath, "/") {
+		return fmt.Errorf("%w: health paths must start with /", ErrInvalidConfig)
+	}
+	if c.HealthPath == c.ReadinessPath {
+		return fmt.Errorf("%w: health_path and readiness_path must differ", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// LoadConfig loads a Config from the file at path with config.Load, e.g. for
+// a service whose config file only holds the server settings. Keys follow the
+// yaml tags of Config and environment variables are inferred from them (ADDR,
+// SHUTDOWN_TIMEOUT, ... plus the config.WithEnvPrefix prefix). To embed the
+// server settings in a larger application config, add a Config field to it
+// and pass that field to New instead.
+func LoadConfig(path string, opts ...config.Option) (Config, error) {
+	var cfg Config
+	if err := config.Load(path, &cfg, opts...); err != nil {
+		return Config{}, fmt.Errorf("khttp: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,91 @@
+package khttp
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/karu-codes/karu-kits/config"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if cfg.Addr != ":8080" || cfg.ReadHeaderTimeout != 10*time.Second || cfg.ShutdownTimeout != 30*time.Second {
+		t.Errorf("Unexpected defaults %+v", cfg)
+	}
+	if cfg.HealthPath != "/healthz" || cfg.ReadinessPath != "/readyz" {
+		t.Errorf("Unexpected health paths %q %q", cfg.HealthPath, cfg.ReadinessPath)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{"negative timeout", func(c *Config) { c.WriteTimeout = -time.Second }},
+		{"negative shutdown delay", func(c *Config) { c.ShutdownDelay = -time.Second }},
+		{"negative max header bytes", func(c *Config) { c.MaxHeaderBytes = -1 }},
+		{"relative health path", func(c *Config) { c.HealthPath = "healthz" }},
+		{"same health paths", func(c *Config) { c.ReadinessPath = c.HealthPath }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(&cfg)
+			if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Expected ErrInvalidConfig, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	fsys := fstest.MapFS{
+		"server.yaml": {Data: []byte(`
+addr: :9090
+write_timeout: 1m
+shutdown_delay: 5s
+`)},
+	}
+	env := map[string]string{"APP_SHUTDOWN_TIMEOUT": "10s"}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	cfg, err := LoadConfig("server.yaml",
+		config.WithFileSystem(fsys),
+		config.WithEnvPrefix("APP"),
+		config.WithEnvLookup(lookup),
+	)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if cfg.Addr != ":9090" || cfg.WriteTimeout != time.Minute || cfg.ShutdownDelay != 5*time.Second {
+		t.Errorf("Expected the file values, got %+v", cfg)
+	}
+	if cfg.ShutdownTimeout != 10*time.Second {
+		t.Errorf("Expected the env override, got %v", cfg.ShutdownTimeout)
+	}
+	if cfg.ReadTimeout != 30*time.Second || cfg.HealthPath != "/healthz" {
+		t.Errorf("Expected the defaults for missing keys, got %+v", cfg)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	fsys := fstest.MapFS{
+		"server.yaml": {Data: []byte("health_path: healthz\n")},
+	}
+	_, err := LoadConfig("server.yaml", config.WithFileSystem(fsys), config.WithoutEnv())
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
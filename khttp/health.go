@@ -0,0 +1,132 @@
+package khttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/karu-codes/karu-kits/kdbx"
+)
+
+// Checker reports the health of a dependency. kdbx.HealthChecker and
+// kcache.HealthChecker implement it; readiness checks call their
+// CheckDetailed method instead of Check.
+type Checker interface {
+	Check(ctx context.Context) *kdbx.HealthCheck
+}
+
+// detailedChecker is implemented by checkers with a more thorough check.
+type detailedChecker interface {
+	CheckDetailed(ctx context.Context) *kdbx.HealthCheck
+}
+
+// CheckerFunc adapts a function to a Checker.
+type CheckerFunc func(ctx context.Context) *kdbx.HealthCheck
+
+// Check calls f.
+func (f CheckerFunc) Check(ctx context.Context) *kdbx.HealthCheck {
+	return f(ctx)
+}
+
+// PingChecker adapts a ping function, such as kpgx.DB.Ping or a Redis client
+// ping, to a Checker that is unhealthy when ping fails.
+func PingChecker(ping func(ctx context.Context) error) Checker {
+	return CheckerFunc(func(ctx context.Context) *kdbx.HealthCheck {
+		start := time.Now()
+		err := ping(ctx)
+		check := &kdbx.HealthCheck{
+			Status:    kdbx.HealthStatusHealthy,
+			Timestamp: start,
+			Duration:  time.Since(start),
+		}
+		if err != nil {
+			check.Status = kdbx.HealthStatusUnhealthy
+			check.Message = err.Error()
+		}
+		return check
+	})
+}
+
+type namedChecker struct {
+	name    string
+	checker Checker
+}
+
+// runChecks runs the checks concurrently, each bounded by timeout, and
+// combines them into one HealthCheck whose Details holds the result of every
+// check by name. The overall status is the worst status of the checks.
+func runChecks(ctx context.Context, checks []namedChecker, timeout time.Duration, detailed bool) *kdbx.HealthCheck {
+	start := time.Now()
+	results := make([]*kdbx.HealthCheck, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			results[i] = runCheck(checkCtx, c.checker, detailed)
+		}()
+	}
+	wg.Wait()
+
+	overall := &kdbx.HealthCheck{
+		Status:    kdbx.HealthStatusHealthy,
+		Timestamp: start,
+		Duration:  time.Since(start),
+	}
+	if len(checks) == 0 {
+		return overall
+	}
+
+	overall.Details = make(map[string]interface{}, len(checks))
+	var failing []string
+	for i, c := range checks {
+		result := results[i]
+		overall.Details[c.name] = result
+		if result.IsHealthy() {
+			continue
+		}
+		failing = append(failing, c.name)
+		if !result.IsDegraded() {
+			overall.Status = kdbx.HealthStatusUnhealthy
+		} else if overall.IsHealthy() {
+			overall.Status = kdbx.HealthStatusDegraded
+		}
+	}
+	if len(failing) > 0 {
+		sort.Strings(failing)
+		overall.Message = "failing checks: " + strings.Join(failing, ", ")
+	}
+	return overall
+}
+
+func runCheck(ctx context.Context, checker Checker, detailed bool) *kdbx.HealthCheck {
+	var check *kdbx.HealthCheck
+	if dc, ok := checker.(detailedChecker); ok && detailed {
+		check = dc.CheckDetailed(ctx)
+	} else {
+		check = checker.Check(ctx)
+	}
+	if check == nil {
+		return &kdbx.HealthCheck{
+			Status:    kdbx.HealthStatusUnhealthy,
+			Message:   "health check returned no result",
+			Timestamp: time.Now(),
+		}
+	}
+	return check
+}
+
+// writeHealth writes check as JSON with its HTTP status code.
+func writeHealth(w http.ResponseWriter, check *kdbx.HealthCheck) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(check.HTTPStatusCode())
+	_ = json.NewEncoder(w).Encode(check)
+}
@@ -0,0 +1,105 @@
+package khttp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/karu-codes/karu-kits/kdbx"
+)
+
+func staticChecker(status kdbx.HealthStatus) Checker {
+	return CheckerFunc(func(context.Context) *kdbx.HealthCheck {
+		return &kdbx.HealthCheck{Status: status, Timestamp: time.Now()}
+	})
+}
+
+type detailedFake struct {
+	detailedCalled bool
+}
+
+func (f *detailedFake) Check(context.Context) *kdbx.HealthCheck {
+	return &kdbx.HealthCheck{Status: kdbx.HealthStatusHealthy}
+}
+
+func (f *detailedFake) CheckDetailed(context.Context) *kdbx.HealthCheck {
+	f.detailedCalled = true
+	return &kdbx.HealthCheck{Status: kdbx.HealthStatusHealthy}
+}
+
+func TestRunChecks(t *testing.T) {
+	tests := []struct {
+		name    string
+		checks  []namedChecker
+		want    kdbx.HealthStatus
+		message string
+	}{
+		{"no checks", nil, kdbx.HealthStatusHealthy, ""},
+		{"all healthy", []namedChecker{
+			{"db", staticChecker(kdbx.HealthStatusHealthy)},
+			{"cache", staticChecker(kdbx.HealthStatusHealthy)},
+		}, kdbx.HealthStatusHealthy, ""},
+		{"degraded", []namedChecker{
+			{"db", staticChecker(kdbx.HealthStatusDegraded)},
+			{"cache", staticChecker(kdbx.HealthStatusHealthy)},
+		}, kdbx.HealthStatusDegraded, "failing checks: db"},
+		{"unhealthy wins", []namedChecker{
+			{"db", staticChecker(kdbx.HealthStatusDegraded)},
+			{"cache", staticChecker(kdbx.HealthStatusUnhealthy)},
+		}, kdbx.HealthStatusUnhealthy, "failing checks: cache, db"},
+		{"nil result", []namedChecker{
+			{"db", CheckerFunc(func(context.Context) *kdbx.HealthCheck { return nil })},
+		}, kdbx.HealthStatusUnhealthy, "failing checks: db"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runChecks(context.Background(), tt.checks, time.Second, false)
+			if got.Status != tt.want || got.Message != tt.message {
+				t.Errorf("Expected %s %q, got %s %q", tt.want, tt.message, got.Status, got.Message)
+			}
+			if len(got.Details) != len(tt.checks) {
+				t.Errorf("Expected one detail per check, got %v", got.Details)
+			}
+		})
+	}
+}
+
+func TestRunChecksTimeout(t *testing.T) {
+	slow := PingChecker(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	got := runChecks(context.Background(), []namedChecker{{"slow", slow}}, 10*time.Millisecond, false)
+	if !got.IsUnhealthy() {
+		t.Fatalf("Expected a timed out check to be unhealthy, got %s", got.Status)
+	}
+	if check := got.Details["slow"].(*kdbx.HealthCheck); check.Message != context.DeadlineExceeded.Error() {
+		t.Errorf("Expected the ping error as message, got %q", check.Message)
+	}
+}
+
+func TestRunChecksDetailed(t *testing.T) {
+	checker := &detailedFake{}
+
+	runChecks(context.Background(), []namedChecker{{"db", checker}}, time.Second, false)
+	if checker.detailedCalled {
+		t.Error("Expected Check for liveness")
+	}
+	runChecks(context.Background(), []namedChecker{{"db", checker}}, time.Second, true)
+	if !checker.detailedCalled {
+		t.Error("Expected CheckDetailed for readiness")
+	}
+}
+
+func TestPingChecker(t *testing.T) {
+	if check := PingChecker(func(context.Context) error { return nil }).Check(context.Background()); !check.IsHealthy() {
+		t.Errorf("Expected healthy, got %s", check.Status)
+	}
+	check := PingChecker(func(context.Context) error { return errors.New("refused") }).Check(context.Background())
+	if !check.IsUnhealthy() || check.Message != "refused" {
+		t.Errorf("Expected unhealthy with the error, got %s %q", check.Status, check.Message)
+	}
+}
@@ -0,0 +1,199 @@
+// Package khttp provides an HTTP server with the defaults every service
+// needs: timeouts, graceful shutdown, liveness and readiness endpoints backed
+// by kdbx-style health checkers, panic recovery through the errors package,
+// request IDs and request logging with klog.
+//
+// Example:
+//
+//	cfg, err := khttp.LoadConfig("server.yaml", config.WithEnvPrefix("APP"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	srv, err := khttp.New(cfg, mux,
+//	    khttp.WithLogger(logger),
+//	    khttp.WithReadinessCheck("postgres", kdbx.NewHealthChecker(db)),
+//	    khttp.WithReadinessCheck("redis", kcache.NewHealthChecker(cache)),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+//	defer stop()
+//	if err := srv.Run(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+package khttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/karu-codes/karu-kits/kdbx"
+	"github.com/karu-codes/karu-kits/klog"
+)
+
+// Server is an HTTP server. Create it with New and start it with Run.
+type Server struct {
+	cfg    Config
+	opts   *options
+	logger *slog.Logger
+
+	handler http.Handler
+	srv     *http.Server
+
+	shuttingDown atomic.Bool
+}
+
+// New creates a Server serving handler with cfg, whose zero fields take their
+// defaults. Requests go through, outermost first: klog.RequestIDMiddleware,
+// klog.HTTPMiddleware, Recover, then the health endpoints or handler.
+func New(cfg Config, handler http.Handler, opts ...Option) (*Server, error) {
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if handler == nil {
+		handler = http.NotFoundHandler()
+	}
+
+	o := applyOptions(opts)
+	s := &Server{
+		cfg:    cfg,
+		opts:   o,
+		logger: o.logger,
+	}
+
+	logOptions := append([]klog.HTTPOption{klog.WithHTTPSkipPaths(cfg.HealthPath, cfg.ReadinessPath)}, o.logOptions...)
+	s.handler = klog.RequestIDMiddleware(
+		klog.HTTPMiddleware(s.logger, logOptions...)(
+			Recover(s.logger)(s.routes(handler)),
+		),
+	)
+
+	s.srv = &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           s.handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(s.logger.Handler(), slog.LevelWarn),
+	}
+
+	return s, nil
+}
+
+// routes serves the health endpoints and passes other requests to handler.
+func (s *Server) routes(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case s.cfg.HealthPath:
+			writeHealth(w, runChecks(r.Context(), s.opts.liveness, s.cfg.HealthCheckTimeout, false))
+		case s.cfg.ReadinessPath:
+			writeHealth(w, s.readiness(r.Context()))
+		default:
+			handler.ServeHTTP(w, r)
+		}
+	})
+}
+
+// readiness fails as soon as a shutdown starts, so load balancers stop
+// routing new requests during the shutdown delay.
+func (s *Server) readiness(ctx context.Context) *kdbx.HealthCheck {
+	if s.shuttingDown.Load() {
+		return &kdbx.HealthCheck{
+			Status:    kdbx.HealthStatusUnhealthy,
+			Message:   "shutting down",
+			Timestamp: time.Now(),
+		}
+	}
+	return runChecks(ctx, s.opts.readiness, s.cfg.HealthCheckTimeout, true)
+}
+
+// Handler returns the handler of the server with its middlewares and health
+// endpoints, e.g. for httptest.
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// HTTPServer returns the underlying http.Server, e.g. to set TLSConfig or
+// ConnState before Run.
+func (s *Server) HTTPServer() *http.Server {
+	return s.srv
+}
+
+// Run listens on Config.Addr and serves until ctx is done, then shuts down
+// gracefully (see Shutdown). It returns nil after a clean shutdown. When
+// Shutdown is called directly instead, Run returns ErrServerClosed as soon as
+// the listener is closed; wait for Shutdown to return before exiting.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("khttp: listen on %s: %w", s.cfg.Addr, err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve is like Run, but accepts connections on ln.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.srv.Serve(ln)
+	}()
+	s.logger.InfoContext(ctx, "http server started", slog.String("addr", ln.Addr().String()))
+
+	select {
+	case err := <-errc:
+		if errors.Is(err, http.ErrServerClosed) {
+			return ErrServerClosed
+		}
+		return fmt.Errorf("khttp: serve: %w", err)
+	case <-ctx.Done():
+		return s.Shutdown(context.WithoutCancel(ctx))
+	}
+}
+
+// Shutdown stops the server gracefully: the readiness endpoint starts failing,
+// the server keeps serving for Config.ShutdownDelay, then stops accepting
+// connections and waits up to Config.ShutdownTimeout (or until ctx is done)
+// for in-flight requests. Connections still open after that are closed and
+// an error is returned.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if !s.shuttingDown.CompareAndSwap(false, true) {
+		return ErrServerClosed
+	}
+	s.logger.InfoContext(ctx, "http server shutting down",
+		slog.Duration("delay", s.cfg.ShutdownDelay),
+		slog.Duration("timeout", s.cfg.ShutdownTimeout),
+	)
+
+	if s.cfg.ShutdownDelay > 0 {
+		timer := time.NewTimer(s.cfg.ShutdownDelay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, s.cfg.ShutdownTimeout)
+	defer cancel()
+	if err := s.srv.Shutdown(shutdownCtx); err != nil {
+		_ = s.srv.Close()
+		err = fmt.Errorf("khttp: shutdown: %w", err)
+		s.logger.ErrorContext(ctx, "http server shutdown failed", slog.Any("error", err))
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "http server stopped")
+	return nil
+}
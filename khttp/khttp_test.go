@@ -0,0 +1,244 @@
+package khttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/karu-codes/karu-kits/kdbx"
+	"github.com/karu-codes/karu-kits/klog"
+)
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of a server.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestServer(t *testing.T, handler http.Handler, opts ...Option) (*Server, *syncBuffer) {
+	t.Helper()
+
+	logs := &syncBuffer{}
+	logger := slog.New(slog.NewJSONHandler(logs, nil))
+	srv, err := New(Config{ShutdownTimeout: time.Second}, handler, append([]Option{WithLogger(logger)}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return srv, logs
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	if _, err := New(Config{HealthPath: "health"}, nil); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestServerHandler(t *testing.T) {
+	srv, logs := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := klog.RequestIDFromContext(r.Context()); !ok {
+			t.Error("Expected a request ID in the context")
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, "created")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.Header.Set(klog.RequestIDHeader, "req-1")
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated || rec.Header().Get(klog.RequestIDHeader) != "req-1" {
+		t.Errorf("Expected 201 with the request ID, got %d %q", rec.Code, rec.Header().Get(klog.RequestIDHeader))
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(logs.String()), &entry); err != nil {
+		t.Fatalf("Expected one JSON log entry, got %s", logs.String())
+	}
+	if entry["msg"] != "http request" || entry["http.path"] != "/orders" || entry["http.status_code"] != float64(201) ||
+		entry["http.response_size"] != float64(7) || entry[klog.RequestIDKey] != "req-1" {
+		t.Errorf("Unexpected log entry %v", entry)
+	}
+}
+
+func TestServerHandlerPanic(t *testing.T) {
+	srv, logs := newTestServer(t, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d", rec.Code)
+	}
+	var levels []string
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to decode log entry %s: %v", line, err)
+		}
+		levels = append(levels, entry["level"].(string)+" "+entry["msg"].(string))
+	}
+	if strings.Join(levels, ", ") != "ERROR http handler panic, ERROR http request" {
+		t.Errorf("Expected the panic and the request to be logged as errors, got %v", levels)
+	}
+}
+
+func TestServerHealthEndpoints(t *testing.T) {
+	dbStatus := kdbx.HealthStatusHealthy
+	var mu sync.Mutex
+	db := CheckerFunc(func(context.Context) *kdbx.HealthCheck {
+		mu.Lock()
+		defer mu.Unlock()
+		return &kdbx.HealthCheck{Status: dbStatus}
+	})
+
+	srv, logs := newTestServer(t, nil, WithReadinessCheck("db", db))
+
+	get := func(path string) (int, kdbx.HealthCheck) {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var check kdbx.HealthCheck
+		if err := json.Unmarshal(rec.Body.Bytes(), &check); err != nil {
+			t.Fatalf("Failed to decode %s: %v", path, err)
+		}
+		return rec.Code, check
+	}
+
+	if code, check := get("/healthz"); code != http.StatusOK || !check.IsHealthy() {
+		t.Errorf("Expected a healthy liveness, got %d %s", code, check.Status)
+	}
+	if code, check := get("/readyz"); code != http.StatusOK || check.Details["db"] == nil {
+		t.Errorf("Expected a healthy readiness with the db check, got %d %+v", code, check)
+	}
+
+	mu.Lock()
+	dbStatus = kdbx.HealthStatusUnhealthy
+	mu.Unlock()
+	if code, check := get("/readyz"); code != http.StatusServiceUnavailable || check.Message != "failing checks: db" {
+		t.Errorf("Expected an unhealthy readiness, got %d %+v", code, check)
+	}
+	if code, _ := get("/healthz"); code != http.StatusOK {
+		t.Errorf("Expected liveness to ignore readiness checks, got %d", code)
+	}
+
+	if logs.String() != "" {
+		t.Errorf("Expected health requests not to be logged, got %s", logs.String())
+	}
+}
+
+func TestServerServeAndShutdown(t *testing.T) {
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	srv, _ := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-finish
+		_, _ = io.WriteString(w, "done")
+	}))
+	srv.cfg.ShutdownDelay = 50 * time.Millisecond
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	base := "http://" + ln.Addr().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx, ln) }()
+
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(base + "/slow")
+		if err != nil {
+			t.Errorf("GET /slow error = %v", err)
+			body <- ""
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		body <- string(b)
+	}()
+	<-started
+
+	cancel()
+
+	// Readiness fails during the shutdown delay, while requests are served.
+	deadline := time.Now().Add(time.Second)
+	for {
+		resp, err := http.Get(base + "/readyz")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusServiceUnavailable {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected readiness to fail during shutdown")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	close(finish)
+	if got := <-body; got != "done" {
+		t.Errorf("Expected the in-flight request to complete, got %q", got)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+
+	if err := srv.Shutdown(context.Background()); !errors.Is(err, ErrServerClosed) {
+		t.Errorf("Expected ErrServerClosed on a second Shutdown, got %v", err)
+	}
+}
+
+func TestServerShutdownTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	srv, _ := newTestServer(t, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		close(started)
+		<-release
+	}))
+	srv.cfg.ShutdownTimeout = 20 * time.Millisecond
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	go func() { _ = srv.Serve(context.Background(), ln) }()
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/stuck")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	if err := srv.Shutdown(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the shutdown timeout, got %v", err)
+	}
+}
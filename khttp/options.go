@@ -0,0 +1,62 @@
+package khttp
+
+import (
+	"log/slog"
+
+	"github.com/karu-codes/karu-kits/klog"
+)
+
+// Option configures a Server.
+type Option func(*options)
+
+type options struct {
+	logger     *slog.Logger
+	logOptions []klog.HTTPOption
+	liveness   []namedChecker
+	readiness  []namedChecker
+}
+
+func applyOptions(opts []Option) *options {
+	o := &options{logger: slog.Default()}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// WithLogger sets the logger of the server, its requests and its panics.
+// Default: slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithLogOptions configures the request logging (see klog.HTTPMiddleware).
+// Requests to the health endpoints are never logged.
+func WithLogOptions(opts ...klog.HTTPOption) Option {
+	return func(o *options) {
+		o.logOptions = append(o.logOptions, opts...)
+	}
+}
+
+// WithLivenessCheck adds a check to the health endpoint. Liveness failures
+// make orchestrators restart the process, so only check what a restart
+// fixes; most services need none.
+func WithLivenessCheck(name string, checker Checker) Option {
+	return func(o *options) {
+		o.liveness = append(o.liveness, namedChecker{name: name, checker: checker})
+	}
+}
+
+// WithReadinessCheck adds a check to the readiness endpoint, typically one
+// per database or cache the service cannot work without.
+func WithReadinessCheck(name string, checker Checker) Option {
+	return func(o *options) {
+		o.readiness = append(o.readiness, namedChecker{name: name, checker: checker})
+	}
+}
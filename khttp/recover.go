@@ -0,0 +1,85 @@
+package khttp
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+// Recover returns a middleware that converts panics of the handler into
+// CodeInternal errors (see errors.Recover), logs them with their stack and
+// writes them with WriteError. When the handler had already started the
+// response, the connection is aborted instead, so the client does not take a
+// truncated response for a complete one.
+func Recover(logger *slog.Logger) func(http.Handler) http.Handler {
+	if logger == nil {
+		panic("khttp: nil slog logger")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &responseWriter{ResponseWriter: w}
+			err := kerrors.RecoverFunc(func() error {
+				next.ServeHTTP(rw, r)
+				return nil
+			})
+			if err == nil {
+				return
+			}
+
+			logger.LogAttrs(r.Context(), slog.LevelError, "http handler panic",
+				slog.String("http.method", r.Method),
+				slog.String("http.path", r.URL.Path),
+				slog.Any("error", err),
+			)
+			if rw.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+			WriteError(w, r, err)
+		})
+	}
+}
+
+// WriteError writes err as an errors.HTTPResponse JSON body with the HTTP
+// status code of its error code, like the errorsgin and errorsecho
+// middlewares. The message is translated into the preferred Accept-Language
+// when a translator is configured.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	lang := kerrors.PreferredLanguage(r.Header.Get("Accept-Language"))
+	resp := kerrors.ToHTTPResponseWithOptions(err, kerrors.HTTPOptions{Language: lang})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// responseWriter records whether the response was started.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if status >= 200 {
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher for handlers that stream responses.
+func (w *responseWriter) Flush() {
+	w.wroteHeader = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying ResponseWriter.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,65 @@
+package khttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+func TestRecover(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	handler := Recover(logger)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d", rec.Code)
+	}
+	var resp kerrors.HTTPResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode the response: %v", err)
+	}
+	if resp.Error.Code != kerrors.CodeInternal.String() {
+		t.Errorf("Expected %s, got %+v", kerrors.CodeInternal, resp.Error)
+	}
+	if !strings.Contains(logs.String(), `"msg":"http handler panic"`) || !strings.Contains(logs.String(), "/orders") {
+		t.Errorf("Expected the panic to be logged, got %s", logs.String())
+	}
+}
+
+func TestRecoverAfterWrite(t *testing.T) {
+	handler := Recover(slog.New(slog.DiscardHandler))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("boom")
+	}))
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("Expected http.ErrAbortHandler, got %v", v)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestWriteError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, httptest.NewRequest(http.MethodGet, "/", nil), kerrors.New(kerrors.CodeNotFound, "order not found"))
+
+	if rec.Code != http.StatusNotFound || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a 404 JSON response, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "order not found") {
+		t.Errorf("Expected the message in the body, got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,228 @@
+// Package klock provides distributed locks backed by Redis or PostgreSQL
+// advisory locks behind one Locker interface, with WithLock helpers that run
+// a function while the lock is held.
+//
+// Example:
+//
+//	locker := klock.NewRedisLocker(cache.Client(), klock.WithTTL(30*time.Second))
+//
+//	err := klock.WithLock(ctx, locker, "invoices:monthly", func(ctx context.Context) error {
+//	    lock, _ := klock.FromContext(ctx)
+//	    return billing.Run(ctx, lock.Token()) // pass the fencing token to the storage layer
+//	})
+package klock
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotAcquired is returned by TryAcquire and TryWithLock when the lock
+	// is held by someone else.
+	ErrNotAcquired = errors.New("klock: lock is held by another owner")
+
+	// ErrNotHeld is returned by Release when the lock had already expired,
+	// been lost or been released.
+	ErrNotHeld = errors.New("klock: lock is not held")
+
+	// ErrLockLost is the cause of the context passed to WithLock functions
+	// when the lock is lost while they run, and is returned by WithLock then.
+	ErrLockLost = errors.New("klock: lock lost")
+)
+
+// Locker acquires named locks. Implementations are safe for concurrent use.
+type Locker interface {
+	// Acquire blocks until the lock for key is acquired or ctx is done.
+	Acquire(ctx context.Context, key string) (Lock, error)
+
+	// TryAcquire acquires the lock for key if it is free and returns
+	// ErrNotAcquired otherwise.
+	TryAcquire(ctx context.Context, key string) (Lock, error)
+}
+
+// Lock is an acquired lock.
+type Lock interface {
+	// Key returns the key the lock was acquired for.
+	Key() string
+
+	// Token returns the fencing token of the lock: a number that increases
+	// with every acquisition of the key. Storage written under the lock can
+	// reject writes carrying a lower token than one already seen, so a holder
+	// that was paused past its lease cannot overwrite the work of the next
+	// one. Implementations without fencing return 0.
+	Token() int64
+
+	// Lost returns a channel that is closed when the lock is lost before it
+	// was released (lease expired, renewal or connection failed).
+	Lost() <-chan struct{}
+
+	// Release releases the lock. It returns ErrNotHeld if the lock was lost
+	// or already released.
+	Release(ctx context.Context) error
+}
+
+// releaseTimeout bounds the Release of WithLock, which runs even if the
+// context of the caller is done.
+const releaseTimeout = 5 * time.Second
+
+type lockKey struct{}
+
+// FromContext returns the lock held by the WithLock call of ctx.
+func FromContext(ctx context.Context) (Lock, bool) {
+	lock, ok := ctx.Value(lockKey{}).(Lock)
+	return lock, ok
+}
+
+// WithLock acquires the lock for key, blocking until it is free or ctx is
+// done, runs fn and releases the lock.
+//
+// The context passed to fn is cancelled with cause ErrLockLost if the lock is
+// lost while fn runs, and holds the lock (see FromContext). WithLock then
+// returns ErrLockLost, even if fn succeeded, since fn may not have run
+// exclusively.
+func WithLock(ctx context.Context, locker Locker, key string, fn func(ctx context.Context) error) error {
+	lock, err := locker.Acquire(ctx, key)
+	if err != nil {
+		return err
+	}
+	return runLocked(ctx, lock, fn)
+}
+
+// TryWithLock is like WithLock, but returns ErrNotAcquired without running fn
+// if the lock is held by someone else.
+func TryWithLock(ctx context.Context, locker Locker, key string, fn func(ctx context.Context) error) error {
+	lock, err := locker.TryAcquire(ctx, key)
+	if err != nil {
+		return err
+	}
+	return runLocked(ctx, lock, fn)
+}
+
+func runLocked(ctx context.Context, lock Lock, fn func(ctx context.Context) error) (err error) {
+	lockCtx, cancel := context.WithCancelCause(context.WithValue(ctx, lockKey{}, lock))
+	defer cancel(nil)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-lock.Lost():
+			cancel(ErrLockLost)
+		case <-stop:
+		}
+	}()
+
+	defer func() {
+		releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+		defer cancelRelease()
+
+		releaseErr := lock.Release(releaseCtx)
+		if isLost(lock) {
+			err = errors.Join(ErrLockLost, err)
+			return
+		}
+		if releaseErr != nil {
+			err = errors.Join(err, releaseErr)
+		}
+	}()
+
+	return fn(lockCtx)
+}
+
+func isLost(lock Lock) bool {
+	select {
+	case <-lock.Lost():
+		return true
+	default:
+		return false
+	}
+}
+
+// heldLock holds the state shared by the Lock implementations.
+type heldLock struct {
+	key      string
+	token    int64
+	acquired time.Time
+	opts     *options
+
+	lost     chan struct{}
+	lostOnce sync.Once
+}
+
+func newHeldLock(key string, token int64, opts *options) *heldLock {
+	return &heldLock{
+		key:      key,
+		token:    token,
+		acquired: time.Now(),
+		opts:     opts,
+		lost:     make(chan struct{}),
+	}
+}
+
+func (l *heldLock) Key() string {
+	return l.key
+}
+
+func (l *heldLock) Token() int64 {
+	return l.token
+}
+
+func (l *heldLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// markLost closes the Lost channel and reports why the lock was lost.
+func (l *heldLock) markLost(reason error) {
+	l.lostOnce.Do(func() {
+		close(l.lost)
+
+		held := time.Since(l.acquired)
+		if l.opts.logger != nil {
+			l.opts.logger.Error("lock lost",
+				slog.String("key", l.key),
+				slog.Int64("token", l.token),
+				slog.Duration("held", held),
+				slog.Any("error", reason),
+			)
+		}
+		if l.opts.metrics != nil {
+			l.opts.metrics.RecordLost(l.key, held)
+		}
+	})
+}
+
+// recordRelease reports a Release call.
+func (l *heldLock) recordRelease(ctx context.Context, err error) {
+	held := time.Since(l.acquired)
+	if l.opts.logger != nil && err == nil {
+		l.opts.logger.DebugContext(ctx, "lock released",
+			slog.String("key", l.key),
+			slog.Duration("held", held),
+		)
+	}
+	if l.opts.metrics != nil {
+		l.opts.metrics.RecordRelease(ctx, l.key, held, err)
+	}
+}
+
+// recordAcquire reports an Acquire or TryAcquire call that started at start.
+func (o *options) recordAcquire(ctx context.Context, key string, start time.Time, lock *heldLock, err error) {
+	wait := time.Since(start)
+	if o.logger != nil && lock != nil {
+		o.logger.DebugContext(ctx, "lock acquired",
+			slog.String("key", key),
+			slog.Int64("token", lock.token),
+			slog.Duration("wait", wait),
+		)
+	}
+	if o.metrics != nil {
+		if errors.Is(err, ErrNotAcquired) {
+			err = nil
+		}
+		o.metrics.RecordAcquire(ctx, key, wait, lock != nil, err)
+	}
+}
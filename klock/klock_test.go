@@ -0,0 +1,108 @@
+package klock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeLock struct {
+	*heldLock
+
+	mu         sync.Mutex
+	released   int
+	releaseErr error
+}
+
+func (l *fakeLock) Release(context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released++
+	return l.releaseErr
+}
+
+type fakeLocker struct {
+	lock *fakeLock
+	err  error
+}
+
+func (l *fakeLocker) Acquire(context.Context, string) (Lock, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	return l.lock, nil
+}
+
+func (l *fakeLocker) TryAcquire(ctx context.Context, key string) (Lock, error) {
+	return l.Acquire(ctx, key)
+}
+
+func newFakeLocker() *fakeLocker {
+	return &fakeLocker{lock: &fakeLock{heldLock: newHeldLock("job", 7, defaultOptions())}}
+}
+
+func TestWithLock(t *testing.T) {
+	locker := newFakeLocker()
+
+	err := WithLock(context.Background(), locker, "job", func(ctx context.Context) error {
+		lock, ok := FromContext(ctx)
+		if !ok || lock.Token() != 7 {
+			t.Errorf("Expected the lock in the context, got %v", lock)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithLock() error = %v", err)
+	}
+	if locker.lock.released != 1 {
+		t.Errorf("Expected one release, got %d", locker.lock.released)
+	}
+}
+
+func TestWithLockReturnsErrors(t *testing.T) {
+	failed := errors.New("failed")
+
+	locker := newFakeLocker()
+	locker.lock.releaseErr = errors.New("release failed")
+	err := WithLock(context.Background(), locker, "job", func(context.Context) error { return failed })
+	if !errors.Is(err, failed) || !errors.Is(err, locker.lock.releaseErr) {
+		t.Errorf("Expected the fn and release errors, got %v", err)
+	}
+
+	locker = newFakeLocker()
+	locker.err = ErrNotAcquired
+	called := false
+	err = TryWithLock(context.Background(), locker, "job", func(context.Context) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, ErrNotAcquired) || called {
+		t.Errorf("Expected ErrNotAcquired without calling fn, got %v", err)
+	}
+}
+
+func TestWithLockCancelsOnLoss(t *testing.T) {
+	locker := newFakeLocker()
+
+	err := WithLock(context.Background(), locker, "job", func(ctx context.Context) error {
+		locker.lock.markLost(errors.New("connection reset"))
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected the context to be cancelled")
+		}
+		if !errors.Is(context.Cause(ctx), ErrLockLost) {
+			t.Errorf("Expected cause ErrLockLost, got %v", context.Cause(ctx))
+		}
+		return nil
+	})
+	if !errors.Is(err, ErrLockLost) {
+		t.Errorf("Expected ErrLockLost, got %v", err)
+	}
+	if locker.lock.released != 1 {
+		t.Errorf("Expected the lost lock to be released, got %d", locker.lock.released)
+	}
+}
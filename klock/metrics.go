@@ -0,0 +1,167 @@
+package klock
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// MetricsCollector is an interface for collecting lock metrics.
+type MetricsCollector interface {
+	// RecordAcquire records an Acquire or TryAcquire call: how long it waited
+	// and whether it got the lock. A lock held by someone else is not an
+	// error for TryAcquire.
+	RecordAcquire(ctx context.Context, key string, wait time.Duration, acquired bool, err error)
+
+	// RecordRelease records the release of a lock and how long it was held.
+	RecordRelease(ctx context.Context, key string, held time.Duration, err error)
+
+	// RecordLost records a lock lost before it was released.
+	RecordLost(key string, held time.Duration)
+}
+
+// NoOpMetricsCollector is a metrics collector that does nothing.
+// Use this when metrics collection is not needed.
+type NoOpMetricsCollector struct{}
+
+func (n *NoOpMetricsCollector) RecordAcquire(ctx context.Context, key string, wait time.Duration, acquired bool, err error) {
+}
+
+func (n *NoOpMetricsCollector) RecordRelease(ctx context.Context, key string, held time.Duration, err error) {
+}
+
+func (n *NoOpMetricsCollector) RecordLost(key string, held time.Duration) {
+}
+
+// LoggingMetricsCollector is a metrics collector that logs metrics using slog.
+type LoggingMetricsCollector struct {
+	logger *slog.Logger
+}
+
+// NewLoggingMetricsCollector creates a new logging metrics collector.
+func NewLoggingMetricsCollector(logger *slog.Logger) *LoggingMetricsCollector {
+	return &LoggingMetricsCollector{
+		logger: logger,
+	}
+}
+
+func (l *LoggingMetricsCollector) RecordAcquire(ctx context.Context, key string, wait time.Duration, acquired bool, err error) {
+	if err != nil {
+		l.logger.ErrorContext(ctx, "lock acquire failed",
+			slog.String("key", key),
+			slog.Duration("wait", wait),
+			slog.Any("error", err),
+		)
+	} else {
+		l.logger.DebugContext(ctx, "lock acquire completed",
+			slog.String("key", key),
+			slog.Duration("wait", wait),
+			slog.Bool("acquired", acquired),
+		)
+	}
+}
+
+func (l *LoggingMetricsCollector) RecordRelease(ctx context.Context, key string, held time.Duration, err error) {
+	if err != nil {
+		l.logger.ErrorContext(ctx, "lock release failed",
+			slog.String("key", key),
+			slog.Duration("held", held),
+			slog.Any("error", err),
+		)
+	} else {
+		l.logger.DebugContext(ctx, "lock release completed",
+			slog.String("key", key),
+			slog.Duration("held", held),
+		)
+	}
+}
+
+func (l *LoggingMetricsCollector) RecordLost(key string, held time.Duration) {
+	l.logger.Warn("lock lost",
+		slog.String("key", key),
+		slog.Duration("held", held),
+	)
+}
+
+// InMemoryMetricsCollector counts lock operations in memory for monitoring
+// and tests.
+type InMemoryMetricsCollector struct {
+	mu      sync.RWMutex
+	metrics Metrics
+}
+
+// NewInMemoryMetricsCollector creates a new in-memory metrics collector.
+func NewInMemoryMetricsCollector() *InMemoryMetricsCollector {
+	return &InMemoryMetricsCollector{}
+}
+
+func (m *InMemoryMetricsCollector) RecordAcquire(ctx context.Context, key string, wait time.Duration, acquired bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch {
+	case err != nil:
+		m.metrics.AcquireErrorCount++
+	case acquired:
+		m.metrics.AcquiredCount++
+		m.metrics.TotalWait += wait
+	default:
+		m.metrics.ContendedCount++
+	}
+}
+
+func (m *InMemoryMetricsCollector) RecordRelease(ctx context.Context, key string, held time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.metrics.ReleaseCount++
+	m.metrics.TotalHeld += held
+	if err != nil {
+		m.metrics.ReleaseErrorCount++
+	}
+}
+
+func (m *InMemoryMetricsCollector) RecordLost(key string, held time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.metrics.LostCount++
+}
+
+// Metrics returns a snapshot of collected metrics.
+func (m *InMemoryMetricsCollector) Metrics() Metrics {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.metrics
+}
+
+// Reset resets all metrics.
+func (m *InMemoryMetricsCollector) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics = Metrics{}
+}
+
+// Metrics represents a snapshot of lock metrics.
+type Metrics struct {
+	// Acquire metrics
+	AcquiredCount     int64
+	ContendedCount    int64 // TryAcquire calls that found the lock held
+	AcquireErrorCount int64 // including Acquire calls whose context ended
+	TotalWait         time.Duration
+
+	// Release metrics
+	ReleaseCount      int64
+	ReleaseErrorCount int64
+	TotalHeld         time.Duration
+
+	LostCount int64
+}
+
+// Ensure the collectors implement MetricsCollector at compile time.
+var (
+	_ MetricsCollector = (*NoOpMetricsCollector)(nil)
+	_ MetricsCollector = (*LoggingMetricsCollector)(nil)
+	_ MetricsCollector = (*InMemoryMetricsCollector)(nil)
+)
@@ -0,0 +1,96 @@
+package klock
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Option configures a Locker.
+type Option func(*options)
+
+type options struct {
+	keyPrefix           string
+	ttl                 time.Duration
+	autoRenew           bool
+	retryInterval       time.Duration
+	healthCheckInterval time.Duration
+	logger              *slog.Logger
+	metrics             MetricsCollector
+}
+
+func defaultOptions() *options {
+	return &options{
+		keyPrefix:           "klock:",
+		ttl:                 30 * time.Second,
+		autoRenew:           true,
+		retryInterval:       100 * time.Millisecond,
+		healthCheckInterval: 10 * time.Second,
+	}
+}
+
+func applyOptions(opts []Option) *options {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithKeyPrefix sets the prefix of the Redis keys, or of the string hashed
+// into the PostgreSQL advisory lock key. Default: "klock:".
+func WithKeyPrefix(prefix string) Option {
+	return func(o *options) {
+		o.keyPrefix = prefix
+	}
+}
+
+// WithTTL sets the lease of Redis locks: a lock that is neither renewed nor
+// released expires after it, e.g. when its holder crashed. Values below 1
+// millisecond, the resolution of Redis expiry, are ignored. Default: 30 seconds.
+func WithTTL(d time.Duration) Option {
+	return func(o *options) {
+		if d >= time.Millisecond {
+			o.ttl = d
+		}
+	}
+}
+
+// WithAutoRenew enables or disables the renewal of Redis locks every third of
+// their TTL while they are held. Without it a lock expires after its TTL even
+// if it was not released. Default: true.
+func WithAutoRenew(enabled bool) Option {
+	return func(o *options) {
+		o.autoRenew = enabled
+	}
+}
+
+// WithRetryInterval sets how often Acquire of Redis locks retries while the
+// lock is held by someone else (with ±10% jitter). Default: 100 milliseconds.
+func WithRetryInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.retryInterval = d
+	}
+}
+
+// WithHealthCheckInterval sets how often the connection holding a PostgreSQL
+// lock is pinged; the lock is reported lost when the ping fails. Set to 0 to
+// disable. Default: 10 seconds.
+func WithHealthCheckInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.healthCheckInterval = d
+	}
+}
+
+// WithLogger sets the structured logger. If nil, logging is disabled.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithMetrics sets the metrics collector. If nil, metrics collection is disabled.
+func WithMetrics(metrics MetricsCollector) Option {
+	return func(o *options) {
+		o.metrics = metrics
+	}
+}
@@ -0,0 +1,234 @@
+package klock
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresLocker is a Locker backed by PostgreSQL session-level advisory
+// locks. Keys are hashed to the 64-bit advisory lock key, and each lock holds
+// a pool connection until it is released. The server releases the lock when
+// the connection ends, so there is no lease to renew; the connection is
+// pinged every health check interval and the lock is reported lost when the
+// ping fails. Locks have no fencing token (Token returns 0).
+type PostgresLocker struct {
+	acquireConn func(ctx context.Context) (advisoryConn, error)
+	opts        *options
+}
+
+// advisoryConn is the connection a PostgreSQL lock is held on.
+type advisoryConn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Ping(ctx context.Context) error
+
+	// Release returns the connection to the pool.
+	Release()
+	// Close closes the connection, which releases the locks of its session.
+	// It must not block on a done ctx.
+	Close(ctx context.Context) error
+}
+
+// poolConn is an advisoryConn acquired from a pgxpool.Pool.
+type poolConn struct {
+	*pgxpool.Conn
+}
+
+// Close closes the connection, even if ctx is done, within releaseTimeout.
+func (c poolConn) Close(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancel()
+	return c.Hijack().Close(ctx)
+}
+
+// NewPostgresLocker creates a Locker on pool (e.g. the Pool() of a kpgx.DB or
+// kdbx.PostgresDB). Size the pool for the locks held at the same time, since
+// each one holds a connection.
+func NewPostgresLocker(pool *pgxpool.Pool, opts ...Option) *PostgresLocker {
+	return &PostgresLocker{
+		acquireConn: func(ctx context.Context) (advisoryConn, error) {
+			conn, err := pool.Acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return poolConn{conn}, nil
+		},
+		opts: applyOptions(opts),
+	}
+}
+
+// Acquire blocks in pg_advisory_lock until the lock for key is acquired or
+// ctx is done.
+func (l *PostgresLocker) Acquire(ctx context.Context, key string) (Lock, error) {
+	start := time.Now()
+	lock, err := l.acquire(ctx, key, func(conn advisoryConn, id int64) (bool, error) {
+		_, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", id)
+		return err == nil, err
+	})
+	if err != nil {
+		l.opts.recordAcquire(ctx, key, start, nil, err)
+		return nil, err
+	}
+	l.opts.recordAcquire(ctx, key, start, lock.heldLock, nil)
+	return lock, nil
+}
+
+// TryAcquire acquires the lock for key with pg_try_advisory_lock if it is
+// free and returns ErrNotAcquired otherwise.
+func (l *PostgresLocker) TryAcquire(ctx context.Context, key string) (Lock, error) {
+	start := time.Now()
+	lock, err := l.acquire(ctx, key, func(conn advisoryConn, id int64) (bool, error) {
+		var acquired bool
+		err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", id).Scan(&acquired)
+		return acquired, err
+	})
+	if err != nil {
+		l.opts.recordAcquire(ctx, key, start, nil, err)
+		return nil, err
+	}
+	l.opts.recordAcquire(ctx, key, start, lock.heldLock, nil)
+	return lock, nil
+}
+
+func (l *PostgresLocker) acquire(ctx context.Context, key string, lockFn func(advisoryConn, int64) (bool, error)) (*postgresLock, error) {
+	conn, err := l.acquireConn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("klock: acquire %q: %w", key, err)
+	}
+
+	id := advisoryLockID(l.opts.keyPrefix + key)
+	acquired, err := lockFn(conn, id)
+	if err != nil {
+		// The lock may have been granted as the call failed; closing the
+		// session releases it.
+		_ = conn.Close(ctx)
+		return nil, fmt.Errorf("klock: acquire %q: %w", key, err)
+	}
+	if !acquired {
+		conn.Release()
+		return nil, ErrNotAcquired
+	}
+
+	lock := &postgresLock{
+		heldLock: newHeldLock(key, 0, l.opts),
+		id:       id,
+		conn:     conn,
+	}
+	if l.opts.healthCheckInterval > 0 {
+		lock.startHealthChecks(l.opts.healthCheckInterval)
+	}
+
+	return lock, nil
+}
+
+// advisoryLockID hashes a key to an advisory lock key.
+func advisoryLockID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+type postgresLock struct {
+	*heldLock
+
+	id int64
+
+	// mu serializes the use of conn, which is nil once the lock is released
+	// or lost.
+	mu   sync.Mutex
+	conn advisoryConn
+
+	stopHealthChecks func()
+}
+
+// startHealthChecks pings the connection every interval until Release.
+func (l *postgresLock) startHealthChecks(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	l.stopHealthChecks = func() {
+		cancel()
+		<-done
+	}
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+
+			if !l.ping(ctx, interval) {
+				return
+			}
+		}
+	}()
+}
+
+// ping checks the connection and reports whether the lock is still held.
+func (l *postgresLock) ping(ctx context.Context, timeout time.Duration) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return false
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	err := l.conn.Ping(pingCtx)
+	cancel()
+	if err == nil || ctx.Err() != nil {
+		return true
+	}
+
+	_ = l.conn.Close(context.Background())
+	l.conn = nil
+	l.markLost(err)
+	return false
+}
+
+// Release unlocks the key and returns the connection to the pool.
+func (l *postgresLock) Release(ctx context.Context) error {
+	if l.stopHealthChecks != nil {
+		l.stopHealthChecks()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return ErrNotHeld
+	}
+	conn := l.conn
+	l.conn = nil
+
+	var unlocked bool
+	if err := conn.QueryRow(ctx, "SELECT pg_advisory_unlock($1)", l.id).Scan(&unlocked); err != nil {
+		// Closing the session releases the lock on the server.
+		_ = conn.Close(ctx)
+		err = fmt.Errorf("klock: release %q: %w", l.key, err)
+		l.recordRelease(ctx, err)
+		return err
+	}
+	conn.Release()
+
+	if !unlocked {
+		l.markLost(ErrNotHeld)
+		l.recordRelease(ctx, ErrNotHeld)
+		return ErrNotHeld
+	}
+
+	l.recordRelease(ctx, nil)
+	return nil
+}
@@ -0,0 +1,216 @@
+package klock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeServer tracks advisory locks like a PostgreSQL server, per session.
+type fakeServer struct {
+	mu    sync.Mutex
+	locks map[int64]*fakeConn
+}
+
+func (s *fakeServer) conn(context.Context) (advisoryConn, error) {
+	return &fakeConn{server: s}, nil
+}
+
+func (s *fakeServer) tryLock(c *fakeConn, id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locks == nil {
+		s.locks = make(map[int64]*fakeConn)
+	}
+	if holder, ok := s.locks[id]; ok && holder != c {
+		return false
+	}
+	s.locks[id] = c
+	return true
+}
+
+func (s *fakeServer) unlock(c *fakeConn, id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locks[id] != c {
+		return false
+	}
+	delete(s.locks, id)
+	return true
+}
+
+func (s *fakeServer) closeSession(c *fakeConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, holder := range s.locks {
+		if holder == c {
+			delete(s.locks, id)
+		}
+	}
+}
+
+type fakeConn struct {
+	server *fakeServer
+
+	mu       sync.Mutex
+	pingErr  error
+	released bool
+	closed   bool
+}
+
+func (c *fakeConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	id := args[0].(int64)
+	for !c.server.tryLock(c, id) {
+		select {
+		case <-time.After(time.Millisecond):
+		case <-ctx.Done():
+			return pgconn.CommandTag{}, ctx.Err()
+		}
+	}
+	return pgconn.NewCommandTag("SELECT 1"), nil
+}
+
+func (c *fakeConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	id := args[0].(int64)
+	if sql == "SELECT pg_advisory_unlock($1)" {
+		return fakeRow{c.server.unlock(c, id)}
+	}
+	return fakeRow{c.server.tryLock(c, id)}
+}
+
+func (c *fakeConn) Ping(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pingErr
+}
+
+func (c *fakeConn) Release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.released = true
+}
+
+func (c *fakeConn) Close(context.Context) error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	c.server.closeSession(c)
+	return nil
+}
+
+type fakeRow struct {
+	value bool
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	*dest[0].(*bool) = r.value
+	return nil
+}
+
+func newTestPostgresLocker(opts ...Option) (*PostgresLocker, *fakeServer) {
+	server := &fakeServer{}
+	return &PostgresLocker{acquireConn: server.conn, opts: applyOptions(opts)}, server
+}
+
+func TestPostgresLockerTryAcquire(t *testing.T) {
+	locker, _ := newTestPostgresLocker()
+	ctx := context.Background()
+
+	lock, err := locker.TryAcquire(ctx, "job")
+	if err != nil {
+		t.Fatalf("TryAcquire() error = %v", err)
+	}
+	if lock.Key() != "job" || lock.Token() != 0 {
+		t.Errorf("Expected lock job without a token, got %s %d", lock.Key(), lock.Token())
+	}
+
+	if _, err := locker.TryAcquire(ctx, "job"); !errors.Is(err, ErrNotAcquired) {
+		t.Fatalf("Expected ErrNotAcquired, got %v", err)
+	}
+	if _, err := locker.TryAcquire(ctx, "other"); err != nil {
+		t.Errorf("Expected another key to be free, got %v", err)
+	}
+
+	conn := lock.(*postgresLock).conn.(*fakeConn)
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if !conn.released {
+		t.Error("Expected the connection to be returned to the pool")
+	}
+	if err := lock.Release(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("Expected ErrNotHeld on a second Release, got %v", err)
+	}
+
+	if _, err := locker.TryAcquire(ctx, "job"); err != nil {
+		t.Errorf("TryAcquire() after Release error = %v", err)
+	}
+}
+
+func TestPostgresLockerAcquireWaits(t *testing.T) {
+	locker, _ := newTestPostgresLocker()
+	ctx := context.Background()
+
+	lock, err := locker.Acquire(ctx, "job")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := locker.Acquire(timeoutCtx, "job"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the context error, got %v", err)
+	}
+
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := locker.Acquire(ctx, "job"); err != nil {
+		t.Errorf("Acquire() after Release error = %v", err)
+	}
+}
+
+func TestPostgresLockLost(t *testing.T) {
+	metrics := NewInMemoryMetricsCollector()
+	locker, server := newTestPostgresLocker(WithHealthCheckInterval(5*time.Millisecond), WithMetrics(metrics))
+	ctx := context.Background()
+
+	lock, err := locker.Acquire(ctx, "job")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	conn := lock.(*postgresLock).conn.(*fakeConn)
+	conn.mu.Lock()
+	conn.pingErr = errors.New("connection reset")
+	conn.mu.Unlock()
+
+	select {
+	case <-lock.Lost():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the lock to be lost")
+	}
+	if !conn.closed || len(server.locks) != 0 {
+		t.Error("Expected the session of the lost lock to be closed")
+	}
+	if err := lock.Release(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("Expected ErrNotHeld, got %v", err)
+	}
+	if m := metrics.Metrics(); m.LostCount != 1 {
+		t.Errorf("Expected one lost lock, got %+v", m)
+	}
+}
+
+func TestAdvisoryLockID(t *testing.T) {
+	if advisoryLockID("klock:a") != advisoryLockID("klock:a") {
+		t.Error("Expected the same ID for the same key")
+	}
+	if advisoryLockID("klock:a") == advisoryLockID("klock:b") {
+		t.Error("Expected different IDs for different keys")
+	}
+}
@@ -0,0 +1,257 @@
+package klock
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// acquireScript sets the lock key if it is free and increments the fencing
+	// counter of the key. Both keys share a hash tag, so they live in the same
+	// cluster slot.
+	acquireScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0`)
+
+	// renewScript extends the lease if the lock is still held by the owner.
+	renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	// releaseScript deletes the lock key if it is still held by the owner.
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// RedisLocker is a Locker backed by Redis. A lock is a key set with SET NX and
+// a TTL holding a random owner ID, so only its holder can renew or release it.
+// Every acquisition increments a per-key counter whose value is the fencing
+// token of the lock; the counter keys do not expire.
+type RedisLocker struct {
+	client redis.Scripter
+	opts   *options
+}
+
+// NewRedisLocker creates a Locker on client, a *redis.Client,
+// *redis.ClusterClient or *redis.Ring (e.g. the Client() of a kcache.Cache).
+func NewRedisLocker(client redis.Scripter, opts ...Option) *RedisLocker {
+	return &RedisLocker{
+		client: client,
+		opts:   applyOptions(opts),
+	}
+}
+
+// Acquire blocks until the lock for key is acquired or ctx is done, polling
+// every retry interval.
+func (l *RedisLocker) Acquire(ctx context.Context, key string) (Lock, error) {
+	start := time.Now()
+	for {
+		lock, err := l.tryAcquire(ctx, key)
+		if err == nil {
+			l.opts.recordAcquire(ctx, key, start, lock.heldLock, nil)
+			return lock, nil
+		}
+		if err != ErrNotAcquired {
+			l.opts.recordAcquire(ctx, key, start, nil, err)
+			return nil, err
+		}
+
+		timer := time.NewTimer(jitter(l.opts.retryInterval))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			err := fmt.Errorf("klock: acquire %q: %w", key, ctx.Err())
+			l.opts.recordAcquire(ctx, key, start, nil, err)
+			return nil, err
+		}
+	}
+}
+
+// TryAcquire acquires the lock for key if it is free and returns
+// ErrNotAcquired otherwise.
+func (l *RedisLocker) TryAcquire(ctx context.Context, key string) (Lock, error) {
+	start := time.Now()
+	lock, err := l.tryAcquire(ctx, key)
+	if err != nil {
+		l.opts.recordAcquire(ctx, key, start, nil, err)
+		return nil, err
+	}
+	l.opts.recordAcquire(ctx, key, start, lock.heldLock, nil)
+	return lock, nil
+}
+
+func (l *RedisLocker) tryAcquire(ctx context.Context, key string) (*redisLock, error) {
+	owner, err := newOwnerID()
+	if err != nil {
+		return nil, err
+	}
+
+	lockKey, fenceKey := l.keys(key)
+	// The lease starts when Redis runs the script, so a lease measured from
+	// here ends no later than the one in Redis.
+	sent := time.Now()
+	token, err := acquireScript.Run(ctx, l.client, []string{lockKey, fenceKey}, owner, l.opts.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("klock: acquire %q: %w", key, err)
+	}
+	if token == 0 {
+		return nil, ErrNotAcquired
+	}
+
+	lock := &redisLock{
+		heldLock: newHeldLock(key, token, l.opts),
+		locker:   l,
+		lockKey:  lockKey,
+		owner:    owner,
+	}
+	if l.opts.autoRenew {
+		lock.startRenewal()
+	} else {
+		lock.startExpiry(sent)
+	}
+
+	return lock, nil
+}
+
+// keys returns the lock and fencing counter keys of key.
+func (l *RedisLocker) keys(key string) (lockKey, fenceKey string) {
+	tagged := l.opts.keyPrefix + "{" + key + "}"
+	return tagged, tagged + ":fence"
+}
+
+type redisLock struct {
+	*heldLock
+
+	locker  *RedisLocker
+	lockKey string
+	owner   string
+
+	mu          sync.Mutex
+	released    bool
+	stopRenewal func()
+}
+
+// startRenewal extends the lease every third of the TTL until Release. The
+// lock is lost when Redis reports another owner, or when no renewal succeeded
+// for a whole TTL.
+func (l *redisLock) startRenewal() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	l.stopRenewal = func() {
+		cancel()
+		<-done
+	}
+
+	ttl := l.locker.opts.ttl
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+
+		lastRenewal := time.Now()
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+
+			renewCtx, cancelRenew := context.WithTimeout(ctx, ttl/3)
+			renewed, err := renewScript.Run(renewCtx, l.locker.client, []string{l.lockKey}, l.owner, ttl.Milliseconds()).Int64()
+			cancelRenew()
+
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err == nil && renewed == 1:
+				lastRenewal = time.Now()
+			case err == nil:
+				l.markLost(ErrNotHeld)
+				return
+			case time.Since(lastRenewal) >= ttl:
+				l.markLost(err)
+				return
+			default:
+				if logger := l.locker.opts.logger; logger != nil {
+					logger.Warn("lock renewal failed",
+						slog.String("key", l.key),
+						slog.Any("error", err),
+					)
+				}
+			}
+		}
+	}()
+}
+
+// startExpiry reports the lock lost when its lease, which started at or after
+// sent, ends. Without renewal nothing else notices the expiry before Release,
+// while another owner may already hold the lock.
+func (l *redisLock) startExpiry(sent time.Time) {
+	timer := time.AfterFunc(l.locker.opts.ttl-time.Since(sent), func() {
+		l.markLost(ErrNotHeld)
+	})
+	l.stopRenewal = func() {
+		timer.Stop()
+	}
+}
+
+// Release stops the renewal and deletes the lock key if it is still held.
+func (l *redisLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.released {
+		return ErrNotHeld
+	}
+	l.released = true
+
+	if l.stopRenewal != nil {
+		l.stopRenewal()
+	}
+
+	err := l.release(ctx)
+	l.recordRelease(ctx, err)
+	return err
+}
+
+func (l *redisLock) release(ctx context.Context) error {
+	deleted, err := releaseScript.Run(ctx, l.locker.client, []string{l.lockKey}, l.owner).Int64()
+	if err != nil {
+		return fmt.Errorf("klock: release %q: %w", l.key, err)
+	}
+	if deleted == 0 {
+		l.markLost(ErrNotHeld)
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// newOwnerID returns a random ID identifying one acquisition.
+func newOwnerID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", fmt.Errorf("klock: generate owner ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// jitter returns d ±10%, so contending clients don't poll in lockstep.
+func jitter(d time.Duration) time.Duration {
+	return d + time.Duration(float64(d)*0.1*(2*rand.Float64()-1))
+}
@@ -0,0 +1,231 @@
+package klock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisLocker(t *testing.T, opts ...Option) (*RedisLocker, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewRedisLocker(client, opts...), server
+}
+
+func TestRedisLockerTryAcquire(t *testing.T) {
+	metrics := NewInMemoryMetricsCollector()
+	locker, server := newTestRedisLocker(t, WithTTL(time.Minute), WithMetrics(metrics))
+	ctx := context.Background()
+
+	lock, err := locker.TryAcquire(ctx, "job")
+	if err != nil {
+		t.Fatalf("TryAcquire() error = %v", err)
+	}
+	if lock.Key() != "job" || lock.Token() != 1 {
+		t.Errorf("Expected lock job with token 1, got %s %d", lock.Key(), lock.Token())
+	}
+	if !server.Exists("klock:{job}") || server.TTL("klock:{job}") != time.Minute {
+		t.Errorf("Expected the lock key with the TTL, got keys %v", server.Keys())
+	}
+
+	if _, err := locker.TryAcquire(ctx, "job"); !errors.Is(err, ErrNotAcquired) {
+		t.Fatalf("Expected ErrNotAcquired, got %v", err)
+	}
+
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if err := lock.Release(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("Expected ErrNotHeld on a second Release, got %v", err)
+	}
+	if server.Exists("klock:{job}") {
+		t.Error("Expected the lock key to be deleted")
+	}
+
+	next, err := locker.TryAcquire(ctx, "job")
+	if err != nil {
+		t.Fatalf("TryAcquire() after Release error = %v", err)
+	}
+	defer next.Release(ctx)
+	if next.Token() != 2 {
+		t.Errorf("Expected the fencing token to increase, got %d", next.Token())
+	}
+
+	m := metrics.Metrics()
+	if m.AcquiredCount != 2 || m.ContendedCount != 1 || m.ReleaseCount != 1 {
+		t.Errorf("Unexpected metrics %+v", m)
+	}
+}
+
+func TestRedisLockerAcquireWaits(t *testing.T) {
+	locker, _ := newTestRedisLocker(t, WithRetryInterval(5*time.Millisecond))
+	ctx := context.Background()
+
+	lock, err := locker.Acquire(ctx, "job")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	defer cancel()
+	if _, err := locker.Acquire(timeoutCtx, "job"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the context error, got %v", err)
+	}
+
+	got := make(chan Lock, 1)
+	go func() {
+		next, err := locker.Acquire(ctx, "job")
+		if err != nil {
+			t.Errorf("Acquire() error = %v", err)
+		}
+		got <- next
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	select {
+	case next := <-got:
+		if next != nil {
+			_ = next.Release(ctx)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected Acquire to get the released lock")
+	}
+}
+
+func TestRedisLockRenewal(t *testing.T) {
+	locker, server := newTestRedisLocker(t, WithTTL(300*time.Millisecond))
+	ctx := context.Background()
+
+	lock, err := locker.Acquire(ctx, "job")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer lock.Release(ctx)
+
+	// miniredis only expires keys on FastForward; a renewal resets the TTL.
+	server.FastForward(250 * time.Millisecond)
+	deadline := time.Now().Add(2 * time.Second)
+	for server.TTL("klock:{job}") < 200*time.Millisecond && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if ttl := server.TTL("klock:{job}"); ttl < 200*time.Millisecond {
+		t.Fatalf("Expected the lease to be renewed, TTL %v", ttl)
+	}
+
+	select {
+	case <-lock.Lost():
+		t.Fatal("Expected the renewed lock not to be lost")
+	default:
+	}
+}
+
+func TestRedisLockLost(t *testing.T) {
+	metrics := NewInMemoryMetricsCollector()
+	locker, server := newTestRedisLocker(t, WithTTL(150*time.Millisecond), WithMetrics(metrics))
+	ctx := context.Background()
+
+	lock, err := locker.Acquire(ctx, "job")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	// Another owner takes over the key
+	server.Set("klock:{job}", "someone else")
+
+	select {
+	case <-lock.Lost():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the lock to be lost")
+	}
+	if err := lock.Release(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("Expected ErrNotHeld, got %v", err)
+	}
+	if got, _ := server.Get("klock:{job}"); got != "someone else" {
+		t.Error("Expected Release not to delete the lock of another owner")
+	}
+	if m := metrics.Metrics(); m.LostCount != 1 {
+		t.Errorf("Expected one lost lock, got %+v", m)
+	}
+}
+
+func TestRedisLockExpiresWithoutRenewal(t *testing.T) {
+	locker, server := newTestRedisLocker(t, WithTTL(time.Second), WithAutoRenew(false))
+	ctx := context.Background()
+
+	err := WithLock(ctx, locker, "job", func(ctx context.Context) error {
+		server.FastForward(2 * time.Second)
+		return nil
+	})
+	if !errors.Is(err, ErrLockLost) {
+		t.Errorf("Expected ErrLockLost for an expired lease, got %v", err)
+	}
+}
+
+func TestRedisLockExpiryCancelsWithLock(t *testing.T) {
+	locker, _ := newTestRedisLocker(t, WithTTL(50*time.Millisecond), WithAutoRenew(false))
+	ctx := context.Background()
+
+	var cause error
+	err := WithLock(ctx, locker, "job", func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			cause = context.Cause(ctx)
+		case <-time.After(2 * time.Second):
+			t.Error("Expected the context of fn to be cancelled when the lease ends")
+		}
+		return nil
+	})
+	if !errors.Is(cause, ErrLockLost) {
+		t.Errorf("Expected the context to be cancelled with ErrLockLost, got %v", cause)
+	}
+	if !errors.Is(err, ErrLockLost) {
+		t.Errorf("Expected ErrLockLost, got %v", err)
+	}
+
+	// A lock released within its lease is not lost.
+	lock, err := locker.Acquire(ctx, "other")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case <-lock.Lost():
+		t.Error("Expected a released lock not to be reported lost")
+	default:
+	}
+}
+
+func TestWithTTL(t *testing.T) {
+	tests := []struct {
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{time.Second, time.Second},
+		{time.Millisecond, time.Millisecond},
+		{999 * time.Microsecond, 30 * time.Second},
+		{2 * time.Nanosecond, 30 * time.Second},
+		{0, 30 * time.Second},
+		{-time.Second, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := applyOptions([]Option{WithTTL(tt.ttl)}).ttl; got != tt.want {
+			t.Errorf("WithTTL(%v) = %v, want %v", tt.ttl, got, tt.want)
+		}
+	}
+}
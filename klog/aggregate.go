@@ -0,0 +1,185 @@
+package klog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AggregateCountKey is the attribute holding how many identical records were
+// collapsed into an aggregated record.
+const AggregateCountKey = "count"
+
+// AggregatingHandler collapses records with the same level and message, logged
+// through the same logger (same attrs and groups), within a window into a single
+// record. The record is emitted when the window ends or on Flush, with the attrs
+// of the first occurrence and a "count" attribute when it occurred more than once.
+//
+// It is meant for noisy paths such as retry loops and failing health checks;
+// records are delayed by up to the window, so call Close, Flush or klog.Flush
+// before the process exits.
+type AggregatingHandler struct {
+	next  slog.Handler
+	scope uint64
+	agg   *aggregator
+}
+
+type aggregator struct {
+	window    time.Duration
+	mu        sync.Mutex
+	entries   map[aggregateKey]*aggregateEntry
+	closed    bool
+	lastScope atomic.Uint64
+}
+
+type aggregateKey struct {
+	scope   uint64
+	level   slog.Level
+	message string
+}
+
+type aggregateEntry struct {
+	ctx    context.Context
+	next   slog.Handler
+	record slog.Record
+	count  int
+	timer  *time.Timer
+}
+
+var _ slog.Handler = (*AggregatingHandler)(nil)
+
+func NewAggregatingHandler(next slog.Handler, window time.Duration) *AggregatingHandler {
+	if next == nil {
+		panic("klog: nil slog handler")
+	}
+	if window <= 0 {
+		window = time.Second
+	}
+	return &AggregatingHandler{
+		next: next,
+		agg: &aggregator{
+			window:  window,
+			entries: make(map[aggregateKey]*aggregateEntry),
+		},
+	}
+}
+
+func (h *AggregatingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *AggregatingHandler) Handle(ctx context.Context, record slog.Record) error {
+	key := aggregateKey{scope: h.scope, level: record.Level, message: record.Message}
+
+	h.agg.mu.Lock()
+	if h.agg.closed {
+		h.agg.mu.Unlock()
+		return h.next.Handle(ctx, record)
+	}
+	defer h.agg.mu.Unlock()
+
+	if entry, ok := h.agg.entries[key]; ok {
+		entry.count++
+		return nil
+	}
+
+	entry := &aggregateEntry{
+		ctx:    context.WithoutCancel(ctx),
+		next:   h.next,
+		record: record.Clone(),
+		count:  1,
+	}
+	entry.timer = time.AfterFunc(h.agg.window, func() {
+		_ = h.agg.flushKey(key, entry)
+	})
+	h.agg.entries[key] = entry
+	return nil
+}
+
+func (h *AggregatingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &AggregatingHandler{
+		next:  h.next.WithAttrs(attrs),
+		scope: h.agg.lastScope.Add(1),
+		agg:   h.agg,
+	}
+}
+
+func (h *AggregatingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &AggregatingHandler{
+		next:  h.next.WithGroup(name),
+		scope: h.agg.lastScope.Add(1),
+		agg:   h.agg,
+	}
+}
+
+// Flush emits every pending aggregated record, for this handler and every
+// handler derived from it with WithAttrs or WithGroup. Records are handled with
+// the context they were first logged with.
+func (h *AggregatingHandler) Flush(_ context.Context) error {
+	h.agg.mu.Lock()
+	entries := h.agg.entries
+	h.agg.entries = make(map[aggregateKey]*aggregateEntry)
+	h.agg.mu.Unlock()
+
+	var errs []error
+	for _, entry := range entries {
+		entry.timer.Stop()
+		if err := entry.emit(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes the pending records like Flush. Records handled afterwards are
+// passed through without aggregation, so nothing logged during shutdown waits
+// for a window that may never end.
+func (h *AggregatingHandler) Close(ctx context.Context) error {
+	h.agg.mu.Lock()
+	h.agg.closed = true
+	h.agg.mu.Unlock()
+
+	return h.Flush(ctx)
+}
+
+func (a *aggregator) flushKey(key aggregateKey, entry *aggregateEntry) error {
+	a.mu.Lock()
+	if a.entries[key] != entry {
+		// Already flushed.
+		a.mu.Unlock()
+		return nil
+	}
+	delete(a.entries, key)
+	a.mu.Unlock()
+
+	return entry.emit()
+}
+
+func (e *aggregateEntry) emit() error {
+	record := e.record
+	if e.count > 1 {
+		record = record.Clone()
+		record.AddAttrs(slog.Int(AggregateCountKey, e.count))
+	}
+	return e.next.Handle(e.ctx, record)
+}
+
+// Flush emits the records buffered by an AggregatingHandler behind logger, if any.
+func Flush(ctx context.Context, logger *slog.Logger) error {
+	if logger == nil {
+		return nil
+	}
+	if f, ok := logger.Handler().(interface{ Flush(context.Context) error }); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
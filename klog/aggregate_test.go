@@ -0,0 +1,239 @@
+package klog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// jsonBuffer collects the output of a slog.JSONHandler, safe for the concurrent
+// writes of timers.
+type jsonBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *jsonBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// entries decodes the JSON lines written so far.
+func (b *jsonBuffer) entries(t *testing.T) []map[string]any {
+	t.Helper()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to decode log entry %s: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// summarize renders entries as sorted "LEVEL msg key=value" lines, ignoring
+// the time.
+func summarize(entries []map[string]any) []string {
+	var lines []string
+	for _, entry := range entries {
+		var attrs []string
+		for k, v := range entry {
+			if k != "time" && k != "level" && k != "msg" {
+				attrs = append(attrs, fmt.Sprintf("%s=%v", k, v))
+			}
+		}
+		sort.Strings(attrs)
+		lines = append(lines, strings.TrimSpace(fmt.Sprintf("%s %s %s", entry["level"], entry["msg"], strings.Join(attrs, " "))))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func newAggregatingLogger(window time.Duration) (*slog.Logger, *AggregatingHandler, *jsonBuffer) {
+	out := &jsonBuffer{}
+	h := NewAggregatingHandler(slog.NewJSONHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}), window)
+	return slog.New(h), h, out
+}
+
+func TestAggregatingHandlerFlush(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(logger *slog.Logger)
+		want []string
+	}{
+		{
+			name: "single record has no count",
+			log:  func(l *slog.Logger) { l.Info("started") },
+			want: []string{"INFO started"},
+		},
+		{
+			name: "duplicates collapse with first attrs",
+			log: func(l *slog.Logger) {
+				for i := 1; i <= 3; i++ {
+					l.Warn("retrying", "attempt", i)
+				}
+			},
+			want: []string{"WARN retrying attempt=1 count=3"},
+		},
+		{
+			name: "levels are kept apart",
+			log: func(l *slog.Logger) {
+				l.Info("ping")
+				l.Error("ping")
+				l.Error("ping")
+			},
+			want: []string{"ERROR ping count=2", "INFO ping"},
+		},
+		{
+			name: "messages are kept apart",
+			log: func(l *slog.Logger) {
+				l.Info("a")
+				l.Info("b")
+				l.Info("a")
+			},
+			want: []string{"INFO a count=2", "INFO b"},
+		},
+		{
+			name: "attrs start a new scope",
+			log: func(l *slog.Logger) {
+				db := l.With("component", "db")
+				l.Info("down")
+				db.Info("down")
+				db.Info("down")
+			},
+			want: []string{"INFO down", "INFO down component=db count=2"},
+		},
+		{
+			name: "each derived logger is its own scope",
+			log: func(l *slog.Logger) {
+				l.With("component", "db").Info("down")
+				l.With("component", "db").Info("down")
+			},
+			want: []string{"INFO down component=db", "INFO down component=db"},
+		},
+		{
+			name: "groups start a new scope",
+			log: func(l *slog.Logger) {
+				l.Info("down", "id", 1)
+				l.WithGroup("db").Info("down", "id", 2)
+			},
+			want: []string{"INFO down db=map[id:2]", "INFO down id=1"},
+		},
+		{
+			name: "empty attrs and group keep the scope",
+			log: func(l *slog.Logger) {
+				l.Info("down")
+				l.With().WithGroup("").Info("down")
+			},
+			want: []string{"INFO down count=2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, _, out := newAggregatingLogger(time.Hour)
+			tt.log(logger)
+
+			if got := out.entries(t); len(got) != 0 {
+				t.Fatalf("Expected records to be held until Flush, got %v", summarize(got))
+			}
+			if err := Flush(context.Background(), logger); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if got := summarize(out.entries(t)); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Flushed records\n got %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregatingHandlerWindow(t *testing.T) {
+	logger, _, out := newAggregatingLogger(20 * time.Millisecond)
+
+	logger.Info("tick")
+	logger.Info("tick")
+
+	waitFor := func(n int) []string {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for {
+			got := summarize(out.entries(t))
+			if len(got) >= n || time.Now().After(deadline) {
+				return got
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	if got := waitFor(1); len(got) != 1 || got[0] != "INFO tick count=2" {
+		t.Fatalf("Expected the window to emit one aggregated record, got %q", got)
+	}
+
+	// A record after the window opens a new one.
+	logger.Info("tick")
+	if got := waitFor(2); len(got) != 2 || got[0] != "INFO tick" {
+		t.Errorf("Expected a second record for the next window, got %q", got)
+	}
+
+	// Flushing after the timer fired emits nothing twice.
+	if err := Flush(context.Background(), logger); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := out.entries(t); len(got) != 2 {
+		t.Errorf("Expected no duplicate records after Flush, got %v", summarize(got))
+	}
+}
+
+func TestAggregatingHandlerClose(t *testing.T) {
+	logger, h, out := newAggregatingLogger(time.Hour)
+	derived := logger.With("component", "db")
+
+	logger.Info("stopping")
+	logger.Info("stopping")
+	derived.Warn("draining")
+
+	if err := h.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	want := []string{"INFO stopping count=2", "WARN draining component=db"}
+	if got := summarize(out.entries(t)); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("Expected Close to flush every scope\n got %q\nwant %q", got, want)
+	}
+
+	// After Close, records pass straight through.
+	derived.Warn("draining")
+	derived.Warn("draining")
+	if got := out.entries(t); len(got) != 4 {
+		t.Errorf("Expected records after Close not to be aggregated, got %v", summarize(got))
+	}
+}
+
+func TestAggregatingHandlerEnabled(t *testing.T) {
+	h := NewAggregatingHandler(slog.NewJSONHandler(&jsonBuffer{}, &slog.HandlerOptions{Level: slog.LevelWarn}), 0)
+
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Expected Enabled to follow the wrapped handler")
+	}
+	if h.agg.window != time.Second {
+		t.Errorf("Expected a non-positive window to default to 1s, got %v", h.agg.window)
+	}
+	if err := Flush(context.Background(), slog.New(slog.NewJSONHandler(&jsonBuffer{}, nil))); err != nil {
+		t.Errorf("Expected Flush without an AggregatingHandler to be a no-op, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package klog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newBenchLogger(b *testing.B) *slog.Logger {
+	b.Helper()
+
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	core := zapcore.NewCore(encoder, zapcore.AddSync(io.Discard), zapcore.DebugLevel)
+	return NewSlogBuilder(zap.New(core, zap.AddCaller())).Build()
+}
+
+func BenchmarkHandle(b *testing.B) {
+	logger := newBenchLogger(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.LogAttrs(ctx, slog.LevelInfo, "request served",
+			slog.String("method", "GET"),
+			slog.Int("status", 200),
+			slog.Duration("latency", time.Millisecond),
+		)
+	}
+}
+
+func BenchmarkHandleWithGroups(b *testing.B) {
+	logger := newBenchLogger(b).With("service", "api").WithGroup("http").WithGroup("request")
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.LogAttrs(ctx, slog.LevelInfo, "request served",
+			slog.String("method", "GET"),
+			slog.Int("status", 200),
+			slog.Group("client", slog.String("ip", "10.0.0.1")),
+		)
+	}
+}
+
+func BenchmarkHandleWithExtractor(b *testing.B) {
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	core := zapcore.NewCore(encoder, zapcore.AddSync(io.Discard), zapcore.DebugLevel)
+	logger := NewSlogBuilder(zap.New(core, zap.AddCaller())).WithRequestID().Build()
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.LogAttrs(ctx, slog.LevelInfo, "request served", slog.Int("status", 200))
+	}
+}
+
+func BenchmarkHandleDisabled(b *testing.B) {
+	logger := newBenchLogger(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.LogAttrs(ctx, slog.LevelDebug, "debug", slog.Int("i", i))
+	}
+}
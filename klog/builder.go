@@ -2,6 +2,7 @@ package klog
 
 import (
 	"log/slog"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -10,7 +11,15 @@ type LoggerBuilder struct {
 	logger      *zap.Logger
 	handlerOpts *slog.HandlerOptions
 	extractors  []ContextExtractor
+	handlers    []slog.Handler
+	outputs     []Output
+	errorHooks  []ErrorHook
+	onError     []ErrorCallback
+	onErrorMax  int
+	onErrorPer  time.Duration
+	aggregate   time.Duration
 	callerSkip  int
+	fixedSkip   bool
 }
 
 func NewSlogBuilder(z *zap.Logger) *LoggerBuilder {
@@ -19,6 +28,8 @@ func NewSlogBuilder(z *zap.Logger) *LoggerBuilder {
 	}
 	return &LoggerBuilder{
 		logger:     z,
+		onErrorMax: DefaultOnErrorLimit,
+		onErrorPer: DefaultOnErrorInterval,
 		callerSkip: 3,
 	}
 }
@@ -42,18 +53,124 @@ func (b *LoggerBuilder) WithContextValue(key any, attrKey string) *LoggerBuilder
 	return b.WithExtractor(ContextValueExtractor(key, attrKey))
 }
 
+// WithHandler fans records out to an additional slog.Handler (e.g. an
+// OpenTelemetry bridge, see klog/klogotel) alongside zap. The handler receives
+// the logger's attrs, groups and extracted context values, and is only called
+// for records that pass both the builder level and its own Enabled check.
+func (b *LoggerBuilder) WithHandler(h slog.Handler) *LoggerBuilder {
+	if h == nil {
+		panic("klog: nil slog handler")
+	}
+	b.handlers = append(b.handlers, h)
+	return b
+}
+
+// WithOutput adds a sink with its own level and encoding next to the zap
+// logger's core, e.g. JSON to stdout at INFO and console to stderr at ERROR.
+// Pass zap.NewNop() to NewSlogBuilder to write only to the outputs. Records
+// below the handler level (see WithHandlerOptions, default INFO) never reach
+// any output.
+func (b *LoggerBuilder) WithOutput(out Output) *LoggerBuilder {
+	b.outputs = append(b.outputs, out)
+	return b
+}
+
+// WithErrorHook registers a hook called for every record at Error level and
+// above, with its attrs, context values and the stack trace of errors.Error causes.
+func (b *LoggerBuilder) WithErrorHook(hook ErrorHook) *LoggerBuilder {
+	if hook == nil {
+		panic("klog: nil error hook")
+	}
+	b.errorHooks = append(b.errorHooks, hook)
+	return b
+}
+
+// OnError registers a callback fired for every record at Error level and above,
+// e.g. to trigger alerts or count SLO errors. Each callback is rate-limited on
+// its own (see OnErrorLimit); records over the limit are still logged.
+func (b *LoggerBuilder) OnError(fn ErrorCallback) *LoggerBuilder {
+	if fn == nil {
+		panic("klog: nil error callback")
+	}
+	b.onError = append(b.onError, fn)
+	return b
+}
+
+// OnErrorLimit allows each OnError callback at most limit calls per interval.
+// limit <= 0 disables rate limiting. Default: DefaultOnErrorLimit per
+// DefaultOnErrorInterval.
+func (b *LoggerBuilder) OnErrorLimit(limit int, per time.Duration) *LoggerBuilder {
+	b.onErrorMax = limit
+	b.onErrorPer = per
+	return b
+}
+
+// WithAggregation collapses identical records logged within window into one
+// record with a "count" attribute (see AggregatingHandler). Use klog.Flush to
+// emit pending records before shutdown.
+func (b *LoggerBuilder) WithAggregation(window time.Duration) *LoggerBuilder {
+	if window > 0 {
+		b.aggregate = window
+	}
+	return b
+}
+
+// WithRequestID logs the request ID stored by ContextWithRequestID.
+func (b *LoggerBuilder) WithRequestID() *LoggerBuilder {
+	return b.WithExtractor(RequestIDExtractor())
+}
+
+// WithCallerSkip reports the caller found by zap's stack walk, skipping skip
+// frames, instead of the logging call site resolved from the slog record.
+// The default resolution is correct for direct slog calls; helpers wrapping a
+// logger should set the record PC themselves (see the log/slog "wrapping"
+// example) rather than rely on a fixed skip.
 func (b *LoggerBuilder) WithCallerSkip(skip int) *LoggerBuilder {
 	if skip > 0 {
 		b.callerSkip = skip
+		b.fixedSkip = true
 	}
 	return b
 }
 
 func (b *LoggerBuilder) Build() *slog.Logger {
+	logger := b.logger
+	if len(b.outputs) > 0 {
+		logger = teeOutputs(logger, b.outputs)
+	}
+
 	handler := &zapSlogHandler{
-		logger:     b.logger.WithOptions(zap.AddCallerSkip(b.callerSkip)),
-		opts:       normalizeHandlerOptions(b.handlerOpts),
-		extractors: append([]ContextExtractor(nil), b.extractors...),
+		logger:         logger.WithOptions(zap.AddCallerSkip(b.callerSkip)),
+		opts:           normalizeHandlerOptions(b.handlerOpts),
+		extractors:     append([]ContextExtractor(nil), b.extractors...),
+		requestID:      extractsRequestID(b.extractors),
+		handlers:       append([]slog.Handler(nil), b.handlers...),
+		errorHooks:     append([]ErrorHook(nil), b.errorHooks...),
+		errorCallbacks: b.errorCallbacks(),
+		// Aggregated records are written on flush, far from the caller's stack.
+		fixedCallerSkip: b.fixedSkip && b.aggregate == 0,
+	}
+	if !handler.fixedCallerSkip {
+		// The handler resolves the caller itself; skip zap's stack walk.
+		handler.addCaller = zapAddsCaller(logger)
+		handler.logger = handler.logger.WithOptions(zap.WithCaller(false))
+	}
+	if b.aggregate > 0 {
+		return slog.New(NewAggregatingHandler(handler, b.aggregate))
 	}
 	return slog.New(handler)
 }
+
+func (b *LoggerBuilder) errorCallbacks() []*errorCallback {
+	if len(b.onError) == 0 {
+		return nil
+	}
+	callbacks := make([]*errorCallback, len(b.onError))
+	for i, fn := range b.onError {
+		callbacks[i] = &errorCallback{
+			fn:      fn,
+			limiter: newWindowLimiter(b.onErrorMax, b.onErrorPer),
+		}
+	}
+	return callbacks
+}
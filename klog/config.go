@@ -0,0 +1,159 @@
+package klog
+
+import (
+	"log/slog"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// RedactedValue replaces the value of attrs listed in Config.Redact.
+const RedactedValue = "[REDACTED]"
+
+// Config is a declarative logger setup, loadable with the config package. As a
+// `yaml:"log"` field of the application config, its values can be overridden
+// with LOG_LEVEL, LOG_FORMAT, LOG_OUTPUTS, LOG_SAMPLING_INITIAL, LOG_REDACT, ...
+//
+//	log:
+//	  level: debug
+//	  format: pretty
+//	  outputs: [stdout]
+//	  redact: [password, authorization]
+type Config struct {
+	// Level is debug, info, warn or error (see ParseLevel). Default: debug in development,
+	// info otherwise.
+	Level string `yaml:"level"`
+
+	// Format is EncodingJSON, EncodingConsole or EncodingPretty. Default: console
+	// in development, json otherwise.
+	Format string `yaml:"format"`
+
+	// Development switches to zap's development defaults (see WithDevelopment).
+	Development bool `yaml:"development"`
+
+	// Preset is "", "ecs" or "loki" (see Preset).
+	Preset string `yaml:"preset"`
+
+	// Outputs are the sinks for log entries (see WithOutputPaths).
+	// Default: stderr.
+	Outputs []string `yaml:"outputs"`
+
+	Sampling SamplingConfig `yaml:"sampling"`
+
+	// Labels are added to every entry as "labels.<key>" fields.
+	Labels map[string]string `yaml:"labels"`
+
+	// Redact lists attr keys whose values are replaced with RedactedValue.
+	// Keys match case-insensitively, either bare ("password") or group-qualified
+	// ("request.password"). Only records logged through slog are redacted.
+	Redact []string `yaml:"redact"`
+
+	// LevelVar, if set, is set to Level and becomes the level of both loggers
+	// (see WithLevelVar), so it can be changed at runtime.
+	LevelVar *slog.LevelVar `yaml:"-"`
+}
+
+// SamplingConfig is the sampling part of Config (see WithSampling). A zero
+// Initial keeps the default (sampling in production only); a negative one
+// disables sampling.
+type SamplingConfig struct {
+	Initial    int `yaml:"initial"`
+	Thereafter int `yaml:"thereafter"`
+}
+
+// FromConfig builds the zap logger and the slog logger on top of it described
+// by cfg. Use NewProvider and NewSlogBuilder directly for options Config does
+// not cover, with cfg.ProviderOptions as a starting point.
+func FromConfig(cfg Config) (*zap.Logger, *slog.Logger, error) {
+	opts, err := cfg.ProviderOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+	level, err := cfg.level()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	z, err := NewProvider(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var handlerLevel slog.Leveler = level
+	if cfg.LevelVar != nil {
+		handlerLevel = cfg.LevelVar
+	}
+	s := NewSlogBuilder(z).
+		WithHandlerOptions(&slog.HandlerOptions{
+			Level:       handlerLevel,
+			ReplaceAttr: redactAttrs(cfg.Redact),
+		}).
+		Build()
+	return z, s, nil
+}
+
+// ProviderOptions returns the NewProvider options equivalent to cfg.
+func (c Config) ProviderOptions() ([]ProviderOption, error) {
+	level, err := c.level()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []ProviderOption{
+		WithDevelopment(c.Development),
+		WithEncoding(strings.ToLower(c.Format)),
+		WithPreset(Preset(strings.ToLower(c.Preset))),
+	}
+	if c.LevelVar != nil {
+		c.LevelVar.Set(level)
+		opts = append(opts, WithLevelVar(c.LevelVar))
+	} else {
+		opts = append(opts, WithLevel(toZapLevel(level)))
+	}
+	if len(c.Outputs) > 0 {
+		opts = append(opts, WithOutputPaths(c.Outputs...))
+	}
+	switch {
+	case c.Sampling.Initial > 0:
+		opts = append(opts, WithSampling(c.Sampling.Initial, c.Sampling.Thereafter))
+	case c.Sampling.Initial < 0:
+		opts = append(opts, WithSampling(0, 0))
+	}
+	if len(c.Labels) > 0 {
+		opts = append(opts, WithLabels(c.Labels))
+	}
+	return opts, nil
+}
+
+func (c Config) level() (slog.Level, error) {
+	if c.Level == "" {
+		if c.Development {
+			return slog.LevelDebug, nil
+		}
+		return slog.LevelInfo, nil
+	}
+	return ParseLevel(c.Level)
+}
+
+func redactAttrs(keys []string) func([]string, slog.Attr) slog.Attr {
+	if len(keys) == 0 {
+		return nil
+	}
+	redact := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		redact[strings.ToLower(key)] = struct{}{}
+	}
+
+	return func(groups []string, attr slog.Attr) slog.Attr {
+		key := strings.ToLower(attr.Key)
+		if _, ok := redact[key]; ok {
+			return slog.String(attr.Key, RedactedValue)
+		}
+		if len(groups) > 0 {
+			if _, ok := redact[strings.ToLower(fullKey(groups, attr.Key))]; ok {
+				return slog.String(attr.Key, RedactedValue)
+			}
+		}
+		return attr
+	}
+}
@@ -0,0 +1,161 @@
+package klog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRedactAttrs(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []string
+		groups []string
+		attr   slog.Attr
+		want   slog.Attr
+	}{
+		{"bare key", []string{"password"}, nil, slog.String("password", "s3cret"), slog.String("password", RedactedValue)},
+		{"case-insensitive", []string{"Authorization"}, nil, slog.String("authorization", "Bearer x"), slog.String("authorization", RedactedValue)},
+		{"keeps the original key", []string{"token"}, nil, slog.String("Token", "x"), slog.String("Token", RedactedValue)},
+		{"non-string value", []string{"pin"}, nil, slog.Int("pin", 1234), slog.String("pin", RedactedValue)},
+		{"other keys untouched", []string{"password"}, nil, slog.String("user", "bob"), slog.String("user", "bob")},
+		{"bare key inside a group", []string{"password"}, []string{"request"}, slog.String("password", "x"), slog.String("password", RedactedValue)},
+		{"qualified key", []string{"request.password"}, []string{"request"}, slog.String("password", "x"), slog.String("password", RedactedValue)},
+		{"qualified key in nested groups", []string{"http.request.token"}, []string{"http", "request"}, slog.String("token", "x"), slog.String("token", RedactedValue)},
+		{"qualified key in another group", []string{"request.password"}, []string{"response"}, slog.String("password", "x"), slog.String("password", "x")},
+		{"qualified key outside groups", []string{"request.password"}, nil, slog.String("password", "x"), slog.String("password", "x")},
+		{"qualified key at a deeper level", []string{"request.password"}, []string{"http", "request"}, slog.String("password", "x"), slog.String("password", "x")},
+		{"whole group", []string{"headers"}, []string{"request"}, slog.Group("headers", slog.String("cookie", "x")), slog.String("headers", RedactedValue)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactAttrs(tt.keys)(tt.groups, tt.attr)
+			if !got.Equal(tt.want) {
+				t.Errorf("redactAttrs(%v)(%v, %v) = %v, want %v", tt.keys, tt.groups, tt.attr, got, tt.want)
+			}
+		})
+	}
+
+	if redactAttrs(nil) != nil {
+		t.Error("Expected no ReplaceAttr without keys")
+	}
+}
+
+func TestRedactAttrsNestedGroups(t *testing.T) {
+	var buf bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zapcore.DebugLevel)
+	logger := NewSlogBuilder(zap.New(core)).
+		WithHandlerOptions(&slog.HandlerOptions{
+			ReplaceAttr: redactAttrs([]string{"password", "http.request.headers.authorization"}),
+		}).
+		Build()
+
+	logger.WithGroup("http").Info("request",
+		slog.Group("request",
+			slog.Group("headers", slog.String("Authorization", "Bearer x"), slog.String("Accept", "*/*")),
+			slog.String("password", "b"),
+		),
+		slog.Group("response", slog.Group("headers", slog.String("Authorization", "kept"))),
+	)
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode %s: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"http.request.headers.Authorization":  RedactedValue,
+		"http.request.headers.Accept":         "*/*",
+		"http.request.password":               RedactedValue,
+		"http.response.headers.Authorization": "kept",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %v, want %v (entry %s)", key, got[key], value, buf.String())
+		}
+	}
+}
+
+func TestConfigProviderOptions(t *testing.T) {
+	levelVar := new(slog.LevelVar)
+	info := zapcore.InfoLevel
+	debug := zapcore.DebugLevel
+	warn := zapcore.WarnLevel
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    providerOptions
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			cfg:  Config{},
+			want: providerOptions{level: &info},
+		},
+		{
+			name: "development defaults to debug",
+			cfg:  Config{Development: true},
+			want: providerOptions{development: true, level: &debug},
+		},
+		{
+			name: "format and preset are case-insensitive",
+			cfg:  Config{Level: "WARN", Format: "JSON", Preset: "ECS"},
+			want: providerOptions{level: &warn, encoding: EncodingJSON, preset: PresetECS},
+		},
+		{
+			name: "outputs and labels",
+			cfg:  Config{Outputs: []string{"stdout", "/var/log/app.log"}, Labels: map[string]string{"env": "prod"}},
+			want: providerOptions{level: &info, outputPaths: []string{"stdout", "/var/log/app.log"}, labels: map[string]string{"env": "prod"}},
+		},
+		{
+			name: "sampling",
+			cfg:  Config{Sampling: SamplingConfig{Initial: 10, Thereafter: 100}},
+			want: providerOptions{level: &info, samplingSet: true, sampling: &zap.SamplingConfig{Initial: 10, Thereafter: 100}},
+		},
+		{
+			name: "negative sampling disables it",
+			cfg:  Config{Sampling: SamplingConfig{Initial: -1}},
+			want: providerOptions{level: &info, samplingSet: true},
+		},
+		{
+			name: "level var",
+			cfg:  Config{Level: "error", LevelVar: levelVar},
+			want: providerOptions{levelVar: levelVar},
+		},
+		{
+			name:    "invalid level",
+			cfg:     Config{Level: "verbose"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := tt.cfg.ProviderOptions()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProviderOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			got := providerOptions{}
+			for _, opt := range opts {
+				opt(&got)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProviderOptions()\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+
+	if levelVar.Level() != slog.LevelError {
+		t.Errorf("Expected the LevelVar to be set to the config level, got %v", levelVar.Level())
+	}
+}
@@ -0,0 +1,73 @@
+package klog
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+// errorField renders errors whose chain contains an *errors.Error as an object
+// with its code, message, cause, operations (see errors.WithOp), details and
+// stack frames, instead of the single string (plus verbose stack) of
+// zap.NamedError.
+func errorField(key string, err error) zap.Field {
+	var kerr *kerrors.Error
+	if !kerrors.As(err, &kerr) {
+		return zap.NamedError(key, err)
+	}
+	return zap.Object(key, structuredError{err: err, kerr: kerr})
+}
+
+type structuredError struct {
+	err  error
+	kerr *kerrors.Error
+}
+
+func (e structuredError) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("error", e.err.Error())
+	enc.AddString("code", e.kerr.Code.String())
+	enc.AddString("message", e.kerr.Message)
+	if e.kerr.Cause != nil {
+		enc.AddString("cause", e.kerr.Cause.Error())
+	}
+	if ops := kerrors.Ops(e.err); len(ops) > 0 {
+		if err := enc.AddArray("ops", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, op := range ops {
+				arr.AppendString(op)
+			}
+			return nil
+		})); err != nil {
+			return err
+		}
+	}
+	if len(e.kerr.Details) > 0 {
+		if err := enc.AddReflected("details", e.kerr.Details); err != nil {
+			return err
+		}
+	}
+	if len(e.kerr.StackTrace) > 0 {
+		return enc.AddArray("stack", stackFrames(e.kerr.StackTrace))
+	}
+	return nil
+}
+
+type stackFrames []kerrors.StackFrame
+
+func (s stackFrames) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, frame := range s {
+		if err := enc.AppendObject(stackFrame(frame)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type stackFrame kerrors.StackFrame
+
+func (f stackFrame) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("function", f.Function)
+	enc.AddString("file", f.File)
+	enc.AddInt("line", f.Line)
+	return nil
+}
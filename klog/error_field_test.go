@@ -0,0 +1,87 @@
+package klog
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+// encodeField encodes field into a map, as a JSON encoder would see it.
+func encodeField(field zapcore.Field) map[string]any {
+	enc := zapcore.NewMapObjectEncoder()
+	field.AddTo(enc)
+	return enc.Fields
+}
+
+func TestErrorField(t *testing.T) {
+	cause := errors.New("connection refused")
+	kerr := kerrors.Wrap(cause, kerrors.CodeDatabase, "query users").
+		WithOp("userrepo.List").
+		WithDetail("table", "users")
+	err := kerrors.WithOp(fmt.Errorf("list: %w", kerr), "userservice.List")
+
+	fields := encodeField(errorField("error", err))
+	obj, ok := fields["error"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected an object for an *errors.Error, got %#v", fields["error"])
+	}
+
+	want := map[string]any{
+		"error":   err.Error(),
+		"code":    string(kerrors.CodeDatabase),
+		"message": "query users",
+		"cause":   "connection refused",
+	}
+	for key, value := range want {
+		if obj[key] != value {
+			t.Errorf("%s = %v, want %v", key, obj[key], value)
+		}
+	}
+
+	ops, _ := obj["ops"].([]any)
+	if len(ops) != 2 || ops[0] != "userservice.List" || ops[1] != "userrepo.List" {
+		t.Errorf("ops = %v, want [userservice.List userrepo.List]", obj["ops"])
+	}
+	if details, _ := obj["details"].(map[string]any); details["table"] != "users" {
+		t.Errorf("details = %v, want table=users", obj["details"])
+	}
+
+	stack, _ := obj["stack"].([]any)
+	if len(stack) == 0 || len(stack) != len(kerr.StackTrace) {
+		t.Fatalf("Expected %d stack frames, got %v", len(kerr.StackTrace), obj["stack"])
+	}
+	frame, _ := stack[0].(map[string]any)
+	top := kerr.StackTrace[0]
+	if frame["function"] != top.Function || frame["file"] != top.File || frame["line"] != top.Line {
+		t.Errorf("stack[0] = %v, want %s %s:%d", frame, top.Function, top.File, top.Line)
+	}
+}
+
+func TestErrorFieldMinimal(t *testing.T) {
+	fields := encodeField(errorField("error", kerrors.NewError(kerrors.CodeNotFound, "user not found")))
+	obj, ok := fields["error"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected an object, got %#v", fields["error"])
+	}
+	for _, key := range []string{"cause", "ops", "details", "stack"} {
+		if _, ok := obj[key]; ok {
+			t.Errorf("Expected no %s for an error without one, got %v", key, obj[key])
+		}
+	}
+}
+
+func TestErrorFieldPlainError(t *testing.T) {
+	fields := encodeField(errorField("error", errors.New("boom")))
+	if fields["error"] != "boom" {
+		t.Errorf("Expected a plain error to log as a string, got %#v", fields["error"])
+	}
+
+	fields = encodeField(anyToField("failure", fmt.Errorf("wrapped: %w", errors.New("boom"))))
+	if fields["failure"] != "wrapped: boom" {
+		t.Errorf("Expected a wrapped plain error to log as a string, got %#v", fields["failure"])
+	}
+}
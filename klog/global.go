@@ -0,0 +1,50 @@
+package klog
+
+import (
+	"log"
+	"log/slog"
+
+	"go.uber.org/zap"
+)
+
+// SetGlobal makes z and s the process-wide loggers: zap.L/zap.S return z,
+// slog.Default returns s and the standard log package writes through s at
+// Info level. Either logger may be nil to leave that surface unchanged.
+//
+// The returned function restores the previous loggers, e.g. in tests:
+//
+//	restore := klog.SetGlobal(zapLogger, slogLogger)
+//	defer restore()
+func SetGlobal(z *zap.Logger, s *slog.Logger) (restore func()) {
+	prevSlog := slog.Default()
+	prevWriter := log.Writer()
+	prevFlags := log.Flags()
+	prevPrefix := log.Prefix()
+
+	undoZap := func() {}
+	if z != nil {
+		undoZap = zap.ReplaceGlobals(z)
+	}
+	if s != nil {
+		// slog.SetDefault also redirects the standard log package to s.
+		log.SetPrefix("")
+		slog.SetDefault(s)
+	}
+
+	return func() {
+		undoZap()
+		if s != nil {
+			slog.SetDefault(prevSlog)
+			log.SetOutput(prevWriter)
+			log.SetFlags(prevFlags)
+			log.SetPrefix(prevPrefix)
+		}
+	}
+}
+
+// BuildGlobal builds the slog logger and makes it and the builder's zap logger the
+// global loggers (see SetGlobal).
+func (b *LoggerBuilder) BuildGlobal() (*slog.Logger, func()) {
+	s := b.Build()
+	return s, SetGlobal(b.logger, s)
+}
@@ -0,0 +1,120 @@
+package klog_test
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/karu-codes/karu-kits/klog"
+	"github.com/karu-codes/karu-kits/klog/klogtest"
+)
+
+// saveStandardLog sets a known output, flags and prefix on the standard
+// logger and restores the originals after the test.
+func saveStandardLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	writer, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+	t.Cleanup(func() {
+		log.SetOutput(writer)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	})
+
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	log.SetFlags(log.Lmicroseconds)
+	log.SetPrefix("app: ")
+	return &out
+}
+
+func TestSetGlobal(t *testing.T) {
+	out := saveStandardLog(t)
+	prevZap, prevSlog := zap.L(), slog.Default()
+
+	core, observed := observer.New(zapcore.DebugLevel)
+	z := zap.New(core)
+	s, rec := klogtest.NewLogger(t)
+
+	restore := klog.SetGlobal(z, s)
+
+	zap.L().Info("from zap.L")
+	zap.S().Infow("from zap.S", "k", "v")
+	slog.Info("from slog", "k", "v")
+	log.Print("from log")
+
+	if got := observed.FilterMessage("from zap.L").Len() + observed.FilterMessage("from zap.S").Len(); got != 2 {
+		t.Errorf("Expected zap.L and zap.S to write to the new zap logger, got %v", observed.All())
+	}
+	rec.AssertLogged(slog.LevelInfo, "from slog", "k", "v")
+	rec.AssertLogged(slog.LevelInfo, "from log")
+	rec.AssertNotLogged(slog.LevelInfo, "app: ")
+	if out.Len() != 0 {
+		t.Errorf("Expected the standard log to stop writing to its output, got %q", out.String())
+	}
+
+	restore()
+
+	if zap.L() != prevZap {
+		t.Error("Expected restore to put back the previous zap logger")
+	}
+	if slog.Default() != prevSlog {
+		t.Error("Expected restore to put back the previous slog logger")
+	}
+	if log.Flags() != log.Lmicroseconds || log.Prefix() != "app: " || log.Writer() != out {
+		t.Errorf("Expected restore to put back the standard log output, flags and prefix, got flags %d prefix %q", log.Flags(), log.Prefix())
+	}
+
+	rec.Reset()
+	log.SetFlags(0)
+	log.Print("after restore")
+	if out.String() != "app: after restore\n" {
+		t.Errorf("Expected the standard log to write to its output again, got %q", out.String())
+	}
+	if records := rec.Records(); len(records) != 0 {
+		t.Errorf("Expected nothing to reach the test logger after restore, got %v", records)
+	}
+}
+
+func TestSetGlobalNil(t *testing.T) {
+	out := saveStandardLog(t)
+	prevZap, prevSlog := zap.L(), slog.Default()
+
+	restore := klog.SetGlobal(nil, nil)
+	defer restore()
+
+	if zap.L() != prevZap || slog.Default() != prevSlog {
+		t.Error("Expected nil loggers to leave the globals unchanged")
+	}
+	log.SetFlags(0)
+	log.Print("unchanged")
+	if out.String() != "app: unchanged\n" {
+		t.Errorf("Expected the standard log to be unchanged, got %q", out.String())
+	}
+}
+
+func TestBuildGlobal(t *testing.T) {
+	saveStandardLog(t)
+
+	core, observed := observer.New(zapcore.DebugLevel)
+	logger, restore := klog.NewSlogBuilder(zap.New(core)).BuildGlobal()
+	defer restore()
+
+	if slog.Default() != logger {
+		t.Error("Expected BuildGlobal to set slog.Default")
+	}
+	zap.L().Info("from zap.L")
+	slog.Info("from slog")
+	log.Print("from log")
+
+	for _, msg := range []string{"from zap.L", "from slog", "from log"} {
+		if observed.FilterMessage(msg).Len() != 1 {
+			t.Errorf("Expected %q to reach the builder's zap logger, got %v", msg, observed.All())
+		}
+	}
+}
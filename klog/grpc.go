@@ -0,0 +1,244 @@
+package klog
+
+import (
+	"context"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var healthCheckMethods = []string{
+	"/grpc.health.v1.Health/Check",
+	"/grpc.health.v1.Health/Watch",
+	"/grpc.health.v1.Health/List",
+}
+
+type GRPCOption func(*grpcOptions)
+
+type grpcOptions struct {
+	skip          map[string]bool
+	requestIDKeys []string
+	payloads      bool
+	// requestIDLogged is set when the logger adds the request ID itself.
+	requestIDLogged bool
+}
+
+// WithGRPCSkipMethods disables logging for the given full method names
+// (e.g. "/pkg.Service/Method").
+func WithGRPCSkipMethods(methods ...string) GRPCOption {
+	return func(o *grpcOptions) {
+		for _, m := range methods {
+			o.skip[m] = true
+		}
+	}
+}
+
+// WithGRPCSkipHealthChecks disables logging for the standard grpc.health.v1 methods.
+func WithGRPCSkipHealthChecks() GRPCOption {
+	return WithGRPCSkipMethods(healthCheckMethods...)
+}
+
+// WithGRPCRequestIDKeys sets the incoming metadata keys searched, in order, for
+// a request ID. Invalid values are skipped (see ValidRequestID). Default:
+// "x-request-id".
+func WithGRPCRequestIDKeys(keys ...string) GRPCOption {
+	return func(o *grpcOptions) {
+		o.requestIDKeys = append([]string(nil), keys...)
+	}
+}
+
+// WithGRPCPayloads logs request and response messages at debug level.
+// Payloads may contain sensitive data.
+func WithGRPCPayloads() GRPCOption {
+	return func(o *grpcOptions) {
+		o.payloads = true
+	}
+}
+
+func newGRPCOptions(logger *slog.Logger, opts []GRPCOption) *grpcOptions {
+	o := &grpcOptions{
+		skip:            make(map[string]bool),
+		requestIDKeys:   []string{strings.ToLower(RequestIDHeader)},
+		requestIDLogged: logsRequestID(logger.Handler()),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// UnaryServerInterceptor logs every unary RPC with its method, status code,
+// latency, peer address and request ID. The request ID from the metadata is
+// stored in the handler context (see RequestIDFromContext). It is not added
+// again when logger already logs it (see LoggerBuilder.WithRequestID); other
+// handlers with a RequestIDExtractor log it twice.
+func UnaryServerInterceptor(logger *slog.Logger, opts ...GRPCOption) grpc.UnaryServerInterceptor {
+	if logger == nil {
+		panic("klog: nil slog logger")
+	}
+	o := newGRPCOptions(logger, opts)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if o.skip[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		ctx = o.withRequestID(ctx)
+		attrs := o.callAttrs(ctx, info.FullMethod)
+		if o.payloads {
+			logger.LogAttrs(ctx, slog.LevelDebug, "grpc request", append(attrs, slog.Any("request", req))...)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		if o.payloads && err == nil {
+			logger.LogAttrs(ctx, slog.LevelDebug, "grpc response", append(attrs, slog.Any("response", resp))...)
+		}
+		logCall(ctx, logger, attrs, time.Since(start), err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor logs every streaming RPC when it ends, with its method,
+// status code, duration, peer address and request ID. The request ID is
+// handled as by UnaryServerInterceptor.
+func StreamServerInterceptor(logger *slog.Logger, opts ...GRPCOption) grpc.StreamServerInterceptor {
+	if logger == nil {
+		panic("klog: nil slog logger")
+	}
+	o := newGRPCOptions(logger, opts)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if o.skip[info.FullMethod] {
+			return handler(srv, ss)
+		}
+
+		ctx := o.withRequestID(ss.Context())
+		if ctx != ss.Context() {
+			ss = &contextServerStream{ServerStream: ss, ctx: ctx}
+		}
+		attrs := o.callAttrs(ctx, info.FullMethod)
+		if o.payloads {
+			ss = &loggingServerStream{ServerStream: ss, logger: logger, attrs: attrs}
+		}
+
+		start := time.Now()
+		err := handler(srv, ss)
+
+		logCall(ctx, logger, attrs, time.Since(start), err)
+		return err
+	}
+}
+
+func (o *grpcOptions) callAttrs(ctx context.Context, fullMethod string) []slog.Attr {
+	service, method := splitMethod(fullMethod)
+	attrs := []slog.Attr{
+		slog.String("grpc.service", service),
+		slog.String("grpc.method", method),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, slog.String("peer.address", p.Addr.String()))
+	}
+	if id, ok := RequestIDFromContext(ctx); ok && !o.requestIDLogged {
+		attrs = append(attrs, slog.String(RequestIDKey, id))
+	}
+	// Clip so that concurrent appends (stream send/receive) never share a backing array.
+	return slices.Clip(attrs)
+}
+
+// withRequestID stores the metadata request ID in the context (see
+// ContextWithRequestID) unless the context already carries one.
+func (o *grpcOptions) withRequestID(ctx context.Context) context.Context {
+	if _, ok := RequestIDFromContext(ctx); ok {
+		return ctx
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	for _, key := range o.requestIDKeys {
+		if values := md.Get(key); len(values) > 0 && ValidRequestID(values[0]) {
+			return ContextWithRequestID(ctx, values[0])
+		}
+	}
+	return ctx
+}
+
+func logCall(ctx context.Context, logger *slog.Logger, attrs []slog.Attr, elapsed time.Duration, err error) {
+	code := status.Code(err)
+	attrs = append(attrs,
+		slog.String("grpc.code", code.String()),
+		slog.Duration("duration", elapsed),
+	)
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	logger.LogAttrs(ctx, grpcCodeLevel(code), "grpc call", attrs...)
+}
+
+// grpcCodeLevel logs client-caused failures as warnings and server failures as errors.
+func grpcCodeLevel(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		return slog.LevelInfo
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+func splitMethod(fullMethod string) (string, string) {
+	if fullMethod == "" {
+		return "", ""
+	}
+	service, method := path.Split(fullMethod)
+	if len(service) > 1 {
+		service = service[1 : len(service)-1]
+	}
+	return service, method
+}
+
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+type loggingServerStream struct {
+	grpc.ServerStream
+	logger *slog.Logger
+	attrs  []slog.Attr
+}
+
+func (s *loggingServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.logger.LogAttrs(s.Context(), slog.LevelDebug, "grpc stream receive", append(s.attrs, slog.Any("message", m))...)
+	}
+	return err
+}
+
+func (s *loggingServerStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.logger.LogAttrs(s.Context(), slog.LevelDebug, "grpc stream send", append(s.attrs, slog.Any("message", m))...)
+	}
+	return err
+}
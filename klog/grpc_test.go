@@ -0,0 +1,328 @@
+package klog_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/karu-codes/karu-kits/klog"
+	"github.com/karu-codes/karu-kits/klog/klogtest"
+)
+
+var unaryInfo = &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Get"}
+
+func TestUnaryServerInterceptorLevels(t *testing.T) {
+	tests := []struct {
+		code  codes.Code
+		level slog.Level
+	}{
+		{codes.OK, slog.LevelInfo},
+		{codes.Canceled, slog.LevelWarn},
+		{codes.InvalidArgument, slog.LevelWarn},
+		{codes.NotFound, slog.LevelWarn},
+		{codes.AlreadyExists, slog.LevelWarn},
+		{codes.PermissionDenied, slog.LevelWarn},
+		{codes.Unauthenticated, slog.LevelWarn},
+		{codes.ResourceExhausted, slog.LevelWarn},
+		{codes.FailedPrecondition, slog.LevelWarn},
+		{codes.Aborted, slog.LevelWarn},
+		{codes.OutOfRange, slog.LevelWarn},
+		{codes.Unknown, slog.LevelError},
+		{codes.DeadlineExceeded, slog.LevelError},
+		{codes.Unimplemented, slog.LevelError},
+		{codes.Internal, slog.LevelError},
+		{codes.Unavailable, slog.LevelError},
+		{codes.DataLoss, slog.LevelError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			logger, rec := klogtest.NewLogger(t)
+			interceptor := klog.UnaryServerInterceptor(logger)
+
+			handler := func(context.Context, any) (any, error) {
+				if tt.code == codes.OK {
+					return "ok", nil
+				}
+				return nil, status.Error(tt.code, "failed")
+			}
+			_, err := interceptor(context.Background(), "req", unaryInfo, handler)
+			if status.Code(err) != tt.code {
+				t.Fatalf("Expected the handler error to be returned, got %v", err)
+			}
+
+			rec.AssertLogged(tt.level, "grpc call",
+				"grpc.service", "orders.v1.Orders",
+				"grpc.method", "Get",
+				"grpc.code", tt.code.String(),
+			)
+			if records := rec.Records(); len(records) != 1 {
+				t.Errorf("Expected one record, got %v", records)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptorRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		opts []klog.GRPCOption
+		want string
+	}{
+		{
+			name: "default metadata key",
+			ctx:  metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-1")),
+			want: "req-1",
+		},
+		{
+			name: "custom metadata keys in order",
+			ctx:  metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-correlation-id", "corr-1", "x-trace", "trace-1")),
+			opts: []klog.GRPCOption{klog.WithGRPCRequestIDKeys("x-missing", "x-correlation-id", "x-trace")},
+			want: "corr-1",
+		},
+		{
+			name: "invalid metadata ID skipped",
+			ctx:  metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "bad id\n", "x-trace", "trace-1")),
+			opts: []klog.GRPCOption{klog.WithGRPCRequestIDKeys("x-request-id", "x-trace")},
+			want: "trace-1",
+		},
+		{
+			name: "invalid metadata ID only",
+			ctx:  metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", strings.Repeat("a", klog.MaxRequestIDLength+1))),
+		},
+		{
+			name: "context request ID wins",
+			ctx: klog.ContextWithRequestID(
+				metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-1")), "ctx-1"),
+			want: "ctx-1",
+		},
+		{
+			name: "no request ID",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, rec := klogtest.NewLogger(t)
+			interceptor := klog.UnaryServerInterceptor(logger, tt.opts...)
+
+			var handlerID string
+			_, err := interceptor(tt.ctx, "req", unaryInfo, func(ctx context.Context, _ any) (any, error) {
+				handlerID, _ = klog.RequestIDFromContext(ctx)
+				return "ok", nil
+			})
+			if err != nil {
+				t.Fatalf("interceptor() error = %v", err)
+			}
+
+			if handlerID != tt.want {
+				t.Errorf("Expected the handler context to carry request ID %q, got %q", tt.want, handlerID)
+			}
+			if tt.want == "" {
+				if records := rec.Records(); len(records) != 1 {
+					t.Fatalf("Expected one record, got %v", records)
+				} else if _, ok := records[0].Attr(klog.RequestIDKey); ok {
+					t.Errorf("Expected no request ID attr, got %v", records[0].Attrs)
+				}
+				return
+			}
+			rec.AssertLogged(slog.LevelInfo, "grpc call", klog.RequestIDKey, tt.want)
+		})
+	}
+}
+
+// countRequestIDs counts the request_id fields of the entries logged as msg.
+func countRequestIDs(t *testing.T, observed *observer.ObservedLogs, msg string) int {
+	t.Helper()
+
+	entries := observed.FilterMessage(msg).All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected one %q entry, got %v", msg, observed.All())
+	}
+	var n int
+	for _, field := range entries[0].Context {
+		if field.Key == klog.RequestIDKey {
+			n++
+		}
+	}
+	return n
+}
+
+func TestUnaryServerInterceptorRequestIDLoggedOnce(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(*klog.LoggerBuilder) *slog.Logger
+	}{
+		{"without extractor", func(b *klog.LoggerBuilder) *slog.Logger { return b.Build() }},
+		{"WithRequestID", func(b *klog.LoggerBuilder) *slog.Logger { return b.WithRequestID().Build() }},
+		{"RequestIDExtractor", func(b *klog.LoggerBuilder) *slog.Logger {
+			return b.WithExtractor(klog.RequestIDExtractor()).Build().With("component", "grpc")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, observed := observer.New(zapcore.DebugLevel)
+			logger := tt.build(klog.NewSlogBuilder(zap.New(core)).WithHandlerOptions(&slog.HandlerOptions{Level: slog.LevelDebug}))
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-1"))
+
+			unary := klog.UnaryServerInterceptor(logger, klog.WithGRPCPayloads())
+			_, _ = unary(ctx, "req", unaryInfo, func(context.Context, any) (any, error) { return "ok", nil })
+			for _, msg := range []string{"grpc request", "grpc response", "grpc call"} {
+				if n := countRequestIDs(t, observed, msg); n != 1 {
+					t.Errorf("Expected one request_id on %q, got %d", msg, n)
+				}
+			}
+
+			observed.TakeAll()
+			stream := klog.StreamServerInterceptor(logger)
+			_ = stream(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/orders.v1.Orders/Watch"},
+				func(any, grpc.ServerStream) error { return nil })
+			if n := countRequestIDs(t, observed, "grpc call"); n != 1 {
+				t.Errorf("Expected one request_id on the stream call, got %d", n)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptorPayloads(t *testing.T) {
+	logger, rec := klogtest.NewLogger(t)
+	interceptor := klog.UnaryServerInterceptor(logger, klog.WithGRPCPayloads())
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+
+	if _, err := interceptor(ctx, "get order 7", unaryInfo, func(context.Context, any) (any, error) {
+		return "order 7", nil
+	}); err != nil {
+		t.Fatalf("interceptor() error = %v", err)
+	}
+	rec.AssertLogged(slog.LevelDebug, "grpc request", "request", "get order 7", "peer.address", "10.0.0.1:5000")
+	rec.AssertLogged(slog.LevelDebug, "grpc response", "response", "order 7")
+	rec.AssertLogged(slog.LevelInfo, "grpc call", "peer.address", "10.0.0.1:5000")
+
+	// Failed calls have no response to log.
+	rec.Reset()
+	if _, err := interceptor(ctx, "get order 8", unaryInfo, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.NotFound, "no order 8")
+	}); err == nil {
+		t.Fatal("Expected the handler error")
+	}
+	rec.AssertLogged(slog.LevelDebug, "grpc request", "request", "get order 8")
+	rec.AssertNotLogged(slog.LevelDebug, "grpc response")
+
+	// Without the option, payloads are not logged.
+	logger, rec = klogtest.NewLogger(t)
+	interceptor = klog.UnaryServerInterceptor(logger)
+	if _, err := interceptor(ctx, "get order 7", unaryInfo, func(context.Context, any) (any, error) {
+		return "order 7", nil
+	}); err != nil {
+		t.Fatalf("interceptor() error = %v", err)
+	}
+	rec.AssertNotLogged(slog.LevelDebug, "grpc")
+}
+
+func TestUnaryServerInterceptorSkip(t *testing.T) {
+	logger, rec := klogtest.NewLogger(t)
+	interceptor := klog.UnaryServerInterceptor(logger, klog.WithGRPCSkipHealthChecks(), klog.WithGRPCSkipMethods("/orders.v1.Orders/Ping"))
+
+	for _, method := range []string{"/grpc.health.v1.Health/Check", "/orders.v1.Orders/Ping"} {
+		called := false
+		_, _ = interceptor(context.Background(), "req", &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, any) (any, error) {
+			called = true
+			return nil, nil
+		})
+		if !called {
+			t.Errorf("Expected the handler of %s to be called", method)
+		}
+	}
+	if records := rec.Records(); len(records) != 0 {
+		t.Errorf("Expected skipped methods not to be logged, got %v", records)
+	}
+}
+
+// fakeServerStream replays recv and records sent messages.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	recv []string
+	sent []any
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func (s *fakeServerStream) RecvMsg(m any) error {
+	if len(s.recv) == 0 {
+		return io.EOF
+	}
+	*m.(*string) = s.recv[0]
+	s.recv = s.recv[1:]
+	return nil
+}
+
+func (s *fakeServerStream) SendMsg(m any) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	logger, rec := klogtest.NewLogger(t)
+	interceptor := klog.StreamServerInterceptor(logger, klog.WithGRPCPayloads())
+	info := &grpc.StreamServerInfo{FullMethod: "/orders.v1.Orders/Watch"}
+
+	stream := &fakeServerStream{
+		ctx:  metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-1")),
+		recv: []string{"order 7", "order 8"},
+	}
+	errStop := status.Error(codes.Unavailable, "shutting down")
+	err := interceptor(nil, stream, info, func(_ any, ss grpc.ServerStream) error {
+		if id, _ := klog.RequestIDFromContext(ss.Context()); id != "req-1" {
+			t.Errorf("Expected the stream context to carry the request ID, got %q", id)
+		}
+		for {
+			var msg string
+			if err := ss.RecvMsg(&msg); err != nil {
+				if errors.Is(err, io.EOF) {
+					return errStop
+				}
+				return err
+			}
+			if err := ss.SendMsg("ack " + msg); err != nil {
+				return err
+			}
+		}
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("Expected the handler error to be returned, got %v", err)
+	}
+
+	if len(stream.sent) != 2 {
+		t.Errorf("Expected messages to reach the stream, got %v", stream.sent)
+	}
+	for _, msg := range []string{"order 7", "order 8"} {
+		rec.AssertLogged(slog.LevelDebug, "grpc stream receive", "grpc.method", "Watch", klog.RequestIDKey, "req-1")
+		rec.AssertLogged(slog.LevelDebug, "grpc stream send", "message", "ack "+msg)
+	}
+	if got := len(rec.Find(slog.LevelDebug, "grpc stream receive")); got != 2 {
+		t.Errorf("Expected 2 receive records, got %d", got)
+	}
+	rec.AssertLogged(slog.LevelError, "grpc call",
+		"grpc.service", "orders.v1.Orders",
+		"grpc.method", "Watch",
+		"grpc.code", "Unavailable",
+		klog.RequestIDKey, "req-1",
+	)
+}
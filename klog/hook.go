@@ -0,0 +1,95 @@
+package klog
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+// ErrorReport is a record at Error level or above, passed to an ErrorHook.
+type ErrorReport struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+
+	// Attrs are the logger attrs, extracted context values and record attrs,
+	// flattened with group-qualified keys ("group.key").
+	Attrs []slog.Attr
+
+	// Err is the first error-valued attr, if any.
+	Err error
+
+	// StackTrace is the stack trace of the first *errors.Error in Err's chain,
+	// if it captured one.
+	StackTrace []kerrors.StackFrame
+
+	// PC is the program counter of the logging call, or 0.
+	PC uintptr
+}
+
+// ErrorHook receives records at Error level and above, e.g. to forward them to
+// an error-reporting service (see klog/klogsentry). Hooks run synchronously on
+// the logging goroutine and must be safe for concurrent use.
+type ErrorHook func(ctx context.Context, report ErrorReport)
+
+func (h *zapSlogHandler) report(ctx context.Context, record slog.Record, extracted []slog.Attr) {
+	attrs := make([]slog.Attr, 0, len(h.attrs)+len(extracted)+record.NumAttrs())
+	for _, attr := range h.attrs {
+		attrs = flattenAttr(attrs, h.groups, attr, h.opts.replaceAttr)
+	}
+	for _, attr := range extracted {
+		attrs = flattenAttr(attrs, h.groups, attr, h.opts.replaceAttr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = flattenAttr(attrs, h.groups, attr, h.opts.replaceAttr)
+		return true
+	})
+
+	report := ErrorReport{
+		Time:    record.Time,
+		Level:   record.Level,
+		Message: record.Message,
+		Attrs:   attrs,
+		PC:      record.PC,
+	}
+	for _, attr := range attrs {
+		if err, ok := attr.Value.Any().(error); ok && attr.Value.Kind() == slog.KindAny {
+			report.Err = err
+			break
+		}
+	}
+	if report.Err != nil {
+		var kerr *kerrors.Error
+		if kerrors.As(report.Err, &kerr) {
+			report.StackTrace = kerr.StackTrace
+		}
+	}
+
+	for _, hook := range h.errorHooks {
+		hook(ctx, report)
+	}
+}
+
+// flattenAttr appends attr to dst with a group-qualified key, expanding groups.
+// replaceAttr, if set, is applied as by slog.HandlerOptions.ReplaceAttr.
+func flattenAttr(dst []slog.Attr, groups []string, attr slog.Attr, replaceAttr func([]string, slog.Attr) slog.Attr) []slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if replaceAttr != nil {
+		attr = replaceAttr(groups, attr)
+	}
+	if attr.Equal(slog.Attr{}) {
+		return dst
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		childGroups := appendGroup(groups, attr.Key)
+		for _, child := range attr.Value.Group() {
+			dst = flattenAttr(dst, childGroups, child, replaceAttr)
+		}
+		return dst
+	}
+
+	return append(dst, slog.Attr{Key: fullKey(groups, attr.Key), Value: attr.Value})
+}
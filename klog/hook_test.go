@@ -0,0 +1,96 @@
+package klog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"go.uber.org/zap"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+type tenantKey struct{}
+
+func TestWithErrorHook(t *testing.T) {
+	var reports []ErrorReport
+	logger := NewSlogBuilder(zap.NewNop()).
+		WithHandlerOptions(&slog.HandlerOptions{Level: slog.LevelDebug}).
+		WithExtractor(ContextValueExtractor(tenantKey{}, "tenant")).
+		WithErrorHook(func(_ context.Context, report ErrorReport) {
+			reports = append(reports, report)
+		}).
+		Build()
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	logger.InfoContext(ctx, "started")
+	logger.WarnContext(ctx, "slow")
+	if len(reports) != 0 {
+		t.Fatalf("Expected records below Error not to be reported, got %v", reports)
+	}
+
+	cause := kerrors.New(kerrors.CodeDatabase, "connection refused")
+	logger.WithGroup("query").With("component", "db").ErrorContext(ctx, "query failed",
+		"table", "users",
+		slog.Group("stats", slog.Int("rows", 0)),
+		"error", cause,
+	)
+
+	if len(reports) != 1 {
+		t.Fatalf("Expected one report, got %d", len(reports))
+	}
+	report := reports[0]
+	if report.Level != slog.LevelError || report.Message != "query failed" || report.PC == 0 || report.Time.IsZero() {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	want := map[string]any{
+		"query.component":  "db",
+		"query.tenant":     "acme",
+		"query.table":      "users",
+		"query.stats.rows": int64(0),
+	}
+	got := make(map[string]any)
+	for _, attr := range report.Attrs {
+		got[attr.Key] = attr.Value.Any()
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("Attrs[%s] = %v, want %v (attrs %v)", key, got[key], value, report.Attrs)
+		}
+	}
+	if !errors.Is(report.Err, cause) {
+		t.Errorf("Expected Err to be the logged error, got %v", report.Err)
+	}
+	if len(report.StackTrace) == 0 || len(report.StackTrace) != len(cause.StackTrace) {
+		t.Errorf("Expected the stack trace of the *errors.Error, got %d frames", len(report.StackTrace))
+	}
+}
+
+func TestWithErrorHookPlainError(t *testing.T) {
+	var reports []ErrorReport
+	logger := NewSlogBuilder(zap.NewNop()).
+		WithErrorHook(func(_ context.Context, report ErrorReport) {
+			reports = append(reports, report)
+		}).
+		WithHandlerOptions(&slog.HandlerOptions{ReplaceAttr: redactAttrs([]string{"password"})}).
+		Build()
+
+	errPlain := errors.New("boom")
+	logger.Error("failed", "password", "s3cret", "first", errPlain, "second", errors.New("other"))
+	logger.Error("no error attr")
+
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 reports, got %d", len(reports))
+	}
+	if reports[0].Err != errPlain || reports[0].StackTrace != nil {
+		t.Errorf("Expected the first error attr without stack, got %v %v", reports[0].Err, reports[0].StackTrace)
+	}
+	if v := reports[0].Attrs[0]; v.Key != "password" || v.Value.String() != RedactedValue {
+		t.Errorf("Expected ReplaceAttr to apply to reports, got %v", v)
+	}
+	if reports[1].Err != nil {
+		t.Errorf("Expected no Err, got %v", reports[1].Err)
+	}
+}
@@ -0,0 +1,129 @@
+package klog
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type HTTPOption func(*httpOptions)
+
+type httpOptions struct {
+	skip map[string]bool
+}
+
+// WithHTTPSkipPaths disables logging for requests to the given URL paths
+// (e.g. "/healthz").
+func WithHTTPSkipPaths(paths ...string) HTTPOption {
+	return func(o *httpOptions) {
+		for _, p := range paths {
+			o.skip[p] = true
+		}
+	}
+}
+
+func newHTTPOptions(opts []HTTPOption) *httpOptions {
+	o := &httpOptions{skip: make(map[string]bool)}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// HTTPMiddleware logs every request when its handler returns, with its method,
+// path, status code, response size, latency, peer address and request ID.
+// Install it inside RequestIDMiddleware so that the request ID is set. The
+// request ID is not added again when logger already logs it (see
+// LoggerBuilder.WithRequestID); other handlers with a RequestIDExtractor log it
+// twice.
+func HTTPMiddleware(logger *slog.Logger, opts ...HTTPOption) func(http.Handler) http.Handler {
+	if logger == nil {
+		panic("klog: nil slog logger")
+	}
+	o := newHTTPOptions(opts)
+	requestIDLogged := logsRequestID(logger.Handler())
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if o.skip[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &statusRecorder{ResponseWriter: w}
+			start := time.Now()
+			next.ServeHTTP(rec, r)
+			elapsed := time.Since(start)
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			attrs := []slog.Attr{
+				slog.String("http.method", r.Method),
+				slog.String("http.path", r.URL.Path),
+				slog.Int("http.status_code", status),
+				slog.Int64("http.response_size", rec.size),
+				slog.Duration("duration", elapsed),
+			}
+			if r.RemoteAddr != "" {
+				attrs = append(attrs, slog.String("peer.address", r.RemoteAddr))
+			}
+			if ua := r.UserAgent(); ua != "" {
+				attrs = append(attrs, slog.String("http.user_agent", ua))
+			}
+			if id, ok := RequestIDFromContext(r.Context()); ok && !requestIDLogged {
+				attrs = append(attrs, slog.String(RequestIDKey, id))
+			}
+			logger.LogAttrs(r.Context(), httpStatusLevel(status), "http request", attrs...)
+		})
+	}
+}
+
+// httpStatusLevel logs client errors as warnings and server errors as errors.
+func httpStatusLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// statusRecorder records the status code and body size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	// 1xx responses such as 103 Early Hints precede the final status.
+	if r.status == 0 && status >= 200 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher for handlers that stream responses.
+func (r *statusRecorder) Flush() {
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying ResponseWriter.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,55 @@
+package klog_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/karu-codes/karu-kits/klog"
+)
+
+func TestHTTPMiddlewareRequestIDLoggedOnce(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(*klog.LoggerBuilder) *slog.Logger
+	}{
+		{"without extractor", func(b *klog.LoggerBuilder) *slog.Logger { return b.Build() }},
+		{"WithRequestID", func(b *klog.LoggerBuilder) *slog.Logger { return b.WithRequestID().Build() }},
+		{"RequestIDExtractor", func(b *klog.LoggerBuilder) *slog.Logger {
+			return b.WithExtractor(klog.RequestIDExtractor()).Build().With("component", "http")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, observed := observer.New(zapcore.DebugLevel)
+			logger := tt.build(klog.NewSlogBuilder(zap.New(core)))
+			handler := klog.RequestIDMiddleware(klog.HTTPMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})))
+
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			req.Header.Set(klog.RequestIDHeader, "req-1")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			entries := observed.FilterMessage("http request").All()
+			if len(entries) != 1 {
+				t.Fatalf("Expected one http request entry, got %v", observed.All())
+			}
+			var ids []string
+			for _, field := range entries[0].Context {
+				if field.Key == klog.RequestIDKey {
+					ids = append(ids, field.String)
+				}
+			}
+			if len(ids) != 1 || ids[0] != "req-1" {
+				t.Errorf("Expected request_id req-1 once, got %v", ids)
+			}
+		})
+	}
+}
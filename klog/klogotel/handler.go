@@ -0,0 +1,301 @@
+// Package klogotel bridges klog to the OpenTelemetry logs API, so records can
+// be exported through an OTel LoggerProvider (e.g. the SDK with an OTLP
+// exporter) alongside zap.
+//
+//	handler := klogotel.NewHandler("my-service", klogotel.WithLoggerProvider(provider))
+//	logger := klog.NewSlogBuilder(zapLogger).WithHandler(handler).Build()
+package klogotel
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+type Option func(*options)
+
+type options struct {
+	provider  otellog.LoggerProvider
+	version   string
+	schemaURL string
+	level     slog.Leveler
+	addSource bool
+}
+
+// WithLoggerProvider sets the provider records are emitted to.
+// Default: the global provider (global.GetLoggerProvider).
+func WithLoggerProvider(provider otellog.LoggerProvider) Option {
+	return func(o *options) {
+		if provider != nil {
+			o.provider = provider
+		}
+	}
+}
+
+// WithVersion sets the instrumentation scope version.
+func WithVersion(version string) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// WithSchemaURL sets the schema URL of the instrumentation scope.
+func WithSchemaURL(schemaURL string) Option {
+	return func(o *options) {
+		o.schemaURL = schemaURL
+	}
+}
+
+// WithLevel sets the minimum level exported to OpenTelemetry, independently of
+// the zap output. Default: every record the slog logger lets through.
+func WithLevel(level slog.Leveler) Option {
+	return func(o *options) {
+		o.level = level
+	}
+}
+
+// WithSource adds code.filepath, code.lineno and code.function attributes.
+func WithSource(enabled bool) Option {
+	return func(o *options) {
+		o.addSource = enabled
+	}
+}
+
+// Handler is a slog.Handler that emits records as OpenTelemetry log records.
+type Handler struct {
+	logger    otellog.Logger
+	level     slog.Leveler
+	addSource bool
+	attrs     []otellog.KeyValue
+	groups    []string
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// NewHandler creates a handler emitting to an OTel logger named name
+// (the instrumentation scope, usually the service or module name).
+func NewHandler(name string, opts ...Option) *Handler {
+	o := &options{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	if o.provider == nil {
+		o.provider = global.GetLoggerProvider()
+	}
+
+	var loggerOpts []otellog.LoggerOption
+	if o.version != "" {
+		loggerOpts = append(loggerOpts, otellog.WithInstrumentationVersion(o.version))
+	}
+	if o.schemaURL != "" {
+		loggerOpts = append(loggerOpts, otellog.WithSchemaURL(o.schemaURL))
+	}
+
+	return &Handler{
+		logger:    o.provider.Logger(name, loggerOpts...),
+		level:     o.level,
+		addSource: o.addSource,
+	}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.level != nil && level < h.level.Level() {
+		return false
+	}
+	return h.logger.Enabled(ctx, otellog.EnabledParameters{Severity: Severity(level)})
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	var r otellog.Record
+	r.SetTimestamp(record.Time)
+	r.SetObservedTimestamp(time.Now())
+	r.SetSeverity(Severity(record.Level))
+	r.SetSeverityText(record.Level.String())
+	r.SetBody(otellog.StringValue(record.Message))
+
+	attrs := make([]otellog.KeyValue, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = appendAttr(attrs, attr)
+		return true
+	})
+	// Wrap the record attrs in the open groups, innermost first.
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		if len(attrs) == 0 {
+			break
+		}
+		attrs = []otellog.KeyValue{otellog.Map(h.groups[i], attrs...)}
+	}
+
+	if len(h.attrs) > 0 {
+		r.AddAttributes(h.attrs...)
+	}
+	r.AddAttributes(attrs...)
+
+	if h.addSource && record.PC != 0 {
+		r.AddAttributes(sourceAttrs(record.PC)...)
+	}
+
+	h.logger.Emit(ctx, r)
+	return nil
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	converted := make([]otellog.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		converted = appendAttr(converted, attr)
+	}
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		if len(converted) == 0 {
+			break
+		}
+		converted = []otellog.KeyValue{otellog.Map(h.groups[i], converted...)}
+	}
+
+	clone := h.clone()
+	clone.attrs = append(clone.attrs, converted...)
+	return clone
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := h.clone()
+	clone.groups = append(clone.groups, name)
+	return clone
+}
+
+func (h *Handler) clone() *Handler {
+	c := *h
+	c.attrs = append([]otellog.KeyValue(nil), h.attrs...)
+	c.groups = append([]string(nil), h.groups...)
+	return &c
+}
+
+// Severity maps a slog level to an OpenTelemetry severity. slog's levels are
+// spaced so that Debug, Info, Warn and Error map to DEBUG, INFO, WARN and ERROR,
+// with intermediate levels landing on DEBUG2, INFO3 and so on.
+func Severity(level slog.Level) otellog.Severity {
+	sev := otellog.Severity(int(level) - int(slog.LevelInfo) + int(otellog.SeverityInfo))
+	switch {
+	case sev < otellog.SeverityTrace1:
+		return otellog.SeverityTrace1
+	case sev > otellog.SeverityFatal4:
+		return otellog.SeverityFatal4
+	default:
+		return sev
+	}
+}
+
+// appendAttr converts attr and appends it to kvs. Empty attrs are dropped and
+// groups with an empty key are inlined, as slog does.
+func appendAttr(kvs []otellog.KeyValue, attr slog.Attr) []otellog.KeyValue {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return kvs
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key == "" {
+			for _, child := range attr.Value.Group() {
+				kvs = appendAttr(kvs, child)
+			}
+			return kvs
+		}
+		var children []otellog.KeyValue
+		for _, child := range attr.Value.Group() {
+			children = appendAttr(children, child)
+		}
+		if len(children) == 0 {
+			return kvs
+		}
+		return append(kvs, otellog.Map(attr.Key, children...))
+	}
+
+	key := attr.Key
+	if key == "" {
+		key = "value"
+	}
+	return append(kvs, otellog.KeyValue{Key: key, Value: convertValue(attr.Value)})
+}
+
+func sourceAttrs(pc uintptr) []otellog.KeyValue {
+	frames := runtime.CallersFrames([]uintptr{pc})
+	frame, _ := frames.Next()
+	if frame.File == "" {
+		return nil
+	}
+	return []otellog.KeyValue{
+		otellog.String("code.filepath", frame.File),
+		otellog.Int("code.lineno", frame.Line),
+		otellog.String("code.function", frame.Function),
+	}
+}
+
+func convertValue(v slog.Value) otellog.Value {
+	switch v.Kind() {
+	case slog.KindBool:
+		return otellog.BoolValue(v.Bool())
+	case slog.KindDuration:
+		return otellog.Int64Value(v.Duration().Nanoseconds())
+	case slog.KindFloat64:
+		return otellog.Float64Value(v.Float64())
+	case slog.KindInt64:
+		return otellog.Int64Value(v.Int64())
+	case slog.KindUint64:
+		u := v.Uint64()
+		if u > uint64(^uint64(0)>>1) {
+			return otellog.StringValue(fmt.Sprint(u))
+		}
+		return otellog.Int64Value(int64(u))
+	case slog.KindString:
+		return otellog.StringValue(v.String())
+	case slog.KindTime:
+		return otellog.StringValue(v.Time().Format(time.RFC3339Nano))
+	case slog.KindAny:
+		return convertAny(v.Any())
+	default:
+		return otellog.StringValue(v.String())
+	}
+}
+
+func convertAny(value any) otellog.Value {
+	switch v := value.(type) {
+	case nil:
+		return otellog.Value{}
+	case error:
+		return otellog.StringValue(v.Error())
+	case fmt.Stringer:
+		return otellog.StringValue(v.String())
+	case []byte:
+		return otellog.BytesValue(v)
+	case []string:
+		values := make([]otellog.Value, len(v))
+		for i, s := range v {
+			values[i] = otellog.StringValue(s)
+		}
+		return otellog.SliceValue(values...)
+	case map[string]any:
+		kvs := make([]otellog.KeyValue, 0, len(v))
+		for k, val := range v {
+			kvs = append(kvs, otellog.KeyValue{Key: k, Value: convertAny(val)})
+		}
+		return otellog.MapValue(kvs...)
+	default:
+		if sv := slog.AnyValue(v); sv.Kind() != slog.KindAny {
+			return convertValue(sv)
+		}
+		return otellog.StringValue(fmt.Sprintf("%+v", v))
+	}
+}
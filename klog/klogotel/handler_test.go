@@ -0,0 +1,286 @@
+package klogotel
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/embedded"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/karu-codes/karu-kits/klog"
+)
+
+// emitted is a record together with the context it was emitted with.
+type emitted struct {
+	ctx    context.Context
+	record otellog.Record
+}
+
+// recordingProvider is an in-memory LoggerProvider that keeps every record
+// emitted through its loggers.
+type recordingProvider struct {
+	embedded.LoggerProvider
+
+	mu      sync.Mutex
+	name    string
+	config  otellog.LoggerConfig
+	records []emitted
+}
+
+func (p *recordingProvider) Logger(name string, opts ...otellog.LoggerOption) otellog.Logger {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.name = name
+	p.config = otellog.NewLoggerConfig(opts...)
+	return &recordingLogger{provider: p}
+}
+
+func (p *recordingProvider) Records() []emitted {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]emitted(nil), p.records...)
+}
+
+type recordingLogger struct {
+	embedded.Logger
+	provider *recordingProvider
+}
+
+func (l *recordingLogger) Emit(ctx context.Context, record otellog.Record) {
+	l.provider.mu.Lock()
+	defer l.provider.mu.Unlock()
+	l.provider.records = append(l.provider.records, emitted{ctx: ctx, record: record.Clone()})
+}
+
+func (l *recordingLogger) Enabled(context.Context, otellog.EnabledParameters) bool {
+	return true
+}
+
+// attributes returns the attributes of record keyed by name.
+func attributes(record otellog.Record) map[string]otellog.Value {
+	attrs := make(map[string]otellog.Value)
+	record.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	return attrs
+}
+
+// lastRecord returns the last record emitted to provider.
+func lastRecord(t *testing.T, provider *recordingProvider) emitted {
+	t.Helper()
+
+	records := provider.Records()
+	if len(records) == 0 {
+		t.Fatal("Expected a record to be emitted")
+	}
+	return records[len(records)-1]
+}
+
+func TestNewHandler(t *testing.T) {
+	provider := &recordingProvider{}
+	NewHandler("orders", WithLoggerProvider(provider), WithVersion("1.2.0"), WithSchemaURL("https://example.com/schema"))
+
+	if provider.name != "orders" {
+		t.Errorf("Expected scope name orders, got %q", provider.name)
+	}
+	if v := provider.config.InstrumentationVersion(); v != "1.2.0" {
+		t.Errorf("Expected version 1.2.0, got %q", v)
+	}
+	if u := provider.config.SchemaURL(); u != "https://example.com/schema" {
+		t.Errorf("Expected schema URL https://example.com/schema, got %q", u)
+	}
+}
+
+func TestHandleSeverity(t *testing.T) {
+	tests := []struct {
+		level    slog.Level
+		severity otellog.Severity
+		text     string
+	}{
+		{slog.LevelDebug - 8, otellog.SeverityTrace1, "DEBUG-8"},
+		{slog.LevelDebug - 4, otellog.SeverityTrace1, "DEBUG-4"},
+		{slog.LevelDebug, otellog.SeverityDebug, "DEBUG"},
+		{slog.LevelDebug + 1, otellog.SeverityDebug2, "DEBUG+1"},
+		{slog.LevelInfo, otellog.SeverityInfo, "INFO"},
+		{slog.LevelInfo + 2, otellog.SeverityInfo3, "INFO+2"},
+		{slog.LevelWarn, otellog.SeverityWarn, "WARN"},
+		{slog.LevelError, otellog.SeverityError, "ERROR"},
+		{slog.LevelError + 4, otellog.SeverityFatal, "ERROR+4"},
+		{slog.LevelError + 12, otellog.SeverityFatal4, "ERROR+12"},
+	}
+
+	provider := &recordingProvider{}
+	logger := slog.New(NewHandler("test", WithLoggerProvider(provider)))
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := Severity(tt.level); got != tt.severity {
+				t.Errorf("Severity(%v) = %v, want %v", tt.level, got, tt.severity)
+			}
+
+			logger.Log(context.Background(), tt.level, "message")
+			record := lastRecord(t, provider).record
+			if record.Severity() != tt.severity || record.SeverityText() != tt.text {
+				t.Errorf("Expected severity %v %q, got %v %q", tt.severity, tt.text, record.Severity(), record.SeverityText())
+			}
+			if record.Body().AsString() != "message" {
+				t.Errorf("Expected body message, got %v", record.Body())
+			}
+			if record.Timestamp().IsZero() || record.ObservedTimestamp().IsZero() {
+				t.Error("Expected timestamp and observed timestamp to be set")
+			}
+		})
+	}
+}
+
+func TestHandleLevel(t *testing.T) {
+	provider := &recordingProvider{}
+	handler := NewHandler("test", WithLoggerProvider(provider), WithLevel(slog.LevelWarn))
+
+	if handler.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Expected Info to be disabled below WithLevel")
+	}
+	if !handler.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("Expected Warn to be enabled at WithLevel")
+	}
+}
+
+func TestHandleAttrs(t *testing.T) {
+	provider := &recordingProvider{}
+	logger := slog.New(NewHandler("test", WithLoggerProvider(provider)))
+
+	logger.Info("typed",
+		"string", "v",
+		"int", 42,
+		"bool", true,
+		"float", 1.5,
+		"tags", []string{"a", "b"},
+		slog.Group("", slog.String("inlined", "yes")),
+		slog.Group("empty"),
+	)
+
+	attrs := attributes(lastRecord(t, provider).record)
+	if attrs["string"].AsString() != "v" || attrs["int"].AsInt64() != 42 || !attrs["bool"].AsBool() || attrs["float"].AsFloat64() != 1.5 {
+		t.Errorf("Unexpected typed attributes %v", attrs)
+	}
+	if tags := attrs["tags"].AsSlice(); len(tags) != 2 || tags[0].AsString() != "a" || tags[1].AsString() != "b" {
+		t.Errorf("Expected tags as a slice, got %v", attrs["tags"])
+	}
+	if attrs["inlined"].AsString() != "yes" {
+		t.Errorf("Expected an empty-key group to be inlined, got %v", attrs)
+	}
+	if _, ok := attrs["empty"]; ok {
+		t.Error("Expected an empty group to be dropped")
+	}
+}
+
+func TestHandleWithAttrsAndGroup(t *testing.T) {
+	provider := &recordingProvider{}
+	base := slog.New(NewHandler("test", WithLoggerProvider(provider)))
+	nested := base.With("service", "orders").WithGroup("http").With("method", "GET").WithGroup("response")
+
+	tests := []struct {
+		name   string
+		logger *slog.Logger
+		attrs  []any
+		want   []otellog.KeyValue
+	}{
+		{
+			name:   "WithAttrs",
+			logger: base.With("service", "orders"),
+			attrs:  []any{"status", 200},
+			want:   []otellog.KeyValue{otellog.String("service", "orders"), otellog.Int("status", 200)},
+		},
+		{
+			name:   "WithGroup",
+			logger: base.WithGroup("http"),
+			attrs:  []any{"status", 200},
+			want:   []otellog.KeyValue{otellog.Map("http", otellog.Int("status", 200))},
+		},
+		{
+			name:   "nested",
+			logger: nested,
+			attrs:  []any{"status", 200},
+			want: []otellog.KeyValue{
+				otellog.String("service", "orders"),
+				otellog.Map("http", otellog.String("method", "GET")),
+				otellog.Map("http", otellog.Map("response", otellog.Int("status", 200))),
+			},
+		},
+		{
+			name:   "empty groups dropped",
+			logger: nested,
+			want: []otellog.KeyValue{
+				otellog.String("service", "orders"),
+				otellog.Map("http", otellog.String("method", "GET")),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.logger.Info("request", tt.attrs...)
+
+			record := lastRecord(t, provider).record
+			var got []otellog.KeyValue
+			record.WalkAttributes(func(kv otellog.KeyValue) bool {
+				got = append(got, kv)
+				return true
+			})
+			if len(got) != len(tt.want) {
+				t.Fatalf("Attributes = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("Attributes[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHandleSource(t *testing.T) {
+	provider := &recordingProvider{}
+
+	slog.New(NewHandler("test", WithLoggerProvider(provider))).Info("no source")
+	if _, ok := attributes(lastRecord(t, provider).record)["code.filepath"]; ok {
+		t.Error("Expected no source attributes by default")
+	}
+
+	slog.New(NewHandler("test", WithLoggerProvider(provider), WithSource(true))).Info("source")
+	attrs := attributes(lastRecord(t, provider).record)
+	if file := attrs["code.filepath"].AsString(); !strings.HasSuffix(file, "klogotel/handler_test.go") {
+		t.Errorf("Expected code.filepath to be this file, got %q", file)
+	}
+	if attrs["code.lineno"].AsInt64() == 0 {
+		t.Errorf("Expected code.lineno, got %v", attrs["code.lineno"])
+	}
+	if fn := attrs["code.function"].AsString(); !strings.HasSuffix(fn, ".TestHandleSource") {
+		t.Errorf("Expected code.function to be TestHandleSource, got %q", fn)
+	}
+}
+
+func TestHandleTraceContext(t *testing.T) {
+	provider := &recordingProvider{}
+	logger := klog.NewSlogBuilder(zap.NewNop()).WithHandler(NewHandler("test", WithLoggerProvider(provider))).Build()
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01},
+		SpanID:     trace.SpanID{0x02},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+	logger.InfoContext(ctx, "traced")
+
+	got := trace.SpanContextFromContext(lastRecord(t, provider).ctx)
+	if !got.Equal(spanContext) {
+		t.Errorf("Expected the emitted context to carry span %v, got %v", spanContext, got)
+	}
+}
@@ -0,0 +1,181 @@
+// Package klogsentry forwards klog error records to Sentry.
+//
+//	logger := klog.NewSlogBuilder(zapLogger).
+//	    WithErrorHook(klogsentry.NewHook(klogsentry.WithTagKeys("request_id"))).
+//	    Build()
+//
+// The Sentry client must be initialised with sentry.Init beforehand.
+package klogsentry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+
+	"github.com/getsentry/sentry-go"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+	"github.com/karu-codes/karu-kits/klog"
+)
+
+type Option func(*options)
+
+type options struct {
+	hub     *sentry.Hub
+	tagKeys map[string]bool
+	level   slog.Level
+}
+
+// WithHub sets the hub events are sent to when the context carries none.
+// Default: sentry.CurrentHub().
+func WithHub(hub *sentry.Hub) Option {
+	return func(o *options) {
+		if hub != nil {
+			o.hub = hub
+		}
+	}
+}
+
+// WithTagKeys sends the given attrs as Sentry tags (indexed and searchable)
+// instead of extra data.
+func WithTagKeys(keys ...string) Option {
+	return func(o *options) {
+		for _, key := range keys {
+			o.tagKeys[key] = true
+		}
+	}
+}
+
+// WithMinLevel raises the minimum level reported (klog only calls error hooks
+// for records at Error and above). Default: slog.LevelError.
+func WithMinLevel(level slog.Level) Option {
+	return func(o *options) {
+		o.level = level
+	}
+}
+
+// NewHook returns a klog.ErrorHook that captures each report as a Sentry event.
+// The hub is taken from the context (sentry.GetHubFromContext) when present,
+// so request-scoped scope data set by the Sentry middlewares is kept.
+func NewHook(opts ...Option) klog.ErrorHook {
+	o := &options{
+		tagKeys: make(map[string]bool),
+		level:   slog.LevelError,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+
+	return func(ctx context.Context, report klog.ErrorReport) {
+		if report.Level < o.level {
+			return
+		}
+
+		hub := sentry.GetHubFromContext(ctx)
+		if hub == nil {
+			hub = o.hub
+		}
+		if hub == nil {
+			hub = sentry.CurrentHub()
+		}
+
+		hub.CaptureEvent(o.event(report))
+	}
+}
+
+func (o *options) event(report klog.ErrorReport) *sentry.Event {
+	event := sentry.NewEvent()
+	event.Level = Level(report.Level)
+	event.Message = report.Message
+	event.Logger = "klog"
+	if !report.Time.IsZero() {
+		event.Timestamp = report.Time
+	}
+
+	for _, attr := range report.Attrs {
+		if o.tagKeys[attr.Key] {
+			event.Tags[attr.Key] = attr.Value.String()
+			continue
+		}
+		event.Extra[attr.Key] = extraValue(attr.Value)
+	}
+
+	if report.Err != nil {
+		exception := sentry.Exception{
+			Type:  errorType(report.Err),
+			Value: report.Err.Error(),
+		}
+		if len(report.StackTrace) > 0 {
+			exception.Stacktrace = stacktrace(report.StackTrace)
+		} else if report.PC != 0 {
+			exception.Stacktrace = stacktraceFromPC(report.PC)
+		}
+		event.Exception = []sentry.Exception{exception}
+	}
+
+	return event
+}
+
+// Level maps a slog level to a Sentry level.
+func Level(level slog.Level) sentry.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return sentry.LevelDebug
+	case level < slog.LevelWarn:
+		return sentry.LevelInfo
+	case level < slog.LevelError:
+		return sentry.LevelWarning
+	case level < slog.LevelError+4:
+		return sentry.LevelError
+	default:
+		return sentry.LevelFatal
+	}
+}
+
+func errorType(err error) string {
+	return reflect.TypeOf(err).String()
+}
+
+func extraValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindAny:
+		switch val := v.Any().(type) {
+		case error:
+			return val.Error()
+		case fmt.Stringer:
+			return val.String()
+		default:
+			return val
+		}
+	case slog.KindDuration:
+		return v.Duration().String()
+	default:
+		return v.Any()
+	}
+}
+
+// stacktrace converts an errors.Error stack (innermost call first) to a Sentry
+// stack trace, which lists the outermost call first.
+func stacktrace(frames []kerrors.StackFrame) *sentry.Stacktrace {
+	st := &sentry.Stacktrace{Frames: make([]sentry.Frame, 0, len(frames))}
+	for i := len(frames) - 1; i >= 0; i-- {
+		st.Frames = append(st.Frames, sentry.NewFrame(runtime.Frame{
+			Function: frames[i].Function,
+			File:     frames[i].File,
+			Line:     frames[i].Line,
+		}))
+	}
+	return st
+}
+
+func stacktraceFromPC(pc uintptr) *sentry.Stacktrace {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return nil
+	}
+	return &sentry.Stacktrace{Frames: []sentry.Frame{sentry.NewFrame(frame)}}
+}
@@ -0,0 +1,134 @@
+package klogsentry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/zap"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+	"github.com/karu-codes/karu-kits/klog"
+)
+
+func newTestHub(t *testing.T) (*sentry.Hub, *sentry.MockTransport) {
+	t.Helper()
+
+	transport := &sentry.MockTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://key@sentry.example.com/1", Transport: transport})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return sentry.NewHub(client, sentry.NewScope()), transport
+}
+
+func TestHook(t *testing.T) {
+	hub, transport := newTestHub(t)
+	logger := klog.NewSlogBuilder(zap.NewNop()).
+		WithErrorHook(NewHook(WithHub(hub), WithTagKeys("request_id"))).
+		Build()
+
+	cause := kerrors.New(kerrors.CodeDatabase, "connection refused")
+	logger.Error("query failed",
+		"request_id", "req-1",
+		"table", "users",
+		"elapsed", 1500*time.Millisecond,
+		"error", cause,
+	)
+
+	events := transport.Events()
+	if len(events) != 1 {
+		t.Fatalf("Expected one event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Level != sentry.LevelError || event.Message != "query failed" || event.Logger != "klog" {
+		t.Errorf("Unexpected event level %q, message %q, logger %q", event.Level, event.Message, event.Logger)
+	}
+	if event.Tags["request_id"] != "req-1" {
+		t.Errorf("Expected request_id as a tag, got tags %v", event.Tags)
+	}
+	if _, ok := event.Extra["request_id"]; ok {
+		t.Error("Expected tags not to be repeated as extra data")
+	}
+	wantExtra := map[string]any{"table": "users", "elapsed": "1.5s", "error": cause.Error()}
+	for key, want := range wantExtra {
+		if event.Extra[key] != want {
+			t.Errorf("Extra[%s] = %v, want %v", key, event.Extra[key], want)
+		}
+	}
+
+	if len(event.Exception) != 1 {
+		t.Fatalf("Expected one exception, got %v", event.Exception)
+	}
+	exception := event.Exception[0]
+	if exception.Type != "*errors.Error" || exception.Value != cause.Error() {
+		t.Errorf("Unexpected exception %q: %q", exception.Type, exception.Value)
+	}
+	if exception.Stacktrace == nil || len(exception.Stacktrace.Frames) != len(cause.StackTrace) {
+		t.Fatalf("Expected the stack of the *errors.Error, got %+v", exception.Stacktrace)
+	}
+	// Sentry lists the outermost call first.
+	frames := exception.Stacktrace.Frames
+	if last := frames[len(frames)-1]; last.Lineno != cause.StackTrace[0].Line {
+		t.Errorf("Expected the innermost frame last, got line %d, want %d", last.Lineno, cause.StackTrace[0].Line)
+	}
+}
+
+func TestHookPlainErrorUsesCallSite(t *testing.T) {
+	hub, transport := newTestHub(t)
+	logger := klog.NewSlogBuilder(zap.NewNop()).WithErrorHook(NewHook(WithHub(hub))).Build()
+
+	logger.Error("failed", "error", errors.New("boom"))
+
+	events := transport.Events()
+	if len(events) != 1 || len(events[0].Exception) != 1 {
+		t.Fatalf("Expected one event with an exception, got %v", events)
+	}
+	exception := events[0].Exception[0]
+	if exception.Type != "*errors.errorString" || exception.Stacktrace == nil || len(exception.Stacktrace.Frames) != 1 {
+		t.Fatalf("Expected a one-frame stack at the call site, got %+v", exception)
+	}
+	if fn := exception.Stacktrace.Frames[0].Function; fn != "TestHookPlainErrorUsesCallSite" {
+		t.Errorf("Expected the logging call as frame, got %s", fn)
+	}
+}
+
+func TestHookHubAndLevel(t *testing.T) {
+	optionHub, optionTransport := newTestHub(t)
+	ctxHub, ctxTransport := newTestHub(t)
+	hook := NewHook(WithHub(optionHub), WithMinLevel(slog.LevelError+4))
+
+	hook(context.Background(), klog.ErrorReport{Level: slog.LevelError, Message: "below"})
+	hook(context.Background(), klog.ErrorReport{Level: slog.LevelError + 4, Message: "fatal"})
+	hook(sentry.SetHubOnContext(context.Background(), ctxHub), klog.ErrorReport{Level: slog.LevelError + 8, Message: "from ctx"})
+
+	if events := optionTransport.Events(); len(events) != 1 || events[0].Message != "fatal" || events[0].Level != sentry.LevelFatal {
+		t.Errorf("Expected one fatal event on the option hub, got %v", events)
+	}
+	if events := ctxTransport.Events(); len(events) != 1 || events[0].Message != "from ctx" {
+		t.Errorf("Expected the context hub to win, got %v", events)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  sentry.Level
+	}{
+		{slog.LevelDebug, sentry.LevelDebug},
+		{slog.LevelInfo, sentry.LevelInfo},
+		{slog.LevelWarn, sentry.LevelWarning},
+		{slog.LevelError, sentry.LevelError},
+		{slog.LevelError + 2, sentry.LevelError},
+		{slog.LevelError + 4, sentry.LevelFatal},
+	}
+
+	for _, tt := range tests {
+		if got := Level(tt.level); got != tt.want {
+			t.Errorf("Level(%v) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
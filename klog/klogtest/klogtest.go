@@ -0,0 +1,250 @@
+// Package klogtest provides a logger for tests of code logging through klog or
+// slog: every record is captured in memory and can be inspected or asserted on.
+//
+// Example:
+//
+//	func TestCheckout(t *testing.T) {
+//	    logger, rec := klogtest.NewLogger(t, klog.RequestIDExtractor())
+//
+//	    svc := NewCheckoutService(logger)
+//	    svc.Pay(ctx, order)
+//
+//	    rec.AssertLogged(slog.LevelInfo, "payment accepted", "order_id", order.ID)
+//	}
+package klogtest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/karu-codes/karu-kits/klog"
+)
+
+// Record is a record captured by a test logger.
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+
+	// Attrs are the logger and record attrs, flattened with group-qualified
+	// keys ("group.key").
+	Attrs []slog.Attr
+}
+
+// Attr returns the value of the attr with the given (group-qualified) key.
+func (r Record) Attr(key string) (slog.Value, bool) {
+	for _, attr := range r.Attrs {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return slog.Value{}, false
+}
+
+// Recorder holds the records captured by a logger from NewLogger.
+type Recorder struct {
+	t       testing.TB
+	mu      sync.Mutex
+	records []Record
+}
+
+// NewLogger returns a logger capturing every record (from Debug up) in memory,
+// and the recorder to inspect them. Context extractors can be added with the
+// same meaning as klog.LoggerBuilder.WithExtractor.
+func NewLogger(t testing.TB, extractors ...klog.ContextExtractor) (*slog.Logger, *Recorder) {
+	t.Helper()
+
+	rec := &Recorder{t: t}
+	return slog.New(&recordingHandler{recorder: rec, extractors: extractors}), rec
+}
+
+// Records returns a copy of the captured records, in logging order.
+func (r *Recorder) Records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Record(nil), r.records...)
+}
+
+// Reset discards the captured records.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = nil
+}
+
+// Find returns the records at level whose message contains msgContains and
+// that carry every attr in attrs, given as slog-style key/value pairs or
+// slog.Attr values.
+func (r *Recorder) Find(level slog.Level, msgContains string, attrs ...any) []Record {
+	want := argsToAttrs(attrs)
+
+	var found []Record
+	for _, record := range r.Records() {
+		if record.Level == level && strings.Contains(record.Message, msgContains) && hasAttrs(record, want) {
+			found = append(found, record)
+		}
+	}
+	return found
+}
+
+// AssertLogged fails the test unless a record matching Find was captured.
+func (r *Recorder) AssertLogged(level slog.Level, msgContains string, attrs ...any) {
+	r.t.Helper()
+
+	if len(r.Find(level, msgContains, attrs...)) == 0 {
+		r.t.Errorf("klogtest: no %s record containing %q with attrs %v; captured:\n%s", level, msgContains, argsToAttrs(attrs), r.dump())
+	}
+}
+
+// AssertNotLogged fails the test if a record matching Find was captured.
+func (r *Recorder) AssertNotLogged(level slog.Level, msgContains string, attrs ...any) {
+	r.t.Helper()
+
+	if found := r.Find(level, msgContains, attrs...); len(found) > 0 {
+		r.t.Errorf("klogtest: unexpected %s record containing %q: %s %v", level, msgContains, found[0].Message, found[0].Attrs)
+	}
+}
+
+func (r *Recorder) dump() string {
+	var b strings.Builder
+	for _, record := range r.Records() {
+		fmt.Fprintf(&b, "\t%s %q %v\n", record.Level, record.Message, record.Attrs)
+	}
+	if b.Len() == 0 {
+		return "\t(none)"
+	}
+	return b.String()
+}
+
+func (r *Recorder) add(record Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record)
+}
+
+func hasAttrs(record Record, want []slog.Attr) bool {
+	for _, attr := range want {
+		got, ok := record.Attr(attr.Key)
+		if !ok || !valuesEqual(got, attr.Value.Resolve()) {
+			return false
+		}
+	}
+	return true
+}
+
+// valuesEqual is slog.Value.Equal, except that KindAny values are compared with
+// reflect.DeepEqual so slices and maps do not panic.
+func valuesEqual(a, b slog.Value) bool {
+	if a.Kind() == slog.KindAny && b.Kind() == slog.KindAny {
+		return reflect.DeepEqual(a.Any(), b.Any())
+	}
+	return a.Equal(b)
+}
+
+func argsToAttrs(args []any) []slog.Attr {
+	var attrs []slog.Attr
+	for len(args) > 0 {
+		switch v := args[0].(type) {
+		case slog.Attr:
+			attrs = append(attrs, v)
+			args = args[1:]
+		case string:
+			if len(args) == 1 {
+				attrs = append(attrs, slog.String("!BADKEY", v))
+				args = args[1:]
+				continue
+			}
+			attrs = append(attrs, slog.Any(v, args[1]))
+			args = args[2:]
+		default:
+			attrs = append(attrs, slog.Any("!BADKEY", v))
+			args = args[1:]
+		}
+	}
+	return attrs
+}
+
+// flattenAttr appends attr to dst with a group-qualified key, expanding groups
+// like the klog handler does.
+func flattenAttr(dst []slog.Attr, groups []string, attr slog.Attr) []slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return dst
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		childGroups := groups
+		if attr.Key != "" {
+			childGroups = append(append([]string(nil), groups...), attr.Key)
+		}
+		for _, child := range attr.Value.Group() {
+			dst = flattenAttr(dst, childGroups, child)
+		}
+		return dst
+	}
+
+	key := attr.Key
+	if key == "" {
+		key = "value"
+	}
+	if len(groups) > 0 {
+		key = strings.Join(groups, ".") + "." + key
+	}
+	return append(dst, slog.Attr{Key: key, Value: attr.Value})
+}
+
+type recordingHandler struct {
+	recorder   *Recorder
+	extractors []klog.ContextExtractor
+	attrs      []slog.Attr
+	groups     []string
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, record slog.Record) error {
+	attrs := append([]slog.Attr(nil), h.attrs...)
+	for _, extractor := range h.extractors {
+		for _, attr := range extractor(ctx) {
+			attrs = flattenAttr(attrs, h.groups, attr)
+		}
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = flattenAttr(attrs, h.groups, attr)
+		return true
+	})
+
+	h.recorder.add(Record{
+		Time:    record.Time,
+		Level:   record.Level,
+		Message: record.Message,
+		Attrs:   attrs,
+	})
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, attr := range attrs {
+		clone.attrs = flattenAttr(clone.attrs, h.groups, attr)
+	}
+	return &clone
+}
+
+func (h *recordingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(append([]string(nil), h.groups...), name)
+	return &clone
+}
@@ -0,0 +1,92 @@
+package klogtest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/karu-codes/karu-kits/klog"
+)
+
+// fakeTB records the failures reported through Errorf.
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRecorderFind(t *testing.T) {
+	logger, rec := NewLogger(t, klog.RequestIDExtractor())
+	ctx := klog.ContextWithRequestID(context.Background(), "req-1")
+
+	logger.Debug("cache warmed")
+	logger.With("component", "db").WithGroup("query").InfoContext(ctx, "query done",
+		"table", "users",
+		slog.Group("stats", slog.Int("rows", 3)),
+		"tags", []string{"a", "b"},
+	)
+	logger.Error("query failed", "table", "orders")
+
+	tests := []struct {
+		name  string
+		level slog.Level
+		msg   string
+		attrs []any
+		want  int
+	}{
+		{"debug is captured", slog.LevelDebug, "cache", nil, 1},
+		{"message substring", slog.LevelInfo, "done", nil, 1},
+		{"level must match", slog.LevelWarn, "query", nil, 0},
+		{"logger attrs", slog.LevelInfo, "", []any{"component", "db"}, 1},
+		{"group-qualified keys", slog.LevelInfo, "", []any{"query.table", "users", "query.stats.rows", 3}, 1},
+		{"extracted attrs are grouped", slog.LevelInfo, "", []any{"query.request_id", "req-1"}, 1},
+		{"slog.Attr values", slog.LevelError, "", []any{slog.String("table", "orders")}, 1},
+		{"slices compare deeply", slog.LevelInfo, "", []any{"query.tags", []string{"a", "b"}}, 1},
+		{"attr value must match", slog.LevelError, "", []any{"table", "users"}, 0},
+		{"missing attr", slog.LevelError, "", []any{"component", "db"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rec.Find(tt.level, tt.msg, tt.attrs...); len(got) != tt.want {
+				t.Errorf("Find(%v, %q, %v) = %d records, want %d (captured %v)", tt.level, tt.msg, tt.attrs, len(got), tt.want, rec.Records())
+			}
+		})
+	}
+
+	if records := rec.Records(); len(records) != 3 || records[0].Message != "cache warmed" || records[2].Message != "query failed" {
+		t.Errorf("Expected the records in logging order, got %v", records)
+	}
+	rec.Reset()
+	if records := rec.Records(); len(records) != 0 {
+		t.Errorf("Expected Reset to discard the records, got %v", records)
+	}
+}
+
+func TestRecorderAssertions(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	logger, rec := NewLogger(tb)
+	logger.Warn("retrying", "attempt", 2)
+
+	rec.AssertLogged(slog.LevelWarn, "retry", "attempt", 2)
+	rec.AssertNotLogged(slog.LevelError, "retry")
+	if len(tb.errors) != 0 {
+		t.Fatalf("Expected matching assertions to pass, got %q", tb.errors)
+	}
+
+	rec.AssertLogged(slog.LevelWarn, "retry", "attempt", 3)
+	rec.AssertNotLogged(slog.LevelWarn, "retry")
+	if len(tb.errors) != 2 {
+		t.Fatalf("Expected 2 failures, got %q", tb.errors)
+	}
+	if !strings.Contains(tb.errors[0], `WARN "retrying" [attempt=2]`) {
+		t.Errorf("Expected the failure to list the captured records, got %s", tb.errors[0])
+	}
+}
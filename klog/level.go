@@ -0,0 +1,88 @@
+package klog
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelEnv is the environment variable read by LevelVarFromEnv.
+const LevelEnv = "LOG_LEVEL"
+
+// ParseLevel parses a level name as used by LOG_LEVEL and Config.Level: debug,
+// info, warn (or warning) and error, in any case, plus zap's dpanic, panic and
+// fatal, which map above slog.LevelError.
+func ParseLevel(name string) (slog.Level, error) {
+	text := strings.ToLower(strings.TrimSpace(name))
+	if text == "warning" {
+		return slog.LevelWarn, nil
+	}
+	level, err := zapcore.ParseLevel(text)
+	if err != nil || text == "" {
+		return slog.LevelInfo, fmt.Errorf("klog: invalid level %q", name)
+	}
+	return fromZapLevel(level), nil
+}
+
+// LevelVarFromEnv returns a LevelVar set from LOG_LEVEL, or to fallback when the
+// variable is unset or empty. Pass it to both WithLevelVar and the handler
+// options so LevelVar.Set changes the level of the zap and slog loggers:
+//
+//	level, err := klog.LevelVarFromEnv(slog.LevelInfo)
+//	z, err := klog.NewProvider(klog.WithLevelVar(level))
+//	s := klog.NewSlogBuilder(z).WithHandlerOptions(&slog.HandlerOptions{Level: level}).Build()
+func LevelVarFromEnv(fallback slog.Level) (*slog.LevelVar, error) {
+	v := new(slog.LevelVar)
+	v.Set(fallback)
+
+	name := os.Getenv(LevelEnv)
+	if name == "" {
+		return v, nil
+	}
+	level, err := ParseLevel(name)
+	if err != nil {
+		return nil, fmt.Errorf("klog: invalid %s %q", LevelEnv, name)
+	}
+	v.Set(level)
+	return v, nil
+}
+
+// WithLevelVar makes v the level of the zap logger instead of a fixed level:
+// the logger's atomic level is opened to debug and every entry is checked
+// against v, so changes to v apply immediately. It overrides WithLevel.
+func WithLevelVar(v *slog.LevelVar) ProviderOption {
+	return func(o *providerOptions) {
+		o.levelVar = v
+	}
+}
+
+// levelVarCore gates a core on a slog.Leveler.
+type levelVarCore struct {
+	zapcore.Core
+	level slog.Leveler
+}
+
+func (c *levelVarCore) Enabled(level zapcore.Level) bool {
+	return fromZapLevel(level) >= c.level.Level() && c.Core.Enabled(level)
+}
+
+func (c *levelVarCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelVarCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *levelVarCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if fromZapLevel(ent.Level) < c.level.Level() {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func wrapLevelVar(v slog.Leveler) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelVarCore{Core: core, level: v}
+	})
+}
@@ -0,0 +1,111 @@
+package klog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"info", slog.LevelInfo, false},
+		{"warn", slog.LevelWarn, false},
+		{"warning", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"ERROR", slog.LevelError, false},
+		{" Warning ", slog.LevelWarn, false},
+		{"dpanic", slog.LevelError + 4, false},
+		{"panic", slog.LevelError + 4, false},
+		{"fatal", slog.LevelError + 4, false},
+		{"", slog.LevelInfo, true},
+		{"verbose", slog.LevelInfo, true},
+		{"4", slog.LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLevel(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLevel(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelVarFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"unset uses the fallback", "", slog.LevelWarn, false},
+		{"set", "debug", slog.LevelDebug, false},
+		{"invalid", "loud", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(LevelEnv, tt.env)
+
+			v, err := LevelVarFromEnv(slog.LevelWarn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LevelVarFromEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && v.Level() != tt.want {
+				t.Errorf("LevelVarFromEnv() = %v, want %v", v.Level(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelVarCore(t *testing.T) {
+	var buf bytes.Buffer
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelWarn)
+
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zapcore.DebugLevel)
+	logger := zap.New(core, wrapLevelVar(level))
+	derived := logger.With(zap.String("component", "db"))
+
+	logger.Info("before")
+	logger.Warn("warn before")
+	if got := buf.String(); strings.Contains(got, `"before"`) || !strings.Contains(got, "warn before") {
+		t.Errorf("Expected only warn records at warn level, got %s", got)
+	}
+	if logger.Core().Enabled(zapcore.InfoLevel) {
+		t.Error("Expected info to be disabled at warn level")
+	}
+
+	// Lowering the level applies immediately, to derived loggers too.
+	level.Set(slog.LevelDebug)
+	buf.Reset()
+	logger.Debug("after")
+	derived.Info("derived after")
+	if got := buf.String(); !strings.Contains(got, `"after"`) || !strings.Contains(got, "derived after") {
+		t.Errorf("Expected debug and info records after lowering the level, got %s", got)
+	}
+
+	// Raising it drops records again.
+	level.Set(slog.LevelError)
+	buf.Reset()
+	derived.Warn("dropped")
+	if buf.Len() != 0 {
+		t.Errorf("Expected warn records to be dropped at error level, got %s", buf.String())
+	}
+	if !derived.Core().Enabled(zapcore.ErrorLevel) {
+		t.Error("Expected error to be enabled at error level")
+	}
+}
@@ -0,0 +1,74 @@
+package klog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Default rate limit of OnError callbacks: at most DefaultOnErrorLimit calls
+// per DefaultOnErrorInterval for each callback.
+const (
+	DefaultOnErrorLimit    = 10
+	DefaultOnErrorInterval = time.Second
+)
+
+// ErrorCallback is called for records at Error level and above (see
+// LoggerBuilder.OnError). The record carries the message, level, PC and the
+// attrs of the logging call; logger attrs are not included.
+type ErrorCallback func(ctx context.Context, record slog.Record)
+
+type errorCallback struct {
+	fn      ErrorCallback
+	limiter *windowLimiter
+}
+
+func (c *errorCallback) call(ctx context.Context, record slog.Record) {
+	if c.limiter.allow(time.Now()) {
+		c.fn(ctx, record)
+	}
+}
+
+func (h *zapSlogHandler) notifyError(ctx context.Context, record slog.Record) {
+	for _, cb := range h.errorCallbacks {
+		// Callbacks may retain the record, so each gets its own copy.
+		cb.call(ctx, record.Clone())
+	}
+}
+
+// windowLimiter allows up to limit events per fixed window. A nil limiter
+// allows every event.
+type windowLimiter struct {
+	limit int
+	per   time.Duration
+
+	mu    sync.Mutex
+	start time.Time
+	count int
+}
+
+func newWindowLimiter(limit int, per time.Duration) *windowLimiter {
+	if limit <= 0 || per <= 0 {
+		return nil
+	}
+	return &windowLimiter{limit: limit, per: per}
+}
+
+func (l *windowLimiter) allow(now time.Time) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.start) >= l.per {
+		l.start = now
+		l.count = 0
+	}
+	if l.count >= l.limit {
+		return false
+	}
+	l.count++
+	return true
+}
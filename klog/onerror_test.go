@@ -0,0 +1,96 @@
+package klog
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestWindowLimiter(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	tests := []struct {
+		name   string
+		limit  int
+		per    time.Duration
+		events []time.Time
+		want   []bool
+	}{
+		{
+			name:   "allows up to the limit",
+			limit:  2,
+			per:    time.Second,
+			events: []time.Time{at(0), at(10 * time.Millisecond), at(20 * time.Millisecond)},
+			want:   []bool{true, true, false},
+		},
+		{
+			name:   "window rolls over",
+			limit:  1,
+			per:    time.Second,
+			events: []time.Time{at(0), at(999 * time.Millisecond), at(time.Second), at(1500 * time.Millisecond), at(2 * time.Second)},
+			want:   []bool{true, false, true, false, true},
+		},
+		{
+			name:   "new window starts at the first event after it",
+			limit:  1,
+			per:    time.Second,
+			events: []time.Time{at(0), at(1500 * time.Millisecond), at(2 * time.Second), at(2500 * time.Millisecond)},
+			want:   []bool{true, true, false, true},
+		},
+		{
+			name:   "rollover resets the count",
+			limit:  2,
+			per:    time.Second,
+			events: []time.Time{at(0), at(time.Millisecond), at(2 * time.Millisecond), at(time.Second), at(time.Second), at(time.Second)},
+			want:   []bool{true, true, false, true, true, false},
+		},
+		{
+			name:   "zero limit disables limiting",
+			limit:  0,
+			per:    time.Second,
+			events: []time.Time{at(0), at(0), at(0)},
+			want:   []bool{true, true, true},
+		},
+		{
+			name:   "zero interval disables limiting",
+			limit:  1,
+			per:    0,
+			events: []time.Time{at(0), at(0), at(0)},
+			want:   []bool{true, true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newWindowLimiter(tt.limit, tt.per)
+			for i, now := range tt.events {
+				if got := l.allow(now); got != tt.want[i] {
+					t.Errorf("allow(+%v) = %v, want %v", now.Sub(start), got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOnErrorLimit(t *testing.T) {
+	var first, second int
+	logger := NewSlogBuilder(zap.NewNop()).
+		OnError(func(context.Context, slog.Record) { first++ }).
+		OnError(func(context.Context, slog.Record) { second++ }).
+		OnErrorLimit(2, time.Hour).
+		Build()
+
+	logger.Warn("slow query")
+	for i := 0; i < 5; i++ {
+		logger.Error("query failed")
+	}
+
+	// Each callback has its own limiter.
+	if first != 2 || second != 2 {
+		t.Errorf("Expected each callback to be called 2 times, got %d and %d", first, second)
+	}
+}
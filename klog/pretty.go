@@ -0,0 +1,242 @@
+package klog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// EncodingPretty is a human-readable development encoding: colored levels,
+// aligned keys, one attribute per line and multiline errors and stack traces.
+// Colors are disabled when the NO_COLOR environment variable is set.
+const EncodingPretty = "pretty"
+
+func init() {
+	// Ignore the error: it only fails if another package registered the name.
+	_ = zap.RegisterEncoder(EncodingPretty, func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+		return newPrettyEncoder(cfg), nil
+	})
+}
+
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+)
+
+var prettyBufferPool = buffer.NewPool()
+
+// prettyEncoder keeps context fields (logger.With) in a map, so they are
+// printed sorted by key before the entry fields, which keep their order.
+type prettyEncoder struct {
+	*zapcore.MapObjectEncoder
+	lineEnding string
+	color      bool
+}
+
+func newPrettyEncoder(cfg zapcore.EncoderConfig) *prettyEncoder {
+	lineEnding := cfg.LineEnding
+	if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	_, noColor := os.LookupEnv("NO_COLOR")
+	return &prettyEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		lineEnding:       lineEnding,
+		color:            !noColor,
+	}
+}
+
+func (e *prettyEncoder) Clone() zapcore.Encoder {
+	clone := &prettyEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		lineEnding:       e.lineEnding,
+		color:            e.color,
+	}
+	for k, v := range e.Fields {
+		clone.Fields[k] = v
+	}
+	return clone
+}
+
+type prettyField struct {
+	key   string
+	value any
+}
+
+func (e *prettyEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf := prettyBufferPool.Get()
+
+	buf.AppendString(e.paint(ansiDim, ent.Time.Format("15:04:05.000")))
+	buf.AppendByte(' ')
+	buf.AppendString(e.paint(levelColor(ent.Level), fmt.Sprintf("%-5s", ent.Level.CapitalString())))
+	buf.AppendByte(' ')
+	if ent.LoggerName != "" {
+		buf.AppendString(e.paint(ansiDim, ent.LoggerName+": "))
+	}
+	buf.AppendString(e.paint(ansiBold, ent.Message))
+	if ent.Caller.Defined {
+		buf.AppendString(e.paint(ansiDim, "  ("+ent.Caller.TrimmedPath()+")"))
+	}
+	buf.AppendString(e.lineEnding)
+
+	all := e.contextFields()
+	for _, field := range fields {
+		tmp := zapcore.NewMapObjectEncoder()
+		field.AddTo(tmp)
+		all = append(all, sortedFields(tmp.Fields)...)
+	}
+
+	width := 0
+	for _, f := range all {
+		if len(f.key) > width && !isBlock(f.value) {
+			width = len(f.key)
+		}
+	}
+
+	var blocks []prettyField
+	for _, f := range all {
+		if isBlock(f.value) {
+			blocks = append(blocks, f)
+			continue
+		}
+		buf.AppendString("    ")
+		buf.AppendString(e.paint(ansiCyan, fmt.Sprintf("%-*s", width, f.key)))
+		buf.AppendString(" = ")
+		buf.AppendString(formatPrettyValue(f.value))
+		buf.AppendString(e.lineEnding)
+	}
+	for _, f := range blocks {
+		buf.AppendString("    ")
+		buf.AppendString(e.paint(ansiCyan, f.key+":"))
+		buf.AppendString(e.lineEnding)
+		e.appendBlock(buf, f.value)
+	}
+
+	if ent.Stack != "" {
+		buf.AppendString("    ")
+		buf.AppendString(e.paint(ansiCyan, "stacktrace:"))
+		buf.AppendString(e.lineEnding)
+		e.appendIndented(buf, ent.Stack, ansiDim)
+	}
+
+	return buf, nil
+}
+
+func (e *prettyEncoder) contextFields() []prettyField {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return sortedFields(e.Fields)
+}
+
+func sortedFields(m map[string]any) []prettyField {
+	fields := make([]prettyField, 0, len(m))
+	for k, v := range m {
+		fields = append(fields, prettyField{key: k, value: v})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].key < fields[j].key })
+	return fields
+}
+
+// isBlock reports whether a value is printed on its own indented lines:
+// multiline strings and structured errors (see errorField).
+func isBlock(v any) bool {
+	switch val := v.(type) {
+	case string:
+		return strings.Contains(val, "\n")
+	case map[string]any:
+		_, isErr := val["error"].(string)
+		_, hasCode := val["code"].(string)
+		return isErr && hasCode
+	default:
+		return false
+	}
+}
+
+func (e *prettyEncoder) appendBlock(buf *buffer.Buffer, v any) {
+	switch val := v.(type) {
+	case string:
+		e.appendIndented(buf, val, "")
+	case map[string]any:
+		e.appendIndented(buf, val["error"].(string), ansiRed)
+		if details, ok := val["details"]; ok {
+			e.appendIndented(buf, "details: "+formatPrettyValue(details), "")
+		}
+		frames, _ := val["stack"].([]any)
+		for _, frame := range frames {
+			f, ok := frame.(map[string]any)
+			if !ok {
+				continue
+			}
+			e.appendIndented(buf, fmt.Sprintf("at %v", f["function"]), ansiDim)
+			e.appendIndented(buf, fmt.Sprintf("     %v:%v", f["file"], f["line"]), ansiDim)
+		}
+	}
+}
+
+func (e *prettyEncoder) appendIndented(buf *buffer.Buffer, text, color string) {
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		buf.AppendString("        ")
+		buf.AppendString(e.paint(color, line))
+		buf.AppendString(e.lineEnding)
+	}
+}
+
+func (e *prettyEncoder) paint(color, s string) string {
+	if !e.color || color == "" {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+func levelColor(level zapcore.Level) string {
+	switch level {
+	case zapcore.DebugLevel:
+		return ansiMagenta
+	case zapcore.InfoLevel:
+		return ansiBlue
+	case zapcore.WarnLevel:
+		return ansiYellow
+	default:
+		return ansiRed
+	}
+}
+
+func formatPrettyValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case time.Duration:
+		return val.String()
+	case []byte:
+		return string(val)
+	case error:
+		return val.Error()
+	case fmt.Stringer:
+		return val.String()
+	case map[string]any, []any:
+		if b, err := json.Marshal(val); err == nil {
+			return string(b)
+		}
+		return fmt.Sprint(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,148 @@
+package klog
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	kerrors "github.com/karu-codes/karu-kits/errors"
+)
+
+var prettyTime = time.Date(2024, 5, 1, 12, 30, 45, 123456789, time.UTC)
+
+// encodePretty encodes ent with a pretty encoder carrying the context fields,
+// as added by logger.With.
+func encodePretty(t *testing.T, context []zap.Field, ent zapcore.Entry, fields ...zap.Field) string {
+	t.Helper()
+
+	enc := newPrettyEncoder(zapcore.EncoderConfig{}).Clone()
+	for _, f := range context {
+		f.AddTo(enc)
+	}
+	buf, err := enc.EncodeEntry(ent, fields)
+	if err != nil {
+		t.Fatalf("EncodeEntry() error = %v", err)
+	}
+	defer buf.Free()
+	return buf.String()
+}
+
+// setColor enables colors by unsetting NO_COLOR, or disables them; the
+// environment is restored after the test.
+func setColor(t *testing.T, enabled bool) {
+	t.Setenv("NO_COLOR", "1")
+	if enabled {
+		os.Unsetenv("NO_COLOR")
+	}
+}
+
+func TestPrettyEncoder(t *testing.T) {
+	kerr := &kerrors.Error{
+		Code:    kerrors.CodeDatabase,
+		Message: "query users",
+		Details: map[string]any{"table": "users"},
+		StackTrace: []kerrors.StackFrame{
+			{Function: "app.(*Repo).List", File: "/src/app/repo.go", Line: 42},
+			{Function: "main.main", File: "/src/app/main.go", Line: 7},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		color   bool
+		context []zap.Field
+		entry   zapcore.Entry
+		fields  []zap.Field
+		want    string
+	}{
+		{
+			name:  "message only",
+			entry: zapcore.Entry{Level: zapcore.InfoLevel, Time: prettyTime, Message: "started"},
+			want:  "12:30:45.123 INFO  started\n",
+		},
+		{
+			name:    "context fields sorted before entry fields in order",
+			context: []zap.Field{zap.String("service", "orders"), zap.String("env", "prod")},
+			entry:   zapcore.Entry{Level: zapcore.WarnLevel, Time: prettyTime, LoggerName: "db", Message: "slow query"},
+			fields:  []zap.Field{zap.Int("rows", 3), zap.Duration("elapsed", 1500*time.Millisecond), zap.Strings("tags", []string{"a", "b"})},
+			want: "12:30:45.123 WARN  db: slow query\n" +
+				"    env     = prod\n" +
+				"    service = orders\n" +
+				"    rows    = 3\n" +
+				"    elapsed = 1.5s\n" +
+				"    tags    = [\"a\",\"b\"]\n",
+		},
+		{
+			name: "multiline, error and stack blocks",
+			entry: zapcore.Entry{
+				Level:   zapcore.ErrorLevel,
+				Time:    prettyTime,
+				Message: "query failed",
+				Caller:  zapcore.EntryCaller{Defined: true, File: "/src/app/main.go", Line: 42},
+				Stack:   "main.main()\n\t/src/app/main.go:42\n",
+			},
+			fields: []zap.Field{zap.String("sql", "SELECT *\nFROM users"), errorField("error", kerr), zap.Int("attempt", 2)},
+			want: "12:30:45.123 ERROR query failed  (app/main.go:42)\n" +
+				"    attempt = 2\n" +
+				"    sql:\n" +
+				"        SELECT *\n" +
+				"        FROM users\n" +
+				"    error:\n" +
+				"        [DATABASE_ERROR] query users\n" +
+				"        details: {\"table\":\"users\"}\n" +
+				"        at app.(*Repo).List\n" +
+				"             /src/app/repo.go:42\n" +
+				"        at main.main\n" +
+				"             /src/app/main.go:7\n" +
+				"    stacktrace:\n" +
+				"        main.main()\n" +
+				"        \t/src/app/main.go:42\n",
+		},
+		{
+			name:   "colors",
+			color:  true,
+			entry:  zapcore.Entry{Level: zapcore.DebugLevel, Time: prettyTime, LoggerName: "db", Message: "connected"},
+			fields: []zap.Field{zap.String("host", "localhost"), errorField("error", kerrors.NewError(kerrors.CodeNotFound, "no rows"))},
+			want: "\x1b[2m12:30:45.123\x1b[0m \x1b[35mDEBUG\x1b[0m \x1b[2mdb: \x1b[0m\x1b[1mconnected\x1b[0m\n" +
+				"    \x1b[36mhost\x1b[0m = localhost\n" +
+				"    \x1b[36merror:\x1b[0m\n" +
+				"        \x1b[31m[NOT_FOUND] no rows\x1b[0m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setColor(t, tt.color)
+
+			got := encodePretty(t, tt.context, tt.entry, tt.fields...)
+			if got != tt.want {
+				t.Errorf("EncodeEntry()\n got:\n%s\nwant:\n%s\n got %q\nwant %q", got, tt.want, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrettyEncoderClone(t *testing.T) {
+	setColor(t, false)
+
+	parent := newPrettyEncoder(zapcore.EncoderConfig{LineEnding: "\r\n"})
+	parent.AddString("service", "orders")
+	child := parent.Clone()
+	child.AddString("component", "db")
+
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: prettyTime, Message: "hello"}
+	parentOut, _ := parent.EncodeEntry(ent, nil)
+	childOut, _ := child.EncodeEntry(ent, nil)
+
+	if strings.Contains(parentOut.String(), "component") {
+		t.Errorf("Expected clone fields not to leak into the parent, got %q", parentOut.String())
+	}
+	want := "12:30:45.123 INFO  hello\r\n    component = db\r\n    service   = orders\r\n"
+	if childOut.String() != want {
+		t.Errorf("Clone EncodeEntry() = %q, want %q", childOut.String(), want)
+	}
+}
@@ -2,28 +2,263 @@ package klog
 
 import (
 	"fmt"
+	"log/slog"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+const (
+	EncodingJSON    = "json"
+	EncodingConsole = "console"
+)
+
+// Preset selects a field naming convention for the encoder.
+type Preset string
+
+const (
+	// PresetDefault keeps zap's field names (ts, level, msg, caller, ...).
+	PresetDefault Preset = ""
+
+	// PresetECS follows Elastic Common Schema: @timestamp, log.level, message,
+	// log.origin.file.name, log.logger, error.stack_trace and ecs.version.
+	PresetECS Preset = "ecs"
+
+	// PresetLoki follows Loki/Grafana conventions: timestamp (RFC3339 with
+	// nanoseconds), level, msg and caller, so Grafana detects levels and
+	// orders entries without per-service pipeline configuration.
+	PresetLoki Preset = "loki"
+)
+
+// ECSVersion is the Elastic Common Schema version reported by PresetECS.
+const ECSVersion = "8.11.0"
+
+type ProviderOption func(*providerOptions)
+
+type providerOptions struct {
+	development      bool
+	level            *zapcore.Level
+	levelVar         *slog.LevelVar
+	encoding         string
+	outputPaths      []string
+	errorOutputPaths []string
+	sampling         *zap.SamplingConfig
+	samplingSet      bool
+	initialFields    map[string]any
+	labels           map[string]string
+	preset           Preset
+	zapOptions       []zap.Option
+}
+
+// WithDevelopment switches to zap's development defaults: debug level, console
+// encoding with colored levels, no sampling and DPanic panicking.
+func WithDevelopment(enabled bool) ProviderOption {
+	return func(o *providerOptions) {
+		o.development = enabled
+	}
+}
+
+func WithLevel(level zapcore.Level) ProviderOption {
+	return func(o *providerOptions) {
+		o.level = &level
+	}
+}
+
+// WithEncoding selects EncodingJSON, EncodingConsole or EncodingPretty.
+func WithEncoding(encoding string) ProviderOption {
+	return func(o *providerOptions) {
+		o.encoding = encoding
+	}
+}
+
+// WithOutputPaths sets the sinks for log entries (e.g. "stdout", "/var/log/app.log").
+func WithOutputPaths(paths ...string) ProviderOption {
+	return func(o *providerOptions) {
+		o.outputPaths = append([]string(nil), paths...)
+	}
+}
+
+// WithErrorOutputPaths sets the sinks for zap's internal errors.
+func WithErrorOutputPaths(paths ...string) ProviderOption {
+	return func(o *providerOptions) {
+		o.errorOutputPaths = append([]string(nil), paths...)
+	}
+}
+
+// WithSampling logs the first `initial` entries with the same level and message
+// each second, then every `thereafter`-th one. initial <= 0 disables sampling.
+func WithSampling(initial, thereafter int) ProviderOption {
+	return func(o *providerOptions) {
+		o.samplingSet = true
+		o.sampling = nil
+		if initial > 0 {
+			if thereafter <= 0 {
+				thereafter = initial
+			}
+			o.sampling = &zap.SamplingConfig{Initial: initial, Thereafter: thereafter}
+		}
+	}
+}
+
+// WithInitialFields adds fields to every entry (e.g. service, version, env).
+func WithInitialFields(fields map[string]any) ProviderOption {
+	return func(o *providerOptions) {
+		if o.initialFields == nil {
+			o.initialFields = make(map[string]any, len(fields))
+		}
+		for k, v := range fields {
+			o.initialFields[k] = v
+		}
+	}
+}
+
+// WithPreset selects the field names of the encoder (see PresetECS, PresetLoki).
+func WithPreset(preset Preset) ProviderOption {
+	return func(o *providerOptions) {
+		o.preset = preset
+	}
+}
+
+// WithLabels adds low-cardinality labels to every entry as "labels.<key>"
+// fields (e.g. service, env, region), as used by ECS and Loki pipelines.
+func WithLabels(labels map[string]string) ProviderOption {
+	return func(o *providerOptions) {
+		if o.labels == nil {
+			o.labels = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			o.labels[k] = v
+		}
+	}
+}
+
+// WithZapOptions appends raw zap options applied when building the logger.
+func WithZapOptions(opts ...zap.Option) ProviderOption {
+	return func(o *providerOptions) {
+		o.zapOptions = append(o.zapOptions, opts...)
+	}
+}
+
 func InitProvider(debug bool) (*zap.Logger, error) {
-	cfg := zap.NewProductionConfig()
-	if debug {
-		cfg = zap.NewDevelopmentConfig()
-		cfg.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	return NewProvider(WithDevelopment(debug))
+}
+
+func NewProvider(opts ...ProviderOption) (*zap.Logger, error) {
+	o := &providerOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
 	}
 
-	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	cfg.EncoderConfig.EncodeDuration = zapcore.MillisDurationEncoder
+	cfg, err := o.zapConfig()
+	if err != nil {
+		return nil, err
+	}
 
-	logger, err := cfg.Build(
+	zapOpts := append([]zap.Option{
 		zap.AddCaller(),
 		zap.AddStacktrace(zapcore.ErrorLevel),
-	)
+	}, o.zapOptions...)
+	if o.levelVar != nil {
+		zapOpts = append(zapOpts, wrapLevelVar(o.levelVar))
+	}
+
+	logger, err := cfg.Build(zapOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("klog: cannot init zap provider: %v", err)
 	}
 
 	return logger, nil
 }
+
+func (o *providerOptions) zapConfig() (zap.Config, error) {
+	cfg := zap.NewProductionConfig()
+	if o.development {
+		cfg = zap.NewDevelopmentConfig()
+	}
+
+	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	cfg.EncoderConfig.EncodeDuration = zapcore.MillisDurationEncoder
+
+	switch {
+	case o.levelVar != nil:
+		// The level is checked by the levelVarCore wrapping the core.
+		cfg.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	case o.level != nil:
+		cfg.Level = zap.NewAtomicLevelAt(*o.level)
+	}
+
+	switch o.encoding {
+	case "":
+	case EncodingJSON, EncodingConsole, EncodingPretty:
+		cfg.Encoding = o.encoding
+	default:
+		return cfg, fmt.Errorf("klog: unknown encoding %q", o.encoding)
+	}
+	// Colored levels only make sense on a console encoder.
+	if o.development && cfg.Encoding == EncodingConsole {
+		cfg.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+
+	if len(o.outputPaths) > 0 {
+		cfg.OutputPaths = o.outputPaths
+	}
+	if len(o.errorOutputPaths) > 0 {
+		cfg.ErrorOutputPaths = o.errorOutputPaths
+	}
+	if o.samplingSet {
+		cfg.Sampling = o.sampling
+	}
+	if err := o.applyPreset(&cfg); err != nil {
+		return cfg, err
+	}
+
+	if len(o.initialFields) > 0 || len(o.labels) > 0 {
+		if cfg.InitialFields == nil {
+			cfg.InitialFields = make(map[string]any, len(o.initialFields)+len(o.labels))
+		}
+		for k, v := range o.initialFields {
+			cfg.InitialFields[k] = v
+		}
+		for k, v := range o.labels {
+			cfg.InitialFields["labels."+k] = v
+		}
+	}
+
+	return cfg, nil
+}
+
+func (o *providerOptions) applyPreset(cfg *zap.Config) error {
+	enc := &cfg.EncoderConfig
+	switch o.preset {
+	case PresetDefault:
+		return nil
+	case PresetECS:
+		enc.TimeKey = "@timestamp"
+		enc.LevelKey = "log.level"
+		enc.MessageKey = "message"
+		enc.CallerKey = "log.origin.file.name"
+		enc.NameKey = "log.logger"
+		enc.StacktraceKey = "error.stack_trace"
+		enc.FunctionKey = zapcore.OmitKey
+		enc.EncodeTime = zapcore.ISO8601TimeEncoder
+		cfg.InitialFields = map[string]any{"ecs.version": ECSVersion}
+	case PresetLoki:
+		enc.TimeKey = "timestamp"
+		enc.LevelKey = "level"
+		enc.MessageKey = "msg"
+		enc.CallerKey = "caller"
+		enc.NameKey = "logger"
+		enc.StacktraceKey = "stacktrace"
+		enc.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	default:
+		return fmt.Errorf("klog: unknown preset %q", o.preset)
+	}
+
+	// Structured backends expect plain lowercase levels, never ANSI colors.
+	if cfg.Encoding == EncodingJSON {
+		enc.EncodeLevel = zapcore.LowercaseLevelEncoder
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package klog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestPresets(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ProviderOption
+		want    map[string]any
+		absent  []string
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: map[string]any{"ts": "2024-05-01T12:30:45.123Z", "level": "info", "msg": "hello", "caller": "app/main.go:42"},
+		},
+		{
+			name: "ecs",
+			opts: []ProviderOption{WithPreset(PresetECS)},
+			want: map[string]any{
+				"@timestamp":           "2024-05-01T12:30:45.123Z",
+				"log.level":            "info",
+				"message":              "hello",
+				"log.origin.file.name": "app/main.go:42",
+				"log.logger":           "orders",
+				"error.stack_trace":    "main.main()",
+			},
+			absent: []string{"ts", "msg", "function"},
+		},
+		{
+			name: "loki",
+			opts: []ProviderOption{WithPreset(PresetLoki)},
+			want: map[string]any{
+				"timestamp":  "2024-05-01T12:30:45.123456789Z",
+				"level":      "info",
+				"msg":        "hello",
+				"caller":     "app/main.go:42",
+				"logger":     "orders",
+				"stacktrace": "main.main()",
+			},
+			absent: []string{"ts"},
+		},
+		{
+			name: "ecs development console keeps colors",
+			opts: []ProviderOption{WithPreset(PresetECS), WithDevelopment(true)},
+		},
+		{
+			name:    "unknown preset",
+			opts:    []ProviderOption{WithPreset("splunk")},
+			wantErr: true,
+		},
+	}
+
+	entry := zapcore.Entry{
+		Level:      zapcore.InfoLevel,
+		Time:       time.Date(2024, 5, 1, 12, 30, 45, 123456789, time.UTC),
+		LoggerName: "orders",
+		Message:    "hello",
+		Caller:     zapcore.EntryCaller{Defined: true, File: "/src/app/main.go", Line: 42},
+		Stack:      "main.main()",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &providerOptions{}
+			for _, opt := range tt.opts {
+				opt(o)
+			}
+			cfg, err := o.zapConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("zapConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if cfg.Encoding == EncodingConsole {
+				// Console output is for humans: the preset renames fields but
+				// keeps the colored levels of development.
+				enc := zapcore.NewConsoleEncoder(cfg.EncoderConfig)
+				buf, _ := enc.EncodeEntry(entry, nil)
+				if !strings.Contains(buf.String(), "\x1b[") {
+					t.Errorf("Expected colored levels on the console, got %q", buf.String())
+				}
+				return
+			}
+
+			buf, err := zapcore.NewJSONEncoder(cfg.EncoderConfig).EncodeEntry(entry, nil)
+			if err != nil {
+				t.Fatalf("EncodeEntry() error = %v", err)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("Failed to decode %s: %v", buf.String(), err)
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("%s = %v, want %v (entry %s)", key, got[key], want, buf.String())
+				}
+			}
+			for _, key := range tt.absent {
+				if _, ok := got[key]; ok {
+					t.Errorf("Expected no %s field, got %s", key, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPresetInitialFields(t *testing.T) {
+	o := &providerOptions{}
+	for _, opt := range []ProviderOption{
+		WithPreset(PresetECS),
+		WithInitialFields(map[string]any{"service": "orders"}),
+		WithLabels(map[string]string{"env": "prod"}),
+	} {
+		opt(o)
+	}
+
+	cfg, err := o.zapConfig()
+	if err != nil {
+		t.Fatalf("zapConfig() error = %v", err)
+	}
+	want := map[string]any{"ecs.version": ECSVersion, "service": "orders", "labels.env": "prod"}
+	if len(cfg.InitialFields) != len(want) {
+		t.Errorf("InitialFields = %v, want %v", cfg.InitialFields, want)
+	}
+	for k, v := range want {
+		if cfg.InitialFields[k] != v {
+			t.Errorf("InitialFields[%s] = %v, want %v", k, cfg.InitialFields[k], v)
+		}
+	}
+}
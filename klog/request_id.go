@@ -0,0 +1,120 @@
+package klog
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// RequestIDKey is the attribute key of the request ID.
+	RequestIDKey = "request_id"
+
+	// RequestIDHeader is the HTTP header (and lowercase gRPC metadata key)
+	// carrying the request ID.
+	RequestIDHeader = "X-Request-ID"
+
+	// MaxRequestIDLength is the longest request ID accepted from clients.
+	MaxRequestIDLength = 128
+)
+
+type requestIDContextKey struct{}
+
+// NewRequestID returns a new UUIDv7 request ID. UUIDv7 values are time-ordered,
+// so IDs sort by creation time in log indexes.
+func NewRequestID() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return id.String()
+}
+
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(requestIDContextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// RequestIDExtractor adds the request ID stored by ContextWithRequestID as a
+// "request_id" attr.
+func RequestIDExtractor() ContextExtractor {
+	return func(ctx context.Context) []slog.Attr {
+		id, ok := RequestIDFromContext(ctx)
+		if !ok {
+			return nil
+		}
+		return []slog.Attr{slog.String(RequestIDKey, id)}
+	}
+}
+
+// ValidRequestID reports whether a request ID received from a client may be
+// logged and propagated: at most MaxRequestIDLength characters among ASCII
+// letters, digits and "-_.:+/=", which covers UUIDs, ULIDs and base64 IDs but
+// keeps spaces, quotes and control characters out of logs and headers.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > MaxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		switch c := id[i]; {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte("-_.:+/=", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// extractsRequestID reports whether one of extractors adds the request ID
+// stored by ContextWithRequestID, e.g. RequestIDExtractor. Extractors only
+// read the context, so they are probed with a context carrying an ID.
+func extractsRequestID(extractors []ContextExtractor) bool {
+	ctx := ContextWithRequestID(context.Background(), "probe")
+	for _, extractor := range extractors {
+		for _, attr := range extractor(ctx) {
+			if attr.Key == RequestIDKey {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// logsRequestID reports whether handler is a klog handler that already adds
+// the request ID of the context to every record (see WithRequestID), so the
+// middlewares do not log it twice.
+func logsRequestID(handler slog.Handler) bool {
+	switch h := handler.(type) {
+	case *zapSlogHandler:
+		return h.requestID
+	case *AggregatingHandler:
+		return logsRequestID(h.next)
+	default:
+		return false
+	}
+}
+
+// RequestIDMiddleware stores the incoming X-Request-ID header in the request
+// context and echoes it in the response header. A new ID replaces the header
+// when it is absent or invalid (see ValidRequestID).
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !ValidRequestID(id) {
+			id = NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(ContextWithRequestID(r.Context(), id)))
+	})
+}
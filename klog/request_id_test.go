@@ -0,0 +1,79 @@
+package klog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"018f3b2e-7c1a-7d4e-9b2a-3f6c8d1e5a7b", true},
+		{"01HZX3KQ8M4N5P6R7S8T9V0W1X", true},
+		{"req-1_a.b:c", true},
+		{"dGVzdA+/==", true},
+		{strings.Repeat("a", MaxRequestIDLength), true},
+		{"", false},
+		{strings.Repeat("a", MaxRequestIDLength+1), false},
+		{"has space", false},
+		{"line\nbreak", false},
+		{`quote"d`, false},
+		{"ünïcode", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidRequestID(tt.id); got != tt.want {
+			t.Errorf("ValidRequestID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantSame bool
+	}{
+		{name: "generated"},
+		{name: "propagated", header: "req-1", wantSame: true},
+		{name: "too long", header: strings.Repeat("a", MaxRequestIDLength+1)},
+		{name: "unsafe characters", header: "req-1\r\nX-Injected: 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctxID string
+			handler := RequestIDMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				ctxID, _ = RequestIDFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			header := rec.Header().Get(RequestIDHeader)
+			if header != ctxID {
+				t.Errorf("Expected the response header %q to match the context ID %q", header, ctxID)
+			}
+			if tt.wantSame {
+				if ctxID != tt.header {
+					t.Errorf("Expected the client ID %q to be propagated, got %q", tt.header, ctxID)
+				}
+				return
+			}
+			id, err := uuid.Parse(ctxID)
+			if err != nil || id.Version() != 7 {
+				t.Errorf("Expected a new UUIDv7, got %q", ctxID)
+			}
+		})
+	}
+}
@@ -2,11 +2,14 @@ package klog
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -40,13 +43,41 @@ func normalizeHandlerOptions(opts *slog.HandlerOptions) handlerOptions {
 }
 
 type zapSlogHandler struct {
-	logger     *zap.Logger
-	opts       handlerOptions
-	attrs      []slog.Attr
-	groups     []string
+	logger *zap.Logger
+	opts   handlerOptions
+	attrs  []slog.Attr
+	groups []string
+	// prefix is the group-qualified key prefix ("g1.g2.") and attrFields the
+	// converted logger attrs, both computed once in WithAttrs/WithGroup.
+	prefix     string
+	attrFields []zap.Field
 	extractors []ContextExtractor
+	// requestID is set when an extractor adds the request ID.
+	requestID  bool
+	handlers   []slog.Handler
+	errorHooks []ErrorHook
+	// errorCallbacks are shared by the handlers derived with WithAttrs and
+	// WithGroup, so their rate limits apply to the whole logger.
+	errorCallbacks []*errorCallback
+	// fixedCallerSkip reports the caller found by zap's own stack walk with the
+	// builder's caller skip, instead of resolving it from record.PC.
+	fixedCallerSkip bool
+	// addCaller is set when the caller is resolved by the handler: zap's own
+	// caller capture is then disabled on logger.
+	addCaller bool
 }
 
+var fieldPool = sync.Pool{
+	New: func() any {
+		fields := make([]zap.Field, 0, 16)
+		return &fields
+	},
+}
+
+// maxPooledFields bounds the capacity of pooled field buffers so that a rare
+// huge record does not pin memory.
+const maxPooledFields = 256
+
 func (h *zapSlogHandler) Enabled(_ context.Context, level slog.Level) bool {
 	min := slog.LevelInfo
 	if h.opts.level != nil {
@@ -60,13 +91,30 @@ func (h *zapSlogHandler) Handle(ctx context.Context, record slog.Record) error {
 		return nil
 	}
 
-	fields := make([]zap.Field, 0, len(h.attrs)+record.NumAttrs()+4)
-	h.appendAttrs(&fields, h.groups, h.attrs)
+	buf := fieldPool.Get().(*[]zap.Field)
+	fields := append((*buf)[:0], h.attrFields...)
+	defer func() {
+		if cap(fields) <= maxPooledFields {
+			clear(fields)
+			*buf = fields[:0]
+			fieldPool.Put(buf)
+		}
+	}()
+
+	// Extracted attrs are only kept for the error hooks and extra handlers.
+	keepExtracted := len(h.errorHooks) > 0 || len(h.handlers) > 0
+	var extracted []slog.Attr
 	for _, extractor := range h.extractors {
-		h.appendAttrs(&fields, h.groups, extractor(ctx))
+		attrs := extractor(ctx)
+		for _, attr := range attrs {
+			fields = h.appendAttr(fields, h.groups, h.prefix, attr)
+		}
+		if keepExtracted {
+			extracted = append(extracted, attrs...)
+		}
 	}
 	record.Attrs(func(attr slog.Attr) bool {
-		h.appendAttr(&fields, h.groups, attr)
+		fields = h.appendAttr(fields, h.groups, h.prefix, attr)
 		return true
 	})
 
@@ -76,8 +124,138 @@ func (h *zapSlogHandler) Handle(ctx context.Context, record slog.Record) error {
 		}
 	}
 
-	h.logger.Log(toZapLevel(record.Level), record.Message, fields...)
-	return nil
+	if h.fixedCallerSkip {
+		// Log directly from Handle: the caller skip counts this frame.
+		h.logger.Log(toZapLevel(record.Level), record.Message, fields...)
+	} else {
+		h.writeWithCaller(record, fields)
+	}
+	if record.Level >= slog.LevelError {
+		if len(h.errorHooks) > 0 {
+			h.report(ctx, record, extracted)
+		}
+		if len(h.errorCallbacks) > 0 {
+			h.notifyError(ctx, record)
+		}
+	}
+	return h.handleExtra(ctx, record, extracted)
+}
+
+// writeWithCaller logs the entry with the caller taken from record.PC or, when
+// the record has none (e.g. the standard log package redirected by
+// slog.SetDefault), from the first frame outside log, log/slog and klog.
+func (h *zapSlogHandler) writeWithCaller(record slog.Record, fields []zap.Field) {
+	ce := h.logger.Check(toZapLevel(record.Level), record.Message)
+	if ce == nil {
+		return
+	}
+	if h.addCaller {
+		var frame runtime.Frame
+		if record.PC != 0 {
+			frame = cachedFrame(record.PC)
+		} else {
+			frame = externalCallerFrame()
+		}
+		if frame.File != "" {
+			ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+			ce.Caller.Function = frame.Function
+		}
+	}
+	ce.Write(fields...)
+}
+
+// frameCache maps record PCs to their resolved frames. Call sites are few and
+// fixed, so the cache stays small and saves symbolizing the PC on every record.
+var frameCache = struct {
+	sync.RWMutex
+	frames map[uintptr]runtime.Frame
+}{frames: make(map[uintptr]runtime.Frame)}
+
+func cachedFrame(pc uintptr) runtime.Frame {
+	frameCache.RLock()
+	frame, ok := frameCache.frames[pc]
+	frameCache.RUnlock()
+	if ok {
+		return frame
+	}
+
+	frame, _ = runtime.CallersFrames([]uintptr{pc}).Next()
+	frameCache.Lock()
+	frameCache.frames[pc] = frame
+	frameCache.Unlock()
+	return frame
+}
+
+// zapAddsCaller reports whether z annotates entries with their caller. The
+// probe replaces the core, so nothing is written or sampled.
+func zapAddsCaller(z *zap.Logger) bool {
+	probe := z.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return probeCore{}
+	}))
+	ce := probe.Check(zapcore.InfoLevel, "")
+	return ce != nil && ce.Caller.Defined
+}
+
+type probeCore struct{}
+
+func (probeCore) Enabled(zapcore.Level) bool          { return true }
+func (c probeCore) With([]zapcore.Field) zapcore.Core { return c }
+func (c probeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+func (probeCore) Write(zapcore.Entry, []zapcore.Field) error { return nil }
+func (probeCore) Sync() error                                { return nil }
+
+var klogPackage = reflect.TypeOf(zapSlogHandler{}).PkgPath()
+
+// externalCallerFrame returns the first frame on the stack that is not in the
+// log, log/slog or klog packages, or a zero frame.
+func externalCallerFrame() runtime.Frame {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		switch functionPackage(frame.Function) {
+		case "log", "log/slog", klogPackage, "runtime":
+		default:
+			return frame
+		}
+		if !more {
+			return runtime.Frame{}
+		}
+	}
+}
+
+// functionPackage returns the import path of a fully qualified function name
+// such as "github.com/a/b.(*T).M".
+func functionPackage(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+func (h *zapSlogHandler) handleExtra(ctx context.Context, record slog.Record, extracted []slog.Attr) error {
+	if len(h.handlers) == 0 {
+		return nil
+	}
+	if len(extracted) > 0 {
+		record = record.Clone()
+		record.AddAttrs(extracted...)
+	}
+
+	var errs []error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 func (h *zapSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
@@ -86,6 +264,10 @@ func (h *zapSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	}
 	clone := h.clone()
 	clone.attrs = append(clone.attrs, attrs...)
+	clone.convertAttrs()
+	for i, handler := range clone.handlers {
+		clone.handlers[i] = handler.WithAttrs(attrs)
+	}
 	return clone
 }
 
@@ -95,6 +277,11 @@ func (h *zapSlogHandler) WithGroup(name string) slog.Handler {
 	}
 	clone := h.clone()
 	clone.groups = append(clone.groups, name)
+	clone.prefix = h.prefix + name + "."
+	clone.convertAttrs()
+	for i, handler := range clone.handlers {
+		clone.handlers[i] = handler.WithGroup(name)
+	}
 	return clone
 }
 
@@ -106,52 +293,74 @@ func (h *zapSlogHandler) clone() *zapSlogHandler {
 	if len(h.groups) > 0 {
 		c.groups = append([]string(nil), h.groups...)
 	}
+	if len(h.handlers) > 0 {
+		c.handlers = append([]slog.Handler(nil), h.handlers...)
+	}
 	return &c
 }
 
-func (h *zapSlogHandler) appendAttrs(fields *[]zap.Field, groups []string, attrs []slog.Attr) {
-	for _, attr := range attrs {
-		h.appendAttr(fields, groups, attr)
+// convertAttrs converts the logger attrs to zap fields under the current groups.
+func (h *zapSlogHandler) convertAttrs() {
+	h.attrFields = make([]zap.Field, 0, len(h.attrs))
+	for _, attr := range h.attrs {
+		h.attrFields = h.appendAttr(h.attrFields, h.groups, h.prefix, attr)
 	}
 }
 
-func (h *zapSlogHandler) appendAttr(fields *[]zap.Field, groups []string, attr slog.Attr) {
+// appendAttr converts attr to zap fields keyed prefix+key. groups is only
+// maintained for ReplaceAttr, so nested groups do not allocate otherwise.
+func (h *zapSlogHandler) appendAttr(fields []zap.Field, groups []string, prefix string, attr slog.Attr) []zap.Field {
 	attr.Value = attr.Value.Resolve()
 	if h.opts.replaceAttr != nil {
 		attr = h.opts.replaceAttr(groups, attr)
 	}
 	if attr.Equal(slog.Attr{}) {
-		return
+		return fields
 	}
 
 	if attr.Value.Kind() == slog.KindGroup {
-		childGroups := appendGroup(groups, attr.Key)
+		childPrefix := prefix
+		childGroups := groups
+		if attr.Key != "" {
+			childPrefix = prefix + attr.Key + "."
+			if h.opts.replaceAttr != nil {
+				childGroups = appendGroup(groups, attr.Key)
+			}
+		}
 		for _, child := range attr.Value.Group() {
-			h.appendAttr(fields, childGroups, child)
+			fields = h.appendAttr(fields, childGroups, childPrefix, child)
 		}
-		return
+		return fields
 	}
 
-	key := fullKey(groups, attr.Key)
-	switch attr.Value.Kind() {
+	key := attr.Key
+	if key == "" {
+		key = "value"
+	}
+	key = prefix + key
+	return append(fields, attrField(key, attr.Value))
+}
+
+func attrField(key string, value slog.Value) zap.Field {
+	switch value.Kind() {
 	case slog.KindBool:
-		*fields = append(*fields, zap.Bool(key, attr.Value.Bool()))
+		return zap.Bool(key, value.Bool())
 	case slog.KindDuration:
-		*fields = append(*fields, zap.Duration(key, attr.Value.Duration()))
+		return zap.Duration(key, value.Duration())
 	case slog.KindFloat64:
-		*fields = append(*fields, zap.Float64(key, attr.Value.Float64()))
+		return zap.Float64(key, value.Float64())
 	case slog.KindInt64:
-		*fields = append(*fields, zap.Int64(key, attr.Value.Int64()))
+		return zap.Int64(key, value.Int64())
 	case slog.KindUint64:
-		*fields = append(*fields, zap.Uint64(key, attr.Value.Uint64()))
+		return zap.Uint64(key, value.Uint64())
 	case slog.KindString:
-		*fields = append(*fields, zap.String(key, attr.Value.String()))
+		return zap.String(key, value.String())
 	case slog.KindTime:
-		*fields = append(*fields, zap.Time(key, attr.Value.Time()))
+		return zap.Time(key, value.Time())
 	case slog.KindAny:
-		*fields = append(*fields, anyToField(key, attr.Value.Any()))
+		return anyToField(key, value.Any())
 	default:
-		*fields = append(*fields, zap.Any(key, attr.Value.Any()))
+		return zap.Any(key, value.Any())
 	}
 }
 
@@ -207,7 +416,7 @@ func anyToField(key string, value any) zap.Field {
 		v.Key = key
 		return v
 	case error:
-		return zap.NamedError(key, v)
+		return errorField(key, v)
 	case fmt.Stringer:
 		return zap.Stringer(key, v)
 	case []byte:
@@ -0,0 +1,124 @@
+package klog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"runtime"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/karu-codes/karu-kits/klog"
+)
+
+// newCallerLogger returns a zap logger that records full caller paths in entries
+// written to buf.
+func newCallerLogger(buf *bytes.Buffer) *zap.Logger {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.EncodeCaller = zapcore.FullCallerEncoder
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(cfg), zapcore.AddSync(buf), zapcore.DebugLevel)
+	return zap.New(core, zap.AddCaller())
+}
+
+// here returns the file:line of its caller, offset by delta lines.
+func here(delta int) string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("%s:%d", file, line+delta)
+}
+
+// lastCaller decodes the caller of the last entry in buf.
+func lastCaller(t *testing.T, buf *bytes.Buffer) string {
+	t.Helper()
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	var entry map[string]any
+	if err := json.Unmarshal(lines[len(lines)-1], &entry); err != nil {
+		t.Fatalf("Failed to decode %s: %v", buf.String(), err)
+	}
+	caller, _ := entry["caller"].(string)
+	return caller
+}
+
+// logVia logs through a helper, for WithCallerSkip.
+func logVia(logger *slog.Logger, msg string) {
+	logger.Info(msg)
+}
+
+func TestCaller(t *testing.T) {
+	var buf bytes.Buffer
+	logger := klog.NewSlogBuilder(newCallerLogger(&buf)).Build()
+
+	want := here(1)
+	logger.Info("direct")
+	if got := lastCaller(t, &buf); got != want {
+		t.Errorf("Info caller = %s, want %s", got, want)
+	}
+
+	want = here(1)
+	logger.With("component", "db").WithGroup("query").Error("derived", "table", "users")
+	if got := lastCaller(t, &buf); got != want {
+		t.Errorf("derived logger caller = %s, want %s", got, want)
+	}
+
+	want = here(1)
+	logger.Log(context.Background(), slog.LevelWarn, "log")
+	if got := lastCaller(t, &buf); got != want {
+		t.Errorf("Log caller = %s, want %s", got, want)
+	}
+}
+
+func TestCallerSkip(t *testing.T) {
+	var buf bytes.Buffer
+	// Handle, slog's log and Info, then logVia.
+	logger := klog.NewSlogBuilder(newCallerLogger(&buf)).WithCallerSkip(4).Build()
+
+	want := here(1)
+	logVia(logger, "via helper")
+	if got := lastCaller(t, &buf); got != want {
+		t.Errorf("WithCallerSkip caller = %s, want %s", got, want)
+	}
+}
+
+// TestCallerStandardLog runs outside package klog, whose frames are skipped
+// when the record has no PC.
+func TestCallerStandardLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := klog.NewSlogBuilder(newCallerLogger(&buf)).Build()
+
+	previous := slog.Default()
+	flags := log.Flags()
+	defer func() {
+		slog.SetDefault(previous)
+		log.SetFlags(flags)
+	}()
+	slog.SetDefault(logger)
+
+	for _, tt := range []struct {
+		name  string
+		flags int
+	}{
+		{"without file flags", 0},
+		{"with file flags", log.Lshortfile},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			log.SetFlags(tt.flags)
+
+			want := here(1)
+			log.Print("standard log")
+			if got := lastCaller(t, &buf); got != want {
+				t.Errorf("log.Print caller = %s, want %s", got, want)
+			}
+
+			want = here(1)
+			log.Printf("standard log %d", 2)
+			if got := lastCaller(t, &buf); got != want {
+				t.Errorf("log.Printf caller = %s, want %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package klog
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Output is an additional sink for LoggerBuilder.WithOutput, with its own
+// level and encoding.
+type Output struct {
+	// Writer receives the encoded entries. Default: os.Stdout.
+	Writer io.Writer
+
+	// Level is the minimum level written to this output. slog.LevelVar can be
+	// used to change it at runtime. Default: slog.LevelInfo.
+	Level slog.Leveler
+
+	// Encoding is EncodingJSON, EncodingConsole or EncodingPretty.
+	// Default: EncodingJSON.
+	Encoding string
+
+	// EncoderConfig overrides the encoder configuration. Default: zap's
+	// production (JSON) or development (console) encoder config with ISO8601 times.
+	EncoderConfig *zapcore.EncoderConfig
+}
+
+func (o Output) core() zapcore.Core {
+	writer := o.Writer
+	if writer == nil {
+		writer = os.Stdout
+	}
+	level := o.Level
+	if level == nil {
+		level = slog.LevelInfo
+	}
+
+	var encoder zapcore.Encoder
+	switch o.Encoding {
+	case EncodingConsole:
+		encoder = zapcore.NewConsoleEncoder(o.encoderConfig(zap.NewDevelopmentEncoderConfig()))
+	case EncodingPretty:
+		encoder = newPrettyEncoder(o.encoderConfig(zap.NewDevelopmentEncoderConfig()))
+	default:
+		encoder = zapcore.NewJSONEncoder(o.encoderConfig(zap.NewProductionEncoderConfig()))
+	}
+
+	enabler := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return fromZapLevel(l) >= level.Level()
+	})
+	return zapcore.NewCore(encoder, zapcore.Lock(zapcore.AddSync(writer)), enabler)
+}
+
+func (o Output) encoderConfig(base zapcore.EncoderConfig) zapcore.EncoderConfig {
+	if o.EncoderConfig != nil {
+		return *o.EncoderConfig
+	}
+	base.EncodeTime = zapcore.ISO8601TimeEncoder
+	base.EncodeDuration = zapcore.MillisDurationEncoder
+	return base
+}
+
+func teeOutputs(logger *zap.Logger, outputs []Output) *zap.Logger {
+	return logger.WithOptions(
+		zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			cores := make([]zapcore.Core, 0, len(outputs)+1)
+			cores = append(cores, core)
+			for _, out := range outputs {
+				cores = append(cores, out.core())
+			}
+			return zapcore.NewTee(cores...)
+		}),
+		zap.AddCaller(),
+	)
+}
+
+func fromZapLevel(level zapcore.Level) slog.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return slog.LevelDebug
+	case zapcore.InfoLevel:
+		return slog.LevelInfo
+	case zapcore.WarnLevel:
+		return slog.LevelWarn
+	case zapcore.ErrorLevel:
+		return slog.LevelError
+	default:
+		if level < zapcore.DebugLevel {
+			return slog.LevelDebug
+		}
+		return slog.LevelError + 4
+	}
+}
@@ -0,0 +1,142 @@
+package klog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var errWrite = errors.New("disk full")
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errWrite }
+
+func TestTeeOutputs(t *testing.T) {
+	var stdout, stderr, debug bytes.Buffer
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelDebug)
+
+	logger := NewSlogBuilder(zap.NewNop()).
+		WithHandlerOptions(&slog.HandlerOptions{Level: slog.LevelDebug}).
+		WithOutput(Output{Writer: &stdout}).
+		WithOutput(Output{Writer: &stderr, Level: slog.LevelError, Encoding: EncodingConsole}).
+		WithOutput(Output{Writer: &debug, Level: level}).
+		Build()
+
+	logger.Debug("cache warmed")
+	logger.Info("listening", "addr", ":8080")
+	logger.Error("query failed", "table", "users")
+
+	tests := []struct {
+		name   string
+		out    *bytes.Buffer
+		want   []string
+		absent []string
+	}{
+		{"json at info", &stdout, []string{`"msg":"listening"`, `"addr":":8080"`, `"msg":"query failed"`}, []string{"cache warmed"}},
+		{"console at error", &stderr, []string{"ERROR", "query failed", `{"table": "users"}`}, []string{"listening", `"msg"`}},
+		{"level var at debug", &debug, []string{`"msg":"cache warmed"`, `"msg":"listening"`, `"msg":"query failed"`}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.out.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Expected output to contain %s, got %s", want, got)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(got, absent) {
+					t.Errorf("Expected output not to contain %s, got %s", absent, got)
+				}
+			}
+		})
+	}
+
+	// The output level can change at runtime.
+	level.Set(slog.LevelWarn)
+	debug.Reset()
+	logger.Info("ignored")
+	if debug.Len() != 0 {
+		t.Errorf("Expected the raised level to drop info records, got %s", debug.String())
+	}
+}
+
+func TestTeeOutputsWriteErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs []Output
+		written int
+		wantErr bool
+	}{
+		{"all outputs succeed", []Output{{Writer: &bytes.Buffer{}}, {Writer: &bytes.Buffer{}}}, 2, false},
+		{"failing output does not stop the others", []Output{{Writer: failingWriter{}}, {Writer: &bytes.Buffer{}}}, 1, true},
+		{"errors of every output are joined", []Output{{Writer: failingWriter{}}, {Writer: failingWriter{}}}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := teeOutputs(zap.NewNop(), tt.outputs).Core()
+			err := core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "hello", Time: time.Now()}, nil)
+
+			if (err != nil) != tt.wantErr || (tt.wantErr && !errors.Is(err, errWrite)) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			written := 0
+			for _, out := range tt.outputs {
+				if buf, ok := out.Writer.(*bytes.Buffer); ok && strings.Contains(buf.String(), `"msg":"hello"`) {
+					written++
+				}
+			}
+			if written != tt.written {
+				t.Errorf("Expected %d outputs to be written, got %d", tt.written, written)
+			}
+		})
+	}
+}
+
+// stubHandler is a slog.Handler that returns err from Handle and counts calls.
+type stubHandler struct {
+	level slog.Level
+	err   error
+	calls *int
+}
+
+func (h stubHandler) Enabled(_ context.Context, level slog.Level) bool { return level >= h.level }
+func (h stubHandler) Handle(context.Context, slog.Record) error {
+	*h.calls++
+	return h.err
+}
+func (h stubHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h stubHandler) WithGroup(string) slog.Handler      { return h }
+
+func TestWithHandlerFanOutErrors(t *testing.T) {
+	errA := errors.New("handler a")
+	errB := errors.New("handler b")
+	var a, b, c int
+
+	handler := NewSlogBuilder(zap.NewNop()).
+		WithHandler(stubHandler{err: errA, calls: &a}).
+		WithHandler(stubHandler{err: errB, calls: &b}).
+		WithHandler(stubHandler{level: slog.LevelError, calls: &c}).
+		Build().Handler()
+
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "hello", 0)
+	err := handler.Handle(context.Background(), record)
+
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("Expected the errors of every handler to be joined, got %v", err)
+	}
+	if a != 1 || b != 1 || c != 0 {
+		t.Errorf("Expected enabled handlers to be called once and disabled ones skipped, got %d %d %d", a, b, c)
+	}
+}
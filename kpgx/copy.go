@@ -0,0 +1,50 @@
+package kpgx
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// copier is implemented by *pgxpool.Pool and pgx.Tx.
+type copier interface {
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// CopyFromStructs bulk loads rows into table with the COPY protocol and
+// returns the number of rows copied. T is a struct, or a pointer to one,
+// whose fields are mapped to columns like pgx.RowToStructByName: by "db" tag
+// (e.g. `db:"created_at"`), or by the snake_case field name; `db:"-"` skips a
+// field. table may be schema-qualified ("app.users"). Inside RunInTx, the
+// rows are copied in the transaction.
+//
+//	n, err := kpgx.CopyFromStructs(ctx, db, "events", events)
+func CopyFromStructs[T any](ctx context.Context, db *DB, table string, rows []T) (int64, error) {
+	var c copier = db.pool
+	if tx, ok := TxFromContext(ctx); ok {
+		c = tx
+	}
+	return copyFromStructs(ctx, c, table, rows)
+}
+
+func copyFromStructs[T any](ctx context.Context, c copier, table string, rows []T) (int64, error) {
+	columns, err := structColumns(rowType[T]())
+	if err != nil {
+		return 0, fmt.Errorf("failed to map rows: %w", err)
+	}
+
+	src := pgx.CopyFromSlice(len(rows), func(i int) ([]any, error) {
+		values, err := structValues(reflect.ValueOf(rows[i]), columns)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+		return values, nil
+	})
+	n, err := c.CopyFrom(ctx, tableIdentifier(table), columnNames(columns), src)
+	if err != nil {
+		return n, fmt.Errorf("failed to copy into %s: %w", table, err)
+	}
+	return n, nil
+}
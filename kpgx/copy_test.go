@@ -0,0 +1,104 @@
+package kpgx
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type fakeCopier struct {
+	table   pgx.Identifier
+	columns []string
+	rows    [][]any
+}
+
+func (c *fakeCopier) CopyFrom(_ context.Context, table pgx.Identifier, columns []string, src pgx.CopyFromSource) (int64, error) {
+	c.table, c.columns = table, columns
+	for src.Next() {
+		values, err := src.Values()
+		if err != nil {
+			return 0, err
+		}
+		c.rows = append(c.rows, values)
+	}
+	return int64(len(c.rows)), src.Err()
+}
+
+type testAudit struct {
+	CreatedAt time.Time
+}
+
+type testEvent struct {
+	testAudit
+	ID      int64   `db:"id"`
+	UserID  string  // user_id
+	Payload *string `db:"data"`
+	Ignored string  `db:"-"`
+	private string
+}
+
+func TestCopyFromStructs(t *testing.T) {
+	now := time.Now()
+	payload := "{}"
+	rows := []testEvent{
+		{testAudit: testAudit{CreatedAt: now}, ID: 1, UserID: "u1", Payload: &payload, Ignored: "x"},
+		{ID: 2, UserID: "u2"},
+	}
+
+	c := &fakeCopier{}
+	n, err := copyFromStructs(context.Background(), c, "app.events", rows)
+	if err != nil {
+		t.Fatalf("copyFromStructs() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Expected 2 rows, got %d", n)
+	}
+	if !reflect.DeepEqual(c.table, pgx.Identifier{"app", "events"}) {
+		t.Errorf("Unexpected table %v", c.table)
+	}
+	if want := []string{"created_at", "id", "user_id", "data"}; !reflect.DeepEqual(c.columns, want) {
+		t.Errorf("Expected columns %v, got %v", want, c.columns)
+	}
+	if want := []any{now, int64(1), "u1", &payload}; !reflect.DeepEqual(c.rows[0], want) {
+		t.Errorf("Expected values %v, got %v", want, c.rows[0])
+	}
+	if c.rows[1][3] != (*string)(nil) {
+		t.Errorf("Expected nil payload, got %v", c.rows[1][3])
+	}
+
+	ptrRows := []*testEvent{&rows[0], nil}
+	if _, err := copyFromStructs(context.Background(), &fakeCopier{}, "events", ptrRows); err == nil || !strings.Contains(err.Error(), "row 1") {
+		t.Errorf("Expected error for nil row, got %v", err)
+	}
+	if _, err := copyFromStructs(context.Background(), &fakeCopier{}, "events", []int{1}); err == nil {
+		t.Error("Expected error for non-struct rows")
+	}
+}
+
+func TestStructColumnsRejectsDuplicates(t *testing.T) {
+	type dup struct {
+		A string `db:"name"`
+		B string `db:"name"`
+	}
+	if _, err := structColumns(reflect.TypeFor[dup]()); err == nil {
+		t.Error("Expected error for duplicate columns")
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"ID":         "id",
+		"UserID":     "user_id",
+		"CreatedAt":  "created_at",
+		"HTTPServer": "http_server",
+		"Line2Total": "line2_total",
+	} {
+		if got := toSnakeCase(in); got != want {
+			t.Errorf("toSnakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -29,15 +31,84 @@ type Config struct {
 
 	// HealthCheckPeriod is the duration between health checks.
 	HealthCheckPeriod time.Duration
+
+	// QueryTracer traces the queries of all connections, e.g. for logging or
+	// metrics. It may also implement pgx.BatchTracer, pgx.CopyFromTracer,
+	// pgx.PrepareTracer and pgx.ConnectTracer.
+	QueryTracer pgx.QueryTracer
+
+	// AfterConnect is called on every new connection before it is added to
+	// the pool, e.g. to register custom types or set the search_path. An
+	// error discards the connection.
+	AfterConnect func(ctx context.Context, conn *pgx.Conn) error
+
+	// StatementCacheCapacity is the number of prepared statements cached per
+	// connection with pgx.QueryExecModeCacheStatement. Default: 512.
+	StatementCacheCapacity int
+
+	// DescriptionCacheCapacity is the number of statement descriptions
+	// cached per connection with pgx.QueryExecModeCacheDescribe. Default: 512.
+	DescriptionCacheCapacity int
+
+	// DefaultQueryExecMode is how queries are executed by default, e.g.
+	// pgx.QueryExecModeExec. Default: pgx.QueryExecModeCacheStatement. Use
+	// WithQueryExecMode or pass a pgx.QueryExecMode as the first query
+	// argument to override it per query.
+	DefaultQueryExecMode pgx.QueryExecMode
+
+	// PgBouncer makes the pool safe behind PgBouncer in transaction pooling
+	// mode, where consecutive queries may run on different server
+	// connections: it defaults DefaultQueryExecMode to
+	// pgx.QueryExecModeCacheDescribe, which uses no named prepared
+	// statements. Clear the description cache (by reconnecting) after
+	// schema changes.
+	PgBouncer bool
+
+	// Metrics receives the duration of every query and RunInTx transaction,
+	// and the pool statistics every PoolStatsInterval. It is traced next to
+	// QueryTracer.
+	Metrics MetricsCollector
+
+	// PoolStatsInterval sets how often the pool statistics are reported to
+	// Metrics. Default: 0 (disabled).
+	PoolStatsInterval time.Duration
 }
 
 // DB wraps pgxpool.Pool to provide application-specific functionality.
 type DB struct {
-	pool *pgxpool.Pool
+	pool      *pgxpool.Pool
+	metrics   MetricsCollector
+	stopStats func()
 }
 
 // New creates a new DB instance.
 func New(ctx context.Context, cfg Config) (*DB, error) {
+	pgxCfg, err := poolConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, pgxCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+
+	// Ping to ensure connection is established
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	db := &DB{pool: pool, metrics: cfg.Metrics}
+	if cfg.Metrics != nil && cfg.PoolStatsInterval > 0 {
+		db.startPoolStatsReporter(cfg.PoolStatsInterval)
+	}
+	return db, nil
+}
+
+// poolConfig parses the connection string and applies the non-zero fields of
+// cfg, so settings in the connection string keep working.
+func poolConfig(cfg Config) (*pgxpool.Config, error) {
 	pgxCfg, err := pgxpool.ParseConfig(cfg.ConnString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse connection string: %w", err)
@@ -59,22 +130,38 @@ func New(ctx context.Context, cfg Config) (*DB, error) {
 		pgxCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
 	}
 
-	pool, err := pgxpool.NewWithConfig(ctx, pgxCfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+	switch {
+	case cfg.Metrics != nil && cfg.QueryTracer != nil:
+		pgxCfg.ConnConfig.Tracer = multitracer.New(cfg.QueryTracer, &metricsTracer{metrics: cfg.Metrics})
+	case cfg.Metrics != nil:
+		pgxCfg.ConnConfig.Tracer = &metricsTracer{metrics: cfg.Metrics}
+	case cfg.QueryTracer != nil:
+		pgxCfg.ConnConfig.Tracer = cfg.QueryTracer
 	}
-
-	// Ping to ensure connection is established
-	if err := pool.Ping(ctx); err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+	if cfg.AfterConnect != nil {
+		pgxCfg.AfterConnect = cfg.AfterConnect
+	}
+	if cfg.StatementCacheCapacity > 0 {
+		pgxCfg.ConnConfig.StatementCacheCapacity = cfg.StatementCacheCapacity
+	}
+	if cfg.DescriptionCacheCapacity > 0 {
+		pgxCfg.ConnConfig.DescriptionCacheCapacity = cfg.DescriptionCacheCapacity
+	}
+	switch {
+	case cfg.DefaultQueryExecMode != 0:
+		pgxCfg.ConnConfig.DefaultQueryExecMode = cfg.DefaultQueryExecMode
+	case cfg.PgBouncer && pgxCfg.ConnConfig.DefaultQueryExecMode == pgx.QueryExecModeCacheStatement:
+		pgxCfg.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheDescribe
 	}
 
-	return &DB{pool: pool}, nil
+	return pgxCfg, nil
 }
 
 // Close closes the database connection pool.
 func (db *DB) Close() {
+	if db.stopStats != nil {
+		db.stopStats()
+	}
 	db.pool.Close()
 }
 
@@ -0,0 +1,67 @@
+package kpgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ParseQueryExecMode parses the names used by the default_query_exec_mode
+// connection string parameter: "cache_statement", "cache_describe",
+// "describe_exec", "exec" and "simple_protocol", e.g. to read
+// Config.DefaultQueryExecMode from a config file.
+func ParseQueryExecMode(s string) (pgx.QueryExecMode, error) {
+	switch s {
+	case "cache_statement":
+		return pgx.QueryExecModeCacheStatement, nil
+	case "cache_describe":
+		return pgx.QueryExecModeCacheDescribe, nil
+	case "describe_exec":
+		return pgx.QueryExecModeDescribeExec, nil
+	case "exec":
+		return pgx.QueryExecModeExec, nil
+	case "simple_protocol":
+		return pgx.QueryExecModeSimpleProtocol, nil
+	default:
+		return 0, fmt.Errorf("invalid query exec mode %q", s)
+	}
+}
+
+// WithQueryExecMode returns a DBTX that runs the queries of db with mode
+// instead of the default, e.g. for the sqlc queries of one code path:
+//
+//	q := repository.New(kpgx.WithQueryExecMode(db.GetDBTX(ctx), pgx.QueryExecModeSimpleProtocol))
+//
+// Queries that pass a pgx.QueryExecMode as their first argument keep it.
+func WithQueryExecMode(db DBTX, mode pgx.QueryExecMode) DBTX {
+	return execModeDBTX{db: db, mode: mode}
+}
+
+type execModeDBTX struct {
+	db   DBTX
+	mode pgx.QueryExecMode
+}
+
+func (d execModeDBTX) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return d.db.Exec(ctx, sql, d.args(args)...)
+}
+
+func (d execModeDBTX) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return d.db.Query(ctx, sql, d.args(args)...)
+}
+
+func (d execModeDBTX) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return d.db.QueryRow(ctx, sql, d.args(args)...)
+}
+
+// args prepends the mode, which pgx reads from the first argument.
+func (d execModeDBTX) args(args []interface{}) []interface{} {
+	if len(args) > 0 {
+		if _, ok := args[0].(pgx.QueryExecMode); ok {
+			return args
+		}
+	}
+	return append([]interface{}{d.mode}, args...)
+}
@@ -0,0 +1,70 @@
+package kpgx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type recordingDBTX struct {
+	args [][]interface{}
+}
+
+func (r *recordingDBTX) Exec(_ context.Context, _ string, args ...interface{}) (pgconn.CommandTag, error) {
+	r.args = append(r.args, args)
+	return pgconn.CommandTag{}, nil
+}
+
+func (r *recordingDBTX) Query(_ context.Context, _ string, args ...interface{}) (pgx.Rows, error) {
+	r.args = append(r.args, args)
+	return nil, nil
+}
+
+func (r *recordingDBTX) QueryRow(_ context.Context, _ string, args ...interface{}) pgx.Row {
+	r.args = append(r.args, args)
+	return nil
+}
+
+func TestWithQueryExecMode(t *testing.T) {
+	rec := &recordingDBTX{}
+	db := WithQueryExecMode(rec, pgx.QueryExecModeSimpleProtocol)
+	ctx := context.Background()
+
+	_, _ = db.Exec(ctx, "UPDATE t SET a = $1", 1)
+	_, _ = db.Query(ctx, "SELECT 1")
+	_ = db.QueryRow(ctx, "SELECT $1", pgx.QueryExecModeExec, 2)
+
+	if len(rec.args) != 3 {
+		t.Fatalf("Expected 3 calls, got %d", len(rec.args))
+	}
+	if args := rec.args[0]; len(args) != 2 || args[0] != pgx.QueryExecModeSimpleProtocol || args[1] != 1 {
+		t.Errorf("Expected mode before the arguments, got %v", args)
+	}
+	if args := rec.args[1]; len(args) != 1 || args[0] != pgx.QueryExecModeSimpleProtocol {
+		t.Errorf("Expected mode as the only argument, got %v", args)
+	}
+	if args := rec.args[2]; len(args) != 2 || args[0] != pgx.QueryExecModeExec {
+		t.Errorf("Expected the query's own mode to be kept, got %v", args)
+	}
+}
+
+func TestParseQueryExecMode(t *testing.T) {
+	tests := map[string]pgx.QueryExecMode{
+		"cache_statement": pgx.QueryExecModeCacheStatement,
+		"cache_describe":  pgx.QueryExecModeCacheDescribe,
+		"describe_exec":   pgx.QueryExecModeDescribeExec,
+		"exec":            pgx.QueryExecModeExec,
+		"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+	}
+	for name, want := range tests {
+		got, err := ParseQueryExecMode(name)
+		if err != nil || got != want {
+			t.Errorf("ParseQueryExecMode(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseQueryExecMode("prepared"); err == nil {
+		t.Error("Expected error for an unknown mode")
+	}
+}
@@ -0,0 +1,146 @@
+package kpgx
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// HealthStatus is the overall status reported by Health.
+type HealthStatus string
+
+const (
+	// HealthStatusHealthy indicates the database answered the ping.
+	HealthStatusHealthy HealthStatus = "healthy"
+
+	// HealthStatusDegraded indicates the database answered, but more than
+	// 80% of the pool's connections are in use.
+	HealthStatusDegraded HealthStatus = "degraded"
+
+	// HealthStatusUnhealthy indicates the database could not be reached.
+	HealthStatusUnhealthy HealthStatus = "unhealthy"
+)
+
+// degradedUtilization is the pool utilization above which Health reports
+// HealthStatusDegraded.
+const degradedUtilization = 0.8
+
+// Health is the result of DB.Health.
+type Health struct {
+	Status HealthStatus `json:"status"`
+
+	// Error is the ping error of an unhealthy database.
+	Error string `json:"error,omitempty"`
+
+	// Timestamp is when the check started.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Latency is how long the ping took.
+	Latency time.Duration `json:"latency"`
+
+	Pool PoolStats `json:"pool"`
+}
+
+// PoolStats is a snapshot of the connection pool statistics.
+type PoolStats struct {
+	AcquiredConns     int32 `json:"acquired_conns"`
+	IdleConns         int32 `json:"idle_conns"`
+	ConstructingConns int32 `json:"constructing_conns"`
+	TotalConns        int32 `json:"total_conns"`
+	MaxConns          int32 `json:"max_conns"`
+
+	// AcquireCount is the cumulative count of successful acquires.
+	AcquireCount int64 `json:"acquire_count"`
+	// AcquireDuration is the total time spent waiting for connections.
+	AcquireDuration time.Duration `json:"acquire_duration"`
+	// EmptyAcquireCount is the cumulative count of acquires that had to
+	// wait for a connection.
+	EmptyAcquireCount int64 `json:"empty_acquire_count"`
+	// CanceledAcquireCount is the cumulative count of acquires canceled by
+	// their context.
+	CanceledAcquireCount int64 `json:"canceled_acquire_count"`
+}
+
+// Ping checks that a connection can be acquired and the database answers.
+func (db *DB) Ping(ctx context.Context) error {
+	return db.pool.Ping(ctx)
+}
+
+// Stat returns the statistics of the connection pool.
+func (db *DB) Stat() *pgxpool.Stat {
+	return db.pool.Stat()
+}
+
+// Health pings the database and reports the result with the latency and the
+// pool statistics, e.g. for liveness and readiness endpoints. Bound the
+// check with a ctx deadline.
+//
+//	http.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+//	    ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+//	    defer cancel()
+//	    health := db.Health(ctx)
+//	    w.WriteHeader(health.HTTPStatusCode())
+//	    json.NewEncoder(w).Encode(health)
+//	})
+func (db *DB) Health(ctx context.Context) Health {
+	start := time.Now()
+	err := db.Ping(ctx)
+	health := Health{
+		Timestamp: start,
+		Latency:   time.Since(start),
+		Pool:      poolStats(db.pool.Stat()),
+	}
+
+	switch {
+	case err != nil:
+		health.Status = HealthStatusUnhealthy
+		health.Error = err.Error()
+	case health.Pool.Utilization() > degradedUtilization:
+		health.Status = HealthStatusDegraded
+	default:
+		health.Status = HealthStatusHealthy
+	}
+	return health
+}
+
+// IsHealthy reports whether the status is HealthStatusHealthy.
+func (h Health) IsHealthy() bool {
+	return h.Status == HealthStatusHealthy
+}
+
+// HTTPStatusCode returns 200 when healthy, 429 when degraded and 503 when
+// unhealthy, like kdbx.HealthCheck.
+func (h Health) HTTPStatusCode() int {
+	switch h.Status {
+	case HealthStatusHealthy:
+		return http.StatusOK
+	case HealthStatusDegraded:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
+
+// Utilization returns the share of MaxConns that is acquired, from 0 to 1.
+func (s PoolStats) Utilization() float64 {
+	if s.MaxConns <= 0 {
+		return 0
+	}
+	return float64(s.AcquiredConns) / float64(s.MaxConns)
+}
+
+func poolStats(stat *pgxpool.Stat) PoolStats {
+	return PoolStats{
+		AcquiredConns:        stat.AcquiredConns(),
+		IdleConns:            stat.IdleConns(),
+		ConstructingConns:    stat.ConstructingConns(),
+		TotalConns:           stat.TotalConns(),
+		MaxConns:             stat.MaxConns(),
+		AcquireCount:         stat.AcquireCount(),
+		AcquireDuration:      stat.AcquireDuration(),
+		EmptyAcquireCount:    stat.EmptyAcquireCount(),
+		CanceledAcquireCount: stat.CanceledAcquireCount(),
+	}
+}
@@ -132,3 +132,138 @@ func ToTimestamptzPtr(t *time.Time) pgtype.Timestamptz {
 	}
 	return ToTimestamptz(*t)
 }
+
+func FromUUID(id pgtype.UUID) (uuid.UUID, bool) {
+	if !id.Valid {
+		return uuid.Nil, false
+	}
+	return uuid.UUID(id.Bytes), true
+}
+
+func FromUUIDPtr(id pgtype.UUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	v := uuid.UUID(id.Bytes)
+	return &v
+}
+
+func FromTimestamp(t pgtype.Timestamp) (time.Time, bool) {
+	if !t.Valid {
+		return time.Time{}, false
+	}
+	return t.Time, true
+}
+
+func FromTimestampPtr(t pgtype.Timestamp) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time
+	return &v
+}
+
+func FromTimestamptz(t pgtype.Timestamptz) (time.Time, bool) {
+	if !t.Valid {
+		return time.Time{}, false
+	}
+	return t.Time, true
+}
+
+func FromTimestamptzPtr(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time
+	return &v
+}
+
+func FromDate(t pgtype.Date) (time.Time, bool) {
+	if !t.Valid {
+		return time.Time{}, false
+	}
+	return t.Time, true
+}
+
+func FromDatePtr(t pgtype.Date) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time
+	return &v
+}
+
+func FromText(s pgtype.Text) (string, bool) {
+	if !s.Valid {
+		return "", false
+	}
+	return s.String, true
+}
+
+func FromTextPtr(s pgtype.Text) *string {
+	if !s.Valid {
+		return nil
+	}
+	v := s.String
+	return &v
+}
+
+func FromInt4(i pgtype.Int4) (int32, bool) {
+	if !i.Valid {
+		return 0, false
+	}
+	return i.Int32, true
+}
+
+func FromInt4Ptr(i pgtype.Int4) *int32 {
+	if !i.Valid {
+		return nil
+	}
+	v := i.Int32
+	return &v
+}
+
+func FromInt8(i pgtype.Int8) (int64, bool) {
+	if !i.Valid {
+		return 0, false
+	}
+	return i.Int64, true
+}
+
+func FromInt8Ptr(i pgtype.Int8) *int64 {
+	if !i.Valid {
+		return nil
+	}
+	v := i.Int64
+	return &v
+}
+
+func FromBool(b pgtype.Bool) (bool, bool) {
+	if !b.Valid {
+		return false, false
+	}
+	return b.Bool, true
+}
+
+func FromBoolPtr(b pgtype.Bool) *bool {
+	if !b.Valid {
+		return nil
+	}
+	v := b.Bool
+	return &v
+}
+
+func FromFloat8(f pgtype.Float8) (float64, bool) {
+	if !f.Valid {
+		return 0, false
+	}
+	return f.Float64, true
+}
+
+func FromFloat8Ptr(f pgtype.Float8) *float64 {
+	if !f.Valid {
+		return nil
+	}
+	v := f.Float64
+	return &v
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func TestToUUID(t *testing.T) {
@@ -269,3 +270,282 @@ func TestToTimestamptzPtr(t *testing.T) {
 		t.Error("Expected invalid timestamptz for nil pointer")
 	}
 }
+
+func TestFromUUID(t *testing.T) {
+	id := uuid.New()
+	got, ok := FromUUID(ToUUID(id))
+	if !ok {
+		t.Error("Expected valid UUID")
+	}
+	if got != id {
+		t.Errorf("Expected UUID %v, got %v", id, got)
+	}
+
+	got, ok = FromUUID(pgtype.UUID{})
+	if ok || got != uuid.Nil {
+		t.Errorf("Expected zero UUID for NULL, got %v", got)
+	}
+}
+
+func TestFromUUIDPtr(t *testing.T) {
+	id := uuid.New()
+	got := FromUUIDPtr(ToUUID(id))
+	if got == nil {
+		t.Fatal("Expected non-nil UUID")
+	}
+	if *got != id {
+		t.Errorf("Expected UUID %v, got %v", id, *got)
+	}
+
+	if got := FromUUIDPtr(pgtype.UUID{}); got != nil {
+		t.Errorf("Expected nil for NULL UUID, got %v", *got)
+	}
+}
+
+func TestFromTimestamp(t *testing.T) {
+	now := time.Now()
+	got, ok := FromTimestamp(ToTimestamp(now))
+	if !ok {
+		t.Error("Expected valid timestamp")
+	}
+	if !got.Equal(now) {
+		t.Errorf("Expected timestamp %v, got %v", now, got)
+	}
+
+	got, ok = FromTimestamp(pgtype.Timestamp{})
+	if ok || !got.IsZero() {
+		t.Errorf("Expected zero timestamp for NULL, got %v", got)
+	}
+}
+
+func TestFromTimestampPtr(t *testing.T) {
+	now := time.Now()
+	got := FromTimestampPtr(ToTimestamp(now))
+	if got == nil {
+		t.Fatal("Expected non-nil timestamp")
+	}
+	if !got.Equal(now) {
+		t.Errorf("Expected timestamp %v, got %v", now, *got)
+	}
+
+	if got := FromTimestampPtr(pgtype.Timestamp{}); got != nil {
+		t.Errorf("Expected nil for NULL timestamp, got %v", *got)
+	}
+}
+
+func TestFromTimestamptz(t *testing.T) {
+	now := time.Now()
+	got, ok := FromTimestamptz(ToTimestamptz(now))
+	if !ok {
+		t.Error("Expected valid timestamptz")
+	}
+	if !got.Equal(now) {
+		t.Errorf("Expected timestamptz %v, got %v", now, got)
+	}
+
+	got, ok = FromTimestamptz(pgtype.Timestamptz{})
+	if ok || !got.IsZero() {
+		t.Errorf("Expected zero timestamptz for NULL, got %v", got)
+	}
+}
+
+func TestFromTimestamptzPtr(t *testing.T) {
+	now := time.Now()
+	got := FromTimestamptzPtr(ToTimestamptz(now))
+	if got == nil {
+		t.Fatal("Expected non-nil timestamptz")
+	}
+	if !got.Equal(now) {
+		t.Errorf("Expected timestamptz %v, got %v", now, *got)
+	}
+
+	if got := FromTimestamptzPtr(pgtype.Timestamptz{}); got != nil {
+		t.Errorf("Expected nil for NULL timestamptz, got %v", *got)
+	}
+}
+
+func TestFromDate(t *testing.T) {
+	now := time.Now()
+	got, ok := FromDate(ToDate(now))
+	if !ok {
+		t.Error("Expected valid date")
+	}
+	if !got.Equal(now) {
+		t.Errorf("Expected date %v, got %v", now, got)
+	}
+
+	got, ok = FromDate(pgtype.Date{})
+	if ok || !got.IsZero() {
+		t.Errorf("Expected zero date for NULL, got %v", got)
+	}
+}
+
+func TestFromDatePtr(t *testing.T) {
+	now := time.Now()
+	got := FromDatePtr(ToDate(now))
+	if got == nil {
+		t.Fatal("Expected non-nil date")
+	}
+	if !got.Equal(now) {
+		t.Errorf("Expected date %v, got %v", now, *got)
+	}
+
+	if got := FromDatePtr(pgtype.Date{}); got != nil {
+		t.Errorf("Expected nil for NULL date, got %v", *got)
+	}
+}
+
+func TestFromText(t *testing.T) {
+	s := "test string"
+	got, ok := FromText(ToText(s))
+	if !ok {
+		t.Error("Expected valid text")
+	}
+	if got != s {
+		t.Errorf("Expected text %v, got %v", s, got)
+	}
+
+	got, ok = FromText(pgtype.Text{})
+	if ok || got != "" {
+		t.Errorf("Expected zero text for NULL, got %v", got)
+	}
+}
+
+func TestFromTextPtr(t *testing.T) {
+	s := "test string"
+	got := FromTextPtr(ToText(s))
+	if got == nil {
+		t.Fatal("Expected non-nil text")
+	}
+	if *got != s {
+		t.Errorf("Expected text %v, got %v", s, *got)
+	}
+
+	if got := FromTextPtr(pgtype.Text{}); got != nil {
+		t.Errorf("Expected nil for NULL text, got %v", *got)
+	}
+}
+
+func TestFromInt4(t *testing.T) {
+	i := int32(123)
+	got, ok := FromInt4(ToInt4(i))
+	if !ok {
+		t.Error("Expected valid int4")
+	}
+	if got != i {
+		t.Errorf("Expected int4 %v, got %v", i, got)
+	}
+
+	got, ok = FromInt4(pgtype.Int4{})
+	if ok || got != 0 {
+		t.Errorf("Expected zero int4 for NULL, got %v", got)
+	}
+}
+
+func TestFromInt4Ptr(t *testing.T) {
+	i := int32(123)
+	got := FromInt4Ptr(ToInt4(i))
+	if got == nil {
+		t.Fatal("Expected non-nil int4")
+	}
+	if *got != i {
+		t.Errorf("Expected int4 %v, got %v", i, *got)
+	}
+
+	if got := FromInt4Ptr(pgtype.Int4{}); got != nil {
+		t.Errorf("Expected nil for NULL int4, got %v", *got)
+	}
+}
+
+func TestFromInt8(t *testing.T) {
+	i := int64(1234567890)
+	got, ok := FromInt8(ToInt8(i))
+	if !ok {
+		t.Error("Expected valid int8")
+	}
+	if got != i {
+		t.Errorf("Expected int8 %v, got %v", i, got)
+	}
+
+	got, ok = FromInt8(pgtype.Int8{})
+	if ok || got != 0 {
+		t.Errorf("Expected zero int8 for NULL, got %v", got)
+	}
+}
+
+func TestFromInt8Ptr(t *testing.T) {
+	i := int64(1234567890)
+	got := FromInt8Ptr(ToInt8(i))
+	if got == nil {
+		t.Fatal("Expected non-nil int8")
+	}
+	if *got != i {
+		t.Errorf("Expected int8 %v, got %v", i, *got)
+	}
+
+	if got := FromInt8Ptr(pgtype.Int8{}); got != nil {
+		t.Errorf("Expected nil for NULL int8, got %v", *got)
+	}
+}
+
+func TestFromBool(t *testing.T) {
+	b := true
+	got, ok := FromBool(ToBool(b))
+	if !ok {
+		t.Error("Expected valid bool")
+	}
+	if got != b {
+		t.Errorf("Expected bool %v, got %v", b, got)
+	}
+
+	got, ok = FromBool(pgtype.Bool{})
+	if ok || got != false {
+		t.Errorf("Expected zero bool for NULL, got %v", got)
+	}
+}
+
+func TestFromBoolPtr(t *testing.T) {
+	b := true
+	got := FromBoolPtr(ToBool(b))
+	if got == nil {
+		t.Fatal("Expected non-nil bool")
+	}
+	if *got != b {
+		t.Errorf("Expected bool %v, got %v", b, *got)
+	}
+
+	if got := FromBoolPtr(pgtype.Bool{}); got != nil {
+		t.Errorf("Expected nil for NULL bool, got %v", *got)
+	}
+}
+
+func TestFromFloat8(t *testing.T) {
+	f := 123.456
+	got, ok := FromFloat8(ToFloat8(f))
+	if !ok {
+		t.Error("Expected valid float8")
+	}
+	if got != f {
+		t.Errorf("Expected float8 %v, got %v", f, got)
+	}
+
+	got, ok = FromFloat8(pgtype.Float8{})
+	if ok || got != 0 {
+		t.Errorf("Expected zero float8 for NULL, got %v", got)
+	}
+}
+
+func TestFromFloat8Ptr(t *testing.T) {
+	f := 123.456
+	got := FromFloat8Ptr(ToFloat8(f))
+	if got == nil {
+		t.Fatal("Expected non-nil float8")
+	}
+	if *got != f {
+		t.Errorf("Expected float8 %v, got %v", f, *got)
+	}
+
+	if got := FromFloat8Ptr(pgtype.Float8{}); got != nil {
+		t.Errorf("Expected nil for NULL float8, got %v", *got)
+	}
+}
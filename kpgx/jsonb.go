@@ -0,0 +1,50 @@
+package kpgx
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ToJSONB marshals v into a jsonb parameter. sqlc maps jsonb columns to
+// []byte with pgx/v5.
+func ToJSONB[T any](v T) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal jsonb: %w", err)
+	}
+	return b, nil
+}
+
+// ToJSONBPtr marshals *v into a jsonb parameter, or returns nil (SQL NULL)
+// when v is nil.
+func ToJSONBPtr[T any](v *T) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return ToJSONB(*v)
+}
+
+// FromJSONB decodes a jsonb column into a T. A NULL column (nil b) decodes to
+// the zero value.
+func FromJSONB[T any](b []byte) (T, error) {
+	var v T
+	if b == nil {
+		return v, nil
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return v, fmt.Errorf("failed to unmarshal jsonb: %w", err)
+	}
+	return v, nil
+}
+
+// FromJSONBPtr decodes a nullable jsonb column, returning nil for NULL.
+func FromJSONBPtr[T any](b []byte) (*T, error) {
+	if b == nil {
+		return nil, nil
+	}
+	v, err := FromJSONB[T](b)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -0,0 +1,75 @@
+package kpgx
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testSettings struct {
+	Theme string   `json:"theme"`
+	Tags  []string `json:"tags"`
+}
+
+func TestToJSONB(t *testing.T) {
+	b, err := ToJSONB(testSettings{Theme: "dark", Tags: []string{"a"}})
+	if err != nil {
+		t.Fatalf("ToJSONB() error = %v", err)
+	}
+	if string(b) != `{"theme":"dark","tags":["a"]}` {
+		t.Errorf("Unexpected jsonb %s", b)
+	}
+
+	if _, err := ToJSONB(make(chan int)); err == nil {
+		t.Error("Expected error for unsupported type")
+	}
+}
+
+func TestToJSONBPtr(t *testing.T) {
+	b, err := ToJSONBPtr(&testSettings{Theme: "dark"})
+	if err != nil {
+		t.Fatalf("ToJSONBPtr() error = %v", err)
+	}
+	if string(b) != `{"theme":"dark","tags":null}` {
+		t.Errorf("Unexpected jsonb %s", b)
+	}
+
+	b, err = ToJSONBPtr[testSettings](nil)
+	if err != nil || b != nil {
+		t.Errorf("Expected nil jsonb for nil pointer, got %s (%v)", b, err)
+	}
+}
+
+func TestFromJSONB(t *testing.T) {
+	got, err := FromJSONB[testSettings]([]byte(`{"theme":"dark","tags":["a","b"]}`))
+	if err != nil {
+		t.Fatalf("FromJSONB() error = %v", err)
+	}
+	want := testSettings{Theme: "dark", Tags: []string{"a", "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	got, err = FromJSONB[testSettings](nil)
+	if err != nil || !reflect.DeepEqual(got, testSettings{}) {
+		t.Errorf("Expected zero value for NULL, got %+v (%v)", got, err)
+	}
+
+	if _, err := FromJSONB[testSettings]([]byte(`{"theme":1}`)); err == nil {
+		t.Error("Expected error for mismatched jsonb")
+	}
+}
+
+func TestFromJSONBPtr(t *testing.T) {
+	got, err := FromJSONBPtr[map[string]int]([]byte(`{"a":1}`))
+	if err != nil {
+		t.Fatalf("FromJSONBPtr() error = %v", err)
+	}
+	if got == nil || (*got)["a"] != 1 {
+		t.Errorf("Unexpected value %v", got)
+	}
+
+	got, err = FromJSONBPtr[map[string]int](nil)
+	if err != nil || got != nil {
+		t.Errorf("Expected nil for NULL, got %v (%v)", got, err)
+	}
+}
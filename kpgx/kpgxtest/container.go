@@ -0,0 +1,261 @@
+package kpgxtest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	_ "github.com/jackc/pgx/v5/stdlib" // "pgx" driver for the wait strategy
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/karu-codes/karu-kits/kpgx"
+)
+
+const (
+	// DefaultPostgresImage is the image used by StartPostgres and NewPostgres.
+	DefaultPostgresImage = "postgres:16-alpine"
+
+	postgresPort = nat.Port("5432/tcp")
+)
+
+// Option configures a test database container.
+type Option func(*options)
+
+type options struct {
+	image          string
+	database       string
+	username       string
+	password       string
+	schemas        []string
+	schemaFiles    []string
+	config         kpgx.Config
+	startupTimeout time.Duration
+}
+
+func defaultOptions() *options {
+	return &options{
+		image:          DefaultPostgresImage,
+		database:       "test",
+		username:       "test",
+		password:       "test",
+		startupTimeout: 2 * time.Minute,
+	}
+}
+
+// WithImage overrides the container image (e.g. "postgres:17").
+func WithImage(image string) Option {
+	return func(o *options) {
+		o.image = image
+	}
+}
+
+// WithDatabase sets the database name. Default: "test".
+func WithDatabase(name string) Option {
+	return func(o *options) {
+		o.database = name
+	}
+}
+
+// WithCredentials sets the database user and password. Default: "test"/"test".
+func WithCredentials(username, password string) Option {
+	return func(o *options) {
+		o.username = username
+		o.password = password
+	}
+}
+
+// WithSchema adds a SQL script that is executed once the container is ready.
+func WithSchema(script string) Option {
+	return func(o *options) {
+		o.schemas = append(o.schemas, script)
+	}
+}
+
+// WithSchemaFiles adds SQL script files that are executed, in order, once the
+// container is ready (after any WithSchema scripts).
+func WithSchemaFiles(paths ...string) Option {
+	return func(o *options) {
+		o.schemaFiles = append(o.schemaFiles, paths...)
+	}
+}
+
+// WithConfig sets the kpgx configuration of every pool opened on the
+// container. Its ConnString is replaced by the container URL.
+func WithConfig(cfg kpgx.Config) Option {
+	return func(o *options) {
+		o.config = cfg
+	}
+}
+
+// WithStartupTimeout sets how long to wait for the server to accept connections.
+// Default: 2 minutes.
+func WithStartupTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.startupTimeout = d
+	}
+}
+
+// Container is a running PostgreSQL container.
+type Container struct {
+	url       string
+	config    kpgx.Config
+	container testcontainers.Container
+}
+
+// StartPostgres starts a PostgreSQL container and applies the configured schema.
+// Use it from TestMain to share one container across a package; call Terminate
+// when done.
+func StartPostgres(ctx context.Context, opts ...Option) (*Container, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	dsn := func(host string, port nat.Port) string {
+		return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", o.username, o.password, host, port.Port(), o.database)
+	}
+
+	ctr, err := testcontainers.Run(ctx, o.image,
+		testcontainers.WithExposedPorts(string(postgresPort)),
+		testcontainers.WithEnv(map[string]string{
+			"POSTGRES_DB":       o.database,
+			"POSTGRES_USER":     o.username,
+			"POSTGRES_PASSWORD": o.password,
+		}),
+		testcontainers.WithTmpfs(map[string]string{"/var/lib/postgresql/data": "rw"}),
+		testcontainers.WithWaitStrategy(
+			wait.ForSQL(postgresPort, "pgx", dsn).WithStartupTimeout(o.startupTimeout),
+		),
+	)
+	if err != nil {
+		return nil, withTerminate(ctr, fmt.Errorf("kpgxtest: start postgres container: %w", err))
+	}
+
+	host, err := ctr.Host(ctx)
+	if err != nil {
+		return nil, withTerminate(ctr, fmt.Errorf("kpgxtest: container host: %w", err))
+	}
+	mapped, err := ctr.MappedPort(ctx, postgresPort)
+	if err != nil {
+		return nil, withTerminate(ctr, fmt.Errorf("kpgxtest: container port: %w", err))
+	}
+
+	c := &Container{
+		url:       dsn(host, mapped),
+		config:    o.config,
+		container: ctr,
+	}
+	if err := c.applySchema(ctx, o); err != nil {
+		return nil, withTerminate(ctr, err)
+	}
+
+	return c, nil
+}
+
+func (c *Container) applySchema(ctx context.Context, o *options) error {
+	if len(o.schemas) == 0 && len(o.schemaFiles) == 0 {
+		return nil
+	}
+
+	db, err := c.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for i, script := range o.schemas {
+		if err := execScript(ctx, db, script); err != nil {
+			return fmt.Errorf("kpgxtest: apply schema %d: %w", i, err)
+		}
+	}
+	for _, path := range o.schemaFiles {
+		script, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("kpgxtest: read schema file %s: %w", path, err)
+		}
+		if err := execScript(ctx, db, string(script)); err != nil {
+			return fmt.Errorf("kpgxtest: apply schema file %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// URL returns the connection URL of the container.
+func (c *Container) URL() string {
+	return c.url
+}
+
+// Open connects a pool to the container and closes it when the test ends.
+func (c *Container) Open(t testing.TB) *kpgx.DB {
+	t.Helper()
+
+	db, err := c.open(context.Background())
+	if err != nil {
+		t.Fatalf("kpgxtest: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	return db
+}
+
+func (c *Container) open(ctx context.Context) (*kpgx.DB, error) {
+	cfg := c.config
+	cfg.ConnString = c.url
+	return kpgx.New(ctx, cfg)
+}
+
+// Terminate stops and removes the container.
+func (c *Container) Terminate(ctx context.Context) error {
+	return c.container.Terminate(ctx)
+}
+
+// NewPostgres starts a dedicated PostgreSQL container for the test and returns a
+// pool connected to it. The container is removed when the test ends.
+// The test is skipped when Docker is not available.
+func NewPostgres(t testing.TB, opts ...Option) *kpgx.DB {
+	t.Helper()
+
+	skipIfNoDocker(t)
+
+	c, err := StartPostgres(context.Background(), opts...)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	t.Cleanup(func() {
+		_ = c.Terminate(context.Background())
+	})
+
+	return c.Open(t)
+}
+
+// skipIfNoDocker skips the test when no healthy container runtime is reachable.
+func skipIfNoDocker(t testing.TB) {
+	t.Helper()
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Skipf("kpgxtest: docker is not available: %v", r)
+		}
+	}()
+
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err != nil {
+		t.Skipf("kpgxtest: docker is not available: %v", err)
+	}
+	defer provider.Close()
+
+	if err := provider.Health(context.Background()); err != nil {
+		t.Skipf("kpgxtest: docker is not available: %v", err)
+	}
+}
+
+// withTerminate removes a partially started container and returns err.
+func withTerminate(ctr testcontainers.Container, err error) error {
+	_ = testcontainers.TerminateContainer(ctr)
+	return err
+}
@@ -0,0 +1,80 @@
+// Package kpgxtest provides test helpers for code built on kpgx:
+// container-backed PostgreSQL pools, schema loading, per-test transactions
+// that are rolled back, and pgxmock support for pure unit tests.
+//
+// Example:
+//
+//	func TestUserRepository(t *testing.T) {
+//	    db := kpgxtest.NewPostgres(t, kpgxtest.WithSchemaFiles("testdata/schema.sql"))
+//	    ctx := kpgxtest.RollbackOnCleanup(t, db)
+//
+//	    repo := NewUserRepository(db)
+//	    // ... queries through db.GetDBTX(ctx) see only this test's changes.
+//	}
+package kpgxtest
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/karu-codes/karu-kits/kpgx"
+)
+
+// LoadSchema executes a SQL script, which may hold several statements, in a
+// single transaction and fails the test on error.
+func LoadSchema(t testing.TB, db *kpgx.DB, script string) {
+	t.Helper()
+
+	if err := execScript(context.Background(), db, script); err != nil {
+		t.Fatalf("kpgxtest: load schema: %v", err)
+	}
+}
+
+// LoadSchemaFiles reads and executes SQL script files in order and fails the
+// test on error.
+func LoadSchemaFiles(t testing.TB, db *kpgx.DB, paths ...string) {
+	t.Helper()
+
+	for _, path := range paths {
+		script, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("kpgxtest: read schema file %s: %v", path, err)
+		}
+		if err := execScript(context.Background(), db, string(script)); err != nil {
+			t.Fatalf("kpgxtest: load schema file %s: %v", path, err)
+		}
+	}
+}
+
+// execScript runs script in a transaction. Exec without arguments uses the
+// simple protocol, which accepts several statements.
+func execScript(ctx context.Context, db *kpgx.DB, script string) error {
+	return kpgx.RunInTx(ctx, db, func(ctx context.Context) error {
+		_, err := db.GetDBTX(ctx).Exec(ctx, script)
+		return err
+	})
+}
+
+// RollbackOnCleanup begins a transaction and returns a context holding it,
+// which is rolled back when the test finishes, so tests sharing a database do
+// not see each other's changes. Code under test must use the context: queries
+// through db.GetDBTX(ctx) run in the transaction and kpgx.RunInTx runs in
+// savepoints of it, so commits of the code under test are rolled back too.
+// Queries on db.Pool() bypass it. Each call holds one pool connection until
+// the test finishes.
+func RollbackOnCleanup(t testing.TB, db *kpgx.DB) context.Context {
+	t.Helper()
+
+	tx, err := db.Pool().Begin(context.Background())
+	if err != nil {
+		t.Fatalf("kpgxtest: begin test transaction: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := tx.Rollback(context.Background()); err != nil {
+			t.Errorf("kpgxtest: roll back test transaction: %v", err)
+		}
+	})
+
+	return kpgx.ContextWithTx(context.Background(), tx)
+}
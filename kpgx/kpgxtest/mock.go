@@ -0,0 +1,37 @@
+package kpgxtest
+
+import (
+	"context"
+
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/karu-codes/karu-kits/kpgx"
+)
+
+// pgxmock pools and connections can stand in for the pool and transactions
+// of kpgx, e.g. in sqlc queries.
+var (
+	_ kpgx.DBTX = (pgxmock.PgxPoolIface)(nil)
+	_ kpgx.DBTX = (pgxmock.PgxConnIface)(nil)
+)
+
+// MockContext returns a context that makes kpgx run against mock, for unit
+// tests without a server. kpgx treats mock as the transaction of the
+// context: DB.GetDBTX returns it, and every kpgx.RunInTx begins and commits
+// (or rolls back) a transaction on it, so set the expectations as for a real
+// transaction. The *kpgx.DB of the code under test may be nil.
+//
+//	mock, _ := pgxmock.NewPool()
+//	mock.ExpectBegin()
+//	mock.ExpectExec("INSERT INTO users").WithArgs("alice").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+//	mock.ExpectCommit()
+//
+//	svc := NewService(nil)
+//	err := svc.CreateUser(kpgxtest.MockContext(context.Background(), mock), "alice")
+//
+// Transaction options such as kpgx.WithIsolation do not apply, since
+// kpgx.RunInTx runs in savepoints of the transaction of ctx; use
+// ExpectBegin, not ExpectBeginTx.
+func MockContext(ctx context.Context, mock pgxmock.PgxCommonIface) context.Context {
+	return kpgx.ContextWithTx(ctx, mock)
+}
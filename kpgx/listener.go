@@ -0,0 +1,177 @@
+package kpgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	defaultListenerMinReconnectDelay = time.Second
+	defaultListenerMaxReconnectDelay = 30 * time.Second
+	listenerCloseTimeout             = 5 * time.Second
+)
+
+// NotificationHandler handles a notification received by a Listener.
+type NotificationHandler func(ctx context.Context, n *pgconn.Notification)
+
+// ListenerConfig configures a Listener.
+type ListenerConfig struct {
+	// MinReconnectDelay is the delay before the first reconnect after the
+	// connection failed. It doubles for every failed attempt. Default: 1s.
+	MinReconnectDelay time.Duration
+
+	// MaxReconnectDelay caps the reconnect delay. Default: 30s.
+	MaxReconnectDelay time.Duration
+
+	// OnConnect is called whenever the listener has (re)connected and
+	// subscribed to its channels. Notifications sent while it was
+	// disconnected are lost, so use OnConnect to resynchronize.
+	OnConnect func(ctx context.Context)
+
+	// OnError is called when the connection fails, before reconnecting.
+	OnError func(err error)
+}
+
+// Listener receives PostgreSQL LISTEN/NOTIFY notifications on a dedicated
+// connection and dispatches them to handlers by channel.
+type Listener struct {
+	cfg     ListenerConfig
+	connect func(ctx context.Context) (listenConn, error)
+
+	mu       sync.Mutex
+	handlers map[string][]NotificationHandler
+	running  bool
+}
+
+// listenConn is the part of *pgx.Conn used by Listener.
+type listenConn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close(ctx context.Context) error
+}
+
+// NewListener returns a Listener. Its connection is taken out of the pool
+// while it runs, so it does not count against MaxConns.
+func (db *DB) NewListener(cfg ListenerConfig) *Listener {
+	if cfg.MinReconnectDelay <= 0 {
+		cfg.MinReconnectDelay = defaultListenerMinReconnectDelay
+	}
+	if cfg.MaxReconnectDelay <= 0 {
+		cfg.MaxReconnectDelay = defaultListenerMaxReconnectDelay
+	}
+	return &Listener{
+		cfg: cfg,
+		connect: func(ctx context.Context) (listenConn, error) {
+			conn, err := db.pool.Acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return conn.Hijack(), nil
+		},
+		handlers: make(map[string][]NotificationHandler),
+	}
+}
+
+// Handle registers handler for notifications on channel. Handlers must be
+// registered before Run; they are called one at a time, in the order of the
+// notifications, so slow work should be handed off.
+func (l *Listener) Handle(channel string, handler NotificationHandler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handlers[channel] = append(l.handlers[channel], handler)
+}
+
+// Run listens on the channels with handlers until ctx is done, reconnecting
+// with backoff when the connection fails. It returns nil once ctx is done.
+func (l *Listener) Run(ctx context.Context) error {
+	l.mu.Lock()
+	if l.running {
+		l.mu.Unlock()
+		return errors.New("listener is already running")
+	}
+	if len(l.handlers) == 0 {
+		l.mu.Unlock()
+		return errors.New("listener has no handlers")
+	}
+	l.running = true
+	handlers := make(map[string][]NotificationHandler, len(l.handlers))
+	for channel, hs := range l.handlers {
+		handlers[channel] = append([]NotificationHandler(nil), hs...)
+	}
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.running = false
+		l.mu.Unlock()
+	}()
+
+	delay := l.cfg.MinReconnectDelay
+	for {
+		err := l.listen(ctx, handlers, func() { delay = l.cfg.MinReconnectDelay })
+		if ctx.Err() != nil {
+			return nil
+		}
+		if l.cfg.OnError != nil {
+			l.cfg.OnError(err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		}
+		delay = min(delay*2, l.cfg.MaxReconnectDelay)
+	}
+}
+
+// listen connects, subscribes and dispatches notifications until the
+// connection fails or ctx is done. connected is called once subscribed.
+func (l *Listener) listen(ctx context.Context, handlers map[string][]NotificationHandler, connected func()) error {
+	conn, err := l.connect(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire listener connection: %w", err)
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), listenerCloseTimeout)
+		defer cancel()
+		_ = conn.Close(closeCtx)
+	}()
+
+	for channel := range handlers {
+		if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return fmt.Errorf("failed to listen on %q: %w", channel, err)
+		}
+	}
+	connected()
+	if l.cfg.OnConnect != nil {
+		l.cfg.OnConnect(ctx)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for notification: %w", err)
+		}
+		for _, handler := range handlers[n.Channel] {
+			handler(ctx, n)
+		}
+	}
+}
+
+// Notify sends a notification with payload on channel. Inside RunInTx, it is
+// delivered when the transaction commits.
+func (db *DB) Notify(ctx context.Context, channel, payload string) error {
+	if _, err := db.GetDBTX(ctx).Exec(ctx, "SELECT pg_notify($1, $2)", channel, payload); err != nil {
+		return fmt.Errorf("failed to notify %q: %w", channel, err)
+	}
+	return nil
+}
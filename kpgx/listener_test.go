@@ -0,0 +1,141 @@
+package kpgx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type fakeListenConn struct {
+	mu            sync.Mutex
+	execs         []string
+	notifications chan *pgconn.Notification
+	closed        bool
+}
+
+func (c *fakeListenConn) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.execs = append(c.execs, sql)
+	return pgconn.CommandTag{}, nil
+}
+
+func (c *fakeListenConn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case n, ok := <-c.notifications:
+		if !ok {
+			return nil, errors.New("connection lost")
+		}
+		return n, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *fakeListenConn) Close(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func TestListener(t *testing.T) {
+	first := &fakeListenConn{notifications: make(chan *pgconn.Notification, 2)}
+	second := &fakeListenConn{notifications: make(chan *pgconn.Notification, 1)}
+	first.notifications <- &pgconn.Notification{Channel: "orders", Payload: "1"}
+	first.notifications <- &pgconn.Notification{Channel: "unknown", Payload: "x"}
+	close(first.notifications)
+	second.notifications <- &pgconn.Notification{Channel: `user "events"`, Payload: "2"}
+
+	var (
+		mu       sync.Mutex
+		payloads []string
+		errs     []error
+		connects int
+	)
+	attempts := 0
+	received := make(chan struct{})
+	l := &Listener{
+		cfg: ListenerConfig{
+			MinReconnectDelay: time.Millisecond,
+			MaxReconnectDelay: time.Millisecond,
+			OnConnect: func(context.Context) {
+				mu.Lock()
+				connects++
+				mu.Unlock()
+			},
+			OnError: func(err error) {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			},
+		},
+		connect: func(context.Context) (listenConn, error) {
+			attempts++
+			switch attempts {
+			case 1:
+				return nil, errors.New("database unavailable")
+			case 2:
+				return first, nil
+			default:
+				return second, nil
+			}
+		},
+		handlers: make(map[string][]NotificationHandler),
+	}
+	record := func(_ context.Context, n *pgconn.Notification) {
+		mu.Lock()
+		payloads = append(payloads, n.Channel+"="+n.Payload)
+		done := len(payloads) == 2
+		mu.Unlock()
+		if done {
+			close(received)
+		}
+	}
+	l.Handle("orders", record)
+	l.Handle(`user "events"`, record)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- l.Run(ctx) }()
+
+	select {
+	case <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for notifications")
+	}
+	if err := l.Run(ctx); err == nil {
+		t.Error("Expected error when running twice")
+	}
+	cancel()
+	if err := <-result; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payloads) != 2 || payloads[0] != "orders=1" || payloads[1] != `user "events"=2` {
+		t.Errorf("Unexpected notifications %v", payloads)
+	}
+	if len(errs) != 2 || connects != 2 {
+		t.Errorf("Expected 2 errors and 2 connects, got %v and %d", errs, connects)
+	}
+	if !first.closed || !second.closed {
+		t.Error("Expected connections to be closed")
+	}
+	want := map[string]bool{`LISTEN "orders"`: true, `LISTEN "user ""events"""`: true}
+	if len(second.execs) != 2 || !want[second.execs[0]] || !want[second.execs[1]] {
+		t.Errorf("Unexpected LISTEN statements %v", second.execs)
+	}
+}
+
+func TestListenerWithoutHandlers(t *testing.T) {
+	l := (&DB{}).NewListener(ListenerConfig{})
+	if err := l.Run(context.Background()); err == nil {
+		t.Error("Expected error without handlers")
+	}
+}
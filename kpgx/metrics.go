@@ -0,0 +1,94 @@
+package kpgx
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/karu-codes/karu-kits/internal/sqlkind"
+	"github.com/karu-codes/karu-kits/kdbx"
+)
+
+// MetricsCollector is the kdbx metrics interface, so the collectors of kdbx
+// (kdbx.InMemoryMetricsCollector, kdbx.CompositeMetricsCollector, ...) and
+// custom ones report kpgx and kdbx services the same way.
+type MetricsCollector = kdbx.MetricsCollector
+
+// metricsTracer reports every query to a MetricsCollector.
+type metricsTracer struct {
+	metrics MetricsCollector
+}
+
+type metricsQueryKey struct{}
+
+type metricsQuery struct {
+	sql   string
+	start time.Time
+}
+
+func (t *metricsTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, metricsQueryKey{}, &metricsQuery{sql: data.SQL, start: time.Now()})
+}
+
+func (t *metricsTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	query, ok := ctx.Value(metricsQueryKey{}).(*metricsQuery)
+	if !ok {
+		return
+	}
+	duration := time.Since(query.start)
+	sql := kdbx.SanitizeQuery(query.sql)
+	if data.CommandTag.Select() || sqlkind.IsQuery(query.sql) {
+		t.metrics.RecordQuery(ctx, sql, duration, data.Err)
+	} else {
+		t.metrics.RecordExec(ctx, sql, duration, data.Err)
+	}
+}
+
+// recordTransaction reports a RunInTx transaction that was not nested.
+func (db *DB) recordTransaction(ctx context.Context, start time.Time, err error) {
+	if db == nil || db.metrics == nil {
+		return
+	}
+	db.metrics.RecordTransaction(ctx, time.Since(start), err == nil, err)
+}
+
+// startPoolStatsReporter reports the pool statistics to the metrics collector
+// every interval until Close.
+func (db *DB) startPoolStatsReporter(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	db.stopStats = func() {
+		cancel()
+		<-done
+	}
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		db.metrics.RecordPoolStats(kdbxPoolStats(db.pool.Stat()))
+		for {
+			select {
+			case <-ticker.C:
+				db.metrics.RecordPoolStats(kdbxPoolStats(db.pool.Stat()))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func kdbxPoolStats(stat *pgxpool.Stat) kdbx.PoolStats {
+	return kdbx.PoolStats{
+		AcquiredConns:           stat.AcquiredConns(),
+		IdleConns:               stat.IdleConns(),
+		TotalConns:              stat.TotalConns(),
+		MaxConns:                stat.MaxConns(),
+		NewConnsCount:           stat.NewConnsCount(),
+		MaxLifetimeDestroyCount: stat.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     stat.MaxIdleDestroyCount(),
+	}
+}
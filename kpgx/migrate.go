@@ -0,0 +1,199 @@
+package kpgx
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const defaultMigrationsTable = "schema_migrations"
+
+var migrationFileRe = regexp.MustCompile(`^(\d+)_(.+?)(\.up)?\.sql$`)
+
+// Migration is a SQL migration read by Migrate.
+type Migration struct {
+	Version int64
+	Name    string
+	SQL     string
+}
+
+type migrateOptions struct {
+	table string
+}
+
+// MigrateOption configures Migrate.
+type MigrateOption func(*migrateOptions)
+
+// WithMigrationsTable sets the table recording the applied versions, which
+// may be schema-qualified. Default: "schema_migrations".
+func WithMigrationsTable(table string) MigrateOption {
+	return func(o *migrateOptions) {
+		if table != "" {
+			o.table = table
+		}
+	}
+}
+
+// Migrate applies the SQL migrations in the root of fsys that are not
+// recorded in the migrations table yet, in version order:
+//
+//	//go:embed migrations/*.sql
+//	var migrations embed.FS
+//
+//	sub, _ := fs.Sub(migrations, "migrations")
+//	err := kpgx.Migrate(ctx, db, sub)
+//
+// Files are named <version>_<name>.sql (or .up.sql), e.g.
+// 0001_create_users.sql; .down.sql files and other files are ignored. Each
+// migration runs in its own transaction together with its version record, so
+// a failed migration leaves no trace and is retried by the next Migrate. A
+// session advisory lock serializes concurrent Migrate calls, e.g. of service
+// replicas starting at the same time.
+func Migrate(ctx context.Context, db *DB, fsys fs.FS, opts ...MigrateOption) error {
+	o := migrateOptions{table: defaultMigrationsTable}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire migration connection: %w", err)
+	}
+	defer conn.Release()
+
+	return migrate(ctx, conn, o.table, migrations)
+}
+
+// LoadMigrations reads the migrations in the root of fsys, sorted by version,
+// as applied by Migrate.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int64]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, ".down.sql") {
+			continue
+		}
+		m := migrationFileRe.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", name, err)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %q and %q", version, other, name)
+		}
+		seen[version] = name
+
+		sql, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", name, err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: m[2], SQL: string(sql)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// migrateConn is the part of *pgxpool.Conn used by migrate. The advisory
+// lock belongs to the session, so all statements must use one connection.
+type migrateConn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+func migrate(ctx context.Context, conn migrateConn, table string, migrations []Migration) (err error) {
+	lockKey := migrationLockKey(table)
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Unlock even when ctx is canceled; a broken connection releases
+		// the lock when it closes.
+		if _, unlockErr := conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockKey); unlockErr != nil && err == nil {
+			err = fmt.Errorf("failed to release migration lock: %w", unlockErr)
+		}
+	}()
+
+	ident := tableIdentifier(table).Sanitize()
+	if _, err := conn.Exec(ctx, "CREATE TABLE IF NOT EXISTS "+ident+` (
+	version BIGINT PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	rows, err := conn.Query(ctx, "SELECT version FROM "+ident)
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	versions, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	applied := make(map[int64]bool, len(versions))
+	for _, v := range versions {
+		applied[v] = true
+	}
+
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		if err := applyMigration(ctx, conn, ident, m); err != nil {
+			return fmt.Errorf("failed to apply migration %d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+func applyMigration(ctx context.Context, conn migrateConn, ident string, m Migration) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Without arguments, Exec uses the simple protocol, which allows several
+	// statements per migration.
+	if _, err := tx.Exec(ctx, m.SQL); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "INSERT INTO "+ident+" (version, name) VALUES ($1, $2)", m.Version, m.Name); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// migrationLockKey derives the advisory lock key from the migrations table, so
+// services sharing a database with separate tables do not block each other.
+func migrationLockKey(table string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("kpgx.migrate:" + table))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,193 @@
+package kpgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeRows returns fixed rows with the given column names. Scan assigns the
+// values of the current row to pointers of the same types.
+type fakeRows struct {
+	pgx.Rows
+	fields []string
+	rows   [][]any
+	next   int
+	err    error
+	closed bool
+}
+
+func (r *fakeRows) Next() bool {
+	if r.closed || r.next >= len(r.rows) {
+		r.closed = true
+		return false
+	}
+	r.next++
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	row := r.rows[r.next-1]
+	if len(dest) != len(row) {
+		return fmt.Errorf("expected %d destinations, got %d", len(row), len(dest))
+	}
+	for i, d := range dest {
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(row[i]))
+	}
+	return nil
+}
+
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	fields := make([]pgconn.FieldDescription, len(r.fields))
+	for i, name := range r.fields {
+		fields[i].Name = name
+	}
+	return fields
+}
+
+func (r *fakeRows) Err() error                    { return r.err }
+func (r *fakeRows) Close()                        { r.closed = true }
+func (r *fakeRows) CommandTag() pgconn.CommandTag { return pgconn.CommandTag{} }
+
+// migrateTx records the statements of a migration transaction.
+type migrateTx struct {
+	fakeTx
+	conn *fakeMigrateConn
+}
+
+func (tx *migrateTx) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	tx.conn.txExecs = append(tx.conn.txExecs, sql)
+	if tx.conn.failOn != "" && strings.Contains(sql, tx.conn.failOn) {
+		return pgconn.CommandTag{}, errors.New("syntax error")
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+type fakeMigrateConn struct {
+	applied []int64
+	failOn  string
+	execs   []string
+	txExecs []string
+	txs     []*migrateTx
+}
+
+func (c *fakeMigrateConn) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	c.execs = append(c.execs, sql)
+	return pgconn.CommandTag{}, nil
+}
+
+func (c *fakeMigrateConn) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	rows := &fakeRows{}
+	for _, v := range c.applied {
+		rows.rows = append(rows.rows, []any{v})
+	}
+	return rows, nil
+}
+
+func (c *fakeMigrateConn) Begin(context.Context) (pgx.Tx, error) {
+	tx := &migrateTx{conn: c}
+	c.txs = append(c.txs, tx)
+	return tx, nil
+}
+
+var testMigrations = fstest.MapFS{
+	"0002_add_email.up.sql":     {Data: []byte("ALTER TABLE users ADD email TEXT;")},
+	"0002_add_email.down.sql":   {Data: []byte("ALTER TABLE users DROP email;")},
+	"0001_create_users.sql":     {Data: []byte("CREATE TABLE users (id BIGINT);")},
+	"0010_create_orders.sql":    {Data: []byte("CREATE TABLE orders (id BIGINT);")},
+	"README.md":                 {Data: []byte("docs")},
+	"nested/0003_ignored.sql":   {Data: []byte("SELECT 1;")},
+	"0004_without_extension.tx": {Data: []byte("SELECT 1;")},
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(testMigrations)
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	var got []string
+	for _, m := range migrations {
+		got = append(got, fmt.Sprintf("%d_%s", m.Version, m.Name))
+	}
+	want := []string{"1_create_users", "2_add_email", "10_create_orders"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadMigrations() = %v, want %v", got, want)
+	}
+	if migrations[1].SQL != "ALTER TABLE users ADD email TEXT;" {
+		t.Errorf("Expected the up migration SQL, got %q", migrations[1].SQL)
+	}
+
+	_, err = LoadMigrations(fstest.MapFS{
+		"1_a.sql":  {Data: []byte("SELECT 1;")},
+		"01_b.sql": {Data: []byte("SELECT 2;")},
+	})
+	if err == nil || !strings.Contains(err.Error(), "duplicate migration version 1") {
+		t.Errorf("Expected duplicate version error, got %v", err)
+	}
+}
+
+func TestMigrateAppliesPendingMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(testMigrations)
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	conn := &fakeMigrateConn{applied: []int64{1}}
+
+	if err := migrate(context.Background(), conn, "app.schema_migrations", migrations); err != nil {
+		t.Fatalf("migrate() error = %v", err)
+	}
+
+	if len(conn.execs) != 3 ||
+		!strings.Contains(conn.execs[0], "pg_advisory_lock") ||
+		!strings.Contains(conn.execs[1], `CREATE TABLE IF NOT EXISTS "app"."schema_migrations"`) ||
+		!strings.Contains(conn.execs[2], "pg_advisory_unlock") {
+		t.Errorf("Unexpected connection statements %q", conn.execs)
+	}
+	if len(conn.txs) != 2 || !conn.txs[0].committed || !conn.txs[1].committed {
+		t.Fatalf("Expected 2 committed migration transactions, got %d", len(conn.txs))
+	}
+	wantTx := []string{
+		"ALTER TABLE users ADD email TEXT;",
+		`INSERT INTO "app"."schema_migrations" (version, name) VALUES ($1, $2)`,
+		"CREATE TABLE orders (id BIGINT);",
+		`INSERT INTO "app"."schema_migrations" (version, name) VALUES ($1, $2)`,
+	}
+	if !reflect.DeepEqual(conn.txExecs, wantTx) {
+		t.Errorf("Unexpected transaction statements %q", conn.txExecs)
+	}
+}
+
+func TestMigrateStopsAtFailedMigration(t *testing.T) {
+	migrations, err := LoadMigrations(testMigrations)
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	conn := &fakeMigrateConn{failOn: "ADD email"}
+
+	err = migrate(context.Background(), conn, defaultMigrationsTable, migrations)
+	if err == nil || !strings.Contains(err.Error(), "2_add_email") {
+		t.Fatalf("Expected error for migration 2, got %v", err)
+	}
+	if len(conn.txs) != 2 || !conn.txs[0].committed || conn.txs[1].committed || !conn.txs[1].rolledBack {
+		t.Error("Expected the first migration committed and the failed one rolled back")
+	}
+	if last := conn.execs[len(conn.execs)-1]; !strings.Contains(last, "pg_advisory_unlock") {
+		t.Errorf("Expected the lock to be released, last statement %q", last)
+	}
+}
+
+func TestMigrationLockKey(t *testing.T) {
+	if migrationLockKey("a") == migrationLockKey("b") {
+		t.Error("Expected different lock keys for different tables")
+	}
+	if migrationLockKey("a") != migrationLockKey("a") {
+		t.Error("Expected a stable lock key")
+	}
+}
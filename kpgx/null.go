@@ -0,0 +1,166 @@
+package kpgx
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Null is a nullable value of any type. Valid is false for SQL NULL.
+//
+// Null converts to and from pgtype values with the ToX and FromX helpers, and
+// scans nullable columns of types that have no helper:
+//
+//	nickname := kpgx.NullFrom(row.Nickname, kpgx.FromText) // Null[string]
+//	param := kpgx.NullTo(nickname, kpgx.ToText)          // pgtype.Text
+//
+//	var score kpgx.Null[int16]
+//	err := db.Pool().QueryRow(ctx, "SELECT score FROM players WHERE id = $1", id).Scan(&score)
+type Null[T any] struct {
+	Value T
+	Valid bool
+}
+
+// NullOf returns a valid Null holding v.
+func NullOf[T any](v T) Null[T] {
+	return Null[T]{Value: v, Valid: true}
+}
+
+// NullFromPtr returns a Null holding *p, or NULL when p is nil.
+func NullFromPtr[T any](p *T) Null[T] {
+	if p == nil {
+		return Null[T]{}
+	}
+	return NullOf(*p)
+}
+
+// Ptr returns a pointer to a copy of the value, or nil when n is NULL. pgx
+// encodes a nil pointer as NULL, so Ptr can be passed as a query argument.
+func (n Null[T]) Ptr() *T {
+	if !n.Valid {
+		return nil
+	}
+	return PtrTo(n.Value)
+}
+
+// ValueOr returns the value, or def when n is NULL.
+func (n Null[T]) ValueOr(def T) T {
+	if !n.Valid {
+		return def
+	}
+	return n.Value
+}
+
+// NullFrom converts a pgtype value with a FromX helper, e.g.
+// NullFrom(row.CreatedAt, FromTimestamptz).
+func NullFrom[P, T any](p P, from func(P) (T, bool)) Null[T] {
+	v, ok := from(p)
+	if !ok {
+		return Null[T]{}
+	}
+	return NullOf(v)
+}
+
+// NullTo converts n with a ToX helper, e.g. NullTo(n, ToText). NULL converts
+// to the zero pgtype value, which is NULL as well.
+func NullTo[T, P any](n Null[T], to func(T) P) P {
+	if !n.Valid {
+		var null P
+		return null
+	}
+	return to(n.Value)
+}
+
+// Scan implements sql.Scanner, which pgx uses for types it has no codec for.
+// Types implementing sql.Scanner themselves (such as uuid.UUID) scan
+// themselves; other values are converted within their kind (integers,
+// floats, strings and bytes, bools) and fail on overflow.
+func (n *Null[T]) Scan(src any) error {
+	if src == nil {
+		*n = Null[T]{}
+		return nil
+	}
+	if scanner, ok := any(&n.Value).(sql.Scanner); ok {
+		if err := scanner.Scan(src); err != nil {
+			return err
+		}
+		n.Valid = true
+		return nil
+	}
+	if v, ok := src.(T); ok {
+		*n = NullOf(v)
+		return nil
+	}
+
+	dst := reflect.ValueOf(&n.Value).Elem()
+	if err := convertScanned(dst, reflect.ValueOf(src)); err != nil {
+		return fmt.Errorf("failed to scan %T into %s: %w", src, dst.Type(), err)
+	}
+	n.Valid = true
+	return nil
+}
+
+func convertScanned(dst, src reflect.Value) error {
+	switch {
+	case isIntKind(dst.Kind()) && isIntKind(src.Kind()):
+		if intOverflows(dst, src) {
+			return fmt.Errorf("value %v out of range", src)
+		}
+		dst.Set(src.Convert(dst.Type()))
+	case isFloatKind(dst.Kind()) && (isFloatKind(src.Kind()) || isIntKind(src.Kind())):
+		dst.Set(src.Convert(dst.Type()))
+	case dst.Kind() == reflect.String && (src.Kind() == reflect.String || isBytes(src)):
+		dst.SetString(src.Convert(reflect.TypeOf("")).String())
+	case isBytes(dst) && (src.Kind() == reflect.String || isBytes(src)):
+		dst.SetBytes(append([]byte(nil), src.Convert(reflect.TypeOf([]byte(nil))).Bytes()...))
+	case dst.Kind() == reflect.Bool && src.Kind() == reflect.Bool:
+		dst.SetBool(src.Bool())
+	default:
+		return fmt.Errorf("unsupported conversion")
+	}
+	return nil
+}
+
+func intOverflows(dst, src reflect.Value) bool {
+	if src.CanInt() {
+		i := src.Int()
+		if dst.CanInt() {
+			return dst.OverflowInt(i)
+		}
+		return i < 0 || dst.OverflowUint(uint64(i))
+	}
+	u := src.Uint()
+	if dst.CanUint() {
+		return dst.OverflowUint(u)
+	}
+	return u > math.MaxInt64 || dst.OverflowInt(int64(u))
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Uint64
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func isBytes(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8
+}
+
+// PtrTo returns a pointer to a copy of v, e.g. for optional query arguments.
+func PtrTo[T any](v T) *T {
+	return &v
+}
+
+// ToPtr returns a pointer to v when valid is true and nil otherwise. It
+// accepts the results of the FromX helpers directly:
+//
+//	deletedAt := kpgx.ToPtr(kpgx.FromTimestamptz(row.DeletedAt))
+func ToPtr[T any](v T, valid bool) *T {
+	if !valid {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,125 @@
+package kpgx
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestNull(t *testing.T) {
+	n := NullOf("value")
+	if !n.Valid || n.Value != "value" {
+		t.Errorf("Expected valid value, got %+v", n)
+	}
+	if p := n.Ptr(); p == nil || *p != "value" {
+		t.Errorf("Expected pointer to value, got %v", p)
+	}
+	if got := n.ValueOr("default"); got != "value" {
+		t.Errorf("Expected value, got %q", got)
+	}
+
+	var null Null[string]
+	if null.Ptr() != nil {
+		t.Error("Expected nil pointer for NULL")
+	}
+	if got := null.ValueOr("default"); got != "default" {
+		t.Errorf("Expected default for NULL, got %q", got)
+	}
+
+	s := "ptr"
+	if got := NullFromPtr(&s); !got.Valid || got.Value != "ptr" {
+		t.Errorf("Expected valid value from pointer, got %+v", got)
+	}
+	if got := NullFromPtr[string](nil); got.Valid {
+		t.Error("Expected NULL from nil pointer")
+	}
+}
+
+func TestNullFromAndTo(t *testing.T) {
+	now := time.Now()
+	n := NullFrom(ToTimestamptz(now), FromTimestamptz)
+	if !n.Valid || !n.Value.Equal(now) {
+		t.Errorf("Expected valid timestamptz, got %+v", n)
+	}
+	if ts := NullTo(n, ToTimestamptz); !ts.Valid || !ts.Time.Equal(now) {
+		t.Errorf("Expected valid pgtype.Timestamptz, got %+v", ts)
+	}
+
+	null := NullFrom(pgtype.Text{}, FromText)
+	if null.Valid {
+		t.Error("Expected NULL from invalid text")
+	}
+	if text := NullTo(null, ToText); text.Valid {
+		t.Error("Expected invalid pgtype.Text for NULL")
+	}
+}
+
+func TestNullScan(t *testing.T) {
+	var i Null[int16]
+	if err := i.Scan(int64(42)); err != nil || !i.Valid || i.Value != 42 {
+		t.Errorf("Expected 42, got %+v (%v)", i, err)
+	}
+	if err := i.Scan(int64(math.MaxInt16 + 1)); err == nil {
+		t.Error("Expected overflow error")
+	}
+	if err := i.Scan(nil); err != nil || i.Valid {
+		t.Errorf("Expected NULL, got %+v (%v)", i, err)
+	}
+	if err := i.Scan("42"); err == nil {
+		t.Error("Expected error scanning a string into an integer")
+	}
+
+	var u Null[uint8]
+	if err := u.Scan(int64(-1)); err == nil {
+		t.Error("Expected error scanning a negative value into an unsigned integer")
+	}
+
+	var f Null[float32]
+	if err := f.Scan(float64(1.5)); err != nil || f.Value != 1.5 {
+		t.Errorf("Expected 1.5, got %+v (%v)", f, err)
+	}
+
+	type status string
+	var s Null[status]
+	if err := s.Scan([]byte("active")); err != nil || s.Value != "active" {
+		t.Errorf("Expected active, got %+v (%v)", s, err)
+	}
+
+	var b Null[[]byte]
+	src := []byte("raw")
+	if err := b.Scan(src); err != nil || string(b.Value) != "raw" {
+		t.Errorf("Expected raw, got %+v (%v)", b, err)
+	}
+
+	id := uuid.New()
+	var nu Null[uuid.UUID]
+	if err := nu.Scan(id.String()); err != nil || !nu.Valid || nu.Value != id {
+		t.Errorf("Expected %v, got %+v (%v)", id, nu, err)
+	}
+
+	now := time.Now()
+	var ts Null[time.Time]
+	if err := ts.Scan(now); err != nil || !ts.Value.Equal(now) {
+		t.Errorf("Expected %v, got %+v (%v)", now, ts, err)
+	}
+}
+
+func TestPtrTo(t *testing.T) {
+	p := PtrTo(int32(7))
+	if p == nil || *p != 7 {
+		t.Errorf("Expected pointer to 7, got %v", p)
+	}
+}
+
+func TestToPtr(t *testing.T) {
+	p := ToPtr(FromText(ToText("value")))
+	if p == nil || *p != "value" {
+		t.Errorf("Expected pointer to value, got %v", p)
+	}
+	if p := ToPtr(FromText(pgtype.Text{})); p != nil {
+		t.Errorf("Expected nil for NULL, got %v", *p)
+	}
+}
@@ -0,0 +1,122 @@
+package kpgx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const defaultFetchSize = 1000
+
+var cursorSeq atomic.Uint64
+
+type streamOptions struct {
+	fetchSize int
+}
+
+// StreamOption configures Stream.
+type StreamOption func(*streamOptions)
+
+// WithFetchSize sets the number of rows fetched from the cursor at a time.
+// Default: 1000.
+func WithFetchSize(n int) StreamOption {
+	return func(o *streamOptions) {
+		if n > 0 {
+			o.fetchSize = n
+		}
+	}
+}
+
+// Stream runs query through a cursor and calls fn for every row, fetching the
+// rows in batches so the full result is never held in memory, e.g. for
+// exports and backfills:
+//
+//	err := kpgx.Stream(ctx, db, "SELECT id, email FROM users WHERE created_at < $1", []any{cutoff},
+//	    func(u User) error {
+//	        return w.Write(u)
+//	    })
+//
+// Struct rows are scanned with pgx.RowToStructByName (by "db" tag or field
+// name); other types, including pgtype values and sql.Scanner
+// implementations, scan a single column. query must be a SELECT or VALUES
+// query.
+//
+// The cursor lives in a read-only transaction, or in a savepoint of the
+// transaction in ctx. fn is called between fetches, so it may run queries in
+// the transaction of ctx. An error from fn stops the stream and is
+// returned; a canceled ctx stops it before the next fetch.
+func Stream[T any](ctx context.Context, db *DB, query string, args []any, fn func(T) error, opts ...StreamOption) error {
+	o := streamOptions{fetchSize: defaultFetchSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return RunInTx(ctx, db, func(ctx context.Context) error {
+		tx, _ := TxFromContext(ctx)
+		return stream(ctx, tx, query, args, o.fetchSize, rowMapper[T](), fn)
+	}, WithAccessMode(pgx.ReadOnly))
+}
+
+// cursorConn is the part of pgx.Tx used by stream.
+type cursorConn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func stream[T any](ctx context.Context, conn cursorConn, query string, args []any, fetchSize int, scan pgx.RowToFunc[T], fn func(T) error) error {
+	cursor := pgx.Identifier{"kpgx_stream_" + strconv.FormatUint(cursorSeq.Add(1), 10)}.Sanitize()
+	if _, err := conn.Exec(ctx, "DECLARE "+cursor+" NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		return fmt.Errorf("failed to declare cursor: %w", err)
+	}
+
+	fetch := "FETCH FORWARD " + strconv.Itoa(fetchSize) + " FROM " + cursor
+	batch := make([]T, 0, fetchSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rows, err := conn.Query(ctx, fetch)
+		if err != nil {
+			return fmt.Errorf("failed to fetch rows: %w", err)
+		}
+		batch, err = pgx.AppendRows(batch[:0], rows, scan)
+		if err != nil {
+			return fmt.Errorf("failed to scan rows: %w", err)
+		}
+
+		for _, row := range batch {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if len(batch) < fetchSize {
+			break
+		}
+	}
+
+	if _, err := conn.Exec(ctx, "CLOSE "+cursor); err != nil {
+		return fmt.Errorf("failed to close cursor: %w", err)
+	}
+	return nil
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// rowMapper scans structs by column name and other types as a single column.
+// Structs implementing sql.Scanner (pgtype values, Null) and time.Time are
+// single columns too.
+func rowMapper[T any]() pgx.RowToFunc[T] {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}) && !reflect.PointerTo(t).Implements(scannerType) {
+		return pgx.RowToStructByName[T]
+	}
+	return pgx.RowTo[T]
+}
@@ -0,0 +1,116 @@
+package kpgx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeCursor serves FETCH statements from rows, fetchSize at a time.
+type fakeCursor struct {
+	fields  []string
+	rows    [][]any
+	execs   []string
+	args    []any
+	fetches int
+}
+
+func (c *fakeCursor) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	c.execs = append(c.execs, sql)
+	if strings.HasPrefix(sql, "DECLARE") {
+		c.args = args
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func (c *fakeCursor) Query(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+	size, err := strconv.Atoi(strings.Fields(sql)[2])
+	if err != nil {
+		return nil, err
+	}
+	n := min(size, len(c.rows))
+	c.fetches++
+	batch := c.rows[:n]
+	c.rows = c.rows[n:]
+	return &fakeRows{fields: c.fields, rows: batch}, nil
+}
+
+func TestStreamFetchesInBatches(t *testing.T) {
+	type user struct {
+		ID    int64  `db:"id"`
+		Email string `db:"email"`
+	}
+	c := &fakeCursor{
+		fields: []string{"id", "email"},
+		rows:   [][]any{{int64(1), "a@x"}, {int64(2), "b@x"}, {int64(3), "c@x"}, {int64(4), "d@x"}},
+	}
+
+	var got []user
+	err := stream(context.Background(), c, "SELECT id, email FROM users WHERE id > $1", []any{0}, 2, rowMapper[user](), func(u user) error {
+		got = append(got, u)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("stream() error = %v", err)
+	}
+
+	want := []user{{1, "a@x"}, {2, "b@x"}, {3, "c@x"}, {4, "d@x"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stream() rows = %v, want %v", got, want)
+	}
+	// Two full batches and an empty one.
+	if c.fetches != 3 {
+		t.Errorf("Expected 3 fetches, got %d", c.fetches)
+	}
+	if len(c.execs) != 2 || !strings.Contains(c.execs[0], "NO SCROLL CURSOR FOR SELECT id, email FROM users WHERE id > $1") ||
+		!strings.HasPrefix(c.execs[1], "CLOSE ") {
+		t.Errorf("Unexpected cursor statements %q", c.execs)
+	}
+	if !reflect.DeepEqual(c.args, []any{0}) {
+		t.Errorf("Expected the query args on DECLARE, got %v", c.args)
+	}
+}
+
+func TestStreamStopsOnError(t *testing.T) {
+	c := &fakeCursor{fields: []string{"n"}, rows: [][]any{{int64(1)}, {int64(2)}, {int64(3)}}}
+	errStop := errors.New("stop")
+
+	var got []int64
+	err := stream(context.Background(), c, "SELECT n FROM t", nil, 10, rowMapper[int64](), func(n int64) error {
+		got = append(got, n)
+		if n == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) || !reflect.DeepEqual(got, []int64{1, 2}) {
+		t.Errorf("Expected stop after 2 rows, got %v %v", got, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c = &fakeCursor{fields: []string{"n"}, rows: [][]any{{int64(1)}}}
+	err = stream(ctx, c, "SELECT n FROM t", nil, 10, rowMapper[int64](), func(int64) error { return nil })
+	if !errors.Is(err, context.Canceled) || c.fetches != 0 {
+		t.Errorf("Expected canceled stream before fetching, got %v after %d fetches", err, c.fetches)
+	}
+}
+
+func TestRowMapperScansScannerStructsAsColumns(t *testing.T) {
+	c := &fakeCursor{fields: []string{"name"}, rows: [][]any{{pgtype.Text{String: "a", Valid: true}}}}
+	var got []pgtype.Text
+	err := stream(context.Background(), c, "SELECT name FROM t", nil, 10, rowMapper[pgtype.Text](), func(v pgtype.Text) error {
+		got = append(got, v)
+		return nil
+	})
+	if err != nil || len(got) != 1 || got[0].String != "a" {
+		t.Errorf("Expected one pgtype.Text row, got %v %v", got, err)
+	}
+}
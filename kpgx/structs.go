@@ -0,0 +1,129 @@
+package kpgx
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// structColumn is a struct field stored in a column.
+type structColumn struct {
+	name  string
+	index []int
+}
+
+var structColumnsCache sync.Map // reflect.Type -> []structColumn
+
+// structColumns returns the columns of the struct type t, the way
+// pgx.RowToStructByName matches them: the "db" tag names the column, "-"
+// skips the field and untagged fields use the snake_case field name.
+// Fields of embedded structs are flattened.
+func structColumns(t reflect.Type) ([]structColumn, error) {
+	if cached, ok := structColumnsCache.Load(t); ok {
+		return cached.([]structColumn), nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", t)
+	}
+
+	var columns []structColumn
+	collectStructColumns(t, nil, &columns)
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("%s has no columns", t)
+	}
+	seen := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		if seen[c.name] {
+			return nil, fmt.Errorf("%s maps several fields to column %q", t, c.name)
+		}
+		seen[c.name] = true
+	}
+
+	structColumnsCache.Store(t, columns)
+	return columns, nil
+}
+
+func collectStructColumns(t reflect.Type, index []int, columns *[]structColumn) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, hasTag := field.Tag.Lookup("db")
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+		if field.Anonymous && !hasTag && field.Type.Kind() == reflect.Struct {
+			collectStructColumns(field.Type, fieldIndex, columns)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = toSnakeCase(field.Name)
+		}
+		*columns = append(*columns, structColumn{name: name, index: fieldIndex})
+	}
+}
+
+// structValues returns the values of the columns of v, a struct or a pointer
+// to one.
+func structValues(v reflect.Value, columns []structColumn) ([]any, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, fmt.Errorf("nil %s", v.Type())
+		}
+		v = v.Elem()
+	}
+	values := make([]any, len(columns))
+	for i, c := range columns {
+		values[i] = v.FieldByIndex(c.index).Interface()
+	}
+	return values, nil
+}
+
+// rowType returns the struct type of rows of type T, a struct or a pointer
+// to one.
+func rowType[T any]() reflect.Type {
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func columnNames(columns []structColumn) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// tableIdentifier splits a table name such as "app.users" into a quoted
+// identifier.
+func tableIdentifier(table string) pgx.Identifier {
+	return pgx.Identifier(strings.Split(table, "."))
+}
+
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a word at "Id" in "UserId" and at "Id" in "IDName",
+			// but not inside acronyms such as "ID".
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
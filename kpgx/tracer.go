@@ -0,0 +1,193 @@
+package kpgx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const defaultMaxSQLLength = 500
+
+// SlogTracerOptions configures NewSlogTracer.
+type SlogTracerOptions struct {
+	// Level is the level of successful queries. Default: slog.LevelDebug.
+	Level slog.Leveler
+
+	// SlowThreshold logs successful queries taking at least this long at
+	// slog.LevelWarn. Zero disables it.
+	SlowThreshold time.Duration
+
+	// LogArgs adds the query arguments. They may hold sensitive data, so
+	// they are left out by default.
+	LogArgs bool
+
+	// MaxSQLLength truncates the logged SQL. Default: 500.
+	MaxSQLLength int
+}
+
+// SlogTracer logs queries, batches and copies with slog. Use it as
+// Config.QueryTracer:
+//
+//	cfg.QueryTracer = kpgx.NewSlogTracer(logger, kpgx.SlogTracerOptions{
+//	    SlowThreshold: 200 * time.Millisecond,
+//	})
+//
+// Records are logged with the query context, so the context extractors of a
+// klog logger (request IDs, trace IDs) apply. Failed queries are logged at
+// slog.LevelError, and queries canceled by their context at slog.LevelWarn.
+type SlogTracer struct {
+	logger *slog.Logger
+	opts   SlogTracerOptions
+}
+
+var (
+	_ pgx.QueryTracer    = (*SlogTracer)(nil)
+	_ pgx.BatchTracer    = (*SlogTracer)(nil)
+	_ pgx.CopyFromTracer = (*SlogTracer)(nil)
+)
+
+// NewSlogTracer returns a SlogTracer logging to logger.
+func NewSlogTracer(logger *slog.Logger, opts SlogTracerOptions) *SlogTracer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if opts.Level == nil {
+		opts.Level = slog.LevelDebug
+	}
+	if opts.MaxSQLLength <= 0 {
+		opts.MaxSQLLength = defaultMaxSQLLength
+	}
+	return &SlogTracer{logger: logger, opts: opts}
+}
+
+type (
+	slogQueryKey struct{}
+	slogBatchKey struct{}
+	slogCopyKey  struct{}
+)
+
+type slogTrace struct {
+	start time.Time
+	sql   string
+	args  []any
+}
+
+func (t *SlogTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, slogQueryKey{}, &slogTrace{
+		start: time.Now(),
+		sql:   data.SQL,
+		args:  data.Args,
+	})
+}
+
+func (t *SlogTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	trace, ok := ctx.Value(slogQueryKey{}).(*slogTrace)
+	if !ok {
+		return
+	}
+	attrs := t.queryAttrs(trace.sql, trace.args)
+	if data.Err == nil {
+		attrs = append(attrs, slog.Int64("rows_affected", data.CommandTag.RowsAffected()))
+	}
+	t.log(ctx, "query", time.Since(trace.start), data.Err, attrs)
+}
+
+func (t *SlogTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	return context.WithValue(ctx, slogBatchKey{}, &slogTrace{start: time.Now()})
+}
+
+// TraceBatchQuery logs each query of a batch. Batch queries are not timed
+// individually, so only the batch end has a duration.
+func (t *SlogTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	attrs := t.queryAttrs(data.SQL, data.Args)
+	if data.Err != nil {
+		t.logger.LogAttrs(ctx, errorLevel(data.Err), "batch query failed", append(attrs, errorAttrs(data.Err)...)...)
+		return
+	}
+	attrs = append(attrs, slog.Int64("rows_affected", data.CommandTag.RowsAffected()))
+	t.logger.LogAttrs(ctx, t.opts.Level.Level(), "batch query finished", attrs...)
+}
+
+func (t *SlogTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	trace, ok := ctx.Value(slogBatchKey{}).(*slogTrace)
+	if !ok {
+		return
+	}
+	t.log(ctx, "batch", time.Since(trace.start), data.Err, nil)
+}
+
+func (t *SlogTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	return context.WithValue(ctx, slogCopyKey{}, &slogTrace{
+		start: time.Now(),
+		sql:   data.TableName.Sanitize(),
+	})
+}
+
+func (t *SlogTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	trace, ok := ctx.Value(slogCopyKey{}).(*slogTrace)
+	if !ok {
+		return
+	}
+	attrs := []slog.Attr{slog.String("table", trace.sql)}
+	if data.Err == nil {
+		attrs = append(attrs, slog.Int64("rows_affected", data.CommandTag.RowsAffected()))
+	}
+	t.log(ctx, "copy", time.Since(trace.start), data.Err, attrs)
+}
+
+// log logs the end of an operation with its duration at the level for err.
+func (t *SlogTracer) log(ctx context.Context, op string, duration time.Duration, err error, attrs []slog.Attr) {
+	attrs = append(attrs, slog.Duration("duration", duration))
+	if err != nil {
+		t.logger.LogAttrs(ctx, errorLevel(err), op+" failed", append(attrs, errorAttrs(err)...)...)
+		return
+	}
+	if t.opts.SlowThreshold > 0 && duration >= t.opts.SlowThreshold {
+		t.logger.LogAttrs(ctx, slog.LevelWarn, "slow "+op, attrs...)
+		return
+	}
+	t.logger.LogAttrs(ctx, t.opts.Level.Level(), op+" finished", attrs...)
+}
+
+func (t *SlogTracer) queryAttrs(sql string, args []any) []slog.Attr {
+	attrs := []slog.Attr{slog.String("sql", sanitizeSQL(sql, t.opts.MaxSQLLength))}
+	if t.opts.LogArgs && len(args) > 0 {
+		attrs = append(attrs, slog.Any("args", args))
+	}
+	return attrs
+}
+
+// errorLevel returns slog.LevelWarn for canceled queries, which are usually
+// abandoned requests rather than database problems, and slog.LevelError
+// otherwise.
+func errorLevel(err error) slog.Level {
+	if errors.Is(err, context.Canceled) {
+		return slog.LevelWarn
+	}
+	return slog.LevelError
+}
+
+// errorAttrs returns the error and, for PostgreSQL errors, its SQLSTATE code.
+func errorAttrs(err error) []slog.Attr {
+	attrs := []slog.Attr{slog.Any("error", err)}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		attrs = append(attrs, slog.String("sqlstate", pgErr.Code))
+	}
+	return attrs
+}
+
+// sanitizeSQL collapses the whitespace of sql to single spaces, so multi-line
+// queries log on one line, and truncates it to maxLength bytes.
+func sanitizeSQL(sql string, maxLength int) string {
+	sql = strings.Join(strings.Fields(sql), " ")
+	if len(sql) > maxLength {
+		sql = sql[:maxLength] + "... (truncated)"
+	}
+	return sql
+}
@@ -0,0 +1,136 @@
+package kpgx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func newTestSlogTracer(opts SlogTracerOptions) (*SlogTracer, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return NewSlogTracer(logger, opts), &buf
+}
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("failed to decode %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestSlogTracerQuery(t *testing.T) {
+	tracer, buf := newTestSlogTracer(SlogTracerOptions{})
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+		SQL:  "SELECT *\n\tFROM users\n\tWHERE id = $1",
+		Args: []any{42},
+	})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+
+	records := decodeRecords(t, buf)
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	r := records[0]
+	if r["level"] != "DEBUG" || r["msg"] != "query finished" {
+		t.Errorf("Expected debug 'query finished', got %v %v", r["level"], r["msg"])
+	}
+	if r["sql"] != "SELECT * FROM users WHERE id = $1" {
+		t.Errorf("Expected collapsed SQL, got %q", r["sql"])
+	}
+	if r["rows_affected"] != float64(1) {
+		t.Errorf("Expected rows_affected 1, got %v", r["rows_affected"])
+	}
+	if _, ok := r["duration"]; !ok {
+		t.Error("Expected duration attribute")
+	}
+	if _, ok := r["args"]; ok {
+		t.Error("Expected args to be left out by default")
+	}
+}
+
+func TestSlogTracerErrors(t *testing.T) {
+	tracer, buf := newTestSlogTracer(SlogTracerOptions{LogArgs: true})
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "INSERT INTO users VALUES ($1)", Args: []any{"a"}})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: &pgconn.PgError{Code: "23505", Message: "duplicate key"}})
+
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT pg_sleep(10)"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: context.Canceled})
+
+	records := decodeRecords(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if r := records[0]; r["level"] != "ERROR" || r["msg"] != "query failed" || r["sqlstate"] != "23505" || r["args"] == nil {
+		t.Errorf("Expected error record with sqlstate and args, got %v", r)
+	}
+	if r := records[1]; r["level"] != "WARN" || r["msg"] != "query failed" {
+		t.Errorf("Expected warn record for canceled query, got %v", r)
+	}
+}
+
+func TestSlogTracerSlowQuery(t *testing.T) {
+	tracer, buf := newTestSlogTracer(SlogTracerOptions{SlowThreshold: time.Nanosecond})
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	time.Sleep(time.Millisecond)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	records := decodeRecords(t, buf)
+	if len(records) != 1 || records[0]["level"] != "WARN" || records[0]["msg"] != "slow query" {
+		t.Errorf("Expected a slow query warning, got %v", records)
+	}
+}
+
+func TestSlogTracerBatchAndCopy(t *testing.T) {
+	tracer, buf := newTestSlogTracer(SlogTracerOptions{Level: slog.LevelInfo})
+
+	ctx := tracer.TraceBatchStart(context.Background(), nil, pgx.TraceBatchStartData{Batch: &pgx.Batch{}})
+	tracer.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "UPDATE a SET b = 1", CommandTag: pgconn.NewCommandTag("UPDATE 3")})
+	tracer.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{Err: errors.New("boom")})
+
+	ctx = tracer.TraceCopyFromStart(context.Background(), nil, pgx.TraceCopyFromStartData{TableName: pgx.Identifier{"app", "users"}})
+	tracer.TraceCopyFromEnd(ctx, nil, pgx.TraceCopyFromEndData{CommandTag: pgconn.NewCommandTag("COPY 10")})
+
+	records := decodeRecords(t, buf)
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	if r := records[0]; r["level"] != "INFO" || r["msg"] != "batch query finished" || r["rows_affected"] != float64(3) {
+		t.Errorf("Unexpected batch query record %v", r)
+	}
+	if r := records[1]; r["level"] != "ERROR" || r["msg"] != "batch failed" {
+		t.Errorf("Unexpected batch end record %v", r)
+	}
+	if r := records[2]; r["msg"] != "copy finished" || r["table"] != `"app"."users"` || r["rows_affected"] != float64(10) {
+		t.Errorf("Unexpected copy record %v", r)
+	}
+}
+
+func TestSanitizeSQL(t *testing.T) {
+	if got := sanitizeSQL("  SELECT\n  1  ", 100); got != "SELECT 1" {
+		t.Errorf("sanitizeSQL() = %q", got)
+	}
+	if got := sanitizeSQL("SELECT 12345", 6); got != "SELECT... (truncated)" {
+		t.Errorf("sanitizeSQL() = %q", got)
+	}
+}
@@ -0,0 +1,213 @@
+package kpgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	defaultTxInitialBackoff = 10 * time.Millisecond
+	defaultTxMaxBackoff     = time.Second
+)
+
+type txKey struct{}
+
+// TxFunc is a function run inside a transaction. Queries must use the
+// transaction from ctx (see DB.GetDBTX).
+type TxFunc func(ctx context.Context) error
+
+type txOptions struct {
+	pgx            pgx.TxOptions
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// TxOption configures RunInTx.
+type TxOption func(*txOptions)
+
+// WithIsolation sets the isolation level, e.g. pgx.Serializable. Default: the
+// server default (usually read committed).
+func WithIsolation(level pgx.TxIsoLevel) TxOption {
+	return func(o *txOptions) {
+		o.pgx.IsoLevel = level
+	}
+}
+
+// WithAccessMode sets the access mode, pgx.ReadWrite or pgx.ReadOnly.
+func WithAccessMode(mode pgx.TxAccessMode) TxOption {
+	return func(o *txOptions) {
+		o.pgx.AccessMode = mode
+	}
+}
+
+// WithRetry runs the transaction up to maxAttempts times when it fails with a
+// serialization failure (40001) or a deadlock (40P01), which serializable and
+// repeatable read transactions must expect. fn is run again from the start,
+// so it must not have side effects outside the transaction. Default: 1
+// attempt.
+func WithRetry(maxAttempts int) TxOption {
+	return func(o *txOptions) {
+		if maxAttempts > 0 {
+			o.maxAttempts = maxAttempts
+		}
+	}
+}
+
+// WithRetryBackoff sets the delay before the first retry, doubled (with
+// jitter) for every further retry up to maxBackoff. Default: 10ms up to 1s.
+func WithRetryBackoff(initial, maxBackoff time.Duration) TxOption {
+	return func(o *txOptions) {
+		if initial > 0 {
+			o.initialBackoff = initial
+		}
+		if maxBackoff > 0 {
+			o.maxBackoff = maxBackoff
+		}
+	}
+}
+
+// RunInTx runs fn inside a transaction stored in the context passed to fn.
+// The transaction is committed when fn returns nil and rolled back when it
+// returns an error or panics.
+//
+// When ctx already holds a transaction, fn runs in a savepoint of it and the
+// options are ignored: an error rolls back only the changes of fn, so the
+// caller may handle it and continue the outer transaction. The outermost
+// RunInTx commits, and retries the whole transaction.
+func RunInTx(ctx context.Context, db *DB, fn TxFunc, opts ...TxOption) error {
+	// Begin lazily: a nested RunInTx only uses the transaction of ctx.
+	begin := func(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+		return db.pool.BeginTx(ctx, opts)
+	}
+	if _, nested := TxFromContext(ctx); nested {
+		return runInTx(ctx, begin, fn, opts)
+	}
+
+	start := time.Now()
+	err := runInTx(ctx, begin, fn, opts)
+	db.recordTransaction(ctx, start, err)
+	return err
+}
+
+// RunInTxWithResult is RunInTx for functions returning a value. The value of
+// the last attempt is returned.
+func RunInTxWithResult[T any](ctx context.Context, db *DB, fn func(ctx context.Context) (T, error), opts ...TxOption) (T, error) {
+	var result T
+	err := RunInTx(ctx, db, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	}, opts...)
+	return result, err
+}
+
+// ContextWithTx returns a copy of ctx holding tx, as RunInTx does, for
+// transactions started elsewhere: GetDBTX returns tx, and RunInTx runs in
+// savepoints of it. The caller commits or rolls back tx. kpgxtest uses it to
+// roll back the changes of a test.
+func ContextWithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns the transaction started by RunInTx, if any.
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return tx, ok
+}
+
+// GetDBTX returns the transaction of ctx when called inside RunInTx, and the
+// pool otherwise, for use with sqlc queries.
+func (db *DB) GetDBTX(ctx context.Context) DBTX {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return db.pool
+}
+
+type beginFunc func(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+
+func runInTx(ctx context.Context, begin beginFunc, fn TxFunc, opts []TxOption) error {
+	if tx, ok := TxFromContext(ctx); ok {
+		// pgx implements nested transactions with savepoints.
+		savepoint := func(ctx context.Context, _ pgx.TxOptions) (pgx.Tx, error) {
+			return tx.Begin(ctx)
+		}
+		return runTxOnce(ctx, savepoint, fn, pgx.TxOptions{})
+	}
+
+	o := txOptions{
+		maxAttempts:    1,
+		initialBackoff: defaultTxInitialBackoff,
+		maxBackoff:     defaultTxMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := runTxOnce(ctx, begin, fn, o.pgx)
+		if err == nil || attempt >= o.maxAttempts || !isRetryableTxError(err) {
+			return err
+		}
+
+		timer := time.NewTimer(txBackoff(o, attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("transaction retry cancelled: %w", errors.Join(ctx.Err(), err))
+		}
+	}
+}
+
+func runTxOnce(ctx context.Context, begin beginFunc, fn TxFunc, opts pgx.TxOptions) error {
+	tx, err := begin(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+	}()
+
+	if err := fn(ContextWithTx(ctx, tx)); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// isRetryableTxError reports whether err is a serialization failure or a
+// deadlock, after which the transaction can succeed when run again.
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// txBackoff returns the delay after the given failed attempt: the initial
+// backoff doubled per attempt, capped at the maximum, with ±10% jitter.
+func txBackoff(o txOptions, attempt int) time.Duration {
+	backoff := o.initialBackoff
+	for i := 1; i < attempt && backoff < o.maxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, o.maxBackoff)
+	jitter := float64(backoff) * 0.1 * (2*rand.Float64() - 1)
+	return backoff + time.Duration(jitter)
+}
@@ -0,0 +1,209 @@
+package kpgx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeTx records how a transaction ended. Other pgx.Tx methods are not
+// implemented.
+type fakeTx struct {
+	pgx.Tx
+	commitErr  error
+	committed  bool
+	rolledBack bool
+	savepoints []*fakeTx
+}
+
+func (tx *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	savepoint := &fakeTx{}
+	tx.savepoints = append(tx.savepoints, savepoint)
+	return savepoint, nil
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	tx.committed = true
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback(context.Context) error {
+	tx.rolledBack = true
+	return nil
+}
+
+type fakeBeginner struct {
+	txs  []*fakeTx
+	opts []pgx.TxOptions
+	// commitErrs are returned by the commits of the first transactions.
+	commitErrs []error
+}
+
+func (b *fakeBeginner) begin(_ context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	tx := &fakeTx{}
+	if len(b.txs) < len(b.commitErrs) {
+		tx.commitErr = b.commitErrs[len(b.txs)]
+	}
+	b.txs = append(b.txs, tx)
+	b.opts = append(b.opts, opts)
+	return tx, nil
+}
+
+func TestRunInTxCommitsAndRollsBack(t *testing.T) {
+	b := &fakeBeginner{}
+	err := runInTx(context.Background(), b.begin, func(ctx context.Context) error {
+		tx, ok := TxFromContext(ctx)
+		if !ok || tx != b.txs[0] {
+			t.Error("Expected the transaction in the context")
+		}
+		return nil
+	}, []TxOption{WithIsolation(pgx.Serializable), WithAccessMode(pgx.ReadOnly)})
+	if err != nil {
+		t.Fatalf("runInTx() error = %v", err)
+	}
+	if len(b.txs) != 1 || !b.txs[0].committed || b.txs[0].rolledBack {
+		t.Fatalf("Expected one committed transaction, got %+v", b.txs)
+	}
+	if b.opts[0].IsoLevel != pgx.Serializable || b.opts[0].AccessMode != pgx.ReadOnly {
+		t.Errorf("Unexpected transaction options %+v", b.opts[0])
+	}
+
+	errFailed := errors.New("failed")
+	b = &fakeBeginner{}
+	err = runInTx(context.Background(), b.begin, func(context.Context) error { return errFailed }, nil)
+	if !errors.Is(err, errFailed) || !b.txs[0].rolledBack || b.txs[0].committed {
+		t.Fatalf("Expected rollback with the function error, got %v %+v", err, b.txs[0])
+	}
+
+	b = &fakeBeginner{}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected the panic to be re-raised")
+			}
+		}()
+		_ = runInTx(context.Background(), b.begin, func(context.Context) error { panic("boom") }, nil)
+	}()
+	if !b.txs[0].rolledBack {
+		t.Error("Expected rollback on panic")
+	}
+}
+
+func TestRunInTxRetriesSerializationFailures(t *testing.T) {
+	serialization := &pgconn.PgError{Code: "40001"}
+	deadlock := &pgconn.PgError{Code: "40P01"}
+	opts := []TxOption{WithRetry(3), WithRetryBackoff(time.Millisecond, 2*time.Millisecond)}
+
+	b := &fakeBeginner{commitErrs: []error{serialization}}
+	calls := 0
+	err := runInTx(context.Background(), b.begin, func(context.Context) error {
+		calls++
+		if calls == 2 {
+			return deadlock
+		}
+		return nil
+	}, opts)
+	if err != nil {
+		t.Fatalf("runInTx() error = %v", err)
+	}
+	if calls != 3 || len(b.txs) != 3 || !b.txs[2].committed {
+		t.Fatalf("Expected success on the third attempt, got %d calls", calls)
+	}
+
+	b = &fakeBeginner{}
+	calls = 0
+	err = runInTx(context.Background(), b.begin, func(context.Context) error {
+		calls++
+		return serialization
+	}, opts)
+	if !errors.Is(err, serialization) || calls != 3 {
+		t.Fatalf("Expected the error after 3 attempts, got %v after %d", err, calls)
+	}
+
+	calls = 0
+	err = runInTx(context.Background(), b.begin, func(context.Context) error {
+		calls++
+		return &pgconn.PgError{Code: "23505"}
+	}, opts)
+	if err == nil || calls != 1 {
+		t.Fatalf("Expected no retry for other errors, got %d calls", calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = runInTx(ctx, b.begin, func(context.Context) error { return serialization }, opts)
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, serialization) {
+		t.Fatalf("Expected cancellation during backoff, got %v", err)
+	}
+}
+
+func TestTxBackoff(t *testing.T) {
+	o := txOptions{initialBackoff: 10 * time.Millisecond, maxBackoff: 50 * time.Millisecond}
+	for attempt, want := range map[int]time.Duration{1: 10 * time.Millisecond, 3: 40 * time.Millisecond, 10: 50 * time.Millisecond} {
+		got := txBackoff(o, attempt)
+		if got < want*9/10 || got > want*11/10 {
+			t.Errorf("txBackoff(%d) = %v, want %v ±10%%", attempt, got, want)
+		}
+	}
+}
+
+func TestRunInTxNestsSavepoints(t *testing.T) {
+	errInner := errors.New("inner failed")
+	b := &fakeBeginner{}
+	err := runInTx(context.Background(), b.begin, func(ctx context.Context) error {
+		outer, _ := TxFromContext(ctx)
+		err := runInTx(ctx, b.begin, func(ctx context.Context) error {
+			if tx, _ := TxFromContext(ctx); tx == outer {
+				t.Error("Expected a savepoint in the nested context")
+			}
+			return errInner
+		}, []TxOption{WithRetry(3)})
+		if !errors.Is(err, errInner) {
+			t.Errorf("Expected the inner error, got %v", err)
+		}
+		return runInTx(ctx, b.begin, func(context.Context) error { return nil }, nil)
+	}, nil)
+	if err != nil {
+		t.Fatalf("runInTx() error = %v", err)
+	}
+
+	if len(b.txs) != 1 {
+		t.Fatalf("Expected one transaction, got %d", len(b.txs))
+	}
+	tx := b.txs[0]
+	if !tx.committed || len(tx.savepoints) != 2 {
+		t.Fatalf("Expected a committed transaction with two savepoints, got %+v", tx)
+	}
+	if sp := tx.savepoints[0]; !sp.rolledBack || sp.committed {
+		t.Errorf("Expected the failed savepoint to be rolled back, got %+v", sp)
+	}
+	if sp := tx.savepoints[1]; !sp.committed || sp.rolledBack {
+		t.Errorf("Expected the second savepoint to be released, got %+v", sp)
+	}
+}
+
+func TestContextWithTx(t *testing.T) {
+	outer := &fakeTx{}
+	ctx := ContextWithTx(context.Background(), outer)
+
+	var db *DB // only the transaction of ctx is used
+	if got := db.GetDBTX(ctx); got != outer {
+		t.Errorf("Expected GetDBTX to return the context transaction, got %v", got)
+	}
+	err := RunInTx(ctx, db, func(ctx context.Context) error {
+		if tx, _ := TxFromContext(ctx); tx != outer.savepoints[0] {
+			t.Error("Expected a savepoint of the context transaction")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunInTx() error = %v", err)
+	}
+	if len(outer.savepoints) != 1 || !outer.savepoints[0].committed || outer.committed {
+		t.Errorf("Expected a released savepoint and an open outer transaction, got %+v", outer)
+	}
+}
@@ -0,0 +1,183 @@
+package kpgx
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	defaultUpsertBatchSize = 1000
+	// maxQueryParams is the number of parameters PostgreSQL accepts per query.
+	maxQueryParams = 65535
+)
+
+type upsertOptions struct {
+	batchSize int
+}
+
+// UpsertOption configures Upsert.
+type UpsertOption func(*upsertOptions)
+
+// WithUpsertBatchSize sets the number of rows per INSERT statement. It is
+// lowered as needed to stay within the 65535 parameters of a query.
+// Default: 1000.
+func WithUpsertBatchSize(n int) UpsertOption {
+	return func(o *upsertOptions) {
+		if n > 0 {
+			o.batchSize = n
+		}
+	}
+}
+
+// Upsert inserts rows into table, updating the rows that conflict on
+// conflictCols with INSERT ... ON CONFLICT (conflictCols) DO UPDATE, and
+// returns the number of rows inserted or updated. T is mapped to columns like
+// CopyFromStructs. Only updateCols are updated; when updateCols is empty, all
+// columns except conflictCols are, and when no columns remain, conflicting
+// rows are skipped with DO NOTHING.
+//
+//	n, err := kpgx.Upsert(ctx, db, "app.users", users, []string{"id"}, []string{"email", "updated_at"})
+//
+// Rows are sent in batches of multi-row INSERT statements, all in one
+// transaction (a savepoint inside RunInTx). PostgreSQL rejects a statement
+// that updates the same row twice, so rows must not repeat a conflict key
+// within a batch.
+func Upsert[T any](ctx context.Context, db *DB, table string, rows []T, conflictCols, updateCols []string, opts ...UpsertOption) (int64, error) {
+	o := upsertOptions{batchSize: defaultUpsertBatchSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	var n int64
+	err := RunInTx(ctx, db, func(ctx context.Context) error {
+		var err error
+		n, err = upsert(ctx, db.GetDBTX(ctx), table, rows, conflictCols, updateCols, o.batchSize)
+		return err
+	})
+	return n, err
+}
+
+// execer is implemented by DBTX.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+func upsert[T any](ctx context.Context, q execer, table string, rows []T, conflictCols, updateCols []string, batchSize int) (int64, error) {
+	columns, err := structColumns(rowType[T]())
+	if err != nil {
+		return 0, fmt.Errorf("failed to map rows: %w", err)
+	}
+	names := columnNames(columns)
+	update, err := upsertUpdateColumns(names, conflictCols, updateCols)
+	if err != nil {
+		return 0, err
+	}
+	batchSize = min(batchSize, maxQueryParams/len(columns))
+
+	var total int64
+	for start := 0; start < len(rows); start += batchSize {
+		batch := rows[start:min(start+batchSize, len(rows))]
+		args := make([]any, 0, len(batch)*len(columns))
+		for i, row := range batch {
+			values, err := structValues(reflect.ValueOf(row), columns)
+			if err != nil {
+				return total, fmt.Errorf("row %d: %w", start+i, err)
+			}
+			args = append(args, values...)
+		}
+
+		tag, err := q.Exec(ctx, upsertSQL(table, names, conflictCols, update, len(batch)), args...)
+		if err != nil {
+			return total, fmt.Errorf("failed to upsert into %s: %w", table, err)
+		}
+		total += tag.RowsAffected()
+	}
+	return total, nil
+}
+
+// upsertUpdateColumns checks the conflict and update columns against the
+// struct columns and returns the columns to update.
+func upsertUpdateColumns(columns, conflictCols, updateCols []string) ([]string, error) {
+	if len(conflictCols) == 0 {
+		return nil, fmt.Errorf("upsert requires conflict columns")
+	}
+	for _, c := range slices.Concat(conflictCols, updateCols) {
+		if !slices.Contains(columns, c) {
+			return nil, fmt.Errorf("upsert column %q is not a column of the rows", c)
+		}
+	}
+	if len(updateCols) > 0 {
+		return updateCols, nil
+	}
+
+	var update []string
+	for _, c := range columns {
+		if !slices.Contains(conflictCols, c) {
+			update = append(update, c)
+		}
+	}
+	return update, nil
+}
+
+func upsertSQL(table string, columns, conflictCols, updateCols []string, rows int) string {
+	var b strings.Builder
+	b.WriteString("INSERT INTO ")
+	b.WriteString(tableIdentifier(table).Sanitize())
+	b.WriteString(" (")
+	writeIdentifiers(&b, columns)
+	b.WriteString(") VALUES ")
+
+	param := 1
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for j := range columns {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(param))
+			param++
+		}
+		b.WriteByte(')')
+	}
+
+	b.WriteString(" ON CONFLICT (")
+	writeIdentifiers(&b, conflictCols)
+	if len(updateCols) == 0 {
+		b.WriteString(") DO NOTHING")
+		return b.String()
+	}
+	b.WriteString(") DO UPDATE SET ")
+	for i, c := range updateCols {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		ident := pgx.Identifier{c}.Sanitize()
+		b.WriteString(ident)
+		b.WriteString(" = EXCLUDED.")
+		b.WriteString(ident)
+	}
+	return b.String()
+}
+
+func writeIdentifiers(b *strings.Builder, names []string) {
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(pgx.Identifier{name}.Sanitize())
+	}
+}
@@ -0,0 +1,98 @@
+package kpgx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type upsertUser struct {
+	ID    int64  `db:"id"`
+	Email string `db:"email"`
+	Name  string
+}
+
+type recordingExecer struct {
+	sqls []string
+	args [][]any
+	err  error
+}
+
+func (e *recordingExecer) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if e.err != nil {
+		return pgconn.CommandTag{}, e.err
+	}
+	e.sqls = append(e.sqls, sql)
+	e.args = append(e.args, args)
+	// Three columns per row.
+	return pgconn.NewCommandTag("INSERT 0 " + strconv.Itoa(len(args)/3)), nil
+}
+
+func TestUpsertSQL(t *testing.T) {
+	got := upsertSQL("app.users", []string{"id", "email", "name"}, []string{"id"}, []string{"email", "name"}, 2)
+	want := `INSERT INTO "app"."users" ("id", "email", "name") VALUES ($1, $2, $3), ($4, $5, $6)` +
+		` ON CONFLICT ("id") DO UPDATE SET "email" = EXCLUDED."email", "name" = EXCLUDED."name"`
+	if got != want {
+		t.Errorf("upsertSQL() =\n%s\nwant\n%s", got, want)
+	}
+
+	got = upsertSQL("users", []string{"id"}, []string{"id"}, nil, 1)
+	if want := `INSERT INTO "users" ("id") VALUES ($1) ON CONFLICT ("id") DO NOTHING`; got != want {
+		t.Errorf("upsertSQL() = %s, want %s", got, want)
+	}
+}
+
+func TestUpsertBatches(t *testing.T) {
+	rows := []upsertUser{{1, "a@x", "A"}, {2, "b@x", "B"}, {3, "c@x", "C"}}
+	e := &recordingExecer{}
+
+	n, err := upsert(context.Background(), e, "users", rows, []string{"id"}, nil, 2)
+	if err != nil {
+		t.Fatalf("upsert() error = %v", err)
+	}
+	if n != 3 {
+		t.Errorf("Expected 3 rows affected, got %d", n)
+	}
+	if len(e.sqls) != 2 {
+		t.Fatalf("Expected 2 statements, got %d", len(e.sqls))
+	}
+	if !strings.Contains(e.sqls[0], `VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT ("id") DO UPDATE SET "email" = EXCLUDED."email", "name" = EXCLUDED."name"`) {
+		t.Errorf("Unexpected first statement %s", e.sqls[0])
+	}
+	if !reflect.DeepEqual(e.args[1], []any{int64(3), "c@x", "C"}) {
+		t.Errorf("Unexpected second batch args %v", e.args[1])
+	}
+}
+
+func TestUpsertLimitsParameters(t *testing.T) {
+	rows := make([]upsertUser, maxQueryParams/3+1)
+	e := &recordingExecer{}
+	if _, err := upsert(context.Background(), e, "users", rows, []string{"id"}, nil, len(rows)); err != nil {
+		t.Fatalf("upsert() error = %v", err)
+	}
+	if len(e.sqls) != 2 || len(e.args[0]) > maxQueryParams {
+		t.Errorf("Expected 2 batches within the parameter limit, got %d", len(e.sqls))
+	}
+}
+
+func TestUpsertErrors(t *testing.T) {
+	rows := []upsertUser{{ID: 1}}
+	ctx := context.Background()
+
+	if _, err := upsert(ctx, &recordingExecer{}, "users", rows, nil, nil, 10); err == nil {
+		t.Error("Expected error without conflict columns")
+	}
+	if _, err := upsert(ctx, &recordingExecer{}, "users", rows, []string{"id"}, []string{"missing"}, 10); err == nil {
+		t.Error("Expected error for an unknown update column")
+	}
+
+	errFailed := errors.New("failed")
+	if _, err := upsert(ctx, &recordingExecer{err: errFailed}, "users", rows, []string{"id"}, nil, 10); !errors.Is(err, errFailed) {
+		t.Errorf("Expected the exec error, got %v", err)
+	}
+}